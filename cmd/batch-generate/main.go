@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/batch"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// 批量生成 CLI：上传 CSV / JSON 商品列表到 API，轮询进度，结束后可下载合并导出
+func main() {
+	server := flag.String("server", envOr("BATCH_API_URL", "http://localhost:4000"), "API base URL (env BATCH_API_URL)")
+	apiKey := flag.String("api-key", os.Getenv("BATCH_API_KEY"), "API key with creative:read/write scopes (env BATCH_API_KEY)")
+	project := flag.String("project", "", "Project ID (X-Project-ID); empty for the key's project or personal space")
+	name := flag.String("name", "", "Batch name")
+	autoCopy := flag.Bool("auto-copywriting", false, "Generate copywriting with the LLM before creating images")
+	batchID := flag.String("batch", "", "Resume watching an existing batch instead of uploading a file")
+	wait := flag.Bool("wait", true, "Poll until the batch finishes")
+	interval := flag.Duration("interval", 5*time.Second, "Poll interval")
+	out := flag.String("out", "", "Download the combined ZIP export to this path when the batch finishes")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: batch-generate [flags] products.csv|products.json\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	c := &client{base: strings.TrimRight(*server, "/") + "/api/v1", apiKey: *apiKey, project: *project, http: &http.Client{Timeout: 5 * time.Minute}}
+	id := *batchID
+	if id == "" {
+		if flag.NArg() != 1 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		created, err := c.create(flag.Arg(0), *name, *autoCopy)
+		if err != nil {
+			log.Fatalf("✗ Create batch failed: %v", err)
+		}
+		id = created.BatchID
+		fmt.Printf("✓ Batch %s created with %d items\n", id, created.TotalItems)
+	}
+	if !*wait && *out == "" {
+		return
+	}
+
+	var p *batch.Progress
+	for {
+		var err error
+		if p, err = c.progress(id); err != nil {
+			log.Fatalf("✗ Get batch failed: %v", err)
+		}
+		n := p.Counts
+		fmt.Printf("  %s %3d%%  completed %d  running %d  pending %d  failed %d  cost %.4f\n",
+			p.Batch.Status, p.Percent, n.Completed, n.Running, n.Pending, n.Failed, p.Cost)
+		if p.Done() {
+			break
+		}
+		time.Sleep(*interval)
+	}
+
+	fmt.Printf("✓ Batch %s %s\n", id, p.Batch.Status)
+	for _, f := range p.Failures {
+		fmt.Printf("  ✗ row %d %s: %s\n", f.Row, f.Title, f.Error)
+	}
+	if *out != "" && p.Counts.Completed > 0 {
+		if err := c.export(id, *out); err != nil {
+			log.Fatalf("✗ Export failed: %v", err)
+		}
+		fmt.Printf("✓ Export saved to %s\n", *out)
+	}
+	if p.Counts.Failed > 0 {
+		os.Exit(2)
+	}
+}
+
+type client struct {
+	base    string
+	apiKey  string
+	project string
+	http    *http.Client
+}
+
+func (c *client) create(path, name string, autoCopy bool) (*batch.BatchData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(fw, f); err != nil {
+		return nil, err
+	}
+	if name != "" {
+		_ = mw.WriteField("name", name)
+	}
+	if autoCopy {
+		_ = mw.WriteField("auto_copywriting", strconv.FormatBool(autoCopy))
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := c.request(http.MethodPost, "/batches", &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	var data batch.BatchData
+	return &data, c.do(req, &data)
+}
+
+func (c *client) progress(id string) (*batch.Progress, error) {
+	req, err := c.request(http.MethodGet, "/batches/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var p batch.Progress
+	return &p, c.do(req, &p)
+}
+
+func (c *client) export(id, path string) error {
+	req, err := c.request(http.MethodGet, "/batches/"+id+"/export", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *client) request(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.project != "" {
+		req.Header.Set("X-Project-ID", c.project)
+	}
+	return req, nil
+}
+
+// do 发送请求并解出统一响应中的 data
+func (c *client) do(req *http.Request, data interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+	envelope := shared.GenerateResponse{Data: data}
+	return json.NewDecoder(resp.Body).Decode(&envelope)
+}
+
+func decodeError(resp *http.Response) error {
+	var envelope shared.GenerateResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(raw, &envelope); err == nil && envelope.Message != "" {
+		return fmt.Errorf("%s: %s", resp.Status, envelope.Message)
+	}
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(raw)))
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
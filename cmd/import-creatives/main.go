@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ads-creative-gen-platform/config"
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/pkg/database"
+
+	"github.com/joho/godotenv"
+)
+
+// manifest 导入清单：items[].file 为本地路径（相对清单所在目录），或 items[].url
+type manifest struct {
+	Title       string         `json:"title"`
+	ProductName string         `json:"product_name"`
+	Items       []manifestItem `json:"items"`
+}
+
+type manifestItem struct {
+	File string `json:"file"`
+	creative.ImportItem
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	manifestPath := flag.String("manifest", "", "JSON manifest with items (file/url + metadata)")
+	title := flag.String("title", "", "Task title")
+	product := flag.String("product", "", "Product name")
+	cta := flag.String("cta", "", "CTA text applied to every positional file/url")
+	sellingPoints := flag.String("selling-points", "", "Comma separated selling points applied to every positional file/url")
+	format := flag.String("format", "", "Format (e.g. 1:1); derived from image size when empty")
+	userID := flag.Uint("user", 1, "Owner user ID")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: import-creatives [flags] [file|url ...]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	input := creative.ImportInput{UserID: *userID, Title: *title, ProductName: *product}
+	if *manifestPath != "" {
+		m, err := loadManifest(*manifestPath)
+		if err != nil {
+			log.Fatalf("✗ %v", err)
+		}
+		if input.Title == "" {
+			input.Title = m.Title
+		}
+		if input.ProductName == "" {
+			input.ProductName = m.ProductName
+		}
+		for _, item := range m.Items {
+			input.Items = append(input.Items, item.ImportItem)
+		}
+	}
+
+	var points []string
+	for _, p := range strings.Split(*sellingPoints, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			points = append(points, p)
+		}
+	}
+	for _, arg := range flag.Args() {
+		item := creative.ImportItem{CTAText: *cta, SellingPoints: points, Format: *format, ProductName: *product}
+		if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+			item.SourceURL = arg
+		} else {
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				log.Fatalf("✗ read %s: %v", arg, err)
+			}
+			item.FileName = filepath.Base(arg)
+			item.Data = data
+		}
+		input.Items = append(input.Items, item)
+	}
+
+	if len(input.Items) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	config.LoadConfig()
+	database.InitDatabase()
+	defer database.CloseDB()
+
+	svc := creative.NewCreativeService(storage.NewConfiguredRegistry(config.StorageConfig))
+	task, assets, err := svc.ImportCreatives(context.Background(), input)
+	if err != nil {
+		log.Fatalf("✗ Import failed: %v", err)
+	}
+
+	fmt.Printf("✓ Imported %d/%d creatives into task %s (%s)\n", len(assets), len(input.Items), task.UUID, task.Status)
+	for _, a := range assets {
+		note := ""
+		if a.Quarantined {
+			note = " [quarantined: " + a.ModerationReason + "]"
+		}
+		fmt.Printf("  - %s %s %dx%d %s%s\n", a.UUID, a.Format, a.Width, a.Height, a.PublicURL, note)
+	}
+}
+
+func loadManifest(path string) (*manifest, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	base := filepath.Dir(path)
+	for i := range m.Items {
+		item := &m.Items[i]
+		if item.File == "" {
+			continue
+		}
+		p := item.File
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(base, p)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", item.File, err)
+		}
+		item.Data = data
+		if item.FileName == "" {
+			item.FileName = filepath.Base(p)
+		}
+	}
+	return &m, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"ads-creative-gen-platform/internal/infra/moderation"
+)
+
+// 本地审核 stub：实现 HTTPModerator 的协议，便于开发/测试时替代外部审核服务。
+// 文本走规则审核；图片 URL 中包含 -unsafe-words 中任一关键字时判定为不安全。
+func main() {
+	addr := flag.String("addr", ":4100", "listen address")
+	unsafeWords := flag.String("unsafe-words", "nsfw,unsafe", "comma separated keywords that mark an image URL unsafe")
+	flag.Parse()
+
+	rule := moderation.NewRuleModerator(nil)
+	var imageKeywords []string
+	for _, w := range strings.Split(*unsafeWords, ",") {
+		if w = strings.TrimSpace(strings.ToLower(w)); w != "" {
+			imageKeywords = append(imageKeywords, w)
+		}
+	}
+
+	http.HandleFunc("/moderate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Type    string `json:"type"`
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result := moderation.Result{Safe: true}
+		switch req.Type {
+		case "text":
+			result, _ = rule.ModerateText(r.Context(), req.Content)
+		case "image":
+			lower := strings.ToLower(req.Content)
+			for _, kw := range imageKeywords {
+				if strings.Contains(lower, kw) {
+					result = moderation.Result{Safe: false, Score: 0.99, Labels: []string{kw}, Reason: "stub matched " + kw}
+					break
+				}
+			}
+		default:
+			http.Error(w, "unknown type: "+req.Type, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+
+	log.Printf("moderation stub listening on %s (POST /moderate)", *addr)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -13,11 +13,19 @@ import (
 
 // 全局配置对象
 var (
-	AppConfig      *App
-	DatabaseConfig *Database
-	TongyiConfig   *Tongyi
-	QiniuConfig    *Qiniu
-	CacheConfig    *Cache
+	AppConfig        *App
+	DatabaseConfig   *Database
+	TongyiConfig     *Tongyi
+	QiniuConfig      *Qiniu
+	CacheConfig      *Cache
+	ModerationConfig *Moderation
+	StorageConfig    *Storage
+	ReuploadConfig   *Reupload
+	UploadConfig     *Upload
+	AuthConfig       *Auth
+	AuditConfig      *Audit
+	QuotaConfig      *Quota
+	CostConfig       *Cost
 )
 
 // App 服务配置
@@ -42,6 +50,8 @@ type Tongyi struct {
 	APIKey     string
 	ImageModel string
 	LLMModel   string
+	// ImageModels 项目可选的图片生成模型，始终包含 ImageModel
+	ImageModels []string
 }
 
 // Qiniu 七牛云配置
@@ -65,6 +75,113 @@ type Cache struct {
 	DisableTracing    bool
 }
 
+// Storage 存储后端配置
+type Storage struct {
+	Backend string // qiniu / s3 / minio / local
+
+	LocalDir       string
+	LocalURLPrefix string // gin 托管本地文件的路由前缀
+	LocalPublicURL string // 对外访问前缀（为空时使用 LocalURLPrefix）
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
+	S3PublicURL string
+	S3BasePath  string
+
+	// 存储 GC：清理不再被素材引用的对象
+	GCEnabled     bool
+	GCInterval    time.Duration
+	GCGracePeriod time.Duration
+	GCDryRun      bool
+}
+
+// Reupload 临时 URL 补传任务配置
+type Reupload struct {
+	Enabled         bool
+	Interval        time.Duration
+	BatchSize       int
+	MaxAttempts     int
+	BaseBackoff     time.Duration
+	MaxBackoff      time.Duration
+	ProviderURLTTL  time.Duration // 服务商临时 URL 有效期
+	AlertBefore     time.Duration // 距过期多久仍未补传成功时告警
+	AlertWebhookURL string
+}
+
+// Upload 商品图上传限制
+type Upload struct {
+	MaxBytes           int64
+	MinDimension       int // 宽高下限
+	MaxSourceDimension int // 原图宽高上限，超出直接拒绝
+	MaxDimension       int // 归一化后最长边
+}
+
+// Auth 认证配置
+type Auth struct {
+	// Enabled 为 false 时所有请求以 AnonymousUserID 身份执行（兼容未接入登录的部署）
+	Enabled         bool
+	AnonymousUserID uint
+	JWTSecret       string
+	Issuer          string
+	AccessTTL       time.Duration
+	RefreshTTL      time.Duration
+	BcryptCost      int
+	MinPasswordLen  int
+}
+
+// Audit 审计日志配置
+type Audit struct {
+	// Retention 日志保留时长，<=0 表示永久保留
+	Retention time.Duration
+	// PurgeInterval 过期日志清理间隔
+	PurgeInterval time.Duration
+}
+
+// QuotaLimits 单个周期内的用量上限，0 表示不限
+type QuotaLimits struct {
+	Tasks    int64
+	Images   int64
+	LLMCalls int64
+}
+
+// Quota 配额与限流配置
+type Quota struct {
+	UserDaily      QuotaLimits
+	UserMonthly    QuotaLimits
+	ProjectDaily   QuotaLimits
+	ProjectMonthly QuotaLimits
+	// RateLimitRPS 每个调用方（API Key / 用户 / IP）的稳定请求速率，<=0 表示不限流
+	RateLimitRPS   float64
+	RateLimitBurst int
+}
+
+// ModelPrice 模型单价：每张图片，以及每千个输入 / 输出 token
+type ModelPrice struct {
+	Image        float64
+	InputTokens  float64
+	OutputTokens float64
+}
+
+// Cost 成本核算配置
+type Cost struct {
+	Currency string
+	// Prices 按模型名的价格表，未列出的模型按 0 计费
+	Prices map[string]ModelPrice
+}
+
+// Moderation 内容安全审核配置
+type Moderation struct {
+	Provider      string // rule / http / none
+	Endpoint      string
+	Timeout       time.Duration
+	NSFWThreshold float64
+	BlockedWords  []string
+}
+
 // LoadConfig 加载所有配置
 func LoadConfig() {
 	// 加载 .env 文件
@@ -78,6 +195,14 @@ func LoadConfig() {
 	loadTongyiConfig()
 	loadQiniuConfig()
 	loadCacheConfig()
+	loadModerationConfig()
+	loadStorageConfig()
+	loadReuploadConfig()
+	loadUploadConfig()
+	loadAuthConfig()
+	loadAuditConfig()
+	loadQuotaConfig()
+	loadCostConfig()
 
 	log.Println("✓ All configurations loaded successfully")
 }
@@ -133,6 +258,7 @@ func loadTongyiConfig() {
 		ImageModel: getEnv("TONGYI_IMAGE_MODEL", "wanx-v1"),
 		LLMModel:   getEnv("TONGYI_LLM_MODEL", "qwen-turbo"),
 	}
+	TongyiConfig.ImageModels = append([]string{TongyiConfig.ImageModel}, parseList("TONGYI_IMAGE_MODELS")...)
 
 	if TongyiConfig.APIKey == "" {
 		log.Fatal("✗ TONGYI_API_KEY is required in environment variables")
@@ -188,6 +314,183 @@ func loadCacheConfig() {
 	log.Printf("✓ Cache config loaded (enabled=%v, max_entries=%d, default_ttl=%s)", CacheConfig.Enabled, CacheConfig.MaxEntries, CacheConfig.DefaultTTL)
 }
 
+// loadModerationConfig 加载内容安全审核配置
+func loadModerationConfig() {
+	timeoutSeconds := parseInt("MODERATION_TIMEOUT_SECONDS", 10)
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 10
+	}
+	ModerationConfig = &Moderation{
+		Provider:      strings.ToLower(getEnv("MODERATION_PROVIDER", "rule")),
+		Endpoint:      getEnv("MODERATION_ENDPOINT", ""),
+		Timeout:       time.Duration(timeoutSeconds) * time.Second,
+		NSFWThreshold: parseFloat("MODERATION_NSFW_THRESHOLD", 0.7),
+		BlockedWords:  parseList("MODERATION_BLOCKED_WORDS"),
+	}
+	if ModerationConfig.Provider == "http" && ModerationConfig.Endpoint == "" {
+		log.Println("⚠ MODERATION_PROVIDER=http but MODERATION_ENDPOINT is empty, falling back to rule moderator")
+		ModerationConfig.Provider = "rule"
+	}
+	log.Printf("✓ Moderation config loaded (provider=%s, nsfw_threshold=%.2f)", ModerationConfig.Provider, ModerationConfig.NSFWThreshold)
+}
+
+// loadStorageConfig 加载存储后端配置（需在 loadQiniuConfig 之后调用）
+func loadStorageConfig() {
+	defaultBackend := "local"
+	if QiniuConfig != nil && QiniuConfig.AccessKey != "" && QiniuConfig.SecretKey != "" {
+		defaultBackend = "qiniu"
+	}
+
+	StorageConfig = &Storage{
+		Backend:        strings.ToLower(getEnv("STORAGE_BACKEND", defaultBackend)),
+		LocalDir:       getEnv("STORAGE_LOCAL_DIR", "./data/uploads"),
+		LocalURLPrefix: getEnv("STORAGE_LOCAL_URL_PREFIX", "/files"),
+		LocalPublicURL: getEnv("STORAGE_LOCAL_PUBLIC_URL", ""),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:       getEnv("S3_BUCKET", "ads-creative-gen-platform"),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:    parseBool("S3_PATH_STYLE", true),
+		S3PublicURL:    getEnv("S3_PUBLIC_URL", ""),
+		S3BasePath:     getEnv("S3_BASE_PATH", "creatives/"),
+		GCEnabled:      parseBool("STORAGE_GC_ENABLED", true),
+		GCInterval:     parseDuration("STORAGE_GC_INTERVAL", 24*time.Hour),
+		GCGracePeriod:  parseDuration("STORAGE_GC_GRACE_PERIOD", 72*time.Hour),
+		GCDryRun:       parseBool("STORAGE_GC_DRY_RUN", true),
+	}
+
+	if (StorageConfig.Backend == "s3" || StorageConfig.Backend == "minio") &&
+		(StorageConfig.S3Endpoint == "" || StorageConfig.S3AccessKey == "" || StorageConfig.S3SecretKey == "") {
+		log.Printf("⚠ STORAGE_BACKEND=%s but S3_ENDPOINT/S3_ACCESS_KEY/S3_SECRET_KEY are incomplete, falling back to local", StorageConfig.Backend)
+		StorageConfig.Backend = "local"
+	}
+	if StorageConfig.Backend == "qiniu" && defaultBackend != "qiniu" {
+		log.Println("⚠ STORAGE_BACKEND=qiniu but Qiniu credentials are missing, falling back to local")
+		StorageConfig.Backend = "local"
+	}
+
+	log.Printf("✓ Storage config loaded (backend=%s)", StorageConfig.Backend)
+}
+
+// loadReuploadConfig 加载补传任务配置
+func loadReuploadConfig() {
+	ReuploadConfig = &Reupload{
+		Enabled:         parseBool("REUPLOAD_ENABLED", true),
+		Interval:        parseDuration("REUPLOAD_INTERVAL", 5*time.Minute),
+		BatchSize:       parseInt("REUPLOAD_BATCH_SIZE", 20),
+		MaxAttempts:     parseInt("REUPLOAD_MAX_ATTEMPTS", 8),
+		BaseBackoff:     parseDuration("REUPLOAD_BASE_BACKOFF", time.Minute),
+		MaxBackoff:      parseDuration("REUPLOAD_MAX_BACKOFF", time.Hour),
+		ProviderURLTTL:  parseDuration("PROVIDER_URL_TTL", 24*time.Hour),
+		AlertBefore:     parseDuration("REUPLOAD_ALERT_BEFORE", 2*time.Hour),
+		AlertWebhookURL: getEnv("ALERT_WEBHOOK_URL", ""),
+	}
+	log.Printf("✓ Reupload config loaded (enabled=%v, interval=%s, max_attempts=%d)", ReuploadConfig.Enabled, ReuploadConfig.Interval, ReuploadConfig.MaxAttempts)
+}
+
+// loadUploadConfig 加载上传限制
+func loadUploadConfig() {
+	UploadConfig = &Upload{
+		MaxBytes:           int64(parseInt("UPLOAD_MAX_BYTES", 10<<20)),
+		MinDimension:       parseInt("UPLOAD_MIN_DIMENSION", 64),
+		MaxSourceDimension: parseInt("UPLOAD_MAX_SOURCE_DIMENSION", 8000),
+		MaxDimension:       parseInt("UPLOAD_MAX_DIMENSION", 2048),
+	}
+}
+
+// loadAuthConfig 加载认证配置
+func loadAuthConfig() {
+	AuthConfig = &Auth{
+		Enabled:         parseBool("AUTH_ENABLED", true),
+		AnonymousUserID: uint(parseInt("AUTH_ANONYMOUS_USER_ID", 1)),
+		JWTSecret:       getEnv("AUTH_JWT_SECRET", ""),
+		Issuer:          getEnv("AUTH_JWT_ISSUER", "ads-creative-platform"),
+		AccessTTL:       parseDuration("AUTH_ACCESS_TTL", 15*time.Minute),
+		RefreshTTL:      parseDuration("AUTH_REFRESH_TTL", 30*24*time.Hour),
+		BcryptCost:      parseInt("AUTH_BCRYPT_COST", 10),
+		MinPasswordLen:  parseInt("AUTH_MIN_PASSWORD_LENGTH", 8),
+	}
+	if AuthConfig.Enabled && AuthConfig.JWTSecret == "" {
+		log.Println("⚠ AUTH_JWT_SECRET is not set, using a random secret: tokens will not survive restarts")
+	}
+	log.Printf("✓ Auth config loaded (enabled=%v, access_ttl=%s, refresh_ttl=%s)", AuthConfig.Enabled, AuthConfig.AccessTTL, AuthConfig.RefreshTTL)
+}
+
+// loadAuditConfig 加载审计日志配置
+func loadAuditConfig() {
+	AuditConfig = &Audit{
+		Retention:     time.Duration(parseInt("AUDIT_RETENTION_DAYS", 180)) * 24 * time.Hour,
+		PurgeInterval: parseDuration("AUDIT_PURGE_INTERVAL", 6*time.Hour),
+	}
+	log.Printf("✓ Audit config loaded (retention=%s)", AuditConfig.Retention)
+}
+
+// loadQuotaConfig 加载配额与限流配置
+func loadQuotaConfig() {
+	QuotaConfig = &Quota{
+		UserDaily:      parseQuotaLimits("QUOTA_USER_DAILY", QuotaLimits{Tasks: 200, Images: 800, LLMCalls: 500}),
+		UserMonthly:    parseQuotaLimits("QUOTA_USER_MONTHLY", QuotaLimits{}),
+		ProjectDaily:   parseQuotaLimits("QUOTA_PROJECT_DAILY", QuotaLimits{}),
+		ProjectMonthly: parseQuotaLimits("QUOTA_PROJECT_MONTHLY", QuotaLimits{}),
+		RateLimitRPS:   parseFloat("RATE_LIMIT_RPS", 5),
+		RateLimitBurst: parseInt("RATE_LIMIT_BURST", 20),
+	}
+	log.Printf("✓ Quota config loaded (rate_limit=%.1f/s burst=%d)", QuotaConfig.RateLimitRPS, QuotaConfig.RateLimitBurst)
+}
+
+// parseQuotaLimits 读取 <prefix>_TASKS / _IMAGES / _LLM_CALLS
+func parseQuotaLimits(prefix string, def QuotaLimits) QuotaLimits {
+	return QuotaLimits{
+		Tasks:    int64(parseInt(prefix+"_TASKS", int(def.Tasks))),
+		Images:   int64(parseInt(prefix+"_IMAGES", int(def.Images))),
+		LLMCalls: int64(parseInt(prefix+"_LLM_CALLS", int(def.LLMCalls))),
+	}
+}
+
+// loadCostConfig 加载成本核算配置
+func loadCostConfig() {
+	CostConfig = &Cost{
+		Currency: getEnv("COST_CURRENCY", "CNY"),
+		Prices:   ParseModelPrices(getEnv("COST_MODEL_PRICES", "wanx-v1=image:0.16;qwen-turbo=input:0.0003,output:0.0006")),
+	}
+	log.Printf("✓ Cost config loaded (%d model prices, currency=%s)", len(CostConfig.Prices), CostConfig.Currency)
+}
+
+// ParseModelPrices 解析价格表：model=image:0.16;model2=input:0.0008,output:0.002，格式错误的项忽略
+func ParseModelPrices(raw string) map[string]ModelPrice {
+	prices := make(map[string]ModelPrice)
+	for _, entry := range strings.Split(raw, ";") {
+		model, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		model = strings.TrimSpace(model)
+		if !ok || model == "" {
+			continue
+		}
+		var price ModelPrice
+		for _, part := range strings.Split(spec, ",") {
+			key, val, ok := strings.Cut(strings.TrimSpace(part), ":")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil {
+				log.Printf("Warning: invalid price %q for model %s", part, model)
+				continue
+			}
+			switch strings.TrimSpace(key) {
+			case "image":
+				price.Image = v
+			case "input":
+				price.InputTokens = v
+			case "output":
+				price.OutputTokens = v
+			}
+		}
+		prices[model] = price
+	}
+	return prices
+}
+
 // GetDatabaseDSN 返回数据库 DSN 连接字符串
 func GetDatabaseDSN() string {
 	if DatabaseConfig.Db == "postgres" {
@@ -237,3 +540,40 @@ func parseInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+func parseFloat(key string, defaultVal float64) float64 {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return defaultVal
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+	return defaultVal
+}
+
+// parseList 解析逗号分隔的环境变量
+func parseList(key string) []string {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return nil
+	}
+	var out []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func parseDuration(key string, defaultVal time.Duration) time.Duration {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return defaultVal
+	}
+	if d, err := time.ParseDuration(val); err == nil {
+		return d
+	}
+	return defaultVal
+}
@@ -0,0 +1,105 @@
+package adexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestMapCTA(t *testing.T) {
+	cases := map[string]string{
+		"立即购买":       "SHOP_NOW",
+		"Shop today": "SHOP_NOW",
+		"马上下载":       "DOWNLOAD",
+		"了解更多":       "LEARN_MORE",
+		"注册领取":       "SIGN_UP",
+	}
+	for in, want := range cases {
+		if got, ok := mapCTA(in); !ok || got != want {
+			t.Fatalf("mapCTA(%q) = %s,%v want %s", in, got, ok, want)
+		}
+	}
+	if got, ok := mapCTA("来看看"); ok || got != "LEARN_MORE" {
+		t.Fatalf("unexpected fallback %s,%v", got, ok)
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	if n := textLength("轻薄ab", true); n != 6 {
+		t.Fatalf("wide length = %d", n)
+	}
+	if n := textLength("轻薄ab", false); n != 4 {
+		t.Fatalf("rune length = %d", n)
+	}
+	if s := joinWithin(" ", 13, true, "轻薄", "便携电脑", "ab"); s != "轻薄 便携电脑" {
+		t.Fatalf("joinWithin = %q", s)
+	}
+}
+
+func TestGoogleValidate(t *testing.T) {
+	opts := Options{BusinessName: "Acme", FinalURL: "https://acme.test"}
+	ok := Creative{AssetID: "a", Width: 1024, Height: 1024, Headline: "Fast shoes", LongHeadline: "Fast shoes - light", Descriptions: []string{"light"}}
+	if issues := (GoogleAdsExporter{}).Validate(ok, opts); hasError(issues) {
+		t.Fatalf("unexpected errors: %+v", issues)
+	}
+
+	small := ok
+	small.Width, small.Height = 200, 200
+	if issues := (GoogleAdsExporter{}).Validate(small, opts); !hasError(issues) {
+		t.Fatal("expected size error")
+	}
+
+	tall := ok
+	tall.Width, tall.Height = 720, 1280
+	if issues := (GoogleAdsExporter{}).Validate(tall, opts); !hasError(issues) {
+		t.Fatal("expected aspect error for 9:16")
+	}
+	if issues := (MetaExporter{}).Validate(tall, opts); hasError(issues) {
+		t.Fatalf("9:16 should be accepted by meta: %+v", issues)
+	}
+
+	long := ok
+	long.Headline = strings.Repeat("轻", 16)
+	if issues := (GoogleAdsExporter{}).Validate(long, opts); !hasError(issues) {
+		t.Fatal("expected error for headline over the platform limit")
+	}
+
+	heavy := ok
+	heavy.FileSize = googleImageMaxBytes + 1
+	if issues := (GoogleAdsExporter{}).Validate(heavy, opts); !hasError(issues) {
+		t.Fatal("expected error for image over the byte limit")
+	}
+}
+
+func TestMetaWriteSheet(t *testing.T) {
+	var buf bytes.Buffer
+	creatives := []Creative{{AssetID: "abcdefgh-1", File: "1x1/v0_abcdefgh.jpg", Format: "1:1", Headline: "Shoes", Descriptions: []string{"light", "fast"}, CTAText: "立即购买"}}
+	if err := (MetaExporter{}).WriteSheet(&buf, creatives, Options{Campaign: "c", AdGroup: "g", FinalURL: "https://x"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("rows = %d", len(records))
+	}
+	row := map[string]string{}
+	for i, h := range records[0] {
+		row[h] = records[1][i]
+	}
+	if row["Call to Action"] != "SHOP_NOW" || row["Body"] != "light · fast" || row["Image File Name"] != "1x1/v0_abcdefgh.jpg" {
+		t.Fatalf("unexpected row: %v", row)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := DefaultRegistry()
+	if _, ok := r.Get("TikTok"); !ok {
+		t.Fatal("tiktok not registered")
+	}
+	if names := r.Names(); len(names) != 3 {
+		t.Fatalf("names = %v", names)
+	}
+}
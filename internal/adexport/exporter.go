@@ -0,0 +1,270 @@
+package adexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// Severity 校验问题级别：error 的素材不会写入表格，warning 仅提示
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue 平台规则校验问题
+type Issue struct {
+	AssetID  string   `json:"asset_id"`
+	Field    string   `json:"field"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Options 投放表格的公共参数
+type Options struct {
+	Campaign     string
+	AdGroup      string
+	FinalURL     string
+	BusinessName string
+}
+
+// Creative 平台无关的素材描述
+type Creative struct {
+	AssetID      string
+	File         string // ZIP 中的图片路径
+	ImageURL     string
+	Format       string
+	Width        int
+	Height       int
+	FileSize     int
+	Headline     string
+	LongHeadline string
+	Descriptions []string
+	CTAText      string
+	ProductName  string
+	VariantIndex *int
+}
+
+// Exporter 单个广告平台的导出器
+type Exporter interface {
+	// Name 平台标识，用于路由参数
+	Name() string
+	// SheetName 表格在 ZIP 中的文件名
+	SheetName() string
+	// Validate 按平台的尺寸与文案限制校验素材
+	Validate(c Creative, opts Options) []Issue
+	// WriteSheet 写出平台批量导入表格
+	WriteSheet(w io.Writer, creatives []Creative, opts Options) error
+}
+
+// Registry 已注册的导出器
+type Registry struct {
+	mu        sync.RWMutex
+	exporters map[string]Exporter
+}
+
+// NewRegistry 创建注册表
+func NewRegistry(exporters ...Exporter) *Registry {
+	r := &Registry{exporters: make(map[string]Exporter)}
+	for _, e := range exporters {
+		r.Register(e)
+	}
+	return r
+}
+
+// Register 注册（或覆盖）导出器
+func (r *Registry) Register(e Exporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exporters[e.Name()] = e
+}
+
+// Get 按名称获取导出器
+func (r *Registry) Get(name string) (Exporter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.exporters[strings.ToLower(name)]
+	return e, ok
+}
+
+// Names 返回已注册的平台名
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.exporters))
+	for name := range r.exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultRegistry 内置平台：Google Ads Editor、Meta Ads Manager、TikTok Ads
+func DefaultRegistry() *Registry {
+	return NewRegistry(GoogleAdsExporter{}, MetaExporter{}, TikTokExporter{})
+}
+
+// Plan 已完成校验、待写出的导出
+type Plan struct {
+	exporter  Exporter
+	opts      Options
+	export    *creative.TaskExport
+	creatives map[string]Creative
+	Issues    []Issue
+}
+
+// NewPlan 对任务素材做平台校验，剔除存在 error 的素材；全部不合格时返回错误。
+// 未记录文件大小的素材会读取对象计算字节数，以便校验平台大小上限
+func NewPlan(ctx context.Context, export *creative.TaskExport, exporter Exporter, opts Options) (*Plan, error) {
+	if opts.Campaign == "" {
+		opts.Campaign = export.Task.Title
+	}
+	if opts.AdGroup == "" {
+		opts.AdGroup = export.Task.Title
+	}
+	if opts.BusinessName == "" {
+		opts.BusinessName = export.Task.ProductName
+	}
+
+	p := &Plan{exporter: exporter, opts: opts, export: export, creatives: map[string]Creative{}}
+	var kept []models.CreativeAsset
+	for _, a := range export.Assets {
+		c := FromAsset(a)
+		size, err := export.ObjectSize(ctx, a)
+		if err != nil {
+			p.Issues = append(p.Issues, Issue{AssetID: a.UUID, Field: "image", Severity: SeverityError, Message: "read image failed: " + err.Error()})
+			continue
+		}
+		c.FileSize = size
+		issues := exporter.Validate(c, opts)
+		p.Issues = append(p.Issues, issues...)
+		if hasError(issues) {
+			continue
+		}
+		kept = append(kept, a)
+		p.creatives[a.UUID] = c
+	}
+	if len(kept) == 0 {
+		return p, errors.New("no assets satisfy " + exporter.Name() + " requirements: " + summarize(p.Issues))
+	}
+	export.Assets = kept
+	return p, nil
+}
+
+// FileName 建议的下载文件名
+func (p *Plan) FileName() string {
+	return fmt.Sprintf("task_%s_%s.zip", p.export.Task.UUID, p.exporter.Name())
+}
+
+// Write 写出 ZIP：图片 + 平台表格 + issues.json
+func (p *Plan) Write(ctx context.Context, w io.Writer) error {
+	return p.export.WriteBundle(ctx, w, func(zw *zip.Writer, items []creative.ExportManifestItem) error {
+		var rows []Creative
+		for _, item := range items {
+			c, ok := p.creatives[item.AssetID]
+			if !ok {
+				continue
+			}
+			if item.Error != "" {
+				p.Issues = append(p.Issues, Issue{AssetID: item.AssetID, Field: "image", Severity: SeverityError, Message: item.Error})
+				continue
+			}
+			c.File = item.File
+			rows = append(rows, c)
+		}
+
+		sw, err := zw.Create(p.exporter.SheetName())
+		if err != nil {
+			return err
+		}
+		if err := p.exporter.WriteSheet(sw, rows, p.opts); err != nil {
+			return err
+		}
+
+		iw, err := zw.Create("issues.json")
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(iw)
+		enc.SetIndent("", "  ")
+		issues := p.Issues
+		if issues == nil {
+			issues = []Issue{}
+		}
+		return enc.Encode(issues)
+	})
+}
+
+// FromAsset 将素材映射为平台无关的描述：标题作为 headline，卖点作为描述
+func FromAsset(a models.CreativeAsset) Creative {
+	c := Creative{
+		AssetID:      a.UUID,
+		ImageURL:     a.PublicURL,
+		Format:       a.Format,
+		Width:        a.Width,
+		Height:       a.Height,
+		Headline:     strings.TrimSpace(a.Title),
+		CTAText:      strings.TrimSpace(a.CTAText),
+		ProductName:  strings.TrimSpace(a.ProductName),
+		VariantIndex: a.VariantIndex,
+	}
+	if a.FileSize != nil {
+		c.FileSize = *a.FileSize
+	}
+	for _, sp := range a.SellingPoints {
+		if sp = strings.TrimSpace(sp); sp != "" {
+			c.Descriptions = append(c.Descriptions, sp)
+		}
+	}
+	c.LongHeadline = joinWithin(" - ", googleLongHeadlineMax, true, c.Headline, descriptionAt(c, 0))
+	return c
+}
+
+func hasError(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func summarize(issues []Issue) string {
+	var msgs []string
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			msgs = append(msgs, i.AssetID+": "+i.Message)
+		}
+		if len(msgs) == 3 {
+			break
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// writeCSV 写出带 UTF-8 BOM 的 CSV，便于 Excel/平台工具识别中文
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
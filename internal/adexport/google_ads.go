@@ -0,0 +1,97 @@
+package adexport
+
+import (
+	"io"
+	"strings"
+)
+
+// GoogleAdsExporter Google Ads Editor 自适应展示广告（Responsive display ad）CSV
+type GoogleAdsExporter struct{}
+
+var googleAspects = []aspect{
+	{name: "1.91:1", ratio: 1.91, minWidth: 600, minHeight: 314},
+	{name: "1:1", ratio: 1, minWidth: 300, minHeight: 300},
+	{name: "4:5", ratio: 0.8, minWidth: 480, minHeight: 600},
+}
+
+const (
+	googleHeadlineMax     = 30
+	googleLongHeadlineMax = 90
+	googleDescriptionMax  = 90
+	googleBusinessNameMax = 25
+	googleImageMaxBytes   = 5 << 20
+)
+
+// googleCTA Google Ads 的号召性用语文本
+var googleCTA = map[string]string{
+	"SHOP_NOW":   "Shop now",
+	"ORDER_NOW":  "Order now",
+	"SIGN_UP":    "Sign up",
+	"DOWNLOAD":   "Download",
+	"GET_OFFER":  "Get quote",
+	"CONTACT_US": "Contact us",
+	"LEARN_MORE": "Learn more",
+}
+
+func (GoogleAdsExporter) Name() string      { return "google" }
+func (GoogleAdsExporter) SheetName() string { return "google_ads_editor.csv" }
+
+func (GoogleAdsExporter) Validate(c Creative, opts Options) []Issue {
+	issues := checkImage(c, googleAspects, googleImageMaxBytes)
+	issues = append(issues, checkText(c.AssetID, c.Headline, textLimit{field: "headline", max: googleHeadlineMax, required: true, wide: true})...)
+	issues = append(issues, checkText(c.AssetID, c.LongHeadline, textLimit{field: "long_headline", max: googleLongHeadlineMax, required: true, wide: true})...)
+	issues = append(issues, checkText(c.AssetID, descriptionAt(c, 0), textLimit{field: "description", max: googleDescriptionMax, required: true, wide: true})...)
+	for i := 1; i < len(c.Descriptions) && i < 5; i++ {
+		issues = append(issues, checkText(c.AssetID, c.Descriptions[i], textLimit{field: "description", max: googleDescriptionMax, wide: true})...)
+	}
+	issues = append(issues, checkText(c.AssetID, opts.BusinessName, textLimit{field: "business_name", max: googleBusinessNameMax, required: true, wide: true})...)
+	issues = append(issues, ctaIssue(c)...)
+	return append(issues, finalURLIssue(c, opts)...)
+}
+
+func (GoogleAdsExporter) WriteSheet(w io.Writer, creatives []Creative, opts Options) error {
+	header := []string{"Campaign", "Ad group", "Ad type", "Headline 1", "Long headline",
+		"Description 1", "Description 2", "Description 3", "Description 4", "Description 5",
+		"Business name", "Final URL", "Call to action text", "Images", "Square images", "Portrait images", "Ad status"}
+	rows := make([][]string, 0, len(creatives))
+	for _, c := range creatives {
+		cta, _ := mapCTA(c.CTAText)
+		var landscape, square, portrait string
+		a, _ := matchAspect(c.Width, c.Height, googleAspects)
+		switch a.name {
+		case "1:1":
+			square = c.File
+		case "4:5":
+			portrait = c.File
+		default:
+			landscape = c.File
+		}
+		row := []string{
+			opts.Campaign, opts.AdGroup, "Responsive display ad",
+			c.Headline,
+			c.LongHeadline,
+		}
+		for i := 0; i < 5; i++ {
+			row = append(row, descriptionAt(c, i))
+		}
+		row = append(row,
+			opts.BusinessName,
+			opts.FinalURL,
+			googleCTA[cta],
+			landscape, square, portrait,
+			"Paused",
+		)
+		rows = append(rows, row)
+	}
+	return writeCSV(w, header, trimRows(rows))
+}
+
+// trimRows 去掉单元格首尾空白
+func trimRows(rows [][]string) [][]string {
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+		}
+	}
+	return rows
+}
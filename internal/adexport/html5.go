@@ -0,0 +1,321 @@
+package adexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/jpeg"
+	"io"
+	"math"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+)
+
+// IABSize IAB 标准广告尺寸
+type IABSize struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+func (s IABSize) String() string { return fmt.Sprintf("%dx%d", s.Width, s.Height) }
+
+// IABSizes IAB 新标准广告单元（固定尺寸）
+var IABSizes = []IABSize{
+	{"Medium Rectangle", 300, 250},
+	{"Large Rectangle", 336, 280},
+	{"Leaderboard", 728, 90},
+	{"Billboard", 970, 250},
+	{"Super Leaderboard", 970, 90},
+	{"Half Page", 300, 600},
+	{"Wide Skyscraper", 160, 600},
+	{"Skyscraper", 120, 600},
+	{"Square", 250, 250},
+	{"Full Banner", 468, 60},
+	{"Mobile Leaderboard", 320, 50},
+	{"Large Mobile Banner", 320, 100},
+	{"Mobile Interstitial", 320, 480},
+}
+
+// IABMaxInitialLoad IAB 初始加载包体上限（压缩后 150KB）
+const IABMaxInitialLoad = 150 << 10
+
+// ParseIABSize 解析 300x250 形式的尺寸，仅接受 IAB 标准尺寸
+func ParseIABSize(s string) (IABSize, error) {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(s)), "x", 2)
+	if len(parts) == 2 {
+		w, errW := strconv.Atoi(parts[0])
+		h, errH := strconv.Atoi(parts[1])
+		if errW == nil && errH == nil {
+			for _, size := range IABSizes {
+				if size.Width == w && size.Height == h {
+					return size, nil
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(IABSizes))
+	for _, size := range IABSizes {
+		names = append(names, size.String())
+	}
+	return IABSize{}, fmt.Errorf("unsupported banner size %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// BestIABSize 选取与素材宽高比最接近的 IAB 尺寸
+func BestIABSize(w, h int) IABSize {
+	best := IABSizes[0]
+	if w <= 0 || h <= 0 {
+		return best
+	}
+	r := math.Log(float64(w) / float64(h))
+	diff := math.Inf(1)
+	for _, size := range IABSizes {
+		d := math.Abs(math.Log(float64(size.Width)/float64(size.Height)) - r)
+		if d < diff {
+			best, diff = size, d
+		}
+	}
+	return best
+}
+
+// HTML5Options HTML5 横幅参数
+type HTML5Options struct {
+	Size     IABSize
+	ClickURL string // clickTag 默认值，投放时由广告服务器覆盖
+	MaxBytes int    // 包体上限，默认 IABMaxInitialLoad
+}
+
+// ErrBundleTooLarge 压缩后的包体超出上限
+var ErrBundleTooLarge = errors.New("banner bundle exceeds the size limit")
+
+// HTML5Bundle 自包含的 HTML5 广告包
+type HTML5Bundle struct {
+	Size     IABSize
+	maxBytes int
+	files    []bundleFile
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// jpegQualities 逐级降低质量直到满足包体上限
+var jpegQualities = []int{85, 75, 65, 55, 45, 35}
+
+// BuildHTML5 基于素材图片与文案生成 HTML5 横幅：index.html（clickTag）+ style.css + 背景图
+func BuildHTML5(c Creative, img image.Image, opts HTML5Options) (*HTML5Bundle, error) {
+	if opts.Size.Width == 0 {
+		opts.Size = BestIABSize(c.Width, c.Height)
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = IABMaxInitialLoad
+	}
+	if img == nil {
+		return nil, errors.New("image is required")
+	}
+	if err := ValidateClickURL(opts.ClickURL); err != nil {
+		return nil, err
+	}
+
+	bg := imaging.Cover(img, opts.Size.Width, opts.Size.Height)
+	indexHTML := []byte(renderHTML5Index(c, opts))
+	css := []byte(renderHTML5CSS(opts.Size))
+
+	var last int
+	for _, q := range jpegQualities {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, bg, &jpeg.Options{Quality: q}); err != nil {
+			return nil, fmt.Errorf("encode banner image failed: %w", err)
+		}
+		b := &HTML5Bundle{Size: opts.Size, maxBytes: opts.MaxBytes, files: []bundleFile{
+			{name: "index.html", data: indexHTML},
+			{name: "style.css", data: css},
+			{name: "banner.jpg", data: buf.Bytes()},
+		}}
+		zipped, err := b.ZipSize()
+		if err != nil {
+			return nil, err
+		}
+		if zipped <= opts.MaxBytes {
+			return b, nil
+		}
+		last = zipped
+	}
+	return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrBundleTooLarge, last, opts.MaxBytes)
+}
+
+// Files 包内文件名
+func (b *HTML5Bundle) Files() []string {
+	names := make([]string, 0, len(b.files))
+	for _, f := range b.files {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// File 返回包内文件及其 Content-Type，用于预览
+func (b *HTML5Bundle) File(name string) ([]byte, string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	for _, f := range b.files {
+		if f.name == name {
+			ct := mime.TypeByExtension(path.Ext(name))
+			if ct == "" {
+				ct = "application/octet-stream"
+			}
+			return f.data, ct, true
+		}
+	}
+	return nil, "", false
+}
+
+// FileName 建议的下载文件名
+func (b *HTML5Bundle) FileName(assetID string) string {
+	if len(assetID) > 8 {
+		assetID = assetID[:8]
+	}
+	return fmt.Sprintf("html5_%s_%s.zip", assetID, b.Size)
+}
+
+// WriteZip 写出 ZIP
+func (b *HTML5Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, f := range b.files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Zip 生成最终下载的 ZIP，并按实际写出的字节数再次校验包体上限
+func (b *HTML5Bundle) Zip() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.WriteZip(&buf); err != nil {
+		return nil, err
+	}
+	if b.maxBytes > 0 && buf.Len() > b.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrBundleTooLarge, buf.Len(), b.maxBytes)
+	}
+	return buf.Bytes(), nil
+}
+
+// ZipSize 压缩后的包体大小（IAB 以此计算 weight）
+func (b *HTML5Bundle) ZipSize() (int, error) {
+	var buf bytes.Buffer
+	if err := b.WriteZip(&buf); err != nil {
+		return 0, err
+	}
+	return buf.Len(), nil
+}
+
+// html5Layout 按尺寸决定展示多少文案
+func html5Layout(size IABSize) (layout string, sellingPoints int) {
+	switch {
+	case size.Height <= 100:
+		return "strip", 0
+	case size.Width <= 160:
+		return "tower", 2
+	case size.Height >= 2*size.Width:
+		return "tall", 3
+	default:
+		return "box", 2
+	}
+}
+
+func renderHTML5Index(c Creative, opts HTML5Options) string {
+	layout, n := html5Layout(opts.Size)
+	clickTag, _ := json.Marshal(opts.ClickURL) // json 会转义 <>&，可安全嵌入 script
+
+	var points strings.Builder
+	for i, sp := range c.Descriptions {
+		if i >= n {
+			break
+		}
+		points.WriteString("        <li>" + html.EscapeString(sp) + "</li>\n")
+	}
+	cta := c.CTAText
+	if cta == "" {
+		cta = "Learn more"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="ad.size" content="width=%d,height=%d">
+  <title>%s</title>
+  <link rel="stylesheet" href="style.css">
+  <script type="text/javascript">var clickTag = %s;</script>
+</head>
+<body>
+  <a id="banner" class="banner %s" href="javascript:window.open(window.clickTag)">
+    <img class="bg" src="banner.jpg" width="%d" height="%d" alt="">
+    <div class="copy">
+`, opts.Size.Width, opts.Size.Height, html.EscapeString(c.Headline), clickTag, layout, opts.Size.Width, opts.Size.Height)
+	if c.Headline != "" {
+		b.WriteString("      <h1>" + html.EscapeString(c.Headline) + "</h1>\n")
+	}
+	if points.Len() > 0 {
+		b.WriteString("      <ul>\n" + points.String() + "      </ul>\n")
+	}
+	b.WriteString("      <span class=\"cta\">" + html.EscapeString(cta) + "</span>\n")
+	b.WriteString(`    </div>
+  </a>
+</body>
+</html>
+`)
+	return b.String()
+}
+
+func renderHTML5CSS(size IABSize) string {
+	base := size.Height
+	if size.Width < base {
+		base = size.Width
+	}
+	title := clampPx(base/9, 12, 28)
+	text := clampPx(base/16, 10, 16)
+	return fmt.Sprintf(`* { margin: 0; padding: 0; box-sizing: border-box; }
+html, body { width: %[1]dpx; height: %[2]dpx; overflow: hidden; }
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", Arial, sans-serif; }
+.banner { position: relative; display: block; width: %[1]dpx; height: %[2]dpx; overflow: hidden; color: #fff; text-decoration: none; border: 1px solid #ccc; }
+.bg { position: absolute; top: 0; left: 0; }
+.copy { position: absolute; left: 0; right: 0; bottom: 0; padding: 8px; display: flex; flex-direction: column; gap: 4px; background: linear-gradient(transparent, rgba(0, 0, 0, 0.65)); }
+h1 { font-size: %[3]dpx; line-height: 1.2; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+ul { list-style: none; font-size: %[4]dpx; line-height: 1.3; }
+li { white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+li::before { content: "✓ "; }
+.cta { align-self: flex-start; padding: 4px 10px; border-radius: 3px; background: #ff6a00; font-size: %[4]dpx; font-weight: bold; white-space: nowrap; }
+.strip .copy { top: 0; flex-direction: row; align-items: center; justify-content: space-between; background: rgba(0, 0, 0, 0.45); }
+.strip h1 { font-size: %[4]dpx; }
+.strip .cta { align-self: center; }
+.tower .copy, .tall .copy { top: auto; }
+.tower h1 { white-space: normal; }
+`, size.Width, size.Height, title, text)
+}
+
+func clampPx(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package adexport
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildHTML5(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1024, 1024))
+	for y := 0; y < 1024; y++ {
+		for x := 0; x < 1024; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	c := Creative{AssetID: "asset-1", Width: 1024, Height: 1024, Headline: "Shoes <b>", Descriptions: []string{"light", "fast", "cheap"}, CTAText: "立即购买"}
+
+	size, err := ParseIABSize("300x250")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := BuildHTML5(c, img, HTML5Options{Size: size, ClickURL: "https://x.test/?a=1&b=</script>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipped, err := b.Zip()
+	if err != nil || len(zipped) > IABMaxInitialLoad {
+		t.Fatalf("bundle too large: %d, %v", len(zipped), err)
+	}
+	b.maxBytes = len(zipped) - 1
+	if _, err := b.Zip(); !errors.Is(err, ErrBundleTooLarge) {
+		t.Fatalf("expected final size check to fail, got %v", err)
+	}
+	index, ct, ok := b.File("/")
+	if !ok || !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("index not served: %v %s", ok, ct)
+	}
+	html := string(index)
+	for _, want := range []string{"var clickTag", "window.open(window.clickTag)", `content="width=300,height=250"`, "Shoes &lt;b&gt;", "<li>fast</li>", "立即购买"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("index.html missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "</script>\";") || strings.Contains(html, "<li>cheap</li>") {
+		t.Fatalf("unexpected content:\n%s", html)
+	}
+	if _, _, ok := b.File("../banner.jpg"); !ok {
+		t.Fatal("banner.jpg not found")
+	}
+
+	if _, err := BuildHTML5(c, img, HTML5Options{Size: size, MaxBytes: 1024}); err == nil {
+		t.Fatal("expected weight limit error")
+	}
+	if _, err := BuildHTML5(c, img, HTML5Options{Size: size, ClickURL: "javascript:alert(1)"}); err == nil {
+		t.Fatal("expected javascript: click_url to be rejected")
+	}
+	if _, err := ParseIABSize("1024x1024"); err == nil {
+		t.Fatal("expected non-IAB size error")
+	}
+	if s := BestIABSize(720, 1280); s.Height <= s.Width {
+		t.Fatalf("best size for portrait = %s", s)
+	}
+}
+
+func TestPreviewToken(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+	project := uint(3)
+	signed, err := SignPreview(secret, PreviewToken{AssetID: "asset-1", Size: "300x250", ProjectID: &project, ExpiresAt: now.Add(PreviewTTL).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := ParsePreview(secret, signed, now)
+	if err != nil || tok.AssetID != "asset-1" || tok.ProjectID == nil || *tok.ProjectID != 3 {
+		t.Fatalf("parse: %v %+v", err, tok)
+	}
+	if _, err := ParsePreview([]byte("other"), signed, now); err == nil {
+		t.Fatal("token signed with another secret must be rejected")
+	}
+	if _, err := ParsePreview(secret, "x"+signed, now); err == nil {
+		t.Fatal("tampered token must be rejected")
+	}
+	if _, err := ParsePreview(secret, signed, now.Add(PreviewTTL+time.Minute)); err == nil {
+		t.Fatal("expired token must be rejected")
+	}
+}
+
+func TestPreviewCacheBuildsOncePerToken(t *testing.T) {
+	cache := NewPreviewCache(2)
+	now := time.Now()
+	builds := 0
+	build := func() (*HTML5Bundle, error) {
+		builds++
+		return &HTML5Bundle{}, nil
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get("a", now.Add(time.Minute), now, build); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if builds != 1 {
+		t.Fatalf("expected one build per token, got %d", builds)
+	}
+	// 令牌过期后重新生成
+	_, _ = cache.Get("a", now.Add(3*time.Minute), now.Add(2*time.Minute), build)
+	if builds != 2 {
+		t.Fatalf("expired entry should be rebuilt, builds=%d", builds)
+	}
+	if _, err := cache.Get("b", now.Add(time.Hour), now, func() (*HTML5Bundle, error) { return nil, errors.New("boom") }); err == nil {
+		t.Fatal("build errors should be returned")
+	}
+	_, _ = cache.Get("c", now.Add(time.Hour), now, build)
+	_, _ = cache.Get("d", now.Add(time.Hour), now, build)
+	if len(cache.entries) > 2 {
+		t.Fatalf("cache should stay within its limit, got %d entries", len(cache.entries))
+	}
+}
@@ -0,0 +1,72 @@
+package adexport
+
+import (
+	"io"
+	"strings"
+)
+
+// MetaExporter Meta Ads Manager 批量导入表格
+type MetaExporter struct{}
+
+var metaAspects = []aspect{
+	{name: "1:1", ratio: 1, minWidth: 600, minHeight: 600},
+	{name: "1.91:1", ratio: 1.91, minWidth: 600, minHeight: 314},
+	{name: "4:5", ratio: 0.8, minWidth: 600, minHeight: 750},
+	{name: "9:16", ratio: 9.0 / 16, minWidth: 600, minHeight: 1067},
+}
+
+const (
+	metaTitleMax       = 40
+	metaBodyMax        = 125
+	metaDescriptionMax = 30
+	metaImageMaxBytes  = 30 << 20
+)
+
+func (MetaExporter) Name() string      { return "meta" }
+func (MetaExporter) SheetName() string { return "meta_bulk_import.csv" }
+
+func (MetaExporter) Validate(c Creative, opts Options) []Issue {
+	issues := checkImage(c, metaAspects, metaImageMaxBytes)
+	issues = append(issues, checkText(c.AssetID, c.Headline, textLimit{field: "title", max: metaTitleMax, required: true})...)
+	issues = append(issues, checkText(c.AssetID, metaBody(c), textLimit{field: "body", max: metaBodyMax, required: true})...)
+	issues = append(issues, checkText(c.AssetID, c.ProductName, textLimit{field: "link_description", max: metaDescriptionMax})...)
+	issues = append(issues, ctaIssue(c)...)
+	return append(issues, finalURLIssue(c, opts)...)
+}
+
+func (MetaExporter) WriteSheet(w io.Writer, creatives []Creative, opts Options) error {
+	header := []string{"Campaign Name", "Ad Set Name", "Ad Name", "Ad Status", "Title", "Body",
+		"Link Description", "Link", "Call to Action", "Image File Name", "Image URL"}
+	rows := make([][]string, 0, len(creatives))
+	for _, c := range creatives {
+		cta, _ := mapCTA(c.CTAText)
+		rows = append(rows, []string{
+			opts.Campaign,
+			opts.AdGroup,
+			adName(c),
+			"PAUSED",
+			c.Headline,
+			metaBody(c),
+			c.ProductName,
+			opts.FinalURL,
+			cta,
+			c.File,
+			c.ImageURL,
+		})
+	}
+	return writeCSV(w, header, trimRows(rows))
+}
+
+// metaBody 主文案：卖点拼接，超出长度的卖点不再追加
+func metaBody(c Creative) string {
+	return joinWithin(" · ", metaBodyMax, false, c.Descriptions...)
+}
+
+// adName 广告名：标题 + 格式 + 素材 ID 前缀
+func adName(c Creative) string {
+	id := c.AssetID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return strings.TrimSpace(c.Headline + " " + c.Format + " " + id)
+}
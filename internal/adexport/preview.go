@@ -0,0 +1,134 @@
+package adexport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidPreviewToken 预览令牌无效、被篡改或已过期
+var ErrInvalidPreviewToken = errors.New("invalid or expired preview token")
+
+// PreviewTTL HTML5 预览链接有效期
+const PreviewTTL = 15 * time.Minute
+
+// PreviewToken HTML5 预览令牌内容：签发时的素材、尺寸、clickTag 与数据范围，
+// 预览请求不携带认证信息，按令牌中的范围校验素材
+type PreviewToken struct {
+	AssetID   string `json:"a"`
+	Size      string `json:"s,omitempty"`
+	ClickURL  string `json:"c,omitempty"`
+	All       bool   `json:"all,omitempty"`
+	ProjectID *uint  `json:"p,omitempty"`
+	OwnerID   uint   `json:"o,omitempty"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// SignPreview 生成 URL 安全的签名令牌：base64(payload).base64(HMAC-SHA256)
+func SignPreview(secret []byte, t PreviewToken) (string, error) {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + previewMAC(secret, payload), nil
+}
+
+// ParsePreview 校验签名与有效期并解析令牌
+func ParsePreview(secret []byte, token string, now time.Time) (*PreviewToken, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(previewMAC(secret, payload))) {
+		return nil, ErrInvalidPreviewToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidPreviewToken
+	}
+	var t PreviewToken
+	if err := json.Unmarshal(raw, &t); err != nil || t.AssetID == "" {
+		return nil, ErrInvalidPreviewToken
+	}
+	if now.Unix() > t.ExpiresAt {
+		return nil, ErrInvalidPreviewToken
+	}
+	return &t, nil
+}
+
+func previewMAC(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("html5-preview:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateClickURL clickTag 只允许 http(s) 绝对地址，防止 javascript: 等协议被注入点击跳转
+func ValidateClickURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("click_url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// PreviewCache 按预览令牌缓存已生成的 HTML5 包：浏览器加载 index.html、样式与图片时
+// 同一链接只读取素材、生成一次；条目在令牌过期时失效，数量超出上限时先淘汰最早过期的
+type PreviewCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]previewEntry
+}
+
+type previewEntry struct {
+	bundle    *HTML5Bundle
+	expiresAt time.Time
+}
+
+// NewPreviewCache 创建缓存，max 为最多缓存的预览包数
+func NewPreviewCache(max int) *PreviewCache {
+	if max <= 0 {
+		max = 64
+	}
+	return &PreviewCache{max: max, entries: make(map[string]previewEntry)}
+}
+
+// Get 返回令牌对应的预览包，未缓存时调用 build 生成；生成失败不缓存
+func (c *PreviewCache) Get(token string, expiresAt, now time.Time, build func() (*HTML5Bundle, error)) (*HTML5Bundle, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[token]; ok && now.Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.bundle, nil
+	}
+	c.mu.Unlock()
+
+	bundle, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	for len(c.entries) >= c.max {
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.expiresAt.Before(c.entries[oldest].expiresAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[token] = previewEntry{bundle: bundle, expiresAt: expiresAt}
+	return bundle, nil
+}
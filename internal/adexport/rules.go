@@ -0,0 +1,162 @@
+package adexport
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// aspect 平台支持的图片比例及最小尺寸
+type aspect struct {
+	name      string
+	ratio     float64
+	minWidth  int
+	minHeight int
+}
+
+// textLimit 文案字段长度限制
+type textLimit struct {
+	field    string
+	max      int
+	required bool
+	wide     bool // 中日韩等全角字符按 2 计
+}
+
+// textLength 计算文案长度
+func textLength(s string, wide bool) int {
+	if !wide {
+		return utf8.RuneCountInString(s)
+	}
+	n := 0
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || (r >= 0xFF00 && r <= 0xFFEF) {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func checkText(assetID, value string, l textLimit) []Issue {
+	if strings.TrimSpace(value) == "" {
+		if l.required {
+			return []Issue{{AssetID: assetID, Field: l.field, Severity: SeverityError, Message: l.field + " is required"}}
+		}
+		return nil
+	}
+	if n := textLength(value, l.wide); n > l.max {
+		return []Issue{{AssetID: assetID, Field: l.field, Severity: SeverityError,
+			Message: fmt.Sprintf("%s is %d characters, limit %d", l.field, n, l.max)}}
+	}
+	return nil
+}
+
+// matchAspect 返回与图片比例误差 2% 以内的规格
+func matchAspect(w, h int, aspects []aspect) (aspect, bool) {
+	if w <= 0 || h <= 0 {
+		return aspect{}, false
+	}
+	r := float64(w) / float64(h)
+	for _, a := range aspects {
+		if math.Abs(r-a.ratio)/a.ratio <= 0.02 {
+			return a, true
+		}
+	}
+	return aspect{}, false
+}
+
+func checkImage(c Creative, aspects []aspect, maxBytes int) []Issue {
+	var issues []Issue
+	a, ok := matchAspect(c.Width, c.Height, aspects)
+	if !ok {
+		var names []string
+		for _, a := range aspects {
+			names = append(names, a.name)
+		}
+		issues = append(issues, Issue{AssetID: c.AssetID, Field: "image", Severity: SeverityError,
+			Message: fmt.Sprintf("aspect ratio %dx%d not supported, expected one of %s", c.Width, c.Height, strings.Join(names, ", "))})
+	} else if c.Width < a.minWidth || c.Height < a.minHeight {
+		issues = append(issues, Issue{AssetID: c.AssetID, Field: "image", Severity: SeverityError,
+			Message: fmt.Sprintf("image %dx%d is below the %s minimum %dx%d", c.Width, c.Height, a.name, a.minWidth, a.minHeight)})
+	}
+	if maxBytes > 0 && c.FileSize > maxBytes {
+		issues = append(issues, Issue{AssetID: c.AssetID, Field: "image", Severity: SeverityError,
+			Message: fmt.Sprintf("image is %d bytes, limit %d", c.FileSize, maxBytes)})
+	}
+	return issues
+}
+
+// joinWithin 依次拼接组合文案，放不下的后续部分整段舍弃；首段始终保留，超长由校验报错
+func joinWithin(sep string, max int, wide bool, parts ...string) string {
+	out := ""
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if out == "" {
+			out = p
+			continue
+		}
+		if textLength(out+sep+p, wide) > max {
+			break
+		}
+		out += sep + p
+	}
+	return out
+}
+
+// ctaKeywords 自由文本 CTA 到平台枚举的关键词映射
+var ctaKeywords = []struct {
+	code     string
+	keywords []string
+}{
+	{"SHOP_NOW", []string{"shop", "buy", "购买", "抢购", "下单", "选购"}},
+	{"ORDER_NOW", []string{"order", "订购", "预订"}},
+	{"SIGN_UP", []string{"sign up", "register", "join", "注册", "加入"}},
+	{"DOWNLOAD", []string{"download", "install", "下载", "安装"}},
+	{"GET_OFFER", []string{"offer", "deal", "coupon", "优惠", "领券"}},
+	{"CONTACT_US", []string{"contact", "联系", "咨询"}},
+	{"LEARN_MORE", []string{"learn", "more", "了解", "详情"}},
+}
+
+// mapCTA 返回平台枚举；无法识别时回退 LEARN_MORE 并返回 false
+func mapCTA(text string) (string, bool) {
+	lower := strings.ToLower(text)
+	for _, m := range ctaKeywords {
+		for _, k := range m.keywords {
+			if strings.Contains(lower, k) {
+				return m.code, true
+			}
+		}
+	}
+	return "LEARN_MORE", false
+}
+
+func ctaIssue(c Creative) []Issue {
+	if c.CTAText == "" {
+		return nil
+	}
+	if _, ok := mapCTA(c.CTAText); !ok {
+		return []Issue{{AssetID: c.AssetID, Field: "cta", Severity: SeverityWarning,
+			Message: fmt.Sprintf("CTA %q has no platform equivalent, using LEARN_MORE", c.CTAText)}}
+	}
+	return nil
+}
+
+func finalURLIssue(c Creative, opts Options) []Issue {
+	if opts.FinalURL == "" {
+		return []Issue{{AssetID: c.AssetID, Field: "final_url", Severity: SeverityWarning, Message: "final_url is empty; fill it in before importing"}}
+	}
+	return nil
+}
+
+// descriptionAt 取第 i 条描述，不存在时返回空
+func descriptionAt(c Creative, i int) string {
+	if i < len(c.Descriptions) {
+		return c.Descriptions[i]
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package adexport
+
+import "io"
+
+// TikTokExporter TikTok Ads Manager 创意批量表格
+type TikTokExporter struct{}
+
+var tiktokAspects = []aspect{
+	{name: "9:16", ratio: 9.0 / 16, minWidth: 720, minHeight: 1280},
+	{name: "1:1", ratio: 1, minWidth: 640, minHeight: 640},
+	{name: "16:9", ratio: 16.0 / 9, minWidth: 1280, minHeight: 720},
+}
+
+const (
+	tiktokAdTextMax      = 100
+	tiktokDisplayNameMax = 40
+	tiktokImageMaxBytes  = 1000 << 10
+)
+
+func (TikTokExporter) Name() string      { return "tiktok" }
+func (TikTokExporter) SheetName() string { return "tiktok_creatives.csv" }
+
+func (TikTokExporter) Validate(c Creative, opts Options) []Issue {
+	issues := checkImage(c, tiktokAspects, tiktokImageMaxBytes)
+	issues = append(issues, checkText(c.AssetID, tiktokAdText(c), textLimit{field: "ad_text", max: tiktokAdTextMax, required: true, wide: true})...)
+	issues = append(issues, checkText(c.AssetID, opts.BusinessName, textLimit{field: "display_name", max: tiktokDisplayNameMax, wide: true})...)
+	issues = append(issues, ctaIssue(c)...)
+	return append(issues, finalURLIssue(c, opts)...)
+}
+
+func (TikTokExporter) WriteSheet(w io.Writer, creatives []Creative, opts Options) error {
+	header := []string{"Campaign Name", "Ad Group Name", "Ad Name", "Ad Format", "Ad Text",
+		"Call to Action", "Display Name", "Landing Page URL", "Image File Name", "Image URL"}
+	rows := make([][]string, 0, len(creatives))
+	for _, c := range creatives {
+		cta, _ := mapCTA(c.CTAText)
+		rows = append(rows, []string{
+			opts.Campaign,
+			opts.AdGroup,
+			adName(c),
+			"Single Image",
+			tiktokAdText(c),
+			cta,
+			opts.BusinessName,
+			opts.FinalURL,
+			c.File,
+			c.ImageURL,
+		})
+	}
+	return writeCSV(w, header, trimRows(rows))
+}
+
+// tiktokAdText 广告文案：标题 + 首条卖点（放不下时只用标题）
+func tiktokAdText(c Creative) string {
+	return joinWithin(" ", tiktokAdTextMax, true, c.Headline, descriptionAt(c, 0))
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 审计日志接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务（供其他模块上报审计事件）
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// List 查询审计日志
+// ?action=&resource_type=&resource_id=&actor_id=&request_id=&since=&until=&page=&page_size=
+func (h *Handler) List(c *gin.Context) {
+	in := ListInput{
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		RequestID:    c.Query("request_id"),
+	}
+	in.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	in.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if v := c.Query("actor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid actor_id"))
+			return
+		}
+		in.ActorID = uint(id)
+	}
+	var err error
+	if in.Since, err = parseTime(c.Query("since")); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid since: "+err.Error()))
+		return
+	}
+	if in.Until, err = parseTime(c.Query("until")); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid until: "+err.Error()))
+		return
+	}
+
+	result, err := h.service.List(c.Request.Context(), in)
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			c.JSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// parseTime 支持 RFC3339 与 YYYY-MM-DD，空值返回 nil
+func parseTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, errors.New("expected RFC3339 or YYYY-MM-DD")
+	}
+	return &t, nil
+}
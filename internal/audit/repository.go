@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Query 审计日志查询条件
+type Query struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	ActorID      uint
+	RequestID    string
+	Since        *time.Time
+	Until        *time.Time
+	// ScopeCond 数据范围过滤条件（由 shared.ProjectScope.Condition 生成）
+	ScopeCond string
+	ScopeArgs []interface{}
+	Page      int
+	PageSize  int
+}
+
+// Repository 审计日志仓储
+type Repository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	List(ctx context.Context, q Query) ([]models.AuditLog, int64, error)
+	// DeleteBefore 删除早于 t 的日志，返回删除条数
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+func (r *gormRepository) List(ctx context.Context, q Query) ([]models.AuditLog, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if q.ScopeCond != "" {
+		db = db.Where(q.ScopeCond, q.ScopeArgs...)
+	}
+	if q.Action != "" {
+		db = db.Where("action = ?", q.Action)
+	}
+	if q.ResourceType != "" {
+		db = db.Where("resource_type = ?", q.ResourceType)
+	}
+	if q.ResourceID != "" {
+		db = db.Where("resource_id = ?", q.ResourceID)
+	}
+	if q.ActorID > 0 {
+		db = db.Where("actor_id = ?", q.ActorID)
+	}
+	if q.RequestID != "" {
+		db = db.Where("request_id = ?", q.RequestID)
+	}
+	if q.Since != nil {
+		db = db.Where("created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		db = db.Where("created_at < ?", *q.Until)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []models.AuditLog
+	offset := (q.Page - 1) * q.PageSize
+	if err := db.Order("created_at desc, id desc").Offset(offset).Limit(q.PageSize).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+func (r *gormRepository) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("created_at < ?", t).Delete(&models.AuditLog{})
+	return res.RowsAffected, res.Error
+}
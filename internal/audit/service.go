@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+)
+
+// ErrForbidden 项目内只有管理员可查看审计日志
+var ErrForbidden = errors.New("audit log requires project admin")
+
+// 审计动作
+const (
+	ActionTaskDelete       = "task.delete"
+	ActionTaskPurge        = "task.purge"
+	ActionTaskStart        = "task.start"
+	ActionTaskConfirm      = "task.confirm_copywriting"
+	ActionExperimentCreate = "experiment.create"
+	ActionExperimentStatus = "experiment.status"
+	ActionTraceForceFail   = "trace.force_fail"
+	ActionWarmupRun        = "warmup.run"
+	// 素材审核（批量审核按素材逐条记录）与隔离复核
+	ActionAssetApprove          = "asset.approve"
+	ActionAssetReject           = "asset.reject"
+	ActionAssetRelease          = "asset.quarantine_release"
+	ActionAssetQuarantineDelete = "asset.quarantine_delete"
+	ActionAssetTagAttach        = "asset.tag_attach"
+	ActionAssetTagDetach        = "asset.tag_detach"
+	ActionAPIKeyCreate          = "api_key.create"
+	ActionAPIKeyRotate          = "api_key.rotate"
+	ActionAPIKeyRevoke          = "api_key.revoke"
+	ActionProjectMemberAdd      = "project.member_add"
+	ActionProjectMemberRole     = "project.member_role"
+	ActionProjectMemberRemove   = "project.member_remove"
+	ActionProjectSettings       = "project.settings"
+	ActionProjectReviewPolicy   = "project.review_policy"
+	ActionProjectBrandColors    = "project.brand_colors"
+	ActionTagCreate             = "tag.create"
+	ActionTagUpdate             = "tag.update"
+	ActionTagDelete             = "tag.delete"
+)
+
+// Service 审计日志服务
+type Service struct {
+	repo      Repository
+	retention time.Duration
+}
+
+// NewService 创建服务，保留期取自配置
+func NewService() *Service {
+	svc := NewServiceWithDeps(NewRepository(database.DB))
+	if config.AuditConfig != nil {
+		svc.retention = config.AuditConfig.Retention
+	}
+	return svc
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetRetention 设置日志保留时长（<=0 表示永久保留）
+func (s *Service) SetRetention(d time.Duration) {
+	s.retention = d
+}
+
+// Record 记录一次变更，实现 ports.AuditRecorder；写入失败只记日志，不影响业务操作
+func (s *Service) Record(ctx context.Context, e shared.AuditEvent) {
+	entry := models.AuditLog{
+		CreatedAt:    time.Now(),
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		ProjectID:    e.ProjectID,
+	}
+	entry.Before, entry.After = diff(toMap(e.Before), toMap(e.After))
+	if entry.ProjectID == nil {
+		entry.ProjectID = shared.ProjectIDFrom(ctx)
+	}
+	if p := shared.PrincipalFrom(ctx); p != nil {
+		id := p.UserID
+		entry.ActorID = &id
+		entry.ActorName = p.Username
+		entry.ActorRole = p.Role
+		if p.APIKeyID != 0 {
+			keyID := p.APIKeyID
+			entry.APIKeyID = &keyID
+		}
+	}
+	if m := shared.RequestMetaFrom(ctx); m != nil {
+		entry.RequestID = m.RequestID
+		entry.Method = m.Method
+		entry.Path = truncate(m.Path, 255)
+		entry.IP = m.IP
+		entry.UserAgent = truncate(m.UserAgent, 255)
+	}
+	if err := s.repo.Create(ctx, &entry); err != nil {
+		log.Printf("audit: record %s %s/%s failed: %v", e.Action, e.ResourceType, e.ResourceID, err)
+	}
+}
+
+// ListInput 审计日志查询参数
+type ListInput struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	ActorID      uint
+	RequestID    string
+	Since        *time.Time
+	Until        *time.Time
+	Page         int
+	PageSize     int
+}
+
+// ListResult 审计日志分页结果
+type ListResult struct {
+	Logs     []models.AuditLog `json:"logs"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// List 查询当前范围内的审计日志：项目内需要 admin 角色，个人空间只可见本人操作，系统管理员可见全部
+func (s *Service) List(ctx context.Context, in ListInput) (*ListResult, error) {
+	scope := shared.ScopeFrom(ctx)
+	if scope != nil && !scope.All && scope.ProjectID != nil && !scope.CanWrite(shared.ProjectRoleAdmin) {
+		return nil, ErrForbidden
+	}
+	if in.Page <= 0 {
+		in.Page = 1
+	}
+	if in.PageSize <= 0 || in.PageSize > 200 {
+		in.PageSize = 50
+	}
+	cond, args := scope.Condition("project_id", "actor_id")
+	logs, total, err := s.repo.List(ctx, Query{
+		Action:       in.Action,
+		ResourceType: in.ResourceType,
+		ResourceID:   in.ResourceID,
+		ActorID:      in.ActorID,
+		RequestID:    in.RequestID,
+		Since:        in.Since,
+		Until:        in.Until,
+		ScopeCond:    cond,
+		ScopeArgs:    args,
+		Page:         in.Page,
+		PageSize:     in.PageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list audit logs failed: %w", err)
+	}
+	return &ListResult{Logs: logs, Total: total, Page: in.Page, PageSize: in.PageSize}, nil
+}
+
+// Purge 删除超过保留期的日志
+func (s *Service) Purge(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.repo.DeleteBefore(ctx, time.Now().Add(-s.retention))
+}
+
+// toMap 将结构体或 map 按 JSON 字段名转换为 map，便于比较
+func toMap(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// diff 只保留变更前后不同的字段；创建或删除时保留完整一侧
+func diff(before, after map[string]interface{}) (models.JSONMap, models.JSONMap) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	b, a := models.JSONMap{}, models.JSONMap{}
+	for k, v := range before {
+		if nv, ok := after[k]; !ok || !reflect.DeepEqual(v, nv) {
+			b[k] = v
+		}
+	}
+	for k, v := range after {
+		if ov, ok := before[k]; !ok || !reflect.DeepEqual(ov, v) {
+			a[k] = v
+		}
+	}
+	return b, a
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+type memRepo struct {
+	logs  []models.AuditLog
+	query Query
+	cut   time.Time
+}
+
+func (m *memRepo) Create(_ context.Context, l *models.AuditLog) error {
+	l.ID = uint(len(m.logs) + 1)
+	m.logs = append(m.logs, *l)
+	return nil
+}
+
+func (m *memRepo) List(_ context.Context, q Query) ([]models.AuditLog, int64, error) {
+	m.query = q
+	return m.logs, int64(len(m.logs)), nil
+}
+
+func (m *memRepo) DeleteBefore(_ context.Context, t time.Time) (int64, error) {
+	m.cut = t
+	return 0, nil
+}
+
+func TestRecordDiffAndActor(t *testing.T) {
+	repo := &memRepo{}
+	svc := NewServiceWithDeps(repo)
+	projectID := uint(7)
+	ctx := shared.WithPrincipal(context.Background(), &shared.Principal{UserID: 3, Username: "alice", Role: "user", APIKeyID: 9})
+	ctx = shared.WithRequestMeta(ctx, &shared.RequestMeta{RequestID: "req-1", Method: "POST", Path: "/api/v1/creative/start", IP: "10.0.0.1"})
+
+	task := &models.CreativeTask{Status: models.TaskCompleted, NumVariants: 2, RequestedStyles: models.StringArray{"minimal"}}
+	before := TaskState(task)
+	svc.Record(ctx, shared.AuditEvent{
+		Action:       ActionTaskStart,
+		ResourceType: models.AuditResourceTask,
+		ResourceID:   "t-1",
+		ProjectID:    &projectID,
+		Before:       before,
+		After:        Apply(before, map[string]interface{}{"status": models.TaskQueued, "num_variants": 4, "queued_at": time.Now()}),
+	})
+
+	got := repo.logs[0]
+	if *got.ActorID != 3 || got.ActorName != "alice" || *got.APIKeyID != 9 || *got.ProjectID != 7 || got.RequestID != "req-1" || got.IP != "10.0.0.1" {
+		t.Fatalf("actor/request metadata not recorded: %+v", got)
+	}
+	if len(got.Before) != 2 || got.Before["status"] != string(models.TaskCompleted) || got.After["num_variants"] != float64(4) {
+		t.Fatalf("expected only changed fields, got before=%v after=%v", got.Before, got.After)
+	}
+	if _, ok := got.After["queued_at"]; ok {
+		t.Fatalf("fields outside the snapshot must be ignored: %v", got.After)
+	}
+
+	svc.Record(context.Background(), shared.AuditEvent{Action: ActionTaskDelete, ResourceType: models.AuditResourceTask, ResourceID: "t-2", Before: map[string]interface{}{"status": "completed"}})
+	if got := repo.logs[1]; got.ActorID != nil || got.After != nil || got.Before["status"] != "completed" {
+		t.Fatalf("system delete should keep full before state: %+v", got)
+	}
+}
+
+func TestListScopeAndRetention(t *testing.T) {
+	repo := &memRepo{}
+	svc := NewServiceWithDeps(repo)
+	projectID := uint(7)
+
+	viewer := shared.WithScope(context.Background(), &shared.ProjectScope{ProjectID: &projectID, Role: shared.ProjectRoleMember})
+	if _, err := svc.List(viewer, ListInput{}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("project member must not read audit log, got %v", err)
+	}
+	admin := shared.WithScope(context.Background(), &shared.ProjectScope{ProjectID: &projectID, Role: shared.ProjectRoleAdmin})
+	if _, err := svc.List(admin, ListInput{PageSize: 1000}); err != nil {
+		t.Fatalf("project admin list: %v", err)
+	}
+	if repo.query.ScopeCond != "project_id = ?" || repo.query.PageSize != 50 {
+		t.Fatalf("unexpected query: %+v", repo.query)
+	}
+	personal := shared.WithScope(context.Background(), &shared.ProjectScope{OwnerID: 3, Role: shared.ProjectRoleOwner})
+	if _, err := svc.List(personal, ListInput{}); err != nil || repo.query.ScopeCond != "project_id IS NULL AND actor_id = ?" {
+		t.Fatalf("personal scope should only list own actions: %v %+v", err, repo.query)
+	}
+
+	if n, _ := svc.Purge(context.Background()); n != 0 || !repo.cut.IsZero() {
+		t.Fatal("no retention configured: nothing should be purged")
+	}
+	svc.SetRetention(24 * time.Hour)
+	_, _ = svc.Purge(context.Background())
+	if d := time.Since(repo.cut); d < 23*time.Hour || d > 25*time.Hour {
+		t.Fatalf("unexpected purge cutoff %s", repo.cut)
+	}
+}
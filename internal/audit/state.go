@@ -0,0 +1,37 @@
+package audit
+
+import "ads-creative-gen-platform/internal/models"
+
+// TaskState 任务审计快照：状态与生成配置
+func TaskState(t *models.CreativeTask) map[string]interface{} {
+	return map[string]interface{}{
+		"status":              t.Status,
+		"title":               t.Title,
+		"user_id":             t.UserID,
+		"product_image_url":   t.ProductImageURL,
+		"cta_text":            t.CTAText,
+		"selling_points":      t.SellingPoints,
+		"selected_cta_index":  t.SelectedCTAIndex,
+		"selected_sp_indexes": t.SelectedSPIndexes,
+		"requested_formats":   t.RequestedFormats,
+		"requested_styles":    t.RequestedStyles,
+		"variant_styles":      t.VariantStyles,
+		"num_variants":        t.NumVariants,
+		"prompt_template":     t.PromptTemplate,
+		"image_model":         t.ImageModel,
+	}
+}
+
+// Apply 在快照上应用字段更新，只覆盖快照中已有的字段
+func Apply(state, updates map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		out[k] = v
+	}
+	for k, v := range updates {
+		if _, ok := out[k]; ok {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/google/uuid"
+)
+
+// API Key 权限范围
+const (
+	ScopeCreativeRead    = "creative:read"
+	ScopeCreativeWrite   = "creative:write"
+	ScopeExperimentRead  = "experiment:read"
+	ScopeExperimentWrite = "experiment:write"
+	ScopeExperimentTrack = "experiment:track"
+	ScopeTracesRead      = "traces:read"
+	ScopeTracesWrite     = "traces:write"
+	ScopeProjectsRead    = "projects:read"
+	ScopeProjectsWrite   = "projects:write"
+	ScopeOps             = "ops:run"
+	ScopeAuditRead       = "audit:read"
+)
+
+// Scopes 全部可授予的权限范围
+var Scopes = []string{
+	ScopeCreativeRead, ScopeCreativeWrite,
+	ScopeExperimentRead, ScopeExperimentWrite, ScopeExperimentTrack,
+	ScopeTracesRead, ScopeTracesWrite,
+	ScopeProjectsRead, ScopeProjectsWrite,
+	ScopeOps,
+	ScopeAuditRead,
+}
+
+const (
+	// apiKeyPrefix API Key 固定前缀，便于识别与密钥扫描
+	apiKeyPrefix = "acg_"
+	// defaultKeyTTL / maxKeyTTL 未指定时 90 天过期，最长 1 年
+	defaultKeyTTL = 90 * 24 * time.Hour
+	maxKeyTTL     = 365 * 24 * time.Hour
+	// maxRotateGrace 轮换后旧 Key 最长保留时间
+	maxRotateGrace = 7 * 24 * time.Hour
+	// lastUsedInterval 最近使用时间的最小写入间隔，避免每次请求都写库
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrKeyNotFound API Key 不存在或不属于当前用户
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrInvalidKey API Key 参数不合法
+	ErrInvalidKey = errors.New("invalid api key request")
+	// ErrForbidden 当前身份不允许该操作
+	ErrForbidden = errors.New("forbidden")
+)
+
+// ProjectLookup 按 UUID 查询项目
+type ProjectLookup interface {
+	GetProject(ctx context.Context, projectUUID string) (*models.Project, error)
+}
+
+// SetProjectLookup 设置项目查询（创建项目级 Key 时校验）
+func (s *Service) SetProjectLookup(p ProjectLookup) {
+	s.projects = p
+}
+
+// APIKeyInput 创建 API Key 的参数
+type APIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ProjectID string     `json:"project_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyDTO 对外展示的 API Key（不含明文）
+type APIKeyDTO struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	UserID      uint       `json:"user_id"`
+	ProjectID   *uint      `json:"project_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RotatedFrom *uint      `json:"rotated_from,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// APIKeyCreated 创建/轮换结果，明文 Key 只返回这一次
+type APIKeyCreated struct {
+	APIKeyDTO
+	Key string `json:"key"`
+}
+
+func toAPIKeyDTO(k *models.APIKey) APIKeyDTO {
+	return APIKeyDTO{
+		ID:          k.UUID,
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		Scopes:      k.Scopes,
+		UserID:      k.UserID,
+		ProjectID:   k.ProjectID,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		RevokedAt:   k.RevokedAt,
+		RotatedFrom: k.RotatedFrom,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+// SetAuditRecorder 设置审计日志记录器，记录 API Key 的创建、轮换与撤销（nil 表示不记录）
+func (s *Service) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// auditKey 记录 API Key 变更，只记录元数据，不含明文或摘要
+func (s *Service) auditKey(ctx context.Context, action string, key *models.APIKey, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceAPIKey,
+		ResourceID:   key.UUID,
+		ProjectID:    key.ProjectID,
+		Before:       before,
+		After:        after,
+	})
+}
+
+// CreateAPIKey 为当前登录用户创建 API Key；API Key 本身不能再创建 Key
+func (s *Service) CreateAPIKey(ctx context.Context, principal *shared.Principal, in APIKeyInput) (*APIKeyCreated, error) {
+	if err := requireUserSession(principal); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" || len(name) > 128 {
+		return nil, fmt.Errorf("%w: name is required (max 128 characters)", ErrInvalidKey)
+	}
+	scopes, err := NormalizeScopes(in.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	expiresAt := now.Add(defaultKeyTTL)
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(now) || in.ExpiresAt.Sub(now) > maxKeyTTL {
+			return nil, fmt.Errorf("%w: expires_at must be within the next %d days", ErrInvalidKey, int(maxKeyTTL.Hours()/24))
+		}
+		expiresAt = *in.ExpiresAt
+	}
+
+	key := &models.APIKey{
+		Name:      name,
+		UserID:    principal.UserID,
+		Scopes:    scopes,
+		ExpiresAt: &expiresAt,
+	}
+	if in.ProjectID != "" {
+		if s.projects == nil {
+			return nil, fmt.Errorf("%w: project lookup not configured", ErrInvalidKey)
+		}
+		project, err := s.projects.GetProject(ctx, in.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: project %s not found", ErrInvalidKey, in.ProjectID)
+		}
+		key.ProjectID = &project.ID
+	}
+	created, err := s.storeKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	s.auditKey(ctx, audit.ActionAPIKeyCreate, key, nil, created.APIKeyDTO)
+	return created, nil
+}
+
+// ListAPIKeys 当前用户的全部 API Key（含已撤销）
+func (s *Service) ListAPIKeys(ctx context.Context, principal *shared.Principal) ([]APIKeyDTO, error) {
+	if err := requireUserSession(principal); err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ListAPIKeys(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]APIKeyDTO, 0, len(keys))
+	for i := range keys {
+		out = append(out, toAPIKeyDTO(&keys[i]))
+	}
+	return out, nil
+}
+
+// RotateAPIKey 生成同权限的新 Key；旧 Key 在 grace 后失效（0 表示立即撤销）
+func (s *Service) RotateAPIKey(ctx context.Context, principal *shared.Principal, keyUUID string, grace time.Duration) (*APIKeyCreated, error) {
+	old, err := s.ownedKey(ctx, principal, keyUUID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !old.Active(now) {
+		return nil, fmt.Errorf("%w: key is revoked or expired", ErrInvalidKey)
+	}
+	if grace < 0 || grace > maxRotateGrace {
+		return nil, fmt.Errorf("%w: grace period must be between 0 and %s", ErrInvalidKey, maxRotateGrace)
+	}
+
+	// 新 Key 沿用原有效期长度
+	ttl := defaultKeyTTL
+	if old.ExpiresAt != nil {
+		ttl = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+	expiresAt := now.Add(ttl)
+	created, err := s.storeKey(ctx, &models.APIKey{
+		Name:        old.Name,
+		UserID:      old.UserID,
+		ProjectID:   old.ProjectID,
+		Scopes:      old.Scopes,
+		ExpiresAt:   &expiresAt,
+		RotatedFrom: &old.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"revoked_at": now}
+	if grace > 0 {
+		updates = map[string]interface{}{"expires_at": now.Add(grace)}
+	}
+	if err := s.repo.UpdateAPIKeyFields(ctx, old.ID, updates); err != nil {
+		return nil, fmt.Errorf("retire old key failed: %w", err)
+	}
+	before := map[string]interface{}{"expires_at": old.ExpiresAt, "revoked_at": old.RevokedAt}
+	after := audit.Apply(before, updates)
+	after["rotated_to"] = created.ID
+	s.auditKey(ctx, audit.ActionAPIKeyRotate, old, before, after)
+	return created, nil
+}
+
+// RevokeAPIKey 立即撤销 API Key
+func (s *Service) RevokeAPIKey(ctx context.Context, principal *shared.Principal, keyUUID string) (*APIKeyDTO, error) {
+	key, err := s.ownedKey(ctx, principal, keyUUID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt == nil {
+		now := s.now()
+		if err := s.repo.UpdateAPIKeyFields(ctx, key.ID, map[string]interface{}{"revoked_at": now}); err != nil {
+			return nil, fmt.Errorf("revoke key failed: %w", err)
+		}
+		s.auditKey(ctx, audit.ActionAPIKeyRevoke, key, map[string]interface{}{"revoked_at": nil}, map[string]interface{}{"revoked_at": now})
+		key.RevokedAt = &now
+	}
+	dto := toAPIKeyDTO(key)
+	return &dto, nil
+}
+
+// NormalizeScopes 校验、去重并排序权限范围
+func NormalizeScopes(scopes []string) (models.StringArray, error) {
+	known := make(map[string]bool, len(Scopes))
+	for _, s := range Scopes {
+		known[s] = true
+	}
+	seen := make(map[string]bool, len(scopes))
+	out := models.StringArray{}
+	for _, raw := range scopes {
+		s := strings.ToLower(strings.TrimSpace(raw))
+		if s == "" || seen[s] {
+			continue
+		}
+		if !known[s] {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidKey, raw)
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidKey)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// authenticateKey 校验 API Key，身份为 Key 的所有者，权限限制在 Key 的 scopes 内
+func (s *Service) authenticateKey(ctx context.Context, raw string) (*shared.Principal, error) {
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashToken(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown api key", ErrInvalidToken)
+	}
+	now := s.now()
+	if !key.Active(now) {
+		return nil, fmt.Errorf("%w: api key revoked or expired", ErrInvalidToken)
+	}
+	user, err := s.repo.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: key owner not found", ErrInvalidToken)
+	}
+	if err := checkStatus(user); err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := s.repo.UpdateAPIKeyFields(ctx, key.ID, map[string]interface{}{"last_used_at": now}); err != nil {
+			log.Printf("更新 API Key 最近使用时间失败(key=%s): %v", key.Prefix, err)
+		}
+	}
+	return &shared.Principal{
+		UserID:    user.ID,
+		UUID:      user.UUID,
+		Username:  user.Username,
+		Role:      string(user.Role),
+		APIKeyID:  key.ID,
+		Scopes:    key.Scopes,
+		ProjectID: key.ProjectID,
+	}, nil
+}
+
+// storeKey 生成明文 Key 并保存摘要
+func (s *Service) storeKey(ctx context.Context, key *models.APIKey) (*APIKeyCreated, error) {
+	prefix := make([]byte, 4)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key.UUID = uuid.New().String()
+	key.Prefix = apiKeyPrefix + hex.EncodeToString(prefix)
+	raw := key.Prefix + "_" + secret
+	key.KeyHash = hashToken(raw)
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, fmt.Errorf("create api key failed: %w", err)
+	}
+	return &APIKeyCreated{APIKeyDTO: toAPIKeyDTO(key), Key: raw}, nil
+}
+
+func (s *Service) ownedKey(ctx context.Context, principal *shared.Principal, keyUUID string) (*models.APIKey, error) {
+	if err := requireUserSession(principal); err != nil {
+		return nil, err
+	}
+	key, err := s.repo.GetAPIKeyByUUID(ctx, keyUUID)
+	if err != nil {
+		return nil, ErrKeyNotFound
+	}
+	if key.UserID != principal.UserID && principal.Role != string(models.RoleAdmin) {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// requireUserSession API Key 管理只允许登录用户操作
+func requireUserSession(principal *shared.Principal) error {
+	if principal == nil || principal.UserID == 0 {
+		return ErrInvalidToken
+	}
+	if principal.APIKeyID != 0 {
+		return fmt.Errorf("%w: api keys cannot manage api keys", ErrForbidden)
+	}
+	return nil
+}
+
+// isAPIKey 按前缀区分 API Key 与 JWT 访问令牌
+func isAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 认证接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务供中间件使用
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// LoginRequest 登录请求，username 也可以填写邮箱
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest 登出请求：默认撤销当前会话
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+	All          bool   `json:"all,omitempty"`
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Login 用户名/邮箱 + 密码登录
+func (h *Handler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	pair, err := h.service.Login(c.Request.Context(), req.Username, req.Password, sessionMeta(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(pair))
+}
+
+// Refresh 轮换刷新令牌并签发新的访问令牌
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	pair, err := h.service.Refresh(c.Request.Context(), req.RefreshToken, sessionMeta(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(pair))
+}
+
+// Logout 撤销会话
+func (h *Handler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+			return
+		}
+	}
+	if err := h.service.Logout(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()), req.RefreshToken, req.All); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"logged_out": true}))
+}
+
+// ChangePassword 修改当前用户密码，其他会话随之失效
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	if err := h.service.ChangePassword(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()), req.OldPassword, req.NewPassword); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"changed": true}))
+}
+
+// Me 当前登录用户
+func (h *Handler) Me(c *gin.Context) {
+	user, err := h.service.CurrentUser(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(user))
+}
+
+// ListScopes 可授予 API Key 的权限范围
+func (h *Handler) ListScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"scopes": Scopes}))
+}
+
+// ListAPIKeys 当前用户的 API Key
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.service.ListAPIKeys(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"keys": keys}))
+}
+
+// CreateAPIKey 创建 API Key，明文只在响应中返回一次
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req APIKeyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	key, err := h.service.CreateAPIKey(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(key))
+}
+
+// RotateAPIKeyRequest 轮换请求：旧 Key 保留 grace_hours 小时后失效
+type RotateAPIKeyRequest struct {
+	GraceHours int `json:"grace_hours"`
+}
+
+// RotateAPIKey 轮换 API Key
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	var req RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+			return
+		}
+	}
+	key, err := h.service.RotateAPIKey(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()), c.Param("id"), time.Duration(req.GraceHours)*time.Hour)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(key))
+}
+
+// RevokeAPIKey 撤销 API Key
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	key, err := h.service.RevokeAPIKey(c.Request.Context(), shared.PrincipalFrom(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(key))
+}
+
+func sessionMeta(c *gin.Context) SessionMeta {
+	return SessionMeta{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, shared.ErrorResponse(401, err.Error()))
+	case errors.Is(err, ErrKeyNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, ErrInvalidKey):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	case errors.Is(err, ErrUserDisabled), errors.Is(err, ErrForbidden):
+		c.JSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+	case errors.Is(err, ErrInvalidPassword):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 用户与登录会话仓储
+type Repository interface {
+	// GetUserByLogin 按用户名或邮箱查询
+	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	UpdateUserFields(ctx context.Context, id uint, updates map[string]interface{}) error
+
+	CreateSession(ctx context.Context, session *models.RefreshToken) error
+	GetSessionByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	GetSessionByID(ctx context.Context, id uint) (*models.RefreshToken, error)
+	// RevokeSession 撤销未撤销的会话，返回是否实际撤销（用于刷新轮换的并发保护）
+	RevokeSession(ctx context.Context, id uint, at time.Time) (bool, error)
+	// RevokeUserSessions 撤销用户的全部会话，exceptID 非 0 时保留该会话
+	RevokeUserSessions(ctx context.Context, userID, exceptID uint, at time.Time) (int64, error)
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetAPIKeyByUUID(ctx context.Context, uuid string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error)
+	UpdateAPIKeyFields(ctx context.Context, id uint, updates map[string]interface{}) error
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ? OR email = ?", login, login).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *gormRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *gormRepository) UpdateUserFields(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *gormRepository) CreateSession(ctx context.Context, session *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *gormRepository) GetSessionByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var session models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *gormRepository) GetSessionByID(ctx context.Context, id uint) (*models.RefreshToken, error) {
+	var session models.RefreshToken
+	if err := r.db.WithContext(ctx).First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *gormRepository) RevokeSession(ctx context.Context, id uint, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *gormRepository) RevokeUserSessions(ctx context.Context, userID, exceptID uint, at time.Time) (int64, error) {
+	q := r.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID > 0 {
+		q = q.Where("id <> ?", exceptID)
+	}
+	res := q.Update("revoked_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *gormRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *gormRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *gormRepository) GetAPIKeyByUUID(ctx context.Context, uuid string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *gormRepository) ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *gormRepository) UpdateAPIKeyFields(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Updates(updates).Error
+}
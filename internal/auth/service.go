@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidToken 令牌无效、过期或已撤销
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUserDisabled 用户被禁用或封禁
+	ErrUserDisabled = errors.New("user is disabled")
+	// ErrInvalidPassword 新密码不符合要求
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
+// maxPasswordBytes bcrypt 只使用前 72 字节
+const maxPasswordBytes = 72
+
+// Service 登录、刷新令牌与访问令牌校验
+type Service struct {
+	repo     Repository
+	cfg      config.Auth
+	secret   []byte
+	now      func() time.Time
+	projects ProjectLookup
+	audit    ports.AuditRecorder
+}
+
+// NewService 按全局配置创建服务
+func NewService() *Service {
+	cfg := config.Auth{AccessTTL: 15 * time.Minute, RefreshTTL: 30 * 24 * time.Hour, BcryptCost: bcrypt.DefaultCost, MinPasswordLen: 8}
+	if config.AuthConfig != nil {
+		cfg = *config.AuthConfig
+	}
+	return NewServiceWithDeps(NewRepository(database.DB), cfg)
+}
+
+// NewServiceWithDeps 支持依赖注入；未配置密钥时使用进程内随机密钥
+func NewServiceWithDeps(repo Repository, cfg config.Auth) *Service {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("✗ generate jwt secret failed: %v", err)
+		}
+	}
+	if cfg.BcryptCost < bcrypt.MinCost {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	return &Service{repo: repo, cfg: cfg, secret: secret, now: time.Now}
+}
+
+// Enabled 是否开启认证
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// AnonymousPrincipal 关闭认证时使用的默认身份
+func (s *Service) AnonymousPrincipal(ctx context.Context) *shared.Principal {
+	p := &shared.Principal{UserID: s.cfg.AnonymousUserID, Username: "anonymous", Role: string(models.RoleAdmin)}
+	if user, err := s.repo.GetUserByID(ctx, s.cfg.AnonymousUserID); err == nil {
+		p.UUID, p.Username, p.Role = user.UUID, user.Username, string(user.Role)
+	}
+	return p
+}
+
+// SessionMeta 登录/刷新请求的客户端信息
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair 登录/刷新返回的令牌
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *UserDTO  `json:"user"`
+}
+
+// UserDTO 对外暴露的用户信息
+type UserDTO struct {
+	ID          uint       `json:"id"`
+	UUID        string     `json:"uuid"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+func toUserDTO(u *models.User) *UserDTO {
+	return &UserDTO{
+		ID:          u.ID,
+		UUID:        u.UUID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Role:        string(u.Role),
+		Status:      string(u.Status),
+		LastLoginAt: u.LastLoginAt,
+	}
+}
+
+// Login 校验用户名（或邮箱）与密码，创建会话并签发令牌
+func (s *Service) Login(ctx context.Context, login, password string, meta SessionMeta) (*TokenPair, error) {
+	login = strings.TrimSpace(login)
+	if login == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	user, err := s.repo.GetUserByLogin(ctx, login)
+	if err != nil {
+		// 用户不存在时同样做一次哈希比较，避免通过耗时区分账号是否存在
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := checkStatus(user); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	pair, err := s.issue(ctx, user, meta, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateUserFields(ctx, user.ID, map[string]interface{}{"last_login_at": now}); err != nil {
+		log.Printf("更新最近登录时间失败(user=%d): %v", user.ID, err)
+	}
+	pair.User.LastLoginAt = &now
+	return pair, nil
+}
+
+// Refresh 用刷新令牌换取新令牌，旧刷新令牌立即失效；
+// 已轮换过的令牌被再次使用时视为泄露，撤销该用户全部会话
+func (s *Service) Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidToken
+	}
+	session, err := s.repo.GetSessionByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	now := s.now()
+	if session.RevokedAt != nil {
+		if n, err := s.repo.RevokeUserSessions(ctx, session.UserID, 0, now); err == nil && n > 0 {
+			log.Printf("⚠ 刷新令牌被重复使用(user=%d)，已撤销 %d 个会话", session.UserID, n)
+		}
+		return nil, ErrInvalidToken
+	}
+	if !session.Active(now) {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := checkStatus(user); err != nil {
+		return nil, err
+	}
+	revoked, err := s.repo.RevokeSession(ctx, session.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("revoke session failed: %w", err)
+	}
+	if !revoked {
+		// 并发刷新：另一请求已轮换该令牌
+		return nil, ErrInvalidToken
+	}
+	return s.issue(ctx, user, meta, now)
+}
+
+// Logout 撤销当前会话；refreshToken 非空时撤销对应会话，all 为 true 时撤销该用户全部会话
+func (s *Service) Logout(ctx context.Context, principal *shared.Principal, refreshToken string, all bool) error {
+	if err := requireUserSession(principal); err != nil {
+		return err
+	}
+	now := s.now()
+	if all {
+		_, err := s.repo.RevokeUserSessions(ctx, principal.UserID, 0, now)
+		return err
+	}
+	sessionID := principal.SessionID
+	if refreshToken != "" {
+		session, err := s.repo.GetSessionByHash(ctx, hashToken(refreshToken))
+		if err != nil || session.UserID != principal.UserID {
+			return ErrInvalidToken
+		}
+		sessionID = session.ID
+	}
+	if sessionID == 0 {
+		return nil
+	}
+	_, err := s.repo.RevokeSession(ctx, sessionID, now)
+	return err
+}
+
+// ChangePassword 校验旧密码后更新，并撤销除当前会话外的全部会话
+func (s *Service) ChangePassword(ctx context.Context, principal *shared.Principal, oldPassword, newPassword string) error {
+	if err := requireUserSession(principal); err != nil {
+		return err
+	}
+	user, err := s.repo.GetUserByID(ctx, principal.UserID)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)) != nil {
+		return ErrInvalidCredentials
+	}
+	if err := s.validatePassword(newPassword); err != nil {
+		return err
+	}
+	if oldPassword == newPassword {
+		return fmt.Errorf("%w: new password must differ from the current one", ErrInvalidPassword)
+	}
+	hash, err := s.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateUserFields(ctx, user.ID, map[string]interface{}{"password_hash": hash}); err != nil {
+		return fmt.Errorf("update password failed: %w", err)
+	}
+	if _, err := s.repo.RevokeUserSessions(ctx, user.ID, principal.SessionID, s.now()); err != nil {
+		return fmt.Errorf("revoke sessions failed: %w", err)
+	}
+	return nil
+}
+
+// Authenticate 校验访问令牌（或 API Key），并确认会话未撤销、用户状态正常
+func (s *Service) Authenticate(ctx context.Context, token string) (*shared.Principal, error) {
+	if isAPIKey(token) {
+		return s.authenticateKey(ctx, token)
+	}
+	claims, err := parseToken(s.secret, token, s.cfg.Issuer, s.now())
+	if err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 {
+		return nil, fmt.Errorf("%w: bad subject", ErrInvalidToken)
+	}
+	session, err := s.repo.GetSessionByID(ctx, claims.SessionID)
+	if err != nil || session.UserID != uint(userID) || session.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: session revoked", ErrInvalidToken)
+	}
+	user, err := s.repo.GetUserByID(ctx, uint(userID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: user not found", ErrInvalidToken)
+	}
+	if err := checkStatus(user); err != nil {
+		return nil, err
+	}
+	return &shared.Principal{
+		UserID:    user.ID,
+		UUID:      user.UUID,
+		Username:  user.Username,
+		Role:      string(user.Role),
+		SessionID: session.ID,
+	}, nil
+}
+
+// CurrentUser 当前用户信息
+func (s *Service) CurrentUser(ctx context.Context, principal *shared.Principal) (*UserDTO, error) {
+	if principal == nil || principal.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.repo.GetUserByID(ctx, principal.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return toUserDTO(user), nil
+}
+
+// HashPassword bcrypt 哈希
+func (s *Service) HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.cfg.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password failed: %w", err)
+	}
+	return string(hash), nil
+}
+
+func (s *Service) validatePassword(password string) error {
+	if len(password) < s.cfg.MinPasswordLen {
+		return fmt.Errorf("%w: must be at least %d characters", ErrInvalidPassword, s.cfg.MinPasswordLen)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrInvalidPassword, maxPasswordBytes)
+	}
+	return nil
+}
+
+// issue 创建会话并签发访问令牌与刷新令牌
+func (s *Service) issue(ctx context.Context, user *models.User, meta SessionMeta, now time.Time) (*TokenPair, error) {
+	refresh, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token failed: %w", err)
+	}
+	session := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: now.Add(s.cfg.RefreshTTL),
+		UserAgent: truncate(meta.UserAgent, 255),
+		IP:        truncate(meta.IP, 64),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("create session failed: %w", err)
+	}
+	access, err := signToken(s.secret, accessClaims{
+		Issuer:    s.cfg.Issuer,
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		SessionID: session.ID,
+		Username:  user.Username,
+		Role:      string(user.Role),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.cfg.AccessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign access token failed: %w", err)
+	}
+	return &TokenPair{
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(s.cfg.AccessTTL / time.Second),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             toUserDTO(user),
+	}, nil
+}
+
+// checkStatus 只有 active 用户可以登录与访问
+func checkStatus(user *models.User) error {
+	switch user.Status {
+	case models.StatusActive, "":
+		return nil
+	case models.StatusBanned:
+		return fmt.Errorf("%w: account is banned", ErrUserDisabled)
+	default:
+		return fmt.Errorf("%w: account is %s", ErrUserDisabled, user.Status)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// dummyHash 用户不存在时用于比较的固定哈希
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
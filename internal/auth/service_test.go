@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type memRepo struct {
+	users    map[uint]*models.User
+	sessions map[uint]*models.RefreshToken
+	keys     []*models.APIKey
+}
+
+func (m *memRepo) GetUserByLogin(_ context.Context, login string) (*models.User, error) {
+	for _, u := range m.users {
+		if u.Username == login || u.Email == login {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) GetUserByID(_ context.Context, id uint) (*models.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) UpdateUserFields(_ context.Context, id uint, updates map[string]interface{}) error {
+	if h, ok := updates["password_hash"].(string); ok {
+		m.users[id].PasswordHash = h
+	}
+	return nil
+}
+
+func (m *memRepo) CreateSession(_ context.Context, s *models.RefreshToken) error {
+	s.ID = uint(len(m.sessions) + 1)
+	m.sessions[s.ID] = s
+	return nil
+}
+
+func (m *memRepo) GetSessionByHash(_ context.Context, hash string) (*models.RefreshToken, error) {
+	for _, s := range m.sessions {
+		if s.TokenHash == hash {
+			return s, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) GetSessionByID(_ context.Context, id uint) (*models.RefreshToken, error) {
+	if s, ok := m.sessions[id]; ok {
+		return s, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) RevokeSession(_ context.Context, id uint, at time.Time) (bool, error) {
+	s, ok := m.sessions[id]
+	if !ok || s.RevokedAt != nil {
+		return false, nil
+	}
+	s.RevokedAt = &at
+	return true, nil
+}
+
+func (m *memRepo) RevokeUserSessions(_ context.Context, userID, exceptID uint, at time.Time) (int64, error) {
+	var n int64
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ID != exceptID && s.RevokedAt == nil {
+			s.RevokedAt = &at
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memRepo) CreateAPIKey(_ context.Context, k *models.APIKey) error {
+	k.ID = uint(len(m.keys) + 1)
+	k.CreatedAt = time.Now()
+	m.keys = append(m.keys, k)
+	return nil
+}
+
+func (m *memRepo) GetAPIKeyByHash(_ context.Context, hash string) (*models.APIKey, error) {
+	for _, k := range m.keys {
+		if k.KeyHash == hash {
+			return k, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) GetAPIKeyByUUID(_ context.Context, id string) (*models.APIKey, error) {
+	for _, k := range m.keys {
+		if k.UUID == id {
+			return k, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) ListAPIKeys(_ context.Context, userID uint) ([]models.APIKey, error) {
+	var out []models.APIKey
+	for _, k := range m.keys {
+		if k.UserID == userID {
+			out = append(out, *k)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) UpdateAPIKeyFields(_ context.Context, id uint, updates map[string]interface{}) error {
+	k := m.keys[id-1]
+	if v, ok := updates["revoked_at"].(time.Time); ok {
+		k.RevokedAt = &v
+	}
+	if v, ok := updates["expires_at"].(time.Time); ok {
+		k.ExpiresAt = &v
+	}
+	if v, ok := updates["last_used_at"].(time.Time); ok {
+		k.LastUsedAt = &v
+	}
+	return nil
+}
+
+func TestLoginRefreshRotationAndStatus(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	repo := &memRepo{
+		users: map[uint]*models.User{
+			1: {UUIDModel: models.UUIDModel{ID: 1}, Username: "alice", Email: "a@example.com", PasswordHash: string(hash), Role: models.RoleUser, Status: models.StatusActive},
+		},
+		sessions: map[uint]*models.RefreshToken{},
+	}
+	svc := NewServiceWithDeps(repo, config.Auth{Enabled: true, JWTSecret: "k", Issuer: "test", AccessTTL: time.Minute, RefreshTTL: time.Hour, BcryptCost: bcrypt.MinCost, MinPasswordLen: 8})
+	ctx := context.Background()
+
+	if _, err := svc.Login(ctx, "alice", "wrong", SessionMeta{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	pair, err := svc.Login(ctx, "a@example.com", "secret123", SessionMeta{})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	p, err := svc.Authenticate(ctx, pair.AccessToken)
+	if err != nil || p.UserID != 1 || p.Role != "user" {
+		t.Fatalf("authenticate: %+v %v", p, err)
+	}
+	if _, err := svc.Authenticate(ctx, pair.AccessToken+"x"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("tampered token accepted: %v", err)
+	}
+
+	next, err := svc.Refresh(ctx, pair.RefreshToken, SessionMeta{})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("access token of rotated session should be rejected: %v", err)
+	}
+	// 重放旧刷新令牌：拒绝并撤销全部会话
+	if _, err := svc.Refresh(ctx, pair.RefreshToken, SessionMeta{}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("reused refresh token accepted: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, next.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("sessions should be revoked after reuse: %v", err)
+	}
+
+	again, err := svc.Login(ctx, "alice", "secret123", SessionMeta{})
+	if err != nil {
+		t.Fatalf("login again: %v", err)
+	}
+	repo.users[1].Status = models.StatusBanned
+	if _, err := svc.Authenticate(ctx, again.AccessToken); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("banned user should be rejected: %v", err)
+	}
+	if _, err := svc.Login(ctx, "alice", "secret123", SessionMeta{}); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("banned user login: %v", err)
+	}
+}
+
+func TestAPIKeyScopesRotationAndRevocation(t *testing.T) {
+	repo := &memRepo{
+		users:    map[uint]*models.User{1: {UUIDModel: models.UUIDModel{ID: 1}, Username: "ci", Role: models.RoleUser, Status: models.StatusActive}},
+		sessions: map[uint]*models.RefreshToken{},
+	}
+	svc := NewServiceWithDeps(repo, config.Auth{Enabled: true, JWTSecret: "k", AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	ctx := context.Background()
+	owner := &shared.Principal{UserID: 1, Role: "user"}
+
+	if _, err := svc.CreateAPIKey(ctx, owner, APIKeyInput{Name: "ci", Scopes: []string{"creative:delete"}}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("unknown scope accepted: %v", err)
+	}
+	created, err := svc.CreateAPIKey(ctx, owner, APIKeyInput{Name: "ci", Scopes: []string{"creative:write", "traces:read", "creative:write"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(created.Scopes) != 2 || created.ExpiresAt == nil {
+		t.Fatalf("unexpected key: %+v", created.APIKeyDTO)
+	}
+
+	p, err := svc.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("authenticate key: %v", err)
+	}
+	if p.UserID != 1 || p.APIKeyID == 0 || !p.HasScope("creative:write") || p.HasScope("experiment:write") {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+	if repo.keys[0].LastUsedAt == nil {
+		t.Fatal("last used time not recorded")
+	}
+	if _, err := svc.CreateAPIKey(ctx, p, APIKeyInput{Name: "nested", Scopes: []string{"creative:read"}}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("api key must not create keys: %v", err)
+	}
+
+	rotated, err := svc.RotateAPIKey(ctx, owner, created.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, created.Key); err != nil {
+		t.Fatalf("old key should work during grace period: %v", err)
+	}
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := svc.Authenticate(ctx, created.Key); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("old key should expire after grace period: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, rotated.Key); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if _, err := svc.RevokeAPIKey(ctx, owner, rotated.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, rotated.Key); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("revoked key accepted: %v", err)
+	}
+}
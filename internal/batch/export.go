@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// ExportManifest 合并导出清单：每个商品一项，素材位于各自目录下
+type ExportManifest struct {
+	BatchID    string               `json:"batch_id"`
+	Name       string               `json:"name"`
+	Status     string               `json:"status"`
+	ExportedAt string               `json:"exported_at"`
+	Items      []ExportManifestItem `json:"items"`
+}
+
+// ExportManifestItem 单个商品的导出结果
+type ExportManifestItem struct {
+	Row    int                           `json:"row"`
+	Title  string                        `json:"title"`
+	TaskID string                        `json:"task_id,omitempty"`
+	Status string                        `json:"status"`
+	Dir    string                        `json:"dir,omitempty"`
+	Error  string                        `json:"error,omitempty"`
+	Assets []creative.ExportManifestItem `json:"assets,omitempty"`
+}
+
+// Export 已结束批量的合并导出
+type Export struct {
+	progress *Progress
+	tasks    map[int]*creative.TaskExport // 按行号
+	errs     map[int]string
+}
+
+// PrepareExport 准备合并导出：批量须已结束；没有可导出素材的商品记录在清单中
+func (s *Service) PrepareExport(ctx context.Context, batchUUID string, opts creative.ExportOptions) (*Export, error) {
+	if s.generator == nil {
+		return nil, errors.New("batch generator not configured")
+	}
+	p, err := s.Get(ctx, batchUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Done() {
+		return nil, ErrNotReady
+	}
+	e := &Export{progress: p, tasks: map[int]*creative.TaskExport{}, errs: map[int]string{}}
+	for _, it := range p.Items {
+		if it.TaskID == "" || it.Status != string(models.TaskCompleted) {
+			continue
+		}
+		te, err := s.generator.PrepareExport(ctx, it.TaskID, opts)
+		if err != nil {
+			e.errs[it.Row] = err.Error()
+			continue
+		}
+		e.tasks[it.Row] = te
+	}
+	if len(e.tasks) == 0 {
+		return nil, fmt.Errorf("%w: no completed tasks with exportable assets", ErrInvalid)
+	}
+	return e, nil
+}
+
+// FileName 建议的下载文件名
+func (e *Export) FileName() string {
+	return fmt.Sprintf("batch_%s.zip", e.progress.Batch.UUID)
+}
+
+// WriteZip 流式写出 ZIP：每个商品一个目录（行号_任务ID前缀）+ manifest.json + manifest.csv
+func (e *Export) WriteZip(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	b := e.progress.Batch
+	manifest := ExportManifest{
+		BatchID:    b.UUID,
+		Name:       b.Name,
+		Status:     string(b.Status),
+		ExportedAt: time.Now().Format(time.RFC3339),
+	}
+	for _, it := range e.progress.Items {
+		item := ExportManifestItem{Row: it.Row, Title: it.Title, TaskID: it.TaskID, Status: it.Status, Error: it.Error}
+		if msg, ok := e.errs[it.Row]; ok {
+			item.Error = msg
+		}
+		if te, ok := e.tasks[it.Row]; ok {
+			item.Dir = itemDir(it)
+			item.Assets = te.WriteFiles(ctx, zw, item.Dir+"/")
+		}
+		manifest.Items = append(manifest.Items, item)
+	}
+
+	jw, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(jw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	cw, err := zw.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeManifestCSV(cw, manifest.Items); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// itemDir 商品目录名，形如 003_1a2b3c4d
+func itemDir(it ItemProgress) string {
+	id := it.TaskID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%03d_%s", it.Row, id)
+}
+
+// writeManifestCSV 每个素材一行；没有素材的商品单独一行，便于核对失败项
+func writeManifestCSV(w io.Writer, items []ExportManifestItem) error {
+	cw := csv.NewWriter(w)
+	header := []string{"row", "title", "task_id", "status", "error", "asset_id", "file", "format", "width", "height", "variant_index", "cta_text", "selling_points", "public_url", "asset_error"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, it := range items {
+		base := []string{strconv.Itoa(it.Row), it.Title, it.TaskID, it.Status, it.Error}
+		if len(it.Assets) == 0 {
+			if err := cw.Write(append(base, make([]string, len(header)-len(base))...)); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, a := range it.Assets {
+			variant := ""
+			if a.VariantIndex != nil {
+				variant = strconv.Itoa(*a.VariantIndex)
+			}
+			row := append(append([]string{}, base...),
+				a.AssetID, a.File, a.Format, strconv.Itoa(a.Width), strconv.Itoa(a.Height), variant,
+				a.CTAText, strings.Join(a.SellingPoints, " | "), a.PublicURL, a.Error)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,211 @@
+package batch
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUploadBytes 商品列表文件大小上限
+const maxUploadBytes = 5 << 20
+
+// Handler 批量生成接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务（供注入生成与文案依赖）
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// BatchData 创建批量的响应
+type BatchData struct {
+	BatchID         string `json:"batch_id"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	TotalItems      int    `json:"total_items"`
+	AutoCopywriting bool   `json:"auto_copywriting"`
+}
+
+// Create 创建批量，支持三种请求：
+// multipart（file 为 .csv / .json，可选 name、auto_copywriting 字段）；
+// text/csv 请求体（?name=&auto_copywriting=）；JSON {name, auto_copywriting, items}
+func (h *Handler) Create(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	in, err := parseRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	b, err := h.service.Create(c.Request.Context(), in)
+	if err != nil {
+		if errors.Is(err, ErrInvalid) {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to create batch: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(BatchData{
+		BatchID:         b.UUID,
+		Name:            b.Name,
+		Status:          string(b.Status),
+		TotalItems:      b.TotalItems,
+		AutoCopywriting: b.AutoCopywriting,
+	}))
+}
+
+func parseRequest(c *gin.Context) (CreateInput, error) {
+	var in CreateInput
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return in, errors.New("file is required: " + err.Error())
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return in, errors.New("failed to read file: " + err.Error())
+		}
+		defer f.Close()
+		format := strings.ToLower(c.PostForm("format"))
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fh.Filename)), ".")
+		}
+		if err := parseInto(&in, format, f); err != nil {
+			return in, err
+		}
+		if name := c.PostForm("name"); name != "" {
+			in.Name = name
+		}
+		if v := c.PostForm("auto_copywriting"); v != "" {
+			in.AutoCopywriting, _ = strconv.ParseBool(v)
+		}
+	case "text/csv":
+		if err := parseInto(&in, models.BatchSourceCSV, c.Request.Body); err != nil {
+			return in, err
+		}
+		in.Name = c.Query("name")
+		in.AutoCopywriting, _ = strconv.ParseBool(c.Query("auto_copywriting"))
+	default:
+		if err := parseInto(&in, models.BatchSourceJSON, c.Request.Body); err != nil {
+			return in, err
+		}
+	}
+	return in, nil
+}
+
+// parseInto 按格式解析商品列表；JSON 中的 name / auto_copywriting 一并读取
+func parseInto(in *CreateInput, format string, r io.Reader) error {
+	switch format {
+	case models.BatchSourceCSV:
+		items, err := ParseCSV(r)
+		if err != nil {
+			return err
+		}
+		in.Source, in.Items = models.BatchSourceCSV, items
+	case models.BatchSourceJSON:
+		spec, err := ParseJSON(r)
+		if err != nil {
+			return err
+		}
+		in.Source, in.Name, in.AutoCopywriting, in.Items = models.BatchSourceJSON, spec.Name, spec.AutoCopywriting, spec.Items
+	default:
+		return errors.New("unsupported file format, expected csv or json")
+	}
+	return nil
+}
+
+// List 批量列表 ?page=&page_size=
+func (h *Handler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	result, err := h.service.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// Get 批量汇总进度与失败明细
+func (h *Handler) Get(c *gin.Context) {
+	progress, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(progress))
+}
+
+// Export 合并导出已结束批量的素材（formats/variants 可选，逗号分隔）
+func (h *Handler) Export(c *gin.Context) {
+	opts := creative.ExportOptions{Formats: splitQuery(c, "formats")}
+	for _, v := range splitQuery(c, "variants") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid variants: "+v))
+			return
+		}
+		opts.Variants = append(opts.Variants, n)
+	}
+
+	export, err := h.service.PrepareExport(c.Request.Context(), c.Param("id"), opts)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName()+`"`)
+	c.Status(http.StatusOK)
+	if err := export.WriteZip(c.Request.Context(), c.Writer); err != nil {
+		// 响应已开始写出，只能记录日志
+		log.Printf("导出批量 %s 失败: %v", c.Param("id"), err)
+	}
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, ErrNotReady):
+		c.JSON(http.StatusConflict, shared.ErrorResponse(409, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
+
+// splitQuery 支持 ?k=a,b 与 ?k=a&k=b 两种写法
+func splitQuery(c *gin.Context, key string) []string {
+	var out []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ItemInput 单个商品：标题、卖点、商品图与可选覆盖项（为空时使用项目默认设置）
+type ItemInput struct {
+	Title           string   `json:"title"`
+	ProductName     string   `json:"product_name,omitempty"`
+	SellingPoints   []string `json:"selling_points,omitempty"`
+	ProductImageURL string   `json:"product_image_url,omitempty"`
+	CTAText         string   `json:"cta_text,omitempty"`
+	Style           string   `json:"style,omitempty"`
+	Formats         []string `json:"formats,omitempty"`
+	NumVariants     int      `json:"num_variants,omitempty"`
+	Language        string   `json:"language,omitempty"`
+}
+
+// Spec JSON 批量描述；也接受顶层直接为商品数组
+type Spec struct {
+	Name            string      `json:"name"`
+	AutoCopywriting bool        `json:"auto_copywriting"`
+	Items           []ItemInput `json:"items"`
+}
+
+// listSeparator CSV 中列表字段（卖点、格式）的分隔符
+const listSeparator = "|"
+
+// csvColumns CSV 表头（不区分大小写），image_url 为 product_image_url 的别名
+var csvColumns = map[string]string{
+	"title":             "title",
+	"product_name":      "product_name",
+	"selling_points":    "selling_points",
+	"product_image_url": "product_image_url",
+	"image_url":         "product_image_url",
+	"cta_text":          "cta_text",
+	"style":             "style",
+	"formats":           "formats",
+	"num_variants":      "num_variants",
+	"language":          "language",
+}
+
+// ParseCSV 解析带表头的 CSV，列表字段用 "|" 分隔；空行跳过
+func ParseCSV(r io.Reader) ([]ItemInput, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: csv is empty", ErrInvalid)
+		}
+		return nil, fmt.Errorf("%w: read csv header: %v", ErrInvalid, err)
+	}
+	columns := make([]string, len(header))
+	hasTitle := false
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		field, ok := csvColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown csv column %q", ErrInvalid, h)
+		}
+		columns[i] = field
+		hasTitle = hasTitle || field == "title" || field == "product_name"
+	}
+	if !hasTitle {
+		return nil, fmt.Errorf("%w: csv must have a title or product_name column", ErrInvalid)
+	}
+
+	var items []ItemInput
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: read csv: %v", ErrInvalid, err)
+		}
+		line, _ := cr.FieldPos(0)
+		if blankRecord(record) {
+			continue
+		}
+		var item ItemInput
+		for i, v := range record {
+			if i >= len(columns) {
+				break
+			}
+			v = strings.TrimSpace(v)
+			switch columns[i] {
+			case "title":
+				item.Title = v
+			case "product_name":
+				item.ProductName = v
+			case "selling_points":
+				item.SellingPoints = splitList(v)
+			case "product_image_url":
+				item.ProductImageURL = v
+			case "cta_text":
+				item.CTAText = v
+			case "style":
+				item.Style = v
+			case "formats":
+				item.Formats = splitList(v)
+			case "num_variants":
+				if v == "" {
+					continue
+				}
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("%w: line %d: num_variants must be an integer", ErrInvalid, line)
+				}
+				item.NumVariants = n
+			case "language":
+				item.Language = v
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ParseJSON 解析 JSON 批量描述：{"name":..,"items":[..]} 或商品数组
+func ParseJSON(r io.Reader) (*Spec, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+	var spec Spec
+	if len(raw) > 0 && raw[0] == '[' {
+		err = json.Unmarshal(raw, &spec.Items)
+	} else {
+		err = json.Unmarshal(raw, &spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse json: %v", ErrInvalid, err)
+	}
+	return &spec, nil
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, p := range strings.Split(v, listSeparator) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func blankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
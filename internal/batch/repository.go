@@ -0,0 +1,102 @@
+package batch
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TaskState 批量内任务的进度快照
+type TaskState struct {
+	ID           uint              `json:"-"`
+	UUID         string            `json:"task_id"`
+	Status       models.TaskStatus `json:"status"`
+	Progress     int               `json:"progress"`
+	ErrorMessage string            `json:"error_message,omitempty"`
+	Cost         float64           `json:"cost"`
+}
+
+// Repository 批量生成仓储
+type Repository interface {
+	// Create 创建批量及其全部条目
+	Create(ctx context.Context, b *models.Batch) error
+	GetByUUID(ctx context.Context, uuid string) (*models.Batch, error)
+	// ListByStatus 指定状态的全部批量（不含条目）
+	ListByStatus(ctx context.Context, statuses []models.BatchStatus) ([]models.Batch, error)
+	// List 按范围条件分页查询（不含条目）
+	List(ctx context.Context, scopeCond string, scopeArgs []interface{}, page, pageSize int) ([]models.Batch, int64, error)
+	Items(ctx context.Context, batchID uint) ([]models.BatchItem, error)
+	UpdateFields(ctx context.Context, batchID uint, fields map[string]interface{}) error
+	UpdateItem(ctx context.Context, itemID uint, fields map[string]interface{}) error
+	// TaskStates 指定任务的状态与进度
+	TaskStates(ctx context.Context, taskIDs []uint) ([]TaskState, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, b *models.Batch) error {
+	return r.db.WithContext(ctx).Create(b).Error
+}
+
+func (r *gormRepository) GetByUUID(ctx context.Context, uuid string) (*models.Batch, error) {
+	var b models.Batch
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *gormRepository) ListByStatus(ctx context.Context, statuses []models.BatchStatus) ([]models.Batch, error) {
+	var out []models.Batch
+	err := r.db.WithContext(ctx).Where("status IN ?", statuses).Order("id ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *gormRepository) List(ctx context.Context, scopeCond string, scopeArgs []interface{}, page, pageSize int) ([]models.Batch, int64, error) {
+	q := r.db.WithContext(ctx).Model(&models.Batch{})
+	if scopeCond != "" {
+		q = q.Where(scopeCond, scopeArgs...)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []models.Batch
+	err := q.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&out).Error
+	return out, total, err
+}
+
+func (r *gormRepository) Items(ctx context.Context, batchID uint) ([]models.BatchItem, error) {
+	var out []models.BatchItem
+	err := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("row_num ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *gormRepository) UpdateFields(ctx context.Context, batchID uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Batch{}).Where("id = ?", batchID).Updates(fields).Error
+}
+
+func (r *gormRepository) UpdateItem(ctx context.Context, itemID uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.BatchItem{}).Where("id = ?", itemID).Updates(fields).Error
+}
+
+func (r *gormRepository) TaskStates(ctx context.Context, taskIDs []uint) ([]TaskState, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+	var out []TaskState
+	err := r.db.WithContext(ctx).Model(&models.CreativeTask{}).
+		Select("id, uuid, status, progress, error_message, cost").
+		Where("id IN ?", taskIDs).
+		Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,528 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/copywriting"
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/settings"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalid 批量输入不合法
+	ErrInvalid = errors.New("invalid batch")
+	// ErrNotFound 批量不存在或不在当前范围内
+	ErrNotFound = errors.New("batch not found")
+	// ErrNotReady 批量尚未结束，不能导出
+	ErrNotReady = errors.New("batch is still running")
+)
+
+// MaxItems 单个批量的商品数上限
+const MaxItems = 500
+
+// autoSellingPoints 自动文案时默认选用的卖点数
+const autoSellingPoints = 3
+
+// Generator 创意任务的创建、启动与导出，由 creative service 实现
+type Generator interface {
+	CreateTask(ctx context.Context, input creative.CreateTaskInput) (*models.CreativeTask, error)
+	StartCreativeGeneration(ctx context.Context, taskUUID string, opts *creative.StartCreativeOptions) error
+	PrepareExport(ctx context.Context, taskUUID string, opts creative.ExportOptions) (*creative.TaskExport, error)
+}
+
+// Copywriter 文案生成与确认，由 copywriting service 实现
+type Copywriter interface {
+	GenerateCopywriting(ctx context.Context, input copywriting.GenerateCopywritingInput) (*copywriting.GenerateCopywritingOutput, error)
+	ConfirmCopywriting(ctx context.Context, input copywriting.ConfirmCopywritingInput) (*models.CreativeTask, error)
+}
+
+// Service 批量生成服务
+type Service struct {
+	repo       Repository
+	generator  Generator
+	copywriter Copywriter
+	// spawn 执行后台提交，测试中可替换为同步执行
+	spawn func(func())
+}
+
+// NewService 创建服务
+func NewService() *Service {
+	return NewServiceWithDeps(NewRepository(database.DB))
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository) *Service {
+	return &Service{repo: repo, spawn: func(f func()) { go f() }}
+}
+
+// SetGenerator 设置任务生成方
+func (s *Service) SetGenerator(g Generator) {
+	s.generator = g
+}
+
+// SetCopywriter 设置文案生成方（auto_copywriting 需要）
+func (s *Service) SetCopywriter(c Copywriter) {
+	s.copywriter = c
+}
+
+// CreateInput 创建批量
+type CreateInput struct {
+	Name            string
+	Source          string
+	AutoCopywriting bool
+	Items           []ItemInput
+}
+
+// Create 校验商品列表并创建批量，随后在后台逐个提交任务；返回时任务尚未全部创建
+func (s *Service) Create(ctx context.Context, in CreateInput) (*models.Batch, error) {
+	if s.generator == nil {
+		return nil, errors.New("batch generator not configured")
+	}
+	if in.AutoCopywriting && s.copywriter == nil {
+		return nil, errors.New("copywriting not configured")
+	}
+	if len(in.Items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", ErrInvalid)
+	}
+	if len(in.Items) > MaxItems {
+		return nil, fmt.Errorf("%w: at most %d items per batch", ErrInvalid, MaxItems)
+	}
+
+	items := make([]models.BatchItem, 0, len(in.Items))
+	for i, it := range in.Items {
+		item, err := normalizeItem(it)
+		if err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalid, i+1, err)
+		}
+		item.Row = i + 1
+		items = append(items, item)
+	}
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		name = "batch " + time.Now().Format("2006-01-02 15:04")
+	}
+	if len([]rune(name)) > 128 {
+		return nil, fmt.Errorf("%w: name must be at most 128 characters", ErrInvalid)
+	}
+	source := in.Source
+	if source == "" {
+		source = models.BatchSourceJSON
+	}
+	b := &models.Batch{
+		UUIDModel:       models.UUIDModel{UUID: uuid.New().String()},
+		UserID:          shared.UserIDFrom(ctx),
+		ProjectID:       shared.ProjectIDFrom(ctx),
+		Name:            name,
+		Source:          source,
+		AutoCopywriting: in.AutoCopywriting,
+		Status:          models.BatchPending,
+		TotalItems:      len(items),
+		Items:           items,
+	}
+	if err := s.repo.Create(ctx, b); err != nil {
+		return nil, fmt.Errorf("create batch failed: %w", err)
+	}
+
+	bg := detach(ctx)
+	s.spawn(func() { s.submitAll(bg, b) })
+	return b, nil
+}
+
+// normalizeItem 校验单个商品；title 缺省时取 product_name
+func normalizeItem(in ItemInput) (models.BatchItem, error) {
+	item := models.BatchItem{
+		Title:           strings.TrimSpace(in.Title),
+		ProductName:     strings.TrimSpace(in.ProductName),
+		ProductImageURL: strings.TrimSpace(in.ProductImageURL),
+		CTAText:         strings.TrimSpace(in.CTAText),
+		Style:           strings.TrimSpace(in.Style),
+		NumVariants:     in.NumVariants,
+		Language:        strings.ToLower(strings.TrimSpace(in.Language)),
+		Status:          models.BatchItemPending,
+	}
+	if item.Title == "" {
+		item.Title = item.ProductName
+	}
+	if item.Title == "" {
+		return item, errors.New("title is required")
+	}
+	if len([]rune(item.Title)) > 255 || len([]rune(item.ProductName)) > 255 {
+		return item, errors.New("title must be at most 255 characters")
+	}
+	if len([]rune(item.CTAText)) > 64 {
+		return item, errors.New("cta_text must be at most 64 characters")
+	}
+	for _, p := range in.SellingPoints {
+		if p = strings.TrimSpace(p); p != "" {
+			item.SellingPoints = append(item.SellingPoints, p)
+		}
+	}
+	for _, f := range in.Formats {
+		f = strings.TrimSpace(f)
+		if !settings.IsSupportedFormat(f) {
+			return item, fmt.Errorf("format %q must be one of %s", f, strings.Join(settings.SupportedFormats, ", "))
+		}
+		item.Formats = append(item.Formats, f)
+	}
+	if item.NumVariants < 0 || item.NumVariants > settings.MaxNumVariants {
+		return item, fmt.Errorf("num_variants must be between 0 and %d", settings.MaxNumVariants)
+	}
+	if item.Language != "" && item.Language != "zh" && item.Language != "en" {
+		return item, errors.New("language must be zh or en")
+	}
+	if item.ProductImageURL != "" {
+		u, err := url.Parse(item.ProductImageURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return item, errors.New("product_image_url must be an http(s) URL")
+		}
+		if len(item.ProductImageURL) > 512 {
+			return item, errors.New("product_image_url must be at most 512 characters")
+		}
+	}
+	return item, nil
+}
+
+// detach 复制请求的身份、范围与请求元数据，供请求结束后继续运行的后台提交使用
+func detach(ctx context.Context) context.Context {
+	out := shared.WithPrincipal(context.Background(), shared.PrincipalFrom(ctx))
+	out = shared.WithScope(out, shared.ScopeFrom(ctx))
+	if m := shared.RequestMetaFrom(ctx); m != nil {
+		out = shared.WithRequestMeta(out, m)
+	}
+	return out
+}
+
+// ResumeSubmissions 服务重启后继续提交中断的批量（pending / submitting），
+// 已提交或已失败的商品不会重复处理；返回恢复的批量数
+func (s *Service) ResumeSubmissions(ctx context.Context) (int, error) {
+	if s.generator == nil {
+		return 0, errors.New("batch generator not configured")
+	}
+	batches, err := s.repo.ListByStatus(ctx, []models.BatchStatus{models.BatchPending, models.BatchSubmitting})
+	if err != nil {
+		return 0, fmt.Errorf("list interrupted batches failed: %w", err)
+	}
+	resumed := 0
+	for i := range batches {
+		b := &batches[i]
+		if b.AutoCopywriting && s.copywriter == nil {
+			log.Printf("batch %s: copywriting not configured, skip resume", b.UUID)
+			continue
+		}
+		items, err := s.repo.Items(ctx, b.ID)
+		if err != nil {
+			log.Printf("batch %s: load items failed: %v", b.UUID, err)
+			continue
+		}
+		b.Items = items
+		bg := ownerContext(b)
+		s.spawn(func() { s.submitAll(bg, b) })
+		resumed++
+	}
+	return resumed, nil
+}
+
+// ownerContext 按批量的创建人与项目重建后台提交的身份与范围（恢复时原请求已不存在）
+func ownerContext(b *models.Batch) context.Context {
+	ctx := shared.WithPrincipal(context.Background(), &shared.Principal{UserID: b.UserID})
+	return shared.WithScope(ctx, &shared.ProjectScope{ProjectID: b.ProjectID, OwnerID: b.UserID})
+}
+
+// submitAll 按顺序为每个商品创建任务；超出配额或预算后其余商品直接标记失败
+func (s *Service) submitAll(ctx context.Context, b *models.Batch) {
+	s.updateBatch(ctx, b.ID, map[string]interface{}{"status": models.BatchSubmitting})
+	var stopErr error
+	submitted := 0
+	for i := range b.Items {
+		item := &b.Items[i]
+		// 恢复提交时跳过已处理的商品
+		switch item.Status {
+		case models.BatchItemSubmitted:
+			submitted++
+			continue
+		case models.BatchItemFailed:
+			continue
+		}
+		fields := map[string]interface{}{}
+		err := stopErr
+		if err == nil {
+			var taskID *uint
+			var taskUUID string
+			taskID, taskUUID, err = s.submitItem(ctx, b, item)
+			if taskID != nil {
+				fields["task_id"] = *taskID
+			}
+			if taskUUID != "" {
+				fields["task_uuid"] = taskUUID
+			}
+			if isLimitError(err) {
+				stopErr = err
+			}
+		}
+		if err != nil {
+			fields["status"] = models.BatchItemFailed
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = models.BatchItemSubmitted
+			submitted++
+		}
+		if uerr := s.repo.UpdateItem(ctx, item.ID, fields); uerr != nil {
+			log.Printf("batch %s: update item %d failed: %v", b.UUID, item.Row, uerr)
+		}
+	}
+
+	if submitted == 0 {
+		now := time.Now()
+		s.updateBatch(ctx, b.ID, map[string]interface{}{"status": models.BatchFailed, "completed_at": &now})
+		return
+	}
+	s.updateBatch(ctx, b.ID, map[string]interface{}{"status": models.BatchRunning})
+}
+
+// submitItem 创建并启动单个商品的任务；自动文案时先生成文案并选用前几条候选，
+// 商品自带的卖点与 CTA 优先于候选
+func (s *Service) submitItem(ctx context.Context, b *models.Batch, item *models.BatchItem) (*uint, string, error) {
+	if !b.AutoCopywriting {
+		task, err := s.generator.CreateTask(ctx, creative.CreateTaskInput{
+			UserID:          b.UserID,
+			Title:           item.Title,
+			SellingPoints:   item.SellingPoints,
+			ProductImageURL: item.ProductImageURL,
+			Formats:         item.Formats,
+			Style:           item.Style,
+			CTAText:         item.CTAText,
+			NumVariants:     item.NumVariants,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return &task.ID, task.UUID, nil
+	}
+
+	product := item.ProductName
+	if product == "" {
+		product = item.Title
+	}
+	out, err := s.copywriter.GenerateCopywriting(ctx, copywriting.GenerateCopywritingInput{
+		UserID:      b.UserID,
+		ProductName: product,
+		Language:    item.Language,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("copywriting failed: %w", err)
+	}
+	var spIndexes []int
+	for i := 0; i < len(out.SellingPointCandidates) && i < autoSellingPoints; i++ {
+		spIndexes = append(spIndexes, i)
+	}
+	task, err := s.copywriter.ConfirmCopywriting(ctx, copywriting.ConfirmCopywritingInput{
+		TaskID:            out.TaskID,
+		SelectedCTAIndex:  0,
+		SelectedSPIndexes: spIndexes,
+		EditedCTA:         item.CTAText,
+		EditedSPs:         item.SellingPoints,
+		ProductImageURL:   item.ProductImageURL,
+		Style:             item.Style,
+		NumVariants:       item.NumVariants,
+		Formats:           item.Formats,
+	})
+	if err != nil {
+		return nil, out.TaskID, fmt.Errorf("confirm copywriting failed: %w", err)
+	}
+	if err := s.generator.StartCreativeGeneration(ctx, task.UUID, nil); err != nil {
+		return &task.ID, task.UUID, err
+	}
+	return &task.ID, task.UUID, nil
+}
+
+// isLimitError 配额或预算错误，后续商品必然同样失败
+func isLimitError(err error) bool {
+	var qe *shared.QuotaError
+	var be *shared.BudgetError
+	return errors.As(err, &qe) || errors.As(err, &be)
+}
+
+func (s *Service) updateBatch(ctx context.Context, id uint, fields map[string]interface{}) {
+	if err := s.repo.UpdateFields(ctx, id, fields); err != nil {
+		log.Printf("batch %d: update %v failed: %v", id, fields["status"], err)
+	}
+}
+
+// ItemProgress 单个商品的进度；Status 为提交阶段状态或任务状态
+type ItemProgress struct {
+	Row      int     `json:"row"`
+	Title    string  `json:"title"`
+	TaskID   string  `json:"task_id,omitempty"`
+	Status   string  `json:"status"`
+	Progress int     `json:"progress"`
+	Error    string  `json:"error,omitempty"`
+	Cost     float64 `json:"cost"`
+}
+
+// Counts 各阶段商品数
+type Counts struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"` // 尚未提交
+	Running   int `json:"running"` // 任务排队或生成中
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"` // 提交失败或任务失败 / 取消
+}
+
+// Progress 批量汇总进度
+type Progress struct {
+	Batch    *models.Batch  `json:"batch"`
+	Counts   Counts         `json:"counts"`
+	Percent  int            `json:"percent"`
+	Cost     float64        `json:"cost"`
+	Items    []ItemProgress `json:"items"`
+	Failures []ItemProgress `json:"failures"`
+}
+
+// Done 全部商品已结束（完成或失败）
+func (p *Progress) Done() bool {
+	return p.Counts.Pending == 0 && p.Counts.Running == 0
+}
+
+// Get 查询批量汇总进度；全部任务结束后落库最终状态
+func (s *Service) Get(ctx context.Context, batchUUID string) (*Progress, error) {
+	b, err := s.scopedBatch(ctx, batchUUID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.Items(ctx, b.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list batch items failed: %w", err)
+	}
+	var taskIDs []uint
+	for _, it := range items {
+		if it.TaskID != nil {
+			taskIDs = append(taskIDs, *it.TaskID)
+		}
+	}
+	states, err := s.repo.TaskStates(ctx, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load batch tasks failed: %w", err)
+	}
+	byID := make(map[uint]TaskState, len(states))
+	for _, st := range states {
+		byID[st.ID] = st
+	}
+
+	p := &Progress{Batch: b, Items: make([]ItemProgress, 0, len(items)), Failures: []ItemProgress{}}
+	p.Counts.Total = len(items)
+	sum := 0
+	for _, it := range items {
+		ip := ItemProgress{Row: it.Row, Title: it.Title, TaskID: it.TaskUUID, Status: string(it.Status), Error: it.Error}
+		switch {
+		case it.Status == models.BatchItemFailed:
+			p.Counts.Failed++
+			ip.Progress = 100
+		case it.Status == models.BatchItemPending || it.TaskID == nil:
+			p.Counts.Pending++
+		default:
+			st, ok := byID[*it.TaskID]
+			if !ok {
+				// 任务已被删除
+				p.Counts.Failed++
+				ip.Status = string(models.BatchItemFailed)
+				ip.Error = "task deleted"
+				ip.Progress = 100
+				break
+			}
+			ip.Status = string(st.Status)
+			ip.Cost = st.Cost
+			switch st.Status {
+			case models.TaskCompleted:
+				p.Counts.Completed++
+				ip.Progress = 100
+			case models.TaskFailed, models.TaskCancelled:
+				p.Counts.Failed++
+				ip.Progress = 100
+				ip.Error = st.ErrorMessage
+			default:
+				p.Counts.Running++
+				ip.Progress = st.Progress
+			}
+		}
+		p.Cost += ip.Cost
+		sum += ip.Progress
+		if ip.Error != "" {
+			p.Failures = append(p.Failures, ip)
+		}
+		p.Items = append(p.Items, ip)
+	}
+	if len(items) > 0 {
+		p.Percent = sum / len(items)
+	}
+
+	if b.Status == models.BatchRunning && p.Done() {
+		status := models.BatchCompleted
+		switch {
+		case p.Counts.Completed == 0:
+			status = models.BatchFailed
+		case p.Counts.Failed > 0:
+			status = models.BatchPartial
+		}
+		now := time.Now()
+		if err := s.repo.UpdateFields(ctx, b.ID, map[string]interface{}{"status": status, "completed_at": &now}); err != nil {
+			return nil, fmt.Errorf("update batch failed: %w", err)
+		}
+		b.Status = status
+		b.CompletedAt = &now
+	}
+	return p, nil
+}
+
+// ListResult 批量分页结果
+type ListResult struct {
+	Batches  []models.Batch `json:"batches"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// List 当前范围内的批量列表（不含进度，需逐个查询）
+func (s *Service) List(ctx context.Context, page, pageSize int) (*ListResult, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	cond, args := shared.ScopeFrom(ctx).Condition("project_id", "user_id")
+	batches, total, err := s.repo.List(ctx, cond, args, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("list batches failed: %w", err)
+	}
+	return &ListResult{Batches: batches, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+func (s *Service) scopedBatch(ctx context.Context, batchUUID string) (*models.Batch, error) {
+	if batchUUID == "" {
+		return nil, fmt.Errorf("%w: batch id is required", ErrInvalid)
+	}
+	b, err := s.repo.GetByUUID(ctx, batchUUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !shared.ScopeFrom(ctx).Allows(b.ProjectID, b.UserID) {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
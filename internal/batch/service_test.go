@@ -0,0 +1,314 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/internal/copywriting"
+	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"gorm.io/gorm"
+)
+
+type memRepo struct {
+	batches []*models.Batch
+	items   map[uint]*models.BatchItem
+	tasks   map[uint]*TaskState
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{items: map[uint]*models.BatchItem{}, tasks: map[uint]*TaskState{}}
+}
+
+func (m *memRepo) Create(_ context.Context, b *models.Batch) error {
+	b.ID = uint(len(m.batches) + 1)
+	for i := range b.Items {
+		it := &b.Items[i]
+		it.ID = uint(len(m.items) + 1)
+		it.BatchID = b.ID
+		cp := *it
+		m.items[it.ID] = &cp
+	}
+	cp := *b
+	cp.Items = nil
+	m.batches = append(m.batches, &cp)
+	return nil
+}
+
+func (m *memRepo) GetByUUID(_ context.Context, uuid string) (*models.Batch, error) {
+	for _, b := range m.batches {
+		if b.UUID == uuid {
+			cp := *b
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) ListByStatus(_ context.Context, statuses []models.BatchStatus) ([]models.Batch, error) {
+	var out []models.Batch
+	for _, b := range m.batches {
+		for _, st := range statuses {
+			if b.Status == st {
+				out = append(out, *b)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) List(context.Context, string, []interface{}, int, int) ([]models.Batch, int64, error) {
+	var out []models.Batch
+	for _, b := range m.batches {
+		out = append(out, *b)
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *memRepo) Items(_ context.Context, batchID uint) ([]models.BatchItem, error) {
+	var out []models.BatchItem
+	for id := uint(1); id <= uint(len(m.items)); id++ {
+		if it := m.items[id]; it.BatchID == batchID {
+			out = append(out, *it)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) UpdateFields(_ context.Context, batchID uint, fields map[string]interface{}) error {
+	b := m.batches[batchID-1]
+	if v, ok := fields["status"].(models.BatchStatus); ok {
+		b.Status = v
+	}
+	if v, ok := fields["completed_at"].(*time.Time); ok {
+		b.CompletedAt = v
+	}
+	return nil
+}
+
+func (m *memRepo) UpdateItem(_ context.Context, itemID uint, fields map[string]interface{}) error {
+	it := m.items[itemID]
+	if v, ok := fields["status"].(models.BatchItemStatus); ok {
+		it.Status = v
+	}
+	if v, ok := fields["error"].(string); ok {
+		it.Error = v
+	}
+	if v, ok := fields["task_id"].(uint); ok {
+		it.TaskID = &v
+	}
+	if v, ok := fields["task_uuid"].(string); ok {
+		it.TaskUUID = v
+	}
+	return nil
+}
+
+func (m *memRepo) TaskStates(_ context.Context, ids []uint) ([]TaskState, error) {
+	var out []TaskState
+	for _, id := range ids {
+		if st, ok := m.tasks[id]; ok {
+			out = append(out, *st)
+		}
+	}
+	return out, nil
+}
+
+// fakeGenerator 创建任务时写入 memRepo 的任务状态；failAt 行号起返回 err
+type fakeGenerator struct {
+	repo    *memRepo
+	created []creative.CreateTaskInput
+	started []string
+	failAt  int
+	err     error
+}
+
+func (g *fakeGenerator) newTask() *models.CreativeTask {
+	id := uint(len(g.repo.tasks) + 1)
+	task := &models.CreativeTask{UUIDModel: models.UUIDModel{ID: id, UUID: "task-" + string(rune('a'+id-1))}}
+	g.repo.tasks[id] = &TaskState{ID: id, UUID: task.UUID, Status: models.TaskQueued}
+	return task
+}
+
+func (g *fakeGenerator) CreateTask(_ context.Context, in creative.CreateTaskInput) (*models.CreativeTask, error) {
+	g.created = append(g.created, in)
+	if g.failAt > 0 && len(g.created) >= g.failAt {
+		return nil, g.err
+	}
+	return g.newTask(), nil
+}
+
+func (g *fakeGenerator) StartCreativeGeneration(_ context.Context, taskUUID string, _ *creative.StartCreativeOptions) error {
+	g.started = append(g.started, taskUUID)
+	return nil
+}
+
+func (g *fakeGenerator) PrepareExport(context.Context, string, creative.ExportOptions) (*creative.TaskExport, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakeCopywriter struct {
+	gen       *fakeGenerator
+	confirmed []copywriting.ConfirmCopywritingInput
+}
+
+func (f *fakeCopywriter) GenerateCopywriting(_ context.Context, in copywriting.GenerateCopywritingInput) (*copywriting.GenerateCopywritingOutput, error) {
+	task := f.gen.newTask()
+	return &copywriting.GenerateCopywritingOutput{
+		TaskID:                 task.UUID,
+		CTACandidates:          []string{"立即购买"},
+		SellingPointCandidates: []string{"轻薄", "长续航", "快充", "防水"},
+	}, nil
+}
+
+func (f *fakeCopywriter) ConfirmCopywriting(_ context.Context, in copywriting.ConfirmCopywritingInput) (*models.CreativeTask, error) {
+	f.confirmed = append(f.confirmed, in)
+	for id, st := range f.gen.repo.tasks {
+		if st.UUID == in.TaskID {
+			return &models.CreativeTask{UUIDModel: models.UUIDModel{ID: id, UUID: st.UUID}}, nil
+		}
+	}
+	return nil, errors.New("task not found")
+}
+
+func newTestService() (*Service, *memRepo, *fakeGenerator) {
+	repo := newMemRepo()
+	gen := &fakeGenerator{repo: repo}
+	svc := NewServiceWithDeps(repo)
+	svc.SetGenerator(gen)
+	svc.spawn = func(f func()) { f() }
+	return svc, repo, gen
+}
+
+func TestParseCSV(t *testing.T) {
+	in := "\ufeffTitle,selling_points,image_url,formats,num_variants\n" +
+		"\"Phone, 5G\",轻薄 | 长续航,https://cdn.example.com/p.png,1:1|9:16,2\n" +
+		",,,,\n" +
+		"Earbuds,,,,\n"
+	items, err := ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("blank rows must be skipped, got %d items", len(items))
+	}
+	first := items[0]
+	if first.Title != "Phone, 5G" || len(first.SellingPoints) != 2 || first.SellingPoints[1] != "长续航" ||
+		first.ProductImageURL != "https://cdn.example.com/p.png" || len(first.Formats) != 2 || first.NumVariants != 2 {
+		t.Fatalf("unexpected first item: %+v", first)
+	}
+	if _, err := ParseCSV(strings.NewReader("title,colour\nx,red\n")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("unknown column must be rejected, got %v", err)
+	}
+}
+
+func TestBatchSubmitsItemsAndAggregatesProgress(t *testing.T) {
+	svc, repo, gen := newTestService()
+	gen.failAt, gen.err = 2, &shared.QuotaError{Subject: "user", Window: "daily", Metric: shared.MetricTasks, Limit: 1, Used: 1}
+	ctx := shared.WithPrincipal(context.Background(), &shared.Principal{UserID: 7})
+
+	if _, err := svc.Create(ctx, CreateInput{Items: []ItemInput{{Title: "ok"}, {Title: "bad", Formats: []string{"2:1"}}}}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("unsupported format must be rejected, got %v", err)
+	}
+
+	b, err := svc.Create(ctx, CreateInput{Name: "spring", Items: []ItemInput{
+		{Title: "Phone", SellingPoints: []string{"轻薄"}},
+		{Title: "Watch"},
+		{ProductName: "Earbuds"},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(gen.created) != 2 || gen.created[0].UserID != 7 || gen.created[0].SellingPoints[0] != "轻薄" {
+		t.Fatalf("submission must stop after the quota error: %+v", gen.created)
+	}
+
+	p, err := svc.Get(ctx, b.UUID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if p.Batch.Status != models.BatchRunning || p.Counts.Running != 1 || p.Counts.Failed != 2 || len(p.Failures) != 2 {
+		t.Fatalf("unexpected progress: %+v", p.Counts)
+	}
+	if p.Items[2].Title != "Earbuds" || !strings.Contains(p.Items[2].Error, "quota exceeded") {
+		t.Fatalf("remaining items must fail with the quota error: %+v", p.Items[2])
+	}
+	if _, err := svc.PrepareExport(ctx, b.UUID, creative.ExportOptions{}); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("export before completion must be refused, got %v", err)
+	}
+
+	repo.tasks[1].Status, repo.tasks[1].Progress, repo.tasks[1].Cost = models.TaskCompleted, 100, 0.32
+	p, _ = svc.Get(ctx, b.UUID)
+	if !p.Done() || p.Batch.Status != models.BatchPartial || p.Percent != 100 || p.Cost != 0.32 || p.Batch.CompletedAt == nil {
+		t.Fatalf("finished batch: status=%s percent=%d cost=%v", p.Batch.Status, p.Percent, p.Cost)
+	}
+
+	other := shared.WithScope(ctx, &shared.ProjectScope{OwnerID: 8})
+	if _, err := svc.Get(other, b.UUID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("other users must not see the batch, got %v", err)
+	}
+}
+
+func TestBatchAutoCopywriting(t *testing.T) {
+	svc, _, gen := newTestService()
+	cw := &fakeCopywriter{gen: gen}
+	svc.SetCopywriter(cw)
+
+	_, err := svc.Create(context.Background(), CreateInput{AutoCopywriting: true, Items: []ItemInput{
+		{Title: "Phone", ProductImageURL: "https://cdn.example.com/p.png"},
+		{Title: "Watch", SellingPoints: []string{"心率监测"}, CTAText: "马上抢"},
+	}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(gen.created) != 0 || len(gen.started) != 2 || len(cw.confirmed) != 2 {
+		t.Fatalf("auto copywriting must confirm and start each task: created=%d started=%v", len(gen.created), gen.started)
+	}
+	if c := cw.confirmed[0]; len(c.SelectedSPIndexes) != autoSellingPoints || c.ProductImageURL == "" || c.EditedCTA != "" {
+		t.Fatalf("first item should use the top candidates: %+v", c)
+	}
+	if c := cw.confirmed[1]; c.EditedCTA != "马上抢" || len(c.EditedSPs) != 1 {
+		t.Fatalf("row copy must override candidates: %+v", c)
+	}
+}
+
+func TestResumeSubmissionsSkipsProcessedItems(t *testing.T) {
+	svc, repo, gen := newTestService()
+	var pending []func()
+	svc.spawn = func(f func()) { pending = append(pending, f) }
+	projectID := uint(3)
+	ctx := shared.WithScope(shared.WithPrincipal(context.Background(), &shared.Principal{UserID: 7}), &shared.ProjectScope{ProjectID: &projectID, OwnerID: 7})
+
+	b, err := svc.Create(ctx, CreateInput{Items: []ItemInput{{Title: "Phone"}, {Title: "Watch"}, {Title: "Earbuds"}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// 模拟提交到一半时进程退出：第一项已提交，第二项已失败，后台提交未再运行
+	repo.batches[0].Status = models.BatchSubmitting
+	repo.items[1].Status = models.BatchItemSubmitted
+	repo.items[2].Status, repo.items[2].Error = models.BatchItemFailed, "boom"
+	pending = nil
+
+	n, err := svc.ResumeSubmissions(context.Background())
+	if err != nil || n != 1 || len(pending) != 1 {
+		t.Fatalf("expected one batch to resume, got n=%d err=%v", n, err)
+	}
+	pending[0]()
+	if len(gen.created) != 1 || gen.created[0].Title != "Earbuds" || gen.created[0].UserID != 7 {
+		t.Fatalf("only the pending item must be submitted: %+v", gen.created)
+	}
+	if repo.items[3].Status != models.BatchItemSubmitted || repo.batches[0].Status != models.BatchRunning {
+		t.Fatalf("unexpected state: item=%s batch=%s", repo.items[3].Status, repo.batches[0].Status)
+	}
+	if p, err := svc.Get(ctx, b.UUID); err != nil || p.Counts.Failed != 1 {
+		t.Fatalf("resumed batch must stay visible in its project: %v", err)
+	}
+	if n, _ := svc.ResumeSubmissions(context.Background()); n != 0 {
+		t.Fatalf("running batches must not be resumed again, got %d", n)
+	}
+}
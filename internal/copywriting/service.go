@@ -8,11 +8,16 @@ import (
 	"strings"
 	"unicode"
 
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/audit"
 	creativeRepo "ads-creative-gen-platform/internal/creative/repository"
 	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/infra/moderation"
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/project"
 	"ads-creative-gen-platform/internal/settings"
+	"ads-creative-gen-platform/internal/shared"
 	"ads-creative-gen-platform/pkg/database"
 
 	"github.com/google/uuid"
@@ -22,6 +27,12 @@ import (
 type CopywritingService struct {
 	qwenClient ports.QwenClient
 	taskRepo   ports.TaskRepository
+	moderator  ports.Moderator
+	projects   ports.ProjectSettingsSource
+	audit      ports.AuditRecorder
+	quota      ports.QuotaGuard
+	budget     ports.BudgetGuard
+	costs      ports.CostRecorder
 }
 
 // NewCopywritingService 构造服务
@@ -29,6 +40,8 @@ func NewCopywritingService() *CopywritingService {
 	return &CopywritingService{
 		qwenClient: llm.NewQwenClient(),
 		taskRepo:   creativeRepo.NewTaskRepository(database.DB),
+		moderator:  moderation.NewConfiguredModerator(config.ModerationConfig),
+		projects:   project.NewService(),
 	}
 }
 
@@ -40,6 +53,36 @@ func NewCopywritingServiceWithDeps(qwen ports.QwenClient, repo ports.TaskReposit
 	}
 }
 
+// SetModerator 设置文案审核器（nil 表示不审核）
+func (s *CopywritingService) SetModerator(m ports.Moderator) {
+	s.moderator = m
+}
+
+// SetAuditRecorder 设置审计日志记录器（nil 表示不记录）
+func (s *CopywritingService) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// SetQuotaGuard 设置配额检查（nil 表示不限额）
+func (s *CopywritingService) SetQuotaGuard(q ports.QuotaGuard) {
+	s.quota = q
+}
+
+// SetBudgetGuard 设置项目预算检查（nil 表示不检查）
+func (s *CopywritingService) SetBudgetGuard(b ports.BudgetGuard) {
+	s.budget = b
+}
+
+// SetCostRecorder 设置模型调用成本记录器（nil 表示不核算）
+func (s *CopywritingService) SetCostRecorder(r ports.CostRecorder) {
+	s.costs = r
+}
+
+// SetProjectSettingsSource 设置项目默认设置来源（nil 表示只使用全局默认值）
+func (s *CopywritingService) SetProjectSettingsSource(p ports.ProjectSettingsSource) {
+	s.projects = p
+}
+
 // GenerateCopywritingInput 文案生成输入
 type GenerateCopywritingInput struct {
 	UserID      uint   `json:"user_id"`
@@ -67,40 +110,66 @@ type ConfirmCopywritingInput struct {
 	Formats           []string `json:"formats,omitempty"`
 }
 
-// GenerateCopywriting 调用 LLM 并创建任务
-func (s *CopywritingService) GenerateCopywriting(input GenerateCopywritingInput) (*GenerateCopywritingOutput, error) {
+// GenerateCopywriting 调用 LLM 并在当前项目下创建任务
+func (s *CopywritingService) GenerateCopywriting(ctx context.Context, input GenerateCopywritingInput) (*GenerateCopywritingOutput, error) {
 	if input.ProductName == "" {
 		return nil, errors.New("product_name is required")
 	}
 
-	targetLanguage := resolveLanguage(input.ProductName, input.Language)
+	projectID := shared.ProjectIDFrom(ctx)
+	ps, err := s.projectSettings(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	targetLanguage := resolveLanguage(input.ProductName, ps.Language(input.Language))
 
+	if s.budget != nil && projectID != nil {
+		// 文案调用的 token 用量无法预知，只计入已发生与进行中任务的成本
+		if err := s.budget.CheckBudget(ctx, *projectID, shared.ModelUsage{Kind: shared.CostKindLLM, ProjectID: projectID}); err != nil {
+			return nil, err
+		}
+	}
+	if s.quota != nil {
+		if err := s.quota.Consume(ctx, projectID, shared.Usage{LLMCalls: 1}); err != nil {
+			return nil, err
+		}
+	}
 	result, err := s.qwenClient.GenerateCopywriting(input.ProductName, targetLanguage)
 	if err != nil {
 		return nil, err
 	}
 
+	formats := ps.Formats(nil)
+	if len(formats) == 0 {
+		formats = []string{settings.DefaultFormat}
+	}
+	style, variantStyles := ps.Styles("")
+
 	task := models.CreativeTask{
 		UUIDModel: models.UUIDModel{
 			UUID: uuid.New().String(),
 		},
 		UserID:                 input.UserID,
+		ProjectID:              projectID,
 		Title:                  input.ProductName,
 		ProductName:            input.ProductName,
 		CTACandidates:          models.StringArray(result.CTAOptions),
 		SellingPointCandidates: models.StringArray(result.SellingPointOptions),
-		RequestedFormats:       models.StringArray{settings.DefaultFormat},
-		RequestedStyles:        models.StringArray{""},
-		NumVariants:            settings.DefaultNumVariants,
+		RequestedFormats:       models.StringArray(formats),
+		RequestedStyles:        models.StringArray{style},
+		VariantStyles:          models.StringArray(variantStyles),
+		NumVariants:            ps.Variants(0, settings.DefaultNumVariants),
 		Status:                 models.TaskDraft,
 		CopywritingGenerated:   true,
 		CopywritingRaw:         result.RawResponse,
 		PromptUsed:             fmt.Sprintf("copywriting_language=%s", targetLanguage),
 	}
 
-	if err := s.taskRepo.Create(context.Background(), &task); err != nil {
+	if err := s.taskRepo.Create(ctx, &task); err != nil {
+		s.recordCost(ctx, nil, input.UserID, projectID, result)
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
+	s.recordCost(ctx, &task.ID, input.UserID, projectID, result)
 
 	return &GenerateCopywritingOutput{
 		TaskID:                 task.UUID,
@@ -110,15 +179,18 @@ func (s *CopywritingService) GenerateCopywriting(input GenerateCopywritingInput)
 }
 
 // ConfirmCopywriting 选择/编辑文案并更新任务
-func (s *CopywritingService) ConfirmCopywriting(input ConfirmCopywritingInput) (*models.CreativeTask, error) {
+func (s *CopywritingService) ConfirmCopywriting(ctx context.Context, input ConfirmCopywritingInput) (*models.CreativeTask, error) {
 	if input.TaskID == "" {
 		return nil, errors.New("task_id is required")
 	}
 
-	task, err := s.taskRepo.GetByUUID(context.Background(), input.TaskID)
+	task, err := s.taskRepo.GetByUUID(ctx, input.TaskID)
 	if err != nil {
 		return nil, err
 	}
+	if !shared.ScopeFrom(ctx).Allows(task.ProjectID, task.UserID) {
+		return nil, fmt.Errorf("task not found: %s", input.TaskID)
+	}
 
 	if len(task.CTACandidates) == 0 || len(task.SellingPointCandidates) == 0 {
 		return nil, errors.New("task has no copywriting candidates")
@@ -149,14 +221,20 @@ func (s *CopywritingService) ConfirmCopywriting(input ConfirmCopywritingInput) (
 		}
 	}
 
-	formats := input.Formats
-	if len(formats) == 0 {
-		formats = []string{settings.DefaultFormat}
+	if err := s.moderateCopy(finalCTA, finalSPs); err != nil {
+		return nil, err
 	}
 
-	if input.NumVariants <= 0 {
-		input.NumVariants = settings.DefaultNumVariants
+	ps, err := s.projectSettings(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	formats := ps.Formats(input.Formats)
+	if len(formats) == 0 {
+		formats = []string{settings.DefaultFormat}
 	}
+	input.NumVariants = ps.Variants(input.NumVariants, settings.DefaultNumVariants)
+	style, variantStyles := ps.Styles(input.Style)
 
 	selectedSPIndexes := make(models.StringArray, 0, len(input.SelectedSPIndexes))
 	for _, idx := range input.SelectedSPIndexes {
@@ -169,17 +247,79 @@ func (s *CopywritingService) ConfirmCopywriting(input ConfirmCopywritingInput) (
 		"selected_cta_index":  input.SelectedCTAIndex,
 		"selected_sp_indexes": selectedSPIndexes,
 		"product_image_url":   input.ProductImageURL,
-		"requested_styles":    models.StringArray{input.Style},
+		"requested_styles":    models.StringArray{style},
 		"requested_formats":   models.StringArray(formats),
 		"num_variants":        input.NumVariants,
 	}
+	if len(variantStyles) > 0 {
+		updates["variant_styles"] = models.StringArray(variantStyles)
+	}
 
-	if err := s.taskRepo.UpdateFields(context.Background(), task.ID, updates); err != nil {
+	if err := s.taskRepo.UpdateFields(ctx, task.ID, updates); err != nil {
 		return nil, fmt.Errorf("update task failed: %w", err)
 	}
+	if s.audit != nil {
+		before := audit.TaskState(task)
+		s.audit.Record(ctx, shared.AuditEvent{
+			Action:       audit.ActionTaskConfirm,
+			ResourceType: models.AuditResourceTask,
+			ResourceID:   task.UUID,
+			ProjectID:    task.ProjectID,
+			Before:       before,
+			After:        audit.Apply(before, updates),
+		})
+	}
 
 	// 重新查询最新任务
-	return s.taskRepo.GetByUUID(context.Background(), input.TaskID)
+	return s.taskRepo.GetByUUID(ctx, input.TaskID)
+}
+
+// recordCost 记录文案生成的 token 用量，taskID 为空表示任务未创建成功
+func (s *CopywritingService) recordCost(ctx context.Context, taskID *uint, userID uint, projectID *uint, result *llm.CopywritingResult) {
+	if s.costs == nil || result == nil {
+		return
+	}
+	s.costs.RecordUsage(ctx, shared.ModelUsage{
+		Kind:         shared.CostKindLLM,
+		Model:        result.Model,
+		TaskID:       taskID,
+		UserID:       userID,
+		ProjectID:    projectID,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+	})
+}
+
+// projectSettings 任务所属项目的默认设置；个人空间或未配置来源时返回 nil
+func (s *CopywritingService) projectSettings(ctx context.Context, projectID *uint) (*models.ProjectSettings, error) {
+	if s.projects == nil || projectID == nil {
+		return nil, nil
+	}
+	ps, err := s.projects.ProjectSettings(ctx, *projectID)
+	if err != nil {
+		return nil, fmt.Errorf("load project settings failed: %w", err)
+	}
+	return ps, nil
+}
+
+// moderateCopy 审核用户确认的 CTA 与卖点，不安全时拒绝确认
+func (s *CopywritingService) moderateCopy(cta string, sellingPoints []string) error {
+	if s.moderator == nil {
+		return nil
+	}
+	text := strings.Join(append([]string{cta}, sellingPoints...), "\n")
+	res, err := s.moderator.ModerateText(context.Background(), text)
+	if err != nil {
+		return fmt.Errorf("copy moderation failed: %w", err)
+	}
+	if !res.Safe {
+		reason := res.Reason
+		if reason == "" {
+			reason = strings.Join(res.Labels, ",")
+		}
+		return fmt.Errorf("copy rejected by content moderation: %s", reason)
+	}
+	return nil
 }
 
 // resolveLanguage 决定生成语言（显式选择优先，其次自动检测）
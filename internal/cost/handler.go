@@ -0,0 +1,79 @@
+package cost
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 成本接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务（供生成模块记录用量、检查预算）
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// Summary 当前范围今日、本月成本与项目预算
+func (h *Handler) Summary(c *gin.Context) {
+	summary, err := h.service.Summary(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(summary))
+}
+
+// Report 成本随时间变化
+// ?interval=day|month&group_by=user|project|model|kind&since=&until=
+func (h *Handler) Report(c *gin.Context) {
+	in := ReportInput{Interval: c.Query("interval"), GroupBy: c.Query("group_by")}
+	var err error
+	if in.Since, err = parseTime(c.Query("since")); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid since: "+err.Error()))
+		return
+	}
+	if in.Until, err = parseTime(c.Query("until")); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid until: "+err.Error()))
+		return
+	}
+	report, err := h.service.Report(c.Request.Context(), in)
+	if err != nil {
+		if errors.Is(err, ErrInvalid) {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(report))
+}
+
+// parseTime 支持 RFC3339 与 YYYY-MM-DD，空值返回 nil
+func parseTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, errors.New("expected RFC3339 or YYYY-MM-DD")
+	}
+	return &t, nil
+}
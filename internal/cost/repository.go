@@ -0,0 +1,173 @@
+package cost
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AggregateQuery 成本汇总条件
+type AggregateQuery struct {
+	// Interval 时间分桶 day / month，空表示不分桶
+	Interval string
+	// GroupBy 分组列 user_id / project_id / model / kind，空表示不分组
+	GroupBy string
+	// ScopeCond 数据范围过滤条件（由 shared.ProjectScope.Condition 生成）
+	ScopeCond string
+	ScopeArgs []interface{}
+	ProjectID *uint
+	Since     *time.Time
+	Until     *time.Time
+}
+
+// Row 汇总结果
+type Row struct {
+	Period       string  `json:"period,omitempty"`
+	Key          string  `json:"key,omitempty"`
+	Cost         float64 `json:"cost"`
+	Calls        int64   `json:"calls"`
+	Images       int64   `json:"images"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+}
+
+// InFlightTask 排队中或生成中的任务，用于预估尚未产生的成本
+type InFlightTask struct {
+	ImageModel  string
+	NumVariants int
+	Cost        float64
+}
+
+// Repository 成本记录仓储
+type Repository interface {
+	Create(ctx context.Context, rec *models.CostRecord) error
+	// AddTaskCost 累加任务成本
+	AddTaskCost(ctx context.Context, taskID uint, cost float64) error
+	Aggregate(ctx context.Context, q AggregateQuery) ([]Row, error)
+	// ProjectBudget 项目每月预算，0 表示不限
+	ProjectBudget(ctx context.Context, projectID uint) (float64, error)
+	// InFlightTasks 项目下排队中或生成中的任务
+	InFlightTasks(ctx context.Context, projectID uint) ([]InFlightTask, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, rec *models.CostRecord) error {
+	return r.db.WithContext(ctx).Create(rec).Error
+}
+
+func (r *gormRepository) AddTaskCost(ctx context.Context, taskID uint, cost float64) error {
+	return r.db.WithContext(ctx).Model(&models.CreativeTask{}).Where("id = ?", taskID).
+		UpdateColumn("cost", gorm.Expr("cost + ?", cost)).Error
+}
+
+func (r *gormRepository) Aggregate(ctx context.Context, q AggregateQuery) ([]Row, error) {
+	db := r.db.WithContext(ctx).Model(&models.CostRecord{})
+	if q.ScopeCond != "" {
+		db = db.Where(q.ScopeCond, q.ScopeArgs...)
+	}
+	if q.ProjectID != nil {
+		db = db.Where("project_id = ?", *q.ProjectID)
+	}
+	if q.Since != nil {
+		db = db.Where("created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		db = db.Where("created_at < ?", *q.Until)
+	}
+
+	period, key := "''", "''"
+	var groups []string
+	if q.Interval != "" {
+		period = periodExpr(r.db.Dialector.Name(), q.Interval)
+		groups = append(groups, "period")
+	}
+	if q.GroupBy != "" {
+		key = q.GroupBy
+		groups = append(groups, "group_key")
+	}
+	db = db.Select(period + " AS period, " + key + " AS group_key, " +
+		"COALESCE(SUM(cost), 0) AS cost, COUNT(*) AS calls, COALESCE(SUM(images), 0) AS images, " +
+		"COALESCE(SUM(input_tokens), 0) AS input_tokens, COALESCE(SUM(output_tokens), 0) AS output_tokens")
+	for _, g := range groups {
+		db = db.Group(g)
+	}
+	if q.Interval != "" {
+		db = db.Order("period")
+	}
+
+	var rows []struct {
+		Period       string
+		GroupKey     sql.NullString
+		Cost         float64
+		Calls        int64
+		Images       int64
+		InputTokens  int64
+		OutputTokens int64
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, Row{
+			Period:       row.Period,
+			Key:          row.GroupKey.String,
+			Cost:         row.Cost,
+			Calls:        row.Calls,
+			Images:       row.Images,
+			InputTokens:  row.InputTokens,
+			OutputTokens: row.OutputTokens,
+		})
+	}
+	return out, nil
+}
+
+func (r *gormRepository) ProjectBudget(ctx context.Context, projectID uint) (float64, error) {
+	var p models.Project
+	if err := r.db.WithContext(ctx).Select("monthly_budget").Where("id = ?", projectID).Take(&p).Error; err != nil {
+		return 0, err
+	}
+	return p.MonthlyBudget, nil
+}
+
+// periodExpr 按数据库方言生成时间分桶表达式
+func periodExpr(dialect, interval string) string {
+	switch dialect {
+	case "postgres":
+		if interval == IntervalMonth {
+			return "to_char(created_at, 'YYYY-MM')"
+		}
+		return "to_char(created_at, 'YYYY-MM-DD')"
+	case "mysql":
+		if interval == IntervalMonth {
+			return "DATE_FORMAT(created_at, '%Y-%m')"
+		}
+		return "DATE_FORMAT(created_at, '%Y-%m-%d')"
+	default:
+		if interval == IntervalMonth {
+			return "strftime('%Y-%m', created_at)"
+		}
+		return "strftime('%Y-%m-%d', created_at)"
+	}
+}
+
+func (r *gormRepository) InFlightTasks(ctx context.Context, projectID uint) ([]InFlightTask, error) {
+	var out []InFlightTask
+	err := r.db.WithContext(ctx).Model(&models.CreativeTask{}).
+		Select("image_model, num_variants, cost").
+		Where("project_id = ? AND status IN ?", projectID, []models.TaskStatus{models.TaskQueued, models.TaskProcessing}).
+		Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,292 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalid 报表参数不合法
+var ErrInvalid = errors.New("invalid cost report request")
+
+// 报表时间粒度
+const (
+	IntervalDay   = "day"
+	IntervalMonth = "month"
+)
+
+// groupColumns 报表分组维度
+var groupColumns = map[string]string{
+	"user":    "user_id",
+	"project": "project_id",
+	"model":   "model",
+	"kind":    "kind",
+}
+
+// Service 成本核算服务
+type Service struct {
+	repo     Repository
+	currency string
+	prices   map[string]config.ModelPrice
+	now      func() time.Time
+}
+
+// NewService 创建服务，价格表取自配置
+func NewService() *Service {
+	svc := NewServiceWithDeps(NewRepository(database.DB))
+	if c := config.CostConfig; c != nil {
+		svc.SetPrices(c.Currency, c.Prices)
+	}
+	return svc
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository) *Service {
+	return &Service{repo: repo, prices: map[string]config.ModelPrice{}, now: time.Now}
+}
+
+// SetPrices 设置币种与模型价格表
+func (s *Service) SetPrices(currency string, prices map[string]config.ModelPrice) {
+	s.currency = currency
+	s.prices = prices
+}
+
+// Currency 计价币种
+func (s *Service) Currency() string {
+	return s.currency
+}
+
+// Price 按价格表计算一次调用的费用（token 单价按每千个计），未配置价格的模型为 0
+func (s *Service) Price(u shared.ModelUsage) float64 {
+	p := s.prices[u.Model]
+	cost := float64(u.Images)*p.Image + float64(u.InputTokens)/1000*p.InputTokens + float64(u.OutputTokens)/1000*p.OutputTokens
+	return math.Round(cost*1e6) / 1e6
+}
+
+// RecordUsage 记录计费用量并累加到任务成本，实现 ports.CostRecorder
+func (s *Service) RecordUsage(ctx context.Context, u shared.ModelUsage) {
+	if _, ok := s.prices[u.Model]; !ok {
+		log.Printf("cost: no price configured for model %s, recording zero cost", u.Model)
+	}
+	rec := models.CostRecord{
+		CreatedAt:    s.now(),
+		Kind:         u.Kind,
+		Model:        u.Model,
+		TaskID:       u.TaskID,
+		UserID:       u.UserID,
+		ProjectID:    u.ProjectID,
+		TraceID:      u.TraceID,
+		Images:       u.Images,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		Cost:         s.Price(u),
+		Currency:     s.currency,
+	}
+	if err := s.repo.Create(ctx, &rec); err != nil {
+		log.Printf("cost: record %s usage for task %v failed: %v", u.Model, u.TaskID, err)
+		return
+	}
+	if u.TaskID != nil && rec.Cost > 0 {
+		if err := s.repo.AddTaskCost(ctx, *u.TaskID, rec.Cost); err != nil {
+			log.Printf("cost: add cost to task %d failed: %v", *u.TaskID, err)
+		}
+	}
+}
+
+// CheckBudget 本月已发生成本加上进行中任务与本次调用的预估成本超出预算时拒绝，实现 ports.BudgetGuard
+func (s *Service) CheckBudget(ctx context.Context, projectID uint, planned shared.ModelUsage) error {
+	status, err := s.budgetStatus(ctx, projectID)
+	if err != nil || status == nil {
+		return err
+	}
+	pending := s.Price(planned)
+	if !status.Exceeded {
+		inFlight, err := s.inFlightCost(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		pending += inFlight
+		if math.Round((status.Spent+pending)*1e6)/1e6 <= status.MonthlyBudget {
+			return nil
+		}
+	}
+	return &shared.BudgetError{
+		ProjectID: projectID,
+		Budget:    status.MonthlyBudget,
+		Spent:     status.Spent,
+		Pending:   math.Round(pending*1e6) / 1e6,
+		Currency:  s.currency,
+		ResetAt:   status.ResetsAt,
+	}
+}
+
+// inFlightCost 排队中与生成中任务尚未产生的预估图片成本（按变体数估算，扣除已记录的成本）
+func (s *Service) inFlightCost(ctx context.Context, projectID uint) (float64, error) {
+	tasks, err := s.repo.InFlightTasks(ctx, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("load in-flight tasks failed: %w", err)
+	}
+	var total float64
+	for _, t := range tasks {
+		images := t.NumVariants
+		if images <= 0 {
+			images = 1
+		}
+		model := llm.ImageModelFrom(llm.WithImageModel(ctx, t.ImageModel))
+		if remaining := s.Price(shared.ModelUsage{Kind: shared.CostKindImage, Model: model, Images: int64(images)}) - t.Cost; remaining > 0 {
+			total += remaining
+		}
+	}
+	return total, nil
+}
+
+// BudgetStatus 项目本月预算使用情况
+type BudgetStatus struct {
+	MonthlyBudget float64   `json:"monthly_budget"`
+	Spent         float64   `json:"spent"`
+	Remaining     float64   `json:"remaining"`
+	Exceeded      bool      `json:"exceeded"`
+	ResetsAt      time.Time `json:"resets_at"`
+}
+
+// budgetStatus 未设置预算时返回 nil
+func (s *Service) budgetStatus(ctx context.Context, projectID uint) (*BudgetStatus, error) {
+	budget, err := s.repo.ProjectBudget(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("load project budget failed: %w", err)
+	}
+	if budget <= 0 {
+		return nil, nil
+	}
+	start := monthStart(s.now())
+	spent, err := s.total(ctx, AggregateQuery{ProjectID: &projectID, Since: &start})
+	if err != nil {
+		return nil, err
+	}
+	return &BudgetStatus{
+		MonthlyBudget: budget,
+		Spent:         spent,
+		Remaining:     math.Max(0, budget-spent),
+		Exceeded:      spent >= budget,
+		ResetsAt:      start.AddDate(0, 1, 0),
+	}, nil
+}
+
+func (s *Service) total(ctx context.Context, q AggregateQuery) (float64, error) {
+	rows, err := s.repo.Aggregate(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("aggregate cost failed: %w", err)
+	}
+	var total float64
+	for _, r := range rows {
+		total += r.Cost
+	}
+	return total, nil
+}
+
+// Summary 当前范围内的成本概览
+type Summary struct {
+	Currency    string        `json:"currency"`
+	Today       float64       `json:"today"`
+	MonthToDate float64       `json:"month_to_date"`
+	Budget      *BudgetStatus `json:"budget,omitempty"`
+}
+
+// Summary 当前范围（项目 / 个人空间）今日与本月成本；项目设置了预算时附带预算使用情况
+func (s *Service) Summary(ctx context.Context) (*Summary, error) {
+	scope := shared.ScopeFrom(ctx)
+	cond, args := scope.Condition("project_id", "user_id")
+	now := s.now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := monthStart(now)
+
+	out := &Summary{Currency: s.currency}
+	var err error
+	if out.Today, err = s.total(ctx, AggregateQuery{ScopeCond: cond, ScopeArgs: args, Since: &day}); err != nil {
+		return nil, err
+	}
+	if out.MonthToDate, err = s.total(ctx, AggregateQuery{ScopeCond: cond, ScopeArgs: args, Since: &month}); err != nil {
+		return nil, err
+	}
+	if id := shared.ProjectIDFrom(ctx); id != nil {
+		if out.Budget, err = s.budgetStatus(ctx, *id); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ReportInput 成本报表参数
+type ReportInput struct {
+	Interval string
+	GroupBy  string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// Report 成本随时间变化
+type Report struct {
+	Currency string  `json:"currency"`
+	Interval string  `json:"interval"`
+	GroupBy  string  `json:"group_by,omitempty"`
+	Total    float64 `json:"total"`
+	Points   []Row   `json:"points"`
+}
+
+// Report 按天或按月汇总当前范围内的成本，可按用户、项目、模型或调用类型分组；默认最近 30 天
+func (s *Service) Report(ctx context.Context, in ReportInput) (*Report, error) {
+	if in.Interval == "" {
+		in.Interval = IntervalDay
+	}
+	if in.Interval != IntervalDay && in.Interval != IntervalMonth {
+		return nil, fmt.Errorf("%w: interval must be day or month", ErrInvalid)
+	}
+	column := ""
+	if in.GroupBy != "" {
+		var ok bool
+		if column, ok = groupColumns[in.GroupBy]; !ok {
+			return nil, fmt.Errorf("%w: group_by must be user, project, model or kind", ErrInvalid)
+		}
+	}
+	if in.Since == nil {
+		since := s.now().AddDate(0, 0, -30)
+		in.Since = &since
+	}
+
+	cond, args := shared.ScopeFrom(ctx).Condition("project_id", "user_id")
+	rows, err := s.repo.Aggregate(ctx, AggregateQuery{
+		Interval:  in.Interval,
+		GroupBy:   column,
+		ScopeCond: cond,
+		ScopeArgs: args,
+		Since:     in.Since,
+		Until:     in.Until,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate cost failed: %w", err)
+	}
+	report := &Report{Currency: s.currency, Interval: in.Interval, GroupBy: in.GroupBy, Points: rows}
+	for _, r := range rows {
+		report.Total += r.Cost
+	}
+	report.Total = math.Round(report.Total*1e6) / 1e6
+	return report, nil
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,121 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+type memRepo struct {
+	records   []models.CostRecord
+	taskCost  map[uint]float64
+	budgets   map[uint]float64
+	inFlight  []InFlightTask
+	lastQuery AggregateQuery
+}
+
+func (m *memRepo) Create(_ context.Context, rec *models.CostRecord) error {
+	m.records = append(m.records, *rec)
+	return nil
+}
+
+func (m *memRepo) AddTaskCost(_ context.Context, taskID uint, cost float64) error {
+	m.taskCost[taskID] += cost
+	return nil
+}
+
+func (m *memRepo) Aggregate(_ context.Context, q AggregateQuery) ([]Row, error) {
+	m.lastQuery = q
+	var row Row
+	for _, r := range m.records {
+		if q.ProjectID != nil && (r.ProjectID == nil || *r.ProjectID != *q.ProjectID) {
+			continue
+		}
+		if q.Since != nil && r.CreatedAt.Before(*q.Since) {
+			continue
+		}
+		row.Cost += r.Cost
+		row.Calls++
+	}
+	return []Row{row}, nil
+}
+
+func (m *memRepo) ProjectBudget(_ context.Context, projectID uint) (float64, error) {
+	return m.budgets[projectID], nil
+}
+
+func (m *memRepo) InFlightTasks(context.Context, uint) ([]InFlightTask, error) {
+	return m.inFlight, nil
+}
+
+func TestRecordUsageAndBudget(t *testing.T) {
+	repo := &memRepo{taskCost: map[uint]float64{}, budgets: map[uint]float64{7: 1}}
+	svc := NewServiceWithDeps(repo)
+	svc.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+	svc.SetPrices("CNY", config.ParseModelPrices("wanx-v1=image:0.16; qwen-turbo=input:0.0003,output:0.0006;broken"))
+
+	if got := svc.Price(shared.ModelUsage{Model: "qwen-turbo", InputTokens: 2000, OutputTokens: 500}); got != 0.0009 {
+		t.Fatalf("token price per 1K: %v", got)
+	}
+	projectID, taskID := uint(7), uint(3)
+	ctx := context.Background()
+	if err := svc.CheckBudget(ctx, projectID, shared.ModelUsage{}); err != nil {
+		t.Fatalf("fresh project within budget: %v", err)
+	}
+	svc.RecordUsage(ctx, shared.ModelUsage{Kind: shared.CostKindImage, Model: "wanx-v1", TaskID: &taskID, ProjectID: &projectID, Images: 4})
+	svc.RecordUsage(ctx, shared.ModelUsage{Kind: shared.CostKindImage, Model: "wanx-v1", TaskID: &taskID, ProjectID: &projectID, Images: 3})
+	svc.RecordUsage(ctx, shared.ModelUsage{Kind: shared.CostKindLLM, Model: "unknown", TaskID: &taskID, ProjectID: &projectID, InputTokens: 100})
+	if len(repo.records) != 3 || repo.records[2].Cost != 0 || repo.records[0].Currency != "CNY" {
+		t.Fatalf("records: %+v", repo.records)
+	}
+	if c := repo.taskCost[taskID]; c < 1.119 || c > 1.121 {
+		t.Fatalf("task cost rollup: %v", c)
+	}
+
+	var be *shared.BudgetError
+	if err := svc.CheckBudget(ctx, projectID, shared.ModelUsage{}); !errors.As(err, &be) || be.Budget != 1 || be.ResetAt.Month() != time.November {
+		t.Fatalf("budget should block new tasks, got %v", err)
+	}
+	if err := svc.CheckBudget(ctx, 8, shared.ModelUsage{}); err != nil {
+		t.Fatalf("project without budget is unlimited: %v", err)
+	}
+
+	summary, err := svc.Summary(shared.WithScope(ctx, &shared.ProjectScope{ProjectID: &projectID}))
+	if err != nil || summary.Budget == nil || !summary.Budget.Exceeded || summary.Budget.Remaining != 0 {
+		t.Fatalf("summary: %v %+v", err, summary)
+	}
+	if _, err := svc.Report(ctx, ReportInput{Interval: "week"}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("unsupported interval, got %v", err)
+	}
+	if _, err := svc.Report(ctx, ReportInput{GroupBy: "model"}); err != nil || repo.lastQuery.GroupBy != "model" || repo.lastQuery.Interval != IntervalDay {
+		t.Fatalf("report query: %v %+v", err, repo.lastQuery)
+	}
+}
+
+func TestCheckBudgetCountsInFlightAndPlannedCost(t *testing.T) {
+	repo := &memRepo{taskCost: map[uint]float64{}, budgets: map[uint]float64{7: 1}}
+	svc := NewServiceWithDeps(repo)
+	svc.SetPrices("CNY", config.ParseModelPrices("wanx-v1=image:0.1"))
+	ctx := context.Background()
+	projectID := uint(7)
+	svc.RecordUsage(ctx, shared.ModelUsage{Kind: shared.CostKindImage, Model: "wanx-v1", ProjectID: &projectID, Images: 4})
+
+	planned := shared.ModelUsage{Kind: shared.CostKindImage, Model: "wanx-v1", Images: 4}
+	if err := svc.CheckBudget(ctx, projectID, planned); err != nil {
+		t.Fatalf("0.4 spent + 0.4 planned fits the budget: %v", err)
+	}
+	// 进行中任务：4 张图中已计费 1 张，还剩 0.3
+	repo.inFlight = []InFlightTask{{ImageModel: "wanx-v1", NumVariants: 4, Cost: 0.1}}
+	var be *shared.BudgetError
+	if err := svc.CheckBudget(ctx, projectID, planned); !errors.As(err, &be) || be.Spent != 0.4 || be.Pending != 0.7 {
+		t.Fatalf("in-flight tasks must count against the budget, got %v", err)
+	}
+	if err := svc.CheckBudget(ctx, projectID, shared.ModelUsage{Kind: shared.CostKindImage, Model: "wanx-v1", Images: 3}); err != nil {
+		t.Fatalf("0.4 spent + 0.3 in flight + 0.3 planned reaches but does not exceed the budget: %v", err)
+	}
+}
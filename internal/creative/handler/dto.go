@@ -1,5 +1,7 @@
 package handler
 
+import creative "ads-creative-gen-platform/internal/creative/service"
+
 // === API Request DTOs ===
 
 type GenerateCopywritingRequest struct {
@@ -23,6 +25,7 @@ type GenerateRequest struct {
 	Title           string   `json:"title" binding:"required"`
 	SellingPoints   []string `json:"selling_points"`
 	ProductImageURL string   `json:"product_image_url"`
+	ProductImageID  string   `json:"product_image_id,omitempty"`
 	Formats         []string `json:"formats"`
 	Style           string   `json:"style"`
 	CTAText         string   `json:"cta_text"`
@@ -32,18 +35,101 @@ type GenerateRequest struct {
 type StartCreativeRequest struct {
 	TaskID          string              `json:"task_id" binding:"required"`
 	ProductImageURL string              `json:"product_image_url,omitempty"`
+	ProductImageID  string              `json:"product_image_id,omitempty"`
 	Style           string              `json:"style,omitempty"`
 	NumVariants     int                 `json:"num_variants,omitempty"`
 	Formats         []string            `json:"formats,omitempty"`
 	VariantConfigs  []TaskVariantConfig `json:"variant_configs,omitempty"`
 }
 
+// PlanRequest 生成预演：带 task_id 时等同 /creative/start 的参数，否则等同 /creative/generate
+type PlanRequest struct {
+	TaskID          string              `json:"task_id,omitempty"`
+	Title           string              `json:"title,omitempty"`
+	SellingPoints   []string            `json:"selling_points,omitempty"`
+	ProductImageURL string              `json:"product_image_url,omitempty"`
+	ProductImageID  string              `json:"product_image_id,omitempty"`
+	Formats         []string            `json:"formats,omitempty"`
+	Style           string              `json:"style,omitempty"`
+	CTAText         string              `json:"cta_text,omitempty"`
+	NumVariants     int                 `json:"num_variants,omitempty"`
+	VariantConfigs  []TaskVariantConfig `json:"variant_configs,omitempty"`
+}
+
 type TaskVariantConfig struct {
 	Style  string `json:"style,omitempty"`
 	Prompt string `json:"prompt,omitempty"`
 }
 
+type ImportCreativesRequest struct {
+	Title       string                `json:"title"`
+	ProductName string                `json:"product_name"`
+	Items       []creative.ImportItem `json:"items" binding:"required"`
+}
+
+type CreateAnimationRequest struct {
+	AssetIDs         []string `json:"asset_ids,omitempty"`
+	FrameDelayMs     int      `json:"frame_delay_ms,omitempty"`
+	TextFrameDelayMs int      `json:"text_frame_delay_ms,omitempty"`
+	Loops            int      `json:"loops,omitempty"`
+	TextFrames       bool     `json:"text_frames,omitempty"`
+	Width            int      `json:"width,omitempty"`
+}
+
+type AttachTagsRequest struct {
+	TagIDs []uint   `json:"tag_ids,omitempty"`
+	Names  []string `json:"names,omitempty"`
+}
+
+type ReviewModerationRequest struct {
+	Safe *bool `json:"safe" binding:"required"`
+}
+
 // === API Response DTOs ===
+type ImportCreativesData struct {
+	TaskID    string         `json:"task_id"`
+	Status    string         `json:"status"`
+	Creatives []CreativeData `json:"creatives"`
+}
+
+type AnimationData struct {
+	Creative CreativeData `json:"creative"`
+	Frames   int          `json:"frames"`
+	Warnings []string     `json:"warnings,omitempty"`
+}
+
+type AssetTagsData struct {
+	AssetID string            `json:"asset_id"`
+	Tags    []creative.TagDTO `json:"tags"`
+}
+
+// ReviewRequest 审核单个素材
+type ReviewRequest struct {
+	Decision   string `json:"decision"` // approve | reject
+	Comment    string `json:"comment,omitempty"`
+	ReviewerID *uint  `json:"reviewer_id,omitempty"`
+}
+
+// BulkReviewRequest 批量审核
+type BulkReviewRequest struct {
+	AssetIDs []string `json:"asset_ids"`
+	ReviewRequest
+}
+
+// ReviewCommentRequest 添加审核评论
+type ReviewCommentRequest struct {
+	Body   string `json:"body"`
+	UserID *uint  `json:"user_id,omitempty"`
+}
+
+// ReviewData 审核结果
+type ReviewData struct {
+	AssetID      string `json:"asset_id"`
+	ReviewStatus string `json:"review_status"`
+	ReviewedBy   *uint  `json:"reviewed_by,omitempty"`
+	ReviewedAt   string `json:"reviewed_at,omitempty"`
+}
+
 type TaskData struct {
 	TaskID string `json:"task_id"`
 	Status string `json:"status"`
@@ -83,4 +169,5 @@ type CreativeData struct {
 	SellingPoints    []string `json:"selling_points,omitempty"`
 	Style            string   `json:"style,omitempty"`
 	GenerationPrompt string   `json:"generation_prompt,omitempty"`
+	Quarantined      bool     `json:"quarantined,omitempty"`
 }
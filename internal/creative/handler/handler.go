@@ -1,15 +1,27 @@
 package handler
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"ads-creative-gen-platform/internal/adexport"
 	"ads-creative-gen-platform/internal/copywriting"
 	ctask "ads-creative-gen-platform/internal/creative"
 	creative "ads-creative-gen-platform/internal/creative/service"
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/infra/storage"
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/internal/tag"
 	"ads-creative-gen-platform/internal/task"
 
 	"github.com/gin-gonic/gin"
@@ -20,22 +32,39 @@ type CreativeHandler struct {
 	service            *creative.CreativeService
 	copywritingService *copywriting.CopywritingService
 	runner             *task.Runner
+	// previewSecret HTML5 预览令牌签名密钥
+	previewSecret []byte
+	// previews 按令牌缓存 HTML5 预览包，避免每个文件请求都重新生成
+	previews *adexport.PreviewCache
 }
 
 // NewCreativeHandler 创建处理器
-func NewCreativeHandler() *CreativeHandler {
+func NewCreativeHandler(storageRegistry *storage.Registry) *CreativeHandler {
 	runner := task.NewRunner(10, 100)
 	runner.Start()
 
-	svc := creative.NewCreativeService()
+	svc := creative.NewCreativeService(storageRegistry)
 	svc.SetEnqueuer(func(taskID uint) error {
 		return runner.Enqueue(ctask.NewCreativeGenerateTask(svc, taskID))
 	})
 
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("✗ generate preview secret failed: %v", err)
+	}
 	return &CreativeHandler{
 		service:            svc,
 		copywritingService: copywriting.NewCopywritingService(),
 		runner:             runner,
+		previewSecret:      secret,
+		previews:           adexport.NewPreviewCache(64),
+	}
+}
+
+// SetPreviewSecret 设置 HTML5 预览令牌签名密钥（默认每次启动随机生成，重启后旧链接失效）
+func (h *CreativeHandler) SetPreviewSecret(secret []byte) {
+	if len(secret) > 0 {
+		h.previewSecret = secret
 	}
 }
 
@@ -44,6 +73,11 @@ func (h *CreativeHandler) Service() *creative.CreativeService {
 	return h.service
 }
 
+// CopywritingService 暴露文案服务（用于注入依赖）
+func (h *CreativeHandler) CopywritingService() *copywriting.CopywritingService {
+	return h.copywritingService
+}
+
 // GenerateCopywriting 生成文案候选
 func (h *CreativeHandler) GenerateCopywriting(c *gin.Context) {
 	var req GenerateCopywritingRequest
@@ -52,12 +86,15 @@ func (h *CreativeHandler) GenerateCopywriting(c *gin.Context) {
 		return
 	}
 
-	output, err := h.copywritingService.GenerateCopywriting(copywriting.GenerateCopywritingInput{
-		UserID:      1, // TODO: 认证集成后替换
+	output, err := h.copywritingService.GenerateCopywriting(c.Request.Context(), copywriting.GenerateCopywritingInput{
+		UserID:      shared.UserIDFrom(c.Request.Context()),
 		ProductName: req.ProductName,
 		Language:    req.Language,
 	})
 	if err != nil {
+		if writeLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to generate copywriting: "+err.Error()))
 		return
 	}
@@ -74,11 +111,12 @@ func (h *CreativeHandler) Generate(c *gin.Context) {
 	}
 
 	// 创建任务
-	task, err := h.service.CreateTask(creative.CreateTaskInput{
-		UserID:          1, // TODO: 从认证中获取
+	task, err := h.service.CreateTask(c.Request.Context(), creative.CreateTaskInput{
+		UserID:          shared.UserIDFrom(c.Request.Context()),
 		Title:           req.Title,
 		SellingPoints:   req.SellingPoints,
 		ProductImageURL: req.ProductImageURL,
+		ProductImageID:  req.ProductImageID,
 		Formats:         req.Formats,
 		Style:           req.Style,
 		CTAText:         req.CTAText,
@@ -86,6 +124,9 @@ func (h *CreativeHandler) Generate(c *gin.Context) {
 	})
 
 	if err != nil {
+		if writeLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to create task: "+err.Error()))
 		return
 	}
@@ -105,7 +146,7 @@ func (h *CreativeHandler) ConfirmCopywriting(c *gin.Context) {
 		return
 	}
 
-	task, err := h.copywritingService.ConfirmCopywriting(copywriting.ConfirmCopywritingInput{
+	task, err := h.copywritingService.ConfirmCopywriting(c.Request.Context(), copywriting.ConfirmCopywritingInput{
 		TaskID:            req.TaskID,
 		SelectedCTAIndex:  req.SelectedCTAIndex,
 		SelectedSPIndexes: req.SelectedSPIndexes,
@@ -137,6 +178,7 @@ func (h *CreativeHandler) StartCreative(c *gin.Context) {
 
 	opts := &creative.StartCreativeOptions{
 		ProductImageURL: req.ProductImageURL,
+		ProductImageID:  req.ProductImageID,
 		Style:           req.Style,
 		NumVariants:     req.NumVariants,
 		Formats:         req.Formats,
@@ -146,7 +188,10 @@ func (h *CreativeHandler) StartCreative(c *gin.Context) {
 		opts.VariantStyles = append(opts.VariantStyles, cfg.Style)
 	}
 
-	if err := h.service.StartCreativeGeneration(req.TaskID, opts); err != nil {
+	if err := h.service.StartCreativeGeneration(c.Request.Context(), req.TaskID, opts); err != nil {
+		if writeLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to start creative generation: "+err.Error()))
 		return
 	}
@@ -157,6 +202,384 @@ func (h *CreativeHandler) StartCreative(c *gin.Context) {
 	}))
 }
 
+// PlanCreative 预演生成计划并估算成本与耗时，不调用模型
+func (h *CreativeHandler) PlanCreative(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+
+	in := creative.PlanInput{
+		TaskID: req.TaskID,
+		Create: creative.CreateTaskInput{
+			UserID:          shared.UserIDFrom(c.Request.Context()),
+			Title:           req.Title,
+			SellingPoints:   req.SellingPoints,
+			ProductImageURL: req.ProductImageURL,
+			ProductImageID:  req.ProductImageID,
+			Formats:         req.Formats,
+			Style:           req.Style,
+			CTAText:         req.CTAText,
+			NumVariants:     req.NumVariants,
+		},
+		Start: creative.StartCreativeOptions{
+			ProductImageURL: req.ProductImageURL,
+			ProductImageID:  req.ProductImageID,
+			Style:           req.Style,
+			NumVariants:     req.NumVariants,
+			Formats:         req.Formats,
+		},
+	}
+	for _, cfg := range req.VariantConfigs {
+		in.Create.VariantPrompts = append(in.Create.VariantPrompts, cfg.Prompt)
+		in.Create.VariantStyles = append(in.Create.VariantStyles, cfg.Style)
+	}
+	in.Start.VariantPrompts = in.Create.VariantPrompts
+	in.Start.VariantStyles = in.Create.VariantStyles
+
+	result, err := h.service.PlanGeneration(c.Request.Context(), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to plan creative generation: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// ImportCreatives 导入外部素材：multipart（files + 可选 metadata JSON 数组）或 JSON（items[].url）
+func (h *CreativeHandler) ImportCreatives(c *gin.Context) {
+	var req ImportCreativesRequest
+	if c.ContentType() == "multipart/form-data" {
+		form, err := c.MultipartForm()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid multipart form: "+err.Error()))
+			return
+		}
+		req.Title = c.PostForm("title")
+		req.ProductName = c.PostForm("product_name")
+		if meta := c.PostForm("metadata"); meta != "" {
+			if err := json.Unmarshal([]byte(meta), &req.Items); err != nil {
+				c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid metadata: "+err.Error()))
+				return
+			}
+		}
+		files := form.File["files"]
+		if len(req.Items) > len(files) {
+			// 多出的 metadata 需带 url
+			for _, item := range req.Items[len(files):] {
+				if item.SourceURL == "" {
+					c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "metadata entries beyond uploaded files must have url"))
+					return
+				}
+			}
+		}
+		for i, fh := range files {
+			f, err := fh.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to read file: "+err.Error()))
+				return
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to read file: "+err.Error()))
+				return
+			}
+			if i >= len(req.Items) {
+				req.Items = append(req.Items, creative.ImportItem{})
+			}
+			req.Items[i].FileName = fh.Filename
+			req.Items[i].Data = data
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+
+	task, assets, err := h.service.ImportCreatives(c.Request.Context(), creative.ImportInput{
+		UserID:      shared.UserIDFrom(c.Request.Context()),
+		Title:       req.Title,
+		ProductName: req.ProductName,
+		Items:       req.Items,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to import creatives: "+err.Error()))
+		return
+	}
+
+	data := ImportCreativesData{TaskID: task.UUID, Status: string(task.Status)}
+	for _, a := range assets {
+		data.Creatives = append(data.Creatives, CreativeData{
+			ID:            a.UUID,
+			Format:        a.Format,
+			ImageURL:      a.PublicURL,
+			Width:         a.Width,
+			Height:        a.Height,
+			Title:         a.Title,
+			ProductName:   a.ProductName,
+			CTAText:       a.CTAText,
+			SellingPoints: a.SellingPoints,
+			Style:         a.Style,
+			Quarantined:   a.Quarantined,
+		})
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(data))
+}
+
+// ExportTask 以 ZIP 流式导出任务素材及 manifest（formats/variants 可选，逗号分隔）
+func (h *CreativeHandler) ExportTask(c *gin.Context) {
+	opts, ok := exportOptions(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.PrepareExport(c.Request.Context(), c.Param("id"), opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to export task: "+err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName()+`"`)
+	c.Status(http.StatusOK)
+	if err := export.WriteZip(c.Request.Context(), c.Writer); err != nil {
+		// 响应已开始写出，只能记录日志
+		log.Printf("导出任务 %s 失败: %v", c.Param("id"), err)
+	}
+}
+
+// ExportTaskForPlatform 按广告平台批量上传格式导出（图片 + 平台表格 + issues.json）
+func (h *CreativeHandler) ExportTaskForPlatform(c *gin.Context) {
+	registry := adexport.DefaultRegistry()
+	exporter, ok := registry.Get(c.Param("platform"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Unsupported platform, expected one of: "+strings.Join(registry.Names(), ", ")))
+		return
+	}
+	opts, ok := exportOptions(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.PrepareExport(c.Request.Context(), c.Param("id"), opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to export task: "+err.Error()))
+		return
+	}
+	plan, err := adexport.NewPlan(c.Request.Context(), export, exporter, adexport.Options{
+		Campaign:     c.Query("campaign"),
+		AdGroup:      c.Query("ad_group"),
+		FinalURL:     c.Query("final_url"),
+		BusinessName: c.Query("business_name"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.GenerateResponse{Code: 400, Message: err.Error(), Data: gin.H{"issues": plan.Issues}})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+plan.FileName()+`"`)
+	c.Status(http.StatusOK)
+	if err := plan.Write(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("导出任务 %s 到 %s 失败: %v", c.Param("id"), exporter.Name(), err)
+	}
+}
+
+// CreateAnimation 将任务的多个变体合成为 GIF 动图素材
+func (h *CreativeHandler) CreateAnimation(c *gin.Context) {
+	var req CreateAnimationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+
+	result, err := h.service.CreateAnimation(c.Request.Context(), creative.AnimationInput{
+		TaskID:         c.Param("id"),
+		AssetIDs:       req.AssetIDs,
+		FrameDelay:     time.Duration(req.FrameDelayMs) * time.Millisecond,
+		TextFrameDelay: time.Duration(req.TextFrameDelayMs) * time.Millisecond,
+		Loops:          req.Loops,
+		TextFrames:     req.TextFrames,
+		Width:          req.Width,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to create animation: "+err.Error()))
+		return
+	}
+
+	a := result.Asset
+	c.JSON(http.StatusOK, shared.SuccessResponse(AnimationData{
+		Creative: CreativeData{
+			ID:            a.UUID,
+			Format:        a.Format,
+			ImageURL:      a.PublicURL,
+			Width:         a.Width,
+			Height:        a.Height,
+			Title:         a.Title,
+			ProductName:   a.ProductName,
+			CTAText:       a.CTAText,
+			SellingPoints: a.SellingPoints,
+			Style:         a.Style,
+		},
+		Frames:   result.Frames,
+		Warnings: result.Warnings,
+	}))
+}
+
+// ExportHTML5 将单个素材打包为 IAB HTML5 横幅 ZIP（size 缺省按宽高比选取，click_url 为 clickTag 默认值）
+func (h *CreativeHandler) ExportHTML5(c *gin.Context) {
+	asset, bundle, ok := h.buildHTML5(c.Request.Context(), c, c.Param("id"), c.Query("size"), c.Query("click_url"))
+	if !ok {
+		return
+	}
+	data, err := bundle.Zip()
+	if err != nil {
+		if errors.Is(err, adexport.ErrBundleTooLarge) {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to build HTML5 bundle: "+err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+bundle.FileName(asset.UUID)+`"`)
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// HTML5PreviewData 预览链接
+type HTML5PreviewData struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// CreateHTML5Preview 签发 HTML5 横幅预览链接：浏览器加载包内文件时不携带令牌，
+// 因此把素材、参数与当前数据范围签入有效期较短的路径令牌
+func (h *CreativeHandler) CreateHTML5Preview(c *gin.Context) {
+	size, clickURL := c.Query("size"), c.Query("click_url")
+	if size != "" {
+		if _, err := adexport.ParseIABSize(size); err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
+	}
+	if err := adexport.ValidateClickURL(clickURL); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	ctx := c.Request.Context()
+	asset, err := h.service.GetAsset(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to load asset: "+err.Error()))
+		return
+	}
+
+	expires := time.Now().Add(adexport.PreviewTTL)
+	tok := adexport.PreviewToken{AssetID: asset.UUID, Size: size, ClickURL: clickURL, ExpiresAt: expires.Unix()}
+	if scope := shared.ScopeFrom(ctx); scope == nil || scope.All {
+		tok.All = true
+	} else {
+		tok.ProjectID, tok.OwnerID = scope.ProjectID, scope.OwnerID
+	}
+	signed, err := adexport.SignPreview(h.previewSecret, tok)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(HTML5PreviewData{
+		URL:       "/api/v1/creative/html5-preview/" + signed + "/index.html",
+		ExpiresAt: expires.Format(time.RFC3339),
+	}))
+}
+
+// PreviewHTML5 按预览令牌以解包形式提供 HTML5 横幅文件，素材须在签发时的数据范围内；
+// 同一令牌的包只生成一次，之后的文件请求直接从缓存读取
+func (h *CreativeHandler) PreviewHTML5(c *gin.Context) {
+	token := c.Param("token")
+	now := time.Now()
+	tok, err := adexport.ParsePreview(h.previewSecret, token, now)
+	if err != nil {
+		c.JSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+		return
+	}
+	ctx := shared.WithScope(c.Request.Context(), &shared.ProjectScope{All: tok.All, ProjectID: tok.ProjectID, OwnerID: tok.OwnerID})
+	bundle, err := h.previews.Get(token, time.Unix(tok.ExpiresAt, 0), now, func() (*adexport.HTML5Bundle, error) {
+		_, b, ok := h.buildHTML5(ctx, c, tok.AssetID, tok.Size, tok.ClickURL)
+		if !ok {
+			return nil, errPreviewNotBuilt
+		}
+		return b, nil
+	})
+	if err != nil {
+		// buildHTML5 已写出错误响应
+		return
+	}
+	data, contentType, found := bundle.File(c.Param("file"))
+	if !found {
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, "File not found in bundle"))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// errPreviewNotBuilt 预览包生成失败，错误响应已由 buildHTML5 写出
+var errPreviewNotBuilt = errors.New("preview bundle not built")
+
+func (h *CreativeHandler) buildHTML5(ctx context.Context, c *gin.Context, assetUUID, size, clickURL string) (*models.CreativeAsset, *adexport.HTML5Bundle, bool) {
+	opts := adexport.HTML5Options{ClickURL: clickURL}
+	if size != "" {
+		parsed, err := adexport.ParseIABSize(size)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return nil, nil, false
+		}
+		opts.Size = parsed
+	}
+
+	asset, data, err := h.service.LoadAssetImage(ctx, assetUUID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to load asset: "+err.Error()))
+		return nil, nil, false
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return nil, nil, false
+	}
+	bundle, err := adexport.BuildHTML5(adexport.FromAsset(*asset), img, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to build HTML5 banner: "+err.Error()))
+		return nil, nil, false
+	}
+	return asset, bundle, true
+}
+
+// exportOptions 解析 formats/variants 查询参数，失败时已写出 400
+func exportOptions(c *gin.Context) (creative.ExportOptions, bool) {
+	opts := creative.ExportOptions{Formats: splitQuery(c, "formats")}
+	for _, v := range splitQuery(c, "variants") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid variants: "+v))
+			return opts, false
+		}
+		opts.Variants = append(opts.Variants, n)
+	}
+	return opts, true
+}
+
+// splitQuery 支持 ?k=a,b 与 ?k=a&k=b 两种写法
+func splitQuery(c *gin.Context, key string) []string {
+	var out []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
 // DeleteTask 删除任务及资产
 func (h *CreativeHandler) DeleteTask(c *gin.Context) {
 	taskID := c.Param("id")
@@ -165,7 +588,15 @@ func (h *CreativeHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteTask(taskID); err != nil {
+	deleteFn := h.service.DeleteTask
+	if c.Query("hard") == "true" {
+		deleteFn = h.service.HardDeleteTask
+	}
+	if err := deleteFn(c.Request.Context(), taskID); err != nil {
+		if errors.Is(err, creative.ErrForbidden) {
+			c.JSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+			return
+		}
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to delete task: "+err.Error()))
 		return
 	}
@@ -179,7 +610,7 @@ func (h *CreativeHandler) DeleteTask(c *gin.Context) {
 func (h *CreativeHandler) GetTask(c *gin.Context) {
 	taskID := c.Param("id")
 
-	task, err := h.service.GetTask(taskID)
+	task, err := h.service.GetTask(c.Request.Context(), taskID)
 	if err != nil {
 		// 修复：确保返回标准的JSON错误响应
 		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, "Task not found"))
@@ -227,6 +658,7 @@ func (h *CreativeHandler) GetTask(c *gin.Context) {
 			SellingPoints:    asset.SellingPoints,
 			Style:            asset.Style,
 			GenerationPrompt: asset.GenerationPrompt,
+			Quarantined:      asset.Quarantined,
 		})
 	}
 	data.Creatives = creatives
@@ -253,6 +685,8 @@ func (h *CreativeHandler) ListAllAssets(c *gin.Context) {
 	pageSize := c.DefaultQuery("page_size", "20")
 	format := c.Query("format")
 	taskID := c.Query("task_id")
+	quarantined := c.Query("quarantined") == "true"
+	tags := splitQuery(c, "tags")
 
 	// 转换分页参数
 	pageNum := 1
@@ -267,31 +701,340 @@ func (h *CreativeHandler) ListAllAssets(c *gin.Context) {
 
 	// 构建查询条件
 	query := creative.ListAssetsQuery{
-		Page:     pageNum,
-		PageSize: pageSizeNum,
-		Format:   format,
-		TaskID:   taskID,
+		Page:         pageNum,
+		PageSize:     pageSizeNum,
+		Format:       format,
+		TaskID:       taskID,
+		Quarantined:  quarantined,
+		Tags:         tags,
+		ReviewStatus: c.Query("review_status"),
+		Query:        strings.TrimSpace(c.Query("q")),
+		Style:        c.Query("style"),
+		StorageType:  c.Query("storage_type"),
+		ModelName:    c.Query("model_name"),
+		Sort:         c.Query("sort"),
+		Cursor:       c.Query("cursor"),
+	}
+	var err error
+	if query.CreatedFrom, err = parseTimeQuery(c, "created_from", false); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if query.CreatedTo, err = parseTimeQuery(c, "created_to", true); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if query.MinScore, err = parseFloatQuery(c, "min_score"); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if query.MaxScore, err = parseFloatQuery(c, "max_score"); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if query.MinConformance, err = parseFloatQuery(c, "min_conformance"); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if query.MaxConformance, err = parseFloatQuery(c, "max_conformance"); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+		return
+	}
+	if v := c.Query("in_experiment"); v != "" {
+		b, perr := strconv.ParseBool(v)
+		if perr != nil {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid in_experiment"))
+			return
+		}
+		query.InExperiment = &b
 	}
 
 	// 获取素材列表
-	assets, total, err := h.service.ListAllAssets(query)
+	result, err := h.service.SearchAssets(c.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, creative.ErrInvalidCursor) || errors.Is(err, creative.ErrInvalidSort) {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to fetch assets: "+err.Error()))
 		return
 	}
 
 	// 构建响应数据
 	responseData := map[string]interface{}{
-		"assets":      assets,
-		"total":       total,
+		"assets":      result.Assets,
+		"total":       result.Total,
 		"page":        pageNum,
 		"page_size":   pageSizeNum,
-		"total_pages": int(math.Ceil(float64(total) / float64(pageSizeNum))),
+		"total_pages": int(math.Ceil(float64(result.Total) / float64(pageSizeNum))),
+		"next_cursor": result.NextCursor,
 	}
 
 	c.JSON(http.StatusOK, shared.SuccessResponse(responseData))
 }
 
+// ReviewQueue 审核队列：默认列出待审核素材，支持与素材列表相同的筛选参数
+func (h *CreativeHandler) ReviewQueue(c *gin.Context) {
+	if c.Query("review_status") == "" {
+		q := c.Request.URL.Query()
+		q.Set("review_status", string(models.ReviewPending))
+		c.Request.URL.RawQuery = q.Encode()
+	}
+	h.ListAllAssets(c)
+}
+
+// ReviewAsset 审核通过/驳回单个素材
+func (h *CreativeHandler) ReviewAsset(c *gin.Context) {
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	asset, err := h.service.ReviewAsset(c.Request.Context(), c.Param("id"), creative.ReviewInput{
+		Decision:   req.Decision,
+		Comment:    req.Comment,
+		ReviewerID: req.ReviewerID,
+	})
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+	data := ReviewData{AssetID: asset.UUID, ReviewStatus: string(asset.ReviewStatus), ReviewedBy: asset.ReviewedBy}
+	if asset.ReviewedAt != nil {
+		data.ReviewedAt = asset.ReviewedAt.Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(data))
+}
+
+// BulkReview 批量审核通过/驳回
+func (h *CreativeHandler) BulkReview(c *gin.Context) {
+	var req BulkReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	result, err := h.service.BulkReview(c.Request.Context(), req.AssetIDs, creative.ReviewInput{
+		Decision:   req.Decision,
+		Comment:    req.Comment,
+		ReviewerID: req.ReviewerID,
+	})
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// ListReviewComments 素材审核评论线程
+func (h *CreativeHandler) ListReviewComments(c *gin.Context) {
+	comments, err := h.service.ListReviewComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"asset_id": c.Param("id"), "comments": comments}))
+}
+
+// AddReviewComment 添加审核评论
+func (h *CreativeHandler) AddReviewComment(c *gin.Context) {
+	var req ReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	comment, err := h.service.AddReviewComment(c.Request.Context(), c.Param("id"), req.Body, req.UserID)
+	if err != nil {
+		writeReviewError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(comment))
+}
+
+func writeReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, creative.ErrAssetNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, creative.ErrInvalidReview):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
+
+// SimilarAssets 按视觉特征（颜色直方图、主色、感知哈希）查找相似素材
+func (h *CreativeHandler) SimilarAssets(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	result, err := h.service.SimilarAssets(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to find similar assets: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// BackfillVisualFeatures 为历史素材补算视觉特征
+func (h *CreativeHandler) BackfillVisualFeatures(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	result, err := h.service.BackfillVisualFeatures(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Backfill failed: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// AssetLineage 素材所在的完整派生树及各节点的实验指标汇总
+func (h *CreativeHandler) AssetLineage(c *gin.Context) {
+	result, err := h.service.GetLineage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeLineageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(result))
+}
+
+// DeriveAsset 从素材派生新版本（resize/overlay/edit）
+func (h *CreativeHandler) DeriveAsset(c *gin.Context) {
+	var req creative.DeriveInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	asset, err := h.service.DeriveAsset(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeLineageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(creative.LineageNodeOf(*asset)))
+}
+
+func writeLineageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, creative.ErrAssetNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, creative.ErrInvalidDerivation):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
+
+// writeLimitError 超出配额时返回 429 与 Retry-After（距配额重置的秒数），项目预算用尽时返回 402
+func writeLimitError(c *gin.Context, err error) bool {
+	var qe *shared.QuotaError
+	if errors.As(err, &qe) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(qe.RetryAfter(time.Now()).Seconds()))))
+		c.JSON(http.StatusTooManyRequests, shared.ErrorResponse(429, err.Error()))
+		return true
+	}
+	var be *shared.BudgetError
+	if errors.As(err, &be) {
+		c.JSON(http.StatusPaymentRequired, shared.ErrorResponse(402, err.Error()))
+		return true
+	}
+	return false
+}
+
+// parseTimeQuery 解析 RFC3339 或 YYYY-MM-DD；日期作为截止时间时取次日零点（不含）
+func parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, errors.New("Invalid " + key + ": use RFC3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseFloatQuery(c *gin.Context, key string) (*float64, error) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, errors.New("Invalid " + key)
+	}
+	return &f, nil
+}
+
+// AttachAssetTags 为素材关联标签（tag_ids 或 names）
+func (h *CreativeHandler) AttachAssetTags(c *gin.Context) {
+	var req AttachTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	tags, err := h.service.AttachAssetTags(c.Request.Context(), c.Param("id"), req.TagIDs, req.Names)
+	if err != nil {
+		writeTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(AssetTagsData{AssetID: c.Param("id"), Tags: tags}))
+}
+
+// DetachAssetTag 解除素材与标签的关联
+func (h *CreativeHandler) DetachAssetTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 64)
+	if err != nil || tagID == 0 {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid tag id"))
+		return
+	}
+	tags, err := h.service.DetachAssetTag(c.Request.Context(), c.Param("id"), uint(tagID))
+	if err != nil {
+		writeTagError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(AssetTagsData{AssetID: c.Param("id"), Tags: tags}))
+}
+
+func writeTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, tag.ErrNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, tag.ErrInvalid):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to update asset tags: "+err.Error()))
+	}
+}
+
+// ReviewModeration 人工复核被隔离的素材
+func (h *CreativeHandler) ReviewModeration(c *gin.Context) {
+	assetID := c.Param("id")
+	var req ReviewModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+
+	if err := h.service.ReviewQuarantinedAsset(c.Request.Context(), assetID, *req.Safe); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to review asset: "+err.Error()))
+		return
+	}
+
+	status := "released"
+	if !*req.Safe {
+		status = "deleted"
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(map[string]interface{}{
+		"asset_id": assetID,
+		"status":   status,
+	}))
+}
+
 // ListAllTasks 获取所有任务
 func (h *CreativeHandler) ListAllTasks(c *gin.Context) {
 	// 获取查询参数
@@ -315,11 +1058,10 @@ func (h *CreativeHandler) ListAllTasks(c *gin.Context) {
 		Page:     pageNum,
 		PageSize: pageSizeNum,
 		Status:   status,
-		UserID:   0, // TODO: 从认证中获取
 	}
 
 	// 获取任务列表
-	tasks, total, err := h.service.ListAllTasks(query)
+	tasks, total, err := h.service.ListAllTasks(c.Request.Context(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to fetch tasks: "+err.Error()))
 		return
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/ports"
@@ -26,40 +28,399 @@ func (r *assetRepository) Create(ctx context.Context, asset *models.CreativeAsse
 	return r.db.WithContext(ctx).Create(asset).Error
 }
 
-// List 查询素材列表
+// GetByUUID 根据UUID获取素材
+func (r *assetRepository) GetByUUID(ctx context.Context, uuid string) (*models.CreativeAsset, error) {
+	var asset models.CreativeAsset
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&asset).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// List 查询素材列表：全文检索 + 多条件筛选，支持 offset 或游标翻页
 func (r *assetRepository) List(ctx context.Context, query shared.ListAssetsQuery) ([]models.CreativeAsset, int64, error) {
 	var assets []models.CreativeAsset
 	var total int64
 
 	// 构建查询
-	dbQuery := r.db.WithContext(ctx).Model(&models.CreativeAsset{}).Preload("Task")
+	dbQuery := r.db.WithContext(ctx).Model(&models.CreativeAsset{}).
+		Preload("Task").Preload("Tags").Preload("Score").
+		Joins("LEFT JOIN creative_scores ON creative_scores.creative_id = creative_assets.id")
 
 	// 应用筛选条件
+	dbQuery = dbQuery.Where("creative_assets.quarantined = ?", query.Quarantined)
 	if query.Format != "" {
-		dbQuery = dbQuery.Where("format = ?", query.Format)
+		dbQuery = dbQuery.Where("creative_assets.format = ?", query.Format)
+	}
+	for _, name := range query.Tags {
+		dbQuery = dbQuery.Where("creative_assets.id IN (?)", r.db.Table("creative_tags").
+			Select("creative_tags.creative_asset_id").
+			Joins("JOIN tags ON tags.id = creative_tags.tag_id").
+			Where("tags.name = ?", name))
+	}
+	if cond, args := query.Scope.Condition("creative_tasks.project_id", "creative_tasks.user_id"); cond != "" {
+		// 素材按所属任务的项目/创建人过滤
+		dbQuery = dbQuery.Where("creative_assets.task_id IN (?)", r.db.Table("creative_tasks").Select("creative_tasks.id").Where(cond, args...))
 	}
 	if query.TaskID != "" {
 		dbQuery = dbQuery.Joins("JOIN creative_tasks ON creative_assets.task_id = creative_tasks.id").
 			Where("creative_tasks.uuid = ?", query.TaskID)
 	}
+	if q := strings.TrimSpace(query.Query); q != "" {
+		dbQuery = r.applySearch(dbQuery, q)
+	}
+	if query.Style != "" {
+		dbQuery = dbQuery.Where("creative_assets.style = ?", query.Style)
+	}
+	if query.CreatedFrom != nil {
+		dbQuery = dbQuery.Where("creative_assets.created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		dbQuery = dbQuery.Where("creative_assets.created_at < ?", *query.CreatedTo)
+	}
+	if query.MinScore != nil {
+		dbQuery = dbQuery.Where("creative_scores.quality_overall >= ?", *query.MinScore)
+	}
+	if query.MaxScore != nil {
+		dbQuery = dbQuery.Where("creative_scores.quality_overall <= ?", *query.MaxScore)
+	}
+	if query.MinConformance != nil {
+		dbQuery = dbQuery.Where("creative_assets.brand_conformance >= ?", *query.MinConformance)
+	}
+	if query.MaxConformance != nil {
+		dbQuery = dbQuery.Where("creative_assets.brand_conformance <= ?", *query.MaxConformance)
+	}
+	if query.ReviewStatus != "" {
+		dbQuery = dbQuery.Where("creative_assets.review_status = ?", query.ReviewStatus)
+	}
+	if query.StorageType != "" {
+		dbQuery = dbQuery.Where("creative_assets.storage_type = ?", query.StorageType)
+	}
+	if query.ModelName != "" {
+		dbQuery = dbQuery.Where("creative_assets.model_name = ?", query.ModelName)
+	}
+	if query.InExperiment != nil {
+		cond := "EXISTS (SELECT 1 FROM experiment_variants WHERE experiment_variants.creative_id = creative_assets.id)"
+		if !*query.InExperiment {
+			cond = "NOT " + cond
+		}
+		dbQuery = dbQuery.Where(cond)
+	}
 
 	// 获取总数
 	if err := dbQuery.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("count assets failed: %w", err)
 	}
 
-	// 分页查询
-	offset := (query.Page - 1) * query.PageSize
-	if err := dbQuery.Offset(offset).Limit(query.PageSize).
-		Order("creative_assets.created_at DESC").
-		Find(&assets).Error; err != nil {
+	sortExpr := assetSortExpr(query.Sort)
+	if sortExpr == "" {
+		dbQuery = dbQuery.Select("creative_assets.*").
+			Order("creative_assets.created_at DESC").Order("creative_assets.id DESC")
+		if c := query.Cursor; c != nil {
+			dbQuery = dbQuery.Where("creative_assets.created_at < ? OR (creative_assets.created_at = ? AND creative_assets.id < ?)", c.CreatedAt, c.CreatedAt, c.ID)
+		}
+	} else {
+		dbQuery = dbQuery.Select("creative_assets.*, " + sortExpr + " AS sort_value").
+			Order(sortExpr + " DESC").Order("creative_assets.id DESC")
+		if c := query.Cursor; c != nil {
+			dbQuery = dbQuery.Where(sortExpr+" < ? OR ("+sortExpr+" = ? AND creative_assets.id < ?)", c.Value, c.Value, c.ID)
+		}
+	}
+
+	// 分页查询：游标优先，深分页稳定
+	if query.Cursor == nil {
+		dbQuery = dbQuery.Offset((query.Page - 1) * query.PageSize)
+	}
+	if err := dbQuery.Limit(query.PageSize).Find(&assets).Error; err != nil {
 		return nil, 0, fmt.Errorf("list assets failed: %w", err)
 	}
 
 	return assets, total, nil
 }
 
+// assetSortExpr 评分/CTR/品牌色符合度排序表达式；CTR 优先取实验实际点击率，其次取预测值，缺失排在最后
+func assetSortExpr(sort string) string {
+	switch sort {
+	case shared.AssetSortScore:
+		return "COALESCE(creative_scores.quality_overall, -1)"
+	case shared.AssetSortConformance:
+		return "COALESCE(creative_assets.brand_conformance, -1)"
+	case shared.AssetSortCTR:
+		return "COALESCE((SELECT SUM(experiment_metrics.clicks) * 1.0 / NULLIF(SUM(experiment_metrics.impressions), 0) " +
+			"FROM experiment_metrics WHERE experiment_metrics.creative_id = creative_assets.id), creative_scores.ctr_prediction, -1)"
+	default:
+		return ""
+	}
+}
+
+// applySearch 按数据库方言做全文检索：PostgreSQL tsvector（中文无分词，补充 ILIKE），MySQL ngram FULLTEXT，其余 LIKE
+func (r *assetRepository) applySearch(db *gorm.DB, q string) *gorm.DB {
+	like := "%" + escapeLike(q) + "%"
+	switch r.db.Dialector.Name() {
+	case "postgres":
+		return db.Where("(to_tsvector('simple', coalesce(creative_assets.search_text, '')) @@ plainto_tsquery('simple', ?) OR creative_assets.search_text ILIKE ?)", q, like)
+	case "mysql":
+		if terms := booleanTerms(q); terms != "" {
+			return db.Where("MATCH(creative_assets.search_text) AGAINST (? IN BOOLEAN MODE)", terms)
+		}
+	}
+	return db.Where("creative_assets.search_text LIKE ?", like)
+}
+
+// booleanTerms 将用户输入转为 MySQL 布尔模式查询，所有词必须出现
+func booleanTerms(q string) string {
+	var terms []string
+	for _, f := range strings.Fields(q) {
+		f = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, f)
+		if f != "" {
+			terms = append(terms, `+"`+f+`"`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UpdateFields 更新素材字段
+func (r *assetRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.CreativeAsset{}).
+		Where("id = ?", id).
+		Updates(fields).Error
+}
+
+// SaveScore 写入或更新素材评分（按 creative_id 唯一）
+func (r *assetRepository) SaveScore(ctx context.Context, score *models.CreativeScore) error {
+	var existing models.CreativeScore
+	err := r.db.WithContext(ctx).Where("creative_id = ?", score.CreativeID).First(&existing).Error
+	if err == nil {
+		score.ID = existing.ID
+		return r.db.WithContext(ctx).Save(score).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(score).Error
+}
+
+// Delete 删除素材（软删除）
+func (r *assetRepository) Delete(ctx context.Context, asset *models.CreativeAsset) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagIDs, err := tagIDsOf(tx, []uint{asset.ID})
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(asset).Error; err != nil {
+			return err
+		}
+		return refreshTagUsage(tx, tagIDs)
+	})
+}
+
 // DeleteByTaskID 根据任务删除素材
 func (r *assetRepository) DeleteByTaskID(ctx context.Context, taskID uint) error {
-	return r.db.WithContext(ctx).Where("task_id = ?", taskID).Delete(&models.CreativeAsset{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Model(&models.CreativeAsset{}).Where("task_id = ?", taskID).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		tagIDs, err := tagIDsOf(tx, ids)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", taskID).Delete(&models.CreativeAsset{}).Error; err != nil {
+			return err
+		}
+		return refreshTagUsage(tx, tagIDs)
+	})
+}
+
+// ListByTaskID 列出任务下的全部素材（含评分）
+func (r *assetRepository) ListByTaskID(ctx context.Context, taskID uint) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	if err := r.db.WithContext(ctx).Preload("Score").Where("task_id = ?", taskID).Order("id asc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ListByTaskIDWithDeleted 任务下全部素材，包含已软删除的素材
+func (r *assetRepository) ListByTaskIDWithDeleted(ctx context.Context, taskID uint) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	if err := r.db.WithContext(ctx).Unscoped().Where("task_id = ?", taskID).Order("id asc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ListWithFeatures 范围内最近的 limit 个已有视觉特征、未隔离的素材，作为相似检索候选
+func (r *assetRepository) ListWithFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	db := r.db.WithContext(ctx).Preload("Score").
+		Where("features IS NOT NULL AND quarantined = ?", false)
+	if cond, args := scope.Condition("creative_tasks.project_id", "creative_tasks.user_id"); cond != "" {
+		db = db.Where("task_id IN (?)", r.db.Table("creative_tasks").Select("creative_tasks.id").Where(cond, args...))
+	}
+	if err := db.Order("created_at desc").Limit(limit).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// GetByID 按 ID 查询素材
+func (r *assetRepository) GetByID(ctx context.Context, id uint) (*models.CreativeAsset, error) {
+	var asset models.CreativeAsset
+	if err := r.db.WithContext(ctx).Preload("Score").First(&asset, id).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// ListChildren 以 parentIDs 为父素材的派生素材
+func (r *assetRepository) ListChildren(ctx context.Context, parentIDs []uint) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	if len(parentIDs) == 0 {
+		return assets, nil
+	}
+	if err := r.db.WithContext(ctx).Preload("Score").Where("parent_asset_id IN ?", parentIDs).Order("id asc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// MetricTotals 素材在所有实验中的曝光/点击汇总
+func (r *assetRepository) MetricTotals(ctx context.Context, ids []uint) (map[uint]shared.MetricTotals, error) {
+	out := make(map[uint]shared.MetricTotals)
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		CreativeID  uint
+		Impressions int64
+		Clicks      int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.ExperimentMetric{}).
+		Select("creative_id, SUM(impressions) AS impressions, SUM(clicks) AS clicks").
+		Where("creative_id IN ?", ids).Group("creative_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.CreativeID] = shared.MetricTotals{Impressions: row.Impressions, Clicks: row.Clicks}
+	}
+	return out, nil
+}
+
+// AddReviewComment 写入审核评论
+func (r *assetRepository) AddReviewComment(ctx context.Context, comment *models.AssetReviewComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// ListReviewComments 素材的审核评论，按时间正序
+func (r *assetRepository) ListReviewComments(ctx context.Context, assetID uint) ([]models.AssetReviewComment, error) {
+	var comments []models.AssetReviewComment
+	if err := r.db.WithContext(ctx).Where("asset_id = ?", assetID).Order("created_at asc, id asc").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// ListPalettesByProject 项目下已提取主色的素材（只取 id 与 palette）
+func (r *assetRepository) ListPalettesByProject(ctx context.Context, projectID uint) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	if err := r.db.WithContext(ctx).Model(&models.CreativeAsset{}).
+		Select("creative_assets.id, creative_assets.palette").
+		Joins("JOIN creative_tasks ON creative_tasks.id = creative_assets.task_id").
+		Where("creative_tasks.project_id = ? AND creative_assets.palette IS NOT NULL", projectID).
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ListMissingFeatures 范围内尚未计算视觉特征的素材，供回填使用
+func (r *assetRepository) ListMissingFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error) {
+	var assets []models.CreativeAsset
+	db := r.db.WithContext(ctx).Where("features IS NULL OR palette IS NULL")
+	if cond, args := scope.Condition("creative_tasks.project_id", "creative_tasks.user_id"); cond != "" {
+		db = db.Where("task_id IN (?)", r.db.Table("creative_tasks").Select("creative_tasks.id").Where(cond, args...))
+	}
+	if err := db.Order("id desc").Limit(limit).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// ReferencedByExperiments 返回被实验变体引用的素材 ID
+func (r *assetRepository) ReferencedByExperiments(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	out := make(map[uint]bool)
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var referenced []uint
+	if err := r.db.WithContext(ctx).Model(&models.ExperimentVariant{}).
+		Where("creative_id IN ?", ids).
+		Distinct().Pluck("creative_id", &referenced).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range referenced {
+		out[id] = true
+	}
+	return out, nil
+}
+
+// PurgeByIDs 物理删除素材及其评分、标签关联
+func (r *assetRepository) PurgeByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagIDs, err := tagIDsOf(tx, ids)
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM creative_tags WHERE creative_asset_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := refreshTagUsage(tx, tagIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("creative_id IN ?", ids).Delete(&models.CreativeScore{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("asset_id IN ?", ids).Delete(&models.AssetReupload{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("asset_id IN ?", ids).Delete(&models.AssetReviewComment{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.CreativeAsset{}).Error
+	})
+}
+
+// tagIDsOf 素材关联的标签 ID
+func tagIDsOf(tx *gorm.DB, assetIDs []uint) ([]uint, error) {
+	var tagIDs []uint
+	if len(assetIDs) == 0 {
+		return tagIDs, nil
+	}
+	err := tx.Table("creative_tags").Where("creative_asset_id IN ?", assetIDs).Distinct().Pluck("tag_id", &tagIDs).Error
+	return tagIDs, err
+}
+
+// refreshTagUsage 按未删除素材的关联数重算标签 usage_count
+func refreshTagUsage(tx *gorm.DB, tagIDs []uint) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	return tx.Exec(`UPDATE tags SET usage_count = (
+	SELECT COUNT(*) FROM creative_tags
+	JOIN creative_assets ON creative_assets.id = creative_tags.creative_asset_id
+	WHERE creative_tags.tag_id = tags.id AND creative_assets.deleted_at IS NULL
+) WHERE id IN ?`, tagIDs).Error
 }
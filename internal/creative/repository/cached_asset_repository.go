@@ -41,6 +41,10 @@ func (r *CachedAssetRepository) Create(ctx context.Context, asset *models.Creati
 	return nil
 }
 
+func (r *CachedAssetRepository) GetByUUID(ctx context.Context, uuid string) (*models.CreativeAsset, error) {
+	return r.inner.GetByUUID(ctx, uuid)
+}
+
 func (r *CachedAssetRepository) List(ctx context.Context, query shared.ListAssetsQuery) ([]models.CreativeAsset, int64, error) {
 	key := r.keys.AssetList(query)
 	var payload struct {
@@ -62,6 +66,26 @@ func (r *CachedAssetRepository) List(ctx context.Context, query shared.ListAsset
 	return payload.Assets, payload.Total, nil
 }
 
+func (r *CachedAssetRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	if err := r.inner.UpdateFields(ctx, id, fields); err != nil {
+		return err
+	}
+	r.invalidateLists(ctx)
+	return nil
+}
+
+func (r *CachedAssetRepository) SaveScore(ctx context.Context, score *models.CreativeScore) error {
+	return r.inner.SaveScore(ctx, score)
+}
+
+func (r *CachedAssetRepository) Delete(ctx context.Context, asset *models.CreativeAsset) error {
+	if err := r.inner.Delete(ctx, asset); err != nil {
+		return err
+	}
+	r.invalidateLists(ctx)
+	return nil
+}
+
 func (r *CachedAssetRepository) DeleteByTaskID(ctx context.Context, taskID uint) error {
 	if err := r.inner.DeleteByTaskID(ctx, taskID); err != nil {
 		return err
@@ -70,6 +94,58 @@ func (r *CachedAssetRepository) DeleteByTaskID(ctx context.Context, taskID uint)
 	return nil
 }
 
+func (r *CachedAssetRepository) ListByTaskID(ctx context.Context, taskID uint) ([]models.CreativeAsset, error) {
+	return r.inner.ListByTaskID(ctx, taskID)
+}
+
+func (r *CachedAssetRepository) ListByTaskIDWithDeleted(ctx context.Context, taskID uint) ([]models.CreativeAsset, error) {
+	return r.inner.ListByTaskIDWithDeleted(ctx, taskID)
+}
+
+func (r *CachedAssetRepository) ListWithFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error) {
+	return r.inner.ListWithFeatures(ctx, scope, limit)
+}
+
+func (r *CachedAssetRepository) GetByID(ctx context.Context, id uint) (*models.CreativeAsset, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+func (r *CachedAssetRepository) ListChildren(ctx context.Context, parentIDs []uint) ([]models.CreativeAsset, error) {
+	return r.inner.ListChildren(ctx, parentIDs)
+}
+
+func (r *CachedAssetRepository) MetricTotals(ctx context.Context, ids []uint) (map[uint]shared.MetricTotals, error) {
+	return r.inner.MetricTotals(ctx, ids)
+}
+
+func (r *CachedAssetRepository) AddReviewComment(ctx context.Context, comment *models.AssetReviewComment) error {
+	return r.inner.AddReviewComment(ctx, comment)
+}
+
+func (r *CachedAssetRepository) ListReviewComments(ctx context.Context, assetID uint) ([]models.AssetReviewComment, error) {
+	return r.inner.ListReviewComments(ctx, assetID)
+}
+
+func (r *CachedAssetRepository) ListPalettesByProject(ctx context.Context, projectID uint) ([]models.CreativeAsset, error) {
+	return r.inner.ListPalettesByProject(ctx, projectID)
+}
+
+func (r *CachedAssetRepository) ListMissingFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error) {
+	return r.inner.ListMissingFeatures(ctx, scope, limit)
+}
+
+func (r *CachedAssetRepository) ReferencedByExperiments(ctx context.Context, ids []uint) (map[uint]bool, error) {
+	return r.inner.ReferencedByExperiments(ctx, ids)
+}
+
+func (r *CachedAssetRepository) PurgeByIDs(ctx context.Context, ids []uint) error {
+	if err := r.inner.PurgeByIDs(ctx, ids); err != nil {
+		return err
+	}
+	r.invalidateLists(ctx)
+	return nil
+}
+
 func (r *CachedAssetRepository) invalidateLists(ctx context.Context) {
 	r.cache.DeleteByPrefix(ctx, "asset:list:")
 }
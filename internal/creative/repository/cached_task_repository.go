@@ -49,6 +49,10 @@ func (r *CachedTaskRepository) GetByUUID(ctx context.Context, uuid string) (*mod
 	return r.inner.GetByUUID(ctx, uuid)
 }
 
+func (r *CachedTaskRepository) GetByUUIDWithDeleted(ctx context.Context, uuid string) (*models.CreativeTask, error) {
+	return r.inner.GetByUUIDWithDeleted(ctx, uuid)
+}
+
 func (r *CachedTaskRepository) GetByUUIDWithAssets(ctx context.Context, uuid string) (*models.CreativeTask, error) {
 	key := r.keys.TaskDetail(uuid)
 	var task models.CreativeTask
@@ -110,6 +114,15 @@ func (r *CachedTaskRepository) Delete(ctx context.Context, task *models.Creative
 	return nil
 }
 
+func (r *CachedTaskRepository) Purge(ctx context.Context, task *models.CreativeTask) error {
+	if err := r.inner.Purge(ctx, task); err != nil {
+		return err
+	}
+	r.cache.Delete(ctx, r.keys.TaskDetail(task.UUID))
+	r.invalidateLists(ctx)
+	return nil
+}
+
 func (r *CachedTaskRepository) invalidateLists(ctx context.Context) {
 	r.cache.DeleteByPrefix(ctx, "task:list:")
 }
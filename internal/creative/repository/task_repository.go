@@ -44,6 +44,15 @@ func (r *taskRepository) GetByUUID(ctx context.Context, uuid string) (*models.Cr
 	return &task, nil
 }
 
+// GetByUUIDWithDeleted 根据UUID获取任务，包含已软删除的任务
+func (r *taskRepository) GetByUUIDWithDeleted(ctx context.Context, uuid string) (*models.CreativeTask, error) {
+	var task models.CreativeTask
+	if err := r.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
 // GetByUUIDWithAssets 根据UUID获取任务及素材
 func (r *taskRepository) GetByUUIDWithAssets(ctx context.Context, uuid string) (*models.CreativeTask, error) {
 	var task models.CreativeTask
@@ -95,6 +104,9 @@ func (r *taskRepository) List(ctx context.Context, query shared.ListTasksQuery)
 	if query.UserID > 0 {
 		dbQuery = dbQuery.Where("user_id = ?", query.UserID)
 	}
+	if cond, args := query.Scope.Condition("project_id", "user_id"); cond != "" {
+		dbQuery = dbQuery.Where(cond, args...)
+	}
 
 	// 获取总数
 	if err := dbQuery.Count(&total).Error; err != nil {
@@ -116,3 +128,8 @@ func (r *taskRepository) List(ctx context.Context, query shared.ListTasksQuery)
 func (r *taskRepository) Delete(ctx context.Context, task *models.CreativeTask) error {
 	return r.db.WithContext(ctx).Delete(task).Error
 }
+
+// Purge 物理删除任务
+func (r *taskRepository) Purge(ctx context.Context, task *models.CreativeTask) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(task).Error
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AnimationFormat 动图素材的 format
+const AnimationFormat = "gif"
+
+const (
+	defaultAnimationWidth = 480
+	maxAnimationWidth     = 1024
+	maxAnimationFrames    = 20
+	defaultFrameDelay     = 1500 * time.Millisecond
+)
+
+// AnimationInput 由任务素材合成动图
+type AnimationInput struct {
+	TaskID         string
+	AssetIDs       []string      // 帧顺序；为空时取任务全部可用素材
+	FrameDelay     time.Duration // 图片帧时长，默认 1.5s
+	TextFrameDelay time.Duration // 文字帧时长，默认同 FrameDelay
+	Loops          int           // 播放次数，0 为无限循环
+	TextFrames     bool          // 在图片帧之间插入卖点文字帧
+	Width          int           // 输出宽度，高度按首帧比例
+}
+
+// AnimationResult 合成结果
+type AnimationResult struct {
+	Asset    *models.CreativeAsset
+	Frames   int
+	Warnings []string
+}
+
+// CreateAnimation 将任务的多个变体/尺寸合成为 GIF，并作为 format=gif 的新素材保存
+func (s *CreativeService) CreateAnimation(ctx context.Context, input AnimationInput) (*AnimationResult, error) {
+	if input.TaskID == "" {
+		return nil, errors.New("task_id is required")
+	}
+	if s.reader == nil || s.processor == nil || s.processor.storageClient == nil {
+		return nil, errors.New("storage backend not configured")
+	}
+	task, err := s.scopedTask(ctx, input.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	sources, err := s.animationSources(ctx, task, input.AssetIDs)
+	if err != nil {
+		return nil, err
+	}
+	if input.FrameDelay <= 0 {
+		input.FrameDelay = defaultFrameDelay
+	}
+	if input.TextFrameDelay <= 0 {
+		input.TextFrameDelay = input.FrameDelay
+	}
+	width := input.Width
+	if width <= 0 {
+		width = defaultAnimationWidth
+	}
+	if width > maxAnimationWidth {
+		width = maxAnimationWidth
+	}
+
+	var (
+		frames   []*image.RGBA
+		warnings []string
+		height   int
+	)
+	for _, a := range sources {
+		data, err := s.readAssetImage(ctx, &a)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("asset %s skipped: %v", a.UUID, err))
+			continue
+		}
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("asset %s skipped: %v", a.UUID, err))
+			continue
+		}
+		if height == 0 {
+			b := img.Bounds()
+			height = width * b.Dy() / b.Dx()
+			if height < 1 {
+				height = 1
+			}
+		}
+		frames = append(frames, imaging.Cover(img, width, height))
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("no frames could be loaded: " + strings.Join(warnings, "; "))
+	}
+
+	anim := &gif.GIF{LoopCount: gifLoopCount(input.Loops)}
+	addFrame := func(img image.Image, d time.Duration) {
+		anim.Image = append(anim.Image, quantize(img))
+		anim.Delay = append(anim.Delay, int(d/(10*time.Millisecond)))
+	}
+	var points []string
+	if input.TextFrames {
+		for _, sp := range task.SellingPoints {
+			if sp = strings.TrimSpace(sp); sp == "" {
+				continue
+			}
+			// 内置字体无法渲染的卖点（如中文）不生成文字帧，避免输出方框
+			if !imaging.CanRender(sp) {
+				warnings = append(warnings, fmt.Sprintf("text frame for selling point %q skipped: the built-in font cannot render it", sp))
+				continue
+			}
+			points = append(points, sp)
+		}
+	}
+	// 图片帧与文字帧交替，多余的文字帧以最后一张图片为底
+	for i := 0; i < len(frames) || i < len(points); i++ {
+		base := frames[len(frames)-1]
+		if i < len(frames) {
+			base = frames[i]
+		}
+		if i < len(frames) {
+			addFrame(base, input.FrameDelay)
+		}
+		if i < len(points) {
+			addFrame(textFrame(base, points[i]), input.TextFrameDelay)
+		}
+	}
+	if len(anim.Image) < 2 {
+		return nil, errors.New("animation needs at least two frames")
+	}
+	if len(anim.Image) > maxAnimationFrames {
+		return nil, fmt.Errorf("animation has %d frames, limit %d", len(anim.Image), maxAnimationFrames)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("encode gif failed: %w", err)
+	}
+	data := buf.Bytes()
+
+	backend := s.processor.storageClient
+	assetUUID := uuid.New().String()
+	key := backend.GenerateKey(fmt.Sprintf("%s_anim_%s.gif", task.UUID, assetUUID[:8]))
+	publicURL, err := backend.Put(ctx, key, data, "image/gif")
+	if err != nil {
+		return nil, fmt.Errorf("store animation failed: %w", err)
+	}
+
+	size := len(data)
+	asset := models.CreativeAsset{
+		UUIDModel:     models.UUIDModel{UUID: assetUUID},
+		TaskID:        task.ID,
+		Title:         task.Title,
+		ProductName:   task.ProductName,
+		CTAText:       task.CTAText,
+		SellingPoints: task.SellingPoints,
+		Format:        AnimationFormat,
+		Width:         width,
+		Height:        height,
+		FileSize:      &size,
+		StorageType:   backend.Type(),
+		PublicURL:     publicURL,
+		StorageKey:    key,
+		Style:         sources[0].Style,
+		ModelName:     "animation",
+		HasCTA:        task.CTAText != "",
+		// 动图以首帧素材为父素材
+		ParentAssetID:    &sources[0].ID,
+		DerivationType:   models.DerivationAnimate,
+		DerivationParams: models.JSONMap{"source_assets": sourceUUIDs(sources)},
+	}
+	s.processor.analyzeImage(ctx, &asset, frames[0], task.ProjectID)
+	s.processor.applyAutoTags(ctx, &asset)
+	if err := s.assetRepo.Create(ctx, &asset); err != nil {
+		_ = backend.Delete(ctx, key)
+		return nil, fmt.Errorf("save asset failed: %w", err)
+	}
+	s.processor.refreshTagUsage(ctx, &asset)
+	return &AnimationResult{Asset: &asset, Frames: len(anim.Image), Warnings: warnings}, nil
+}
+
+// animationSources 按请求顺序取帧素材；未指定时取任务中非隔离、非动图的素材
+func (s *CreativeService) animationSources(ctx context.Context, task *models.CreativeTask, ids []string) ([]models.CreativeAsset, error) {
+	assets, err := s.assetRepo.ListByTaskID(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list assets failed: %w", err)
+	}
+	usable := make(map[string]models.CreativeAsset, len(assets))
+	var ordered []models.CreativeAsset
+	for _, a := range assets {
+		if a.Quarantined || a.Format == AnimationFormat {
+			continue
+		}
+		usable[a.UUID] = a
+		ordered = append(ordered, a)
+	}
+	if len(ids) == 0 {
+		if len(ordered) == 0 {
+			return nil, errors.New("task has no usable assets")
+		}
+		if len(ordered) > maxAnimationFrames {
+			ordered = ordered[:maxAnimationFrames]
+		}
+		return ordered, nil
+	}
+	out := make([]models.CreativeAsset, 0, len(ids))
+	for _, id := range ids {
+		a, ok := usable[id]
+		if !ok {
+			return nil, fmt.Errorf("asset %s not found in task or not usable", id)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// gifLoopCount 播放次数转为 GIF LoopCount：0 无限，1 只播一次（-1），n 重复 n-1 次
+func gifLoopCount(loops int) int {
+	switch {
+	case loops <= 0:
+		return 0
+	case loops == 1:
+		return -1
+	default:
+		return loops - 1
+	}
+}
+
+// quantize 使用统一调色板抖动，减少帧间闪烁
+func quantize(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	p := image.NewPaletted(b, palette.Plan9)
+	draw.FloydSteinberg.Draw(p, b, img, b.Min)
+	return p
+}
+
+// textFrame 在压暗的底图上居中绘制卖点
+func textFrame(base *image.RGBA, text string) *image.RGBA {
+	b := base.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, base, b.Min, draw.Src)
+	draw.Draw(out, b, image.NewUniform(color.RGBA{0, 0, 0, 170}), image.Point{}, draw.Over)
+
+	scale := b.Dx() / 160
+	if scale < 2 {
+		scale = 2
+	}
+	margin := b.Dx() / 10
+	lines := imaging.WrapText(text, b.Dx()-2*margin, scale)
+	for len(lines) > 1 {
+		if _, h := imaging.TextSize(lines, scale); h <= b.Dy()-2*margin || scale <= 1 {
+			break
+		}
+		scale--
+		lines = imaging.WrapText(text, b.Dx()-2*margin, scale)
+	}
+	w, h := imaging.TextSize(lines, scale)
+	imaging.DrawText(out, lines, (b.Dx()-w)/2, (b.Dy()-h)/2, scale, color.White)
+	return out
+}
+
+func sourceUUIDs(sources []models.CreativeAsset) []string {
+	out := make([]string, 0, len(sources))
+	for _, a := range sources {
+		out = append(out, a.UUID)
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// SetAuditRecorder 设置审计日志记录器（nil 表示不记录）
+func (s *CreativeService) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// auditTask 记录任务变更；before/after 为任务快照，after 为 nil 表示删除
+func (s *CreativeService) auditTask(ctx context.Context, action string, task *models.CreativeTask, before, after map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceTask,
+		ResourceID:   task.UUID,
+		ProjectID:    task.ProjectID,
+		Before:       before,
+		After:        after,
+	})
+}
+
+// auditAsset 记录素材变更；项目取自已加载的任务，未加载时由审计服务按当前范围补全
+func (s *CreativeService) auditAsset(ctx context.Context, action string, asset *models.CreativeAsset, before, after map[string]interface{}) {
+	if s.audit == nil {
+		return
+	}
+	var projectID *uint
+	if asset.Task != nil {
+		projectID = asset.Task.ProjectID
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceAsset,
+		ResourceID:   asset.UUID,
+		ProjectID:    projectID,
+		Before:       before,
+		After:        after,
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// ComputePalette k-means 提取主色
+func ComputePalette(img image.Image) models.Palette {
+	swatches := imaging.KMeansPalette(img, dominantPaletteSize)
+	out := make(models.Palette, 0, len(swatches))
+	for _, s := range swatches {
+		out = append(out, models.PaletteColor{Hex: imaging.HexColor(s.Color), Weight: math.Round(s.Weight*1e4) / 1e4})
+	}
+	return out
+}
+
+// BrandConformanceScore 主色与品牌色的符合度（保留三位小数）；品牌色为空时返回 nil
+func BrandConformanceScore(palette models.Palette, brandColors []string) *float64 {
+	var brand []color.RGBA
+	for _, hex := range brandColors {
+		if c, ok := imaging.ParseHexColor(hex); ok {
+			brand = append(brand, c)
+		}
+	}
+	if len(brand) == 0 || len(palette) == 0 {
+		return nil
+	}
+	v := math.Round(imaging.BrandConformance(swatchesOf(palette), brand)*1000) / 1000
+	return &v
+}
+
+// brandConformance 读取项目品牌色并计算符合度；未关联项目或未配置品牌色时为 nil
+func (p *TaskProcessor) brandConformance(ctx context.Context, projectID *uint, palette models.Palette) *float64 {
+	if p == nil || p.brands == nil || projectID == nil {
+		return nil
+	}
+	colors, err := p.brands.BrandColors(ctx, *projectID)
+	if err != nil {
+		log.Printf("读取项目品牌色失败(project=%d): %v", *projectID, err)
+		return nil
+	}
+	return BrandConformanceScore(palette, colors)
+}
+
+// RefreshBrandConformance 项目品牌色变更后，按已存主色重算项目下素材的符合度
+func (s *CreativeService) RefreshBrandConformance(ctx context.Context, projectID uint) (int, error) {
+	if s.processor == nil || s.processor.brands == nil {
+		return 0, nil
+	}
+	colors, err := s.processor.brands.BrandColors(ctx, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("load brand colors failed: %w", err)
+	}
+	assets, err := s.assetRepo.ListPalettesByProject(ctx, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("list assets failed: %w", err)
+	}
+	updated := 0
+	for _, a := range assets {
+		score := BrandConformanceScore(a.Palette, colors)
+		if err := s.assetRepo.UpdateFields(ctx, a.ID, map[string]interface{}{"brand_conformance": score}); err != nil {
+			return updated, fmt.Errorf("update asset %d failed: %w", a.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// ExportOptions 导出筛选：为空表示全部
+type ExportOptions struct {
+	Formats  []string
+	Variants []int
+}
+
+// ExportManifestItem manifest 中的单个素材
+type ExportManifestItem struct {
+	AssetID        string   `json:"asset_id"`
+	File           string   `json:"file,omitempty"`
+	Format         string   `json:"format"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	VariantIndex   *int     `json:"variant_index,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	Prompt         string   `json:"prompt,omitempty"`
+	Title          string   `json:"title,omitempty"`
+	CTAText        string   `json:"cta_text,omitempty"`
+	SellingPoints  []string `json:"selling_points,omitempty"`
+	QualityScore   *float64 `json:"quality_score,omitempty"`
+	CTRPrediction  *float64 `json:"ctr_prediction,omitempty"`
+	AestheticScore *float64 `json:"aesthetic_score,omitempty"`
+	PublicURL      string   `json:"public_url"`
+	StorageType    string   `json:"storage_type"`
+	ModelName      string   `json:"model_name,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// ExportManifest manifest.json 内容
+type ExportManifest struct {
+	TaskID      string               `json:"task_id"`
+	Title       string               `json:"title"`
+	ProductName string               `json:"product_name,omitempty"`
+	Source      string               `json:"source,omitempty"`
+	ExportedAt  string               `json:"exported_at"`
+	Assets      []ExportManifestItem `json:"assets"`
+}
+
+// TaskExport 已筛选好的导出内容，WriteZip 时才读取对象
+type TaskExport struct {
+	Task   *models.CreativeTask
+	Assets []models.CreativeAsset
+	open   func(ctx context.Context, a models.CreativeAsset) (io.ReadCloser, error)
+}
+
+// PrepareExport 校验任务并筛选素材；在开始写响应前调用，便于返回正常错误
+func (s *CreativeService) PrepareExport(ctx context.Context, taskUUID string, opts ExportOptions) (*TaskExport, error) {
+	if taskUUID == "" {
+		return nil, errors.New("task_id is required")
+	}
+	if s.reader == nil {
+		return nil, errors.New("storage reader not configured")
+	}
+	task, err := s.scopedTask(ctx, taskUUID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	assets, err := s.assetRepo.ListByTaskID(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list assets failed: %w", err)
+	}
+
+	formats := make(map[string]bool, len(opts.Formats))
+	for _, f := range opts.Formats {
+		formats[f] = true
+	}
+	variants := make(map[int]bool, len(opts.Variants))
+	for _, v := range opts.Variants {
+		variants[v] = true
+	}
+
+	var selected []models.CreativeAsset
+	for _, a := range assets {
+		if a.Quarantined {
+			continue
+		}
+		if len(formats) > 0 && !formats[a.Format] {
+			continue
+		}
+		if len(variants) > 0 && (a.VariantIndex == nil || !variants[*a.VariantIndex]) {
+			continue
+		}
+		selected = append(selected, a)
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no assets match the export filters")
+	}
+
+	reader := s.reader
+	return &TaskExport{
+		Task:   task,
+		Assets: selected,
+		open: func(ctx context.Context, a models.CreativeAsset) (io.ReadCloser, error) {
+			return reader.Open(ctx, a.StorageType, a.StorageKey, a.PublicURL)
+		},
+	}, nil
+}
+
+// maxAssetImageBytes 读取单个素材图片的上限
+const maxAssetImageBytes = 50 << 20
+
+// GetAsset 查询当前范围内的素材（隔离中的素材不可用）
+func (s *CreativeService) GetAsset(ctx context.Context, assetUUID string) (*models.CreativeAsset, error) {
+	if assetUUID == "" {
+		return nil, errors.New("asset_id is required")
+	}
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("asset not found: %w", err)
+	}
+	if asset.Quarantined {
+		return nil, errors.New("asset is quarantined")
+	}
+	return asset, nil
+}
+
+// LoadAssetImage 读取素材及其图片数据（隔离中的素材不可用）
+func (s *CreativeService) LoadAssetImage(ctx context.Context, assetUUID string) (*models.CreativeAsset, []byte, error) {
+	if assetUUID == "" {
+		return nil, nil, errors.New("asset_id is required")
+	}
+	if s.reader == nil {
+		return nil, nil, errors.New("storage reader not configured")
+	}
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("asset not found: %w", err)
+	}
+	if asset.Quarantined {
+		return nil, nil, errors.New("asset is quarantined")
+	}
+	data, err := s.readAssetImage(ctx, asset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, data, nil
+}
+
+func (s *CreativeService) readAssetImage(ctx context.Context, asset *models.CreativeAsset) ([]byte, error) {
+	rc, err := s.reader.Open(ctx, asset.StorageType, asset.StorageKey, asset.PublicURL)
+	if err != nil {
+		return nil, fmt.Errorf("open asset failed: %w", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxAssetImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read asset failed: %w", err)
+	}
+	if len(data) > maxAssetImageBytes {
+		return nil, errors.New("asset image too large")
+	}
+	return data, nil
+}
+
+// ObjectSize 素材图片字节数：记录了 FileSize 时直接返回，否则读取对象计数（生成素材未记录大小）
+func (e *TaskExport) ObjectSize(ctx context.Context, a models.CreativeAsset) (int, error) {
+	if a.FileSize != nil {
+		return *a.FileSize, nil
+	}
+	rc, err := e.open(ctx, a)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	n, err := io.Copy(io.Discard, io.LimitReader(rc, maxAssetImageBytes+1))
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// FileName 建议的下载文件名
+func (e *TaskExport) FileName() string {
+	return fmt.Sprintf("task_%s.zip", e.Task.UUID)
+}
+
+// WriteZip 流式写出 ZIP：素材文件 + manifest.json + manifest.csv；单个素材读取失败时记录在 manifest 中
+func (e *TaskExport) WriteZip(ctx context.Context, w io.Writer) error {
+	return e.WriteBundle(ctx, w, func(zw *zip.Writer, items []ExportManifestItem) error {
+		manifest := ExportManifest{
+			TaskID:      e.Task.UUID,
+			Title:       e.Task.Title,
+			ProductName: e.Task.ProductName,
+			Source:      e.Task.Source,
+			ExportedAt:  time.Now().Format(time.RFC3339),
+			Assets:      items,
+		}
+		jw, err := zw.Create("manifest.json")
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(jw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(manifest); err != nil {
+			return err
+		}
+
+		cw, err := zw.Create("manifest.csv")
+		if err != nil {
+			return err
+		}
+		return writeManifestCSV(cw, items)
+	})
+}
+
+// WriteBundle 写出全部素材文件后调用 finish 追加其他文件（manifest、投放表格等）
+func (e *TaskExport) WriteBundle(ctx context.Context, w io.Writer, finish func(zw *zip.Writer, items []ExportManifestItem) error) error {
+	zw := zip.NewWriter(w)
+	items := e.WriteFiles(ctx, zw, "")
+	if finish != nil {
+		if err := finish(zw, items); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// WriteFiles 将素材文件写入已有 ZIP（路径加 prefix 前缀），返回 manifest 条目；单个素材读取失败时记录在条目中
+func (e *TaskExport) WriteFiles(ctx context.Context, zw *zip.Writer, prefix string) []ExportManifestItem {
+	items := make([]ExportManifestItem, 0, len(e.Assets))
+	for _, a := range e.Assets {
+		item := manifestItem(a)
+		name := prefix + ExportFileName(a)
+		if err := e.copyAsset(ctx, zw, name, a); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.File = name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (e *TaskExport) copyAsset(ctx context.Context, zw *zip.Writer, name string, a models.CreativeAsset) error {
+	rc, err := e.open(ctx, a)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	// 图片已压缩，直接存储避免重复压缩
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: a.CreatedAt})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, rc)
+	return err
+}
+
+func manifestItem(a models.CreativeAsset) ExportManifestItem {
+	item := ExportManifestItem{
+		AssetID:       a.UUID,
+		Format:        a.Format,
+		Width:         a.Width,
+		Height:        a.Height,
+		VariantIndex:  a.VariantIndex,
+		Style:         a.Style,
+		Prompt:        a.GenerationPrompt,
+		Title:         a.Title,
+		CTAText:       a.CTAText,
+		SellingPoints: a.SellingPoints,
+		PublicURL:     a.PublicURL,
+		StorageType:   string(a.StorageType),
+		ModelName:     a.ModelName,
+		CreatedAt:     a.CreatedAt.Format(time.RFC3339),
+	}
+	if a.Score != nil {
+		item.QualityScore = a.Score.QualityOverall
+		item.CTRPrediction = a.Score.CTRPrediction
+		item.AestheticScore = a.Score.AestheticScore
+	}
+	return item
+}
+
+// ExportFileName 素材在 ZIP 中的路径，形如 1x1/v0_1a2b3c4d.png
+func ExportFileName(a models.CreativeAsset) string {
+	ext := path.Ext(a.StorageKey)
+	if ext == "" {
+		ext = path.Ext(strings.SplitN(a.PublicURL, "?", 2)[0])
+	}
+	if ext == "" || len(ext) > 5 {
+		ext = ".png"
+	}
+	dir := strings.ReplaceAll(a.Format, ":", "x")
+	if dir == "" {
+		dir = "other"
+	}
+	variant := "v"
+	if a.VariantIndex != nil {
+		variant += strconv.Itoa(*a.VariantIndex)
+	}
+	id := a.UUID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%s/%s_%s%s", dir, variant, id, ext)
+}
+
+func writeManifestCSV(w io.Writer, items []ExportManifestItem) error {
+	cw := csv.NewWriter(w)
+	header := []string{"asset_id", "file", "format", "width", "height", "variant_index", "style", "prompt", "title", "cta_text", "selling_points", "quality_score", "ctr_prediction", "aesthetic_score", "public_url", "storage_type", "model_name", "created_at", "error"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, it := range items {
+		variant := ""
+		if it.VariantIndex != nil {
+			variant = strconv.Itoa(*it.VariantIndex)
+		}
+		row := []string{
+			it.AssetID, it.File, it.Format, strconv.Itoa(it.Width), strconv.Itoa(it.Height), variant,
+			it.Style, it.Prompt, it.Title, it.CTAText, strings.Join(it.SellingPoints, " | "),
+			formatScore(it.QualityScore), formatScore(it.CTRPrediction), formatScore(it.AestheticScore),
+			it.PublicURL, it.StorageType, it.ModelName, it.CreatedAt, it.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatScore(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/settings"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/internal/upload"
+
+	"github.com/google/uuid"
+)
+
+// ImportItem 待导入的单个素材：Data（文件内容）与 SourceURL 二选一
+type ImportItem struct {
+	FileName      string   `json:"file_name,omitempty"`
+	Data          []byte   `json:"-"`
+	SourceURL     string   `json:"url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ProductName   string   `json:"product_name,omitempty"`
+	CTAText       string   `json:"cta_text,omitempty"`
+	SellingPoints []string `json:"selling_points,omitempty"`
+	Format        string   `json:"format,omitempty"`
+	Style         string   `json:"style,omitempty"`
+}
+
+// ImportInput 导入请求：所有素材归属同一个 source=import 的任务
+type ImportInput struct {
+	UserID      uint
+	Title       string
+	ProductName string
+	Items       []ImportItem
+}
+
+// importFormats 未指定 format 时按宽高比就近选择
+var importFormats = []struct {
+	name  string
+	ratio float64
+}{
+	{"1:1", 1}, {"4:3", 4.0 / 3}, {"3:4", 3.0 / 4}, {"16:9", 16.0 / 9}, {"9:16", 9.0 / 16},
+}
+
+// ImportCreatives 导入外部制作的素材，生成一个已完成的导入任务，素材可直接用于实验
+func (s *CreativeService) ImportCreatives(ctx context.Context, input ImportInput) (*models.CreativeTask, []models.CreativeAsset, error) {
+	if len(input.Items) == 0 {
+		return nil, nil, errors.New("at least one item is required")
+	}
+	if s.processor == nil || s.processor.storageClient == nil {
+		return nil, nil, errors.New("storage backend not configured")
+	}
+	if input.UserID == 0 {
+		input.UserID = shared.UserIDFrom(ctx)
+	}
+	for i, item := range input.Items {
+		if len(item.Data) == 0 && item.SourceURL == "" {
+			return nil, nil, fmt.Errorf("item %d: file or url is required", i)
+		}
+		// format 会写入导出 ZIP 的文件名，只接受支持的画面比例
+		if item.Format != "" && !settings.IsSupportedFormat(item.Format) {
+			return nil, nil, fmt.Errorf("item %d: format %q must be one of %s", i, item.Format, strings.Join(settings.SupportedFormats, ", "))
+		}
+	}
+
+	first := input.Items[0]
+	title := firstNonEmpty(input.Title, first.Title, first.FileName, "Imported creatives")
+	now := time.Now()
+	task := models.CreativeTask{
+		UUIDModel:     models.UUIDModel{UUID: uuid.New().String()},
+		UserID:        input.UserID,
+		ProjectID:     shared.ProjectIDFrom(ctx),
+		Source:        models.TaskSourceImport,
+		Title:         title,
+		ProductName:   firstNonEmpty(input.ProductName, first.ProductName),
+		SellingPoints: models.StringArray(first.SellingPoints),
+		CTAText:       first.CTAText,
+		NumVariants:   len(input.Items),
+		Status:        models.TaskProcessing,
+		StartedAt:     &now,
+	}
+	if err := s.taskRepo.Create(ctx, &task); err != nil {
+		return nil, nil, fmt.Errorf("failed to create import task: %w", err)
+	}
+
+	var assets []models.CreativeAsset
+	var errs []string
+	formats := map[string]bool{}
+	for i, item := range input.Items {
+		asset, err := s.importItem(ctx, &task, i, item)
+		if err != nil {
+			log.Printf("导入素材失败(task=%s, item=%d): %v", task.UUID, i, err)
+			errs = append(errs, fmt.Sprintf("item %d: %v", i, err))
+			continue
+		}
+		formats[asset.Format] = true
+		assets = append(assets, *asset)
+	}
+
+	completed := time.Now()
+	updates := map[string]interface{}{
+		"status":       models.TaskCompleted,
+		"progress":     100,
+		"completed_at": &completed,
+	}
+	if len(assets) == 0 {
+		updates["status"] = models.TaskFailed
+		updates["progress"] = 0
+	}
+	if len(errs) > 0 {
+		updates["error_message"] = strings.Join(errs, "; ")
+	}
+	var requested models.StringArray
+	for f := range formats {
+		requested = append(requested, f)
+	}
+	updates["requested_formats"] = requested
+	for _, a := range assets {
+		if !a.Quarantined {
+			updates["first_asset_url"] = a.PublicURL
+			break
+		}
+	}
+	if err := s.taskRepo.UpdateFields(ctx, task.ID, updates); err != nil {
+		return nil, nil, fmt.Errorf("failed to update import task: %w", err)
+	}
+	task.Status = updates["status"].(models.TaskStatus)
+
+	if len(assets) == 0 {
+		return &task, nil, fmt.Errorf("no items imported: %s", strings.Join(errs, "; "))
+	}
+	return &task, assets, nil
+}
+
+// importItem 存储单个素材并落库，同样经过内容审核
+func (s *CreativeService) importItem(ctx context.Context, task *models.CreativeTask, idx int, item ImportItem) (*models.CreativeAsset, error) {
+	data := item.Data
+	if len(data) == 0 {
+		fetched, _, err := storage.Fetch(ctx, item.SourceURL)
+		if err != nil {
+			return nil, err
+		}
+		data = fetched
+	}
+	if _, err := upload.CheckImage(data, upload.ConfiguredLimits()); err != nil {
+		return nil, err
+	}
+
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, contentType, ext, err := imaging.Encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+
+	backend := s.processor.storageClient
+	key := backend.GenerateKey(fmt.Sprintf("%s_import_%d%s", task.UUID, idx, ext))
+	publicURL, err := backend.Put(ctx, key, encoded, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("store creative failed: %w", err)
+	}
+
+	meta := &models.CreativeTask{
+		UUIDModel:     task.UUIDModel,
+		Title:         firstNonEmpty(item.Title, task.Title),
+		CTAText:       item.CTAText,
+		SellingPoints: models.StringArray(item.SellingPoints),
+	}
+	// 导入源地址可能再次返回不同内容，审核实际存储的数据
+	moderated := s.processor.moderateAsset(ctx, meta, moderationTarget(publicURL, "", encoded, contentType))
+
+	variant := idx
+	size := len(encoded)
+	asset := models.CreativeAsset{
+		UUIDModel:        models.UUIDModel{UUID: uuid.New().String()},
+		TaskID:           task.ID,
+		Title:            meta.Title,
+		ProductName:      firstNonEmpty(item.ProductName, task.ProductName),
+		CTAText:          item.CTAText,
+		SellingPoints:    meta.SellingPoints,
+		Format:           firstNonEmpty(item.Format, nearestFormat(bounds.Dx(), bounds.Dy())),
+		Width:            bounds.Dx(),
+		Height:           bounds.Dy(),
+		FileSize:         &size,
+		StorageType:      backend.Type(),
+		PublicURL:        publicURL,
+		StorageKey:       key,
+		OriginalPath:     firstNonEmpty(item.SourceURL, item.FileName),
+		Style:            item.Style,
+		VariantIndex:     &variant,
+		ModelName:        models.TaskSourceImport,
+		HasCTA:           item.CTAText != "",
+		Quarantined:      !moderated.safe,
+		ModerationReason: moderated.reason,
+	}
+	s.processor.analyzeImage(ctx, &asset, img, task.ProjectID)
+	s.processor.applyAutoTags(ctx, &asset)
+	if err := s.assetRepo.Create(ctx, &asset); err != nil {
+		_ = backend.Delete(ctx, key)
+		return nil, fmt.Errorf("save asset failed: %w", err)
+	}
+	s.processor.refreshTagUsage(ctx, &asset)
+	s.processor.saveModerationScore(ctx, asset.ID, moderated)
+	return &asset, nil
+}
+
+func nearestFormat(w, h int) string {
+	if w <= 0 || h <= 0 {
+		return "1:1"
+	}
+	ratio := float64(w) / float64(h)
+	best := importFormats[0].name
+	bestDiff := math.MaxFloat64
+	for _, f := range importFormats {
+		if d := math.Abs(math.Log(ratio / f.ratio)); d < bestDiff {
+			best, bestDiff = f.name, d
+		}
+	}
+	return best
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/internal/upload"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxLineageDepth 向上查找根素材的最大层数，防止异常数据成环
+	maxLineageDepth = 50
+	// maxLineageNodes 单棵派生树返回的最大节点数
+	maxLineageNodes = 1000
+	// maxDeriveDimension 缩放派生的最大边长
+	maxDeriveDimension = 4096
+)
+
+// ErrInvalidDerivation 派生参数不合法
+var ErrInvalidDerivation = errors.New("invalid derivation")
+
+// LineageMetrics 实验指标，Rollup 为节点及全部子孙素材之和
+type LineageMetrics struct {
+	Impressions int64   `json:"impressions"`
+	Clicks      int64   `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+}
+
+// LineageNode 派生树中的一个素材
+type LineageNode struct {
+	CreativeAssetDTO
+	DerivationType   string         `json:"derivation_type,omitempty"`
+	DerivationParams models.JSONMap `json:"derivation_params,omitempty"`
+	Metrics          LineageMetrics `json:"metrics"`
+	Rollup           LineageMetrics `json:"rollup"`
+	Children         []*LineageNode `json:"children"`
+}
+
+// LineageResult 素材所在的完整派生树
+type LineageResult struct {
+	AssetID   string       `json:"asset_id"`
+	RootID    string       `json:"root_id"`
+	Total     int          `json:"total"`
+	Truncated bool         `json:"truncated,omitempty"`
+	Tree      *LineageNode `json:"tree"`
+}
+
+// DeriveInput 从已有素材派生新版本：resize 按 Width/Height 裁剪缩放，
+// overlay/edit 由外部编辑后通过 SourceURL 回传结果
+type DeriveInput struct {
+	Type      string                 `json:"type"`
+	Width     int                    `json:"width,omitempty"`
+	Height    int                    `json:"height,omitempty"`
+	SourceURL string                 `json:"url,omitempty"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+}
+
+// GetLineage 返回素材所在的整棵派生树（从根素材开始），每个节点附带自身及子树的实验指标汇总
+func (s *CreativeService) GetLineage(ctx context.Context, assetUUID string) (*LineageResult, error) {
+	if assetUUID == "" {
+		return nil, errors.New("asset_id is required")
+	}
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, assetUUID)
+	}
+
+	root := asset
+	visited := map[uint]bool{root.ID: true}
+	for depth := 0; root.ParentAssetID != nil && depth < maxLineageDepth; depth++ {
+		if visited[*root.ParentAssetID] {
+			break
+		}
+		parent, err := s.assetRepo.GetByID(ctx, *root.ParentAssetID)
+		if err != nil {
+			// 父素材已被删除时，以当前最上层素材为根
+			break
+		}
+		visited[parent.ID] = true
+		root = parent
+	}
+
+	rootNode := LineageNodeOf(*root)
+	nodes := map[uint]*LineageNode{root.ID: rootNode}
+	seen := map[uint]bool{root.ID: true}
+	order := []uint{root.ID}
+	level := []uint{root.ID}
+	truncated := false
+	for len(level) > 0 && !truncated {
+		children, err := s.assetRepo.ListChildren(ctx, level)
+		if err != nil {
+			return nil, fmt.Errorf("list derived assets failed: %w", err)
+		}
+		var next []uint
+		for _, child := range children {
+			if seen[child.ID] || child.ParentAssetID == nil {
+				continue
+			}
+			if len(order) >= maxLineageNodes {
+				truncated = true
+				break
+			}
+			parent := nodes[*child.ParentAssetID]
+			if parent == nil {
+				continue
+			}
+			node := LineageNodeOf(child)
+			parent.Children = append(parent.Children, node)
+			nodes[child.ID] = node
+			seen[child.ID] = true
+			order = append(order, child.ID)
+			next = append(next, child.ID)
+		}
+		level = next
+	}
+
+	totals, err := s.assetRepo.MetricTotals(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("load metrics failed: %w", err)
+	}
+	rollupLineage(rootNode, totals)
+
+	return &LineageResult{
+		AssetID:   asset.UUID,
+		RootID:    root.UUID,
+		Total:     len(order),
+		Truncated: truncated,
+		Tree:      rootNode,
+	}, nil
+}
+
+// LineageNodeOf 单个素材的派生信息（不含子节点与指标）
+func LineageNodeOf(asset models.CreativeAsset) *LineageNode {
+	return &LineageNode{
+		CreativeAssetDTO: toAssetDTO(asset),
+		DerivationType:   string(asset.DerivationType),
+		DerivationParams: asset.DerivationParams,
+		Children:         []*LineageNode{},
+	}
+}
+
+// rollupLineage 后序遍历汇总子树指标
+func rollupLineage(node *LineageNode, totals map[uint]shared.MetricTotals) shared.MetricTotals {
+	own := totals[node.NumericID]
+	sum := own
+	for _, child := range node.Children {
+		c := rollupLineage(child, totals)
+		sum.Impressions += c.Impressions
+		sum.Clicks += c.Clicks
+	}
+	node.Metrics = toLineageMetrics(own)
+	node.Rollup = toLineageMetrics(sum)
+	return sum
+}
+
+func toLineageMetrics(t shared.MetricTotals) LineageMetrics {
+	m := LineageMetrics{Impressions: t.Impressions, Clicks: t.Clicks}
+	if t.Impressions > 0 {
+		m.CTR = float64(t.Clicks) / float64(t.Impressions)
+	}
+	return m
+}
+
+// DeriveAsset 基于已有素材创建派生版本，新素材归属同一任务并记录父素材与派生参数
+func (s *CreativeService) DeriveAsset(ctx context.Context, assetUUID string, in DeriveInput) (*models.CreativeAsset, error) {
+	if s.processor == nil || s.processor.storageClient == nil {
+		return nil, errors.New("storage backend not configured")
+	}
+	kind := models.DerivationType(strings.ToLower(strings.TrimSpace(in.Type)))
+	params := models.JSONMap{}
+	for k, v := range in.Params {
+		params[k] = v
+	}
+
+	switch kind {
+	case models.DerivationResize:
+		if in.Width <= 0 || in.Height <= 0 || in.Width > maxDeriveDimension || in.Height > maxDeriveDimension {
+			return nil, fmt.Errorf("%w: width and height must be between 1 and %d", ErrInvalidDerivation, maxDeriveDimension)
+		}
+		params["width"] = in.Width
+		params["height"] = in.Height
+	case models.DerivationOverlay, models.DerivationEdit:
+		if strings.TrimSpace(in.SourceURL) == "" {
+			return nil, fmt.Errorf("%w: url is required for %s", ErrInvalidDerivation, kind)
+		}
+		params["source_url"] = in.SourceURL
+	default:
+		return nil, fmt.Errorf("%w: type must be resize, overlay or edit", ErrInvalidDerivation)
+	}
+
+	parent, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, assetUUID)
+	}
+	task, err := s.taskRepo.GetByID(ctx, parent.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task failed: %w", err)
+	}
+
+	var data []byte
+	if kind == models.DerivationResize {
+		if s.reader == nil {
+			return nil, errors.New("storage reader not configured")
+		}
+		data, err = s.readAssetImage(ctx, parent)
+	} else {
+		data, _, err = storage.Fetch(ctx, in.SourceURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read image failed: %w", err)
+	}
+	if _, err := upload.CheckImage(data, upload.ConfiguredLimits()); err != nil {
+		return nil, err
+	}
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode image failed: %w", err)
+	}
+	if kind == models.DerivationResize {
+		img = imaging.Cover(img, in.Width, in.Height)
+	}
+	encoded, contentType, ext, err := imaging.Encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+
+	backend := s.processor.storageClient
+	newUUID := uuid.New().String()
+	key := backend.GenerateKey(fmt.Sprintf("%s_%s_%s%s", task.UUID, kind, newUUID[:8], ext))
+	publicURL, err := backend.Put(ctx, key, encoded, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("store creative failed: %w", err)
+	}
+
+	moderated := s.processor.moderateAsset(ctx, task, moderationTarget(publicURL, "", encoded, contentType))
+	size := len(encoded)
+	asset := models.CreativeAsset{
+		UUIDModel:        models.UUIDModel{UUID: newUUID},
+		TaskID:           parent.TaskID,
+		Title:            parent.Title,
+		ProductName:      parent.ProductName,
+		CTAText:          parent.CTAText,
+		SellingPoints:    parent.SellingPoints,
+		Format:           nearestFormat(bounds.Dx(), bounds.Dy()),
+		Width:            bounds.Dx(),
+		Height:           bounds.Dy(),
+		FileSize:         &size,
+		StorageType:      backend.Type(),
+		PublicURL:        publicURL,
+		StorageKey:       key,
+		OriginalPath:     in.SourceURL,
+		Style:            parent.Style,
+		GenerationPrompt: parent.GenerationPrompt,
+		ModelName:        parent.ModelName,
+		HasCTA:           parent.HasCTA,
+		Quarantined:      !moderated.safe,
+		ModerationReason: moderated.reason,
+		ParentAssetID:    &parent.ID,
+		DerivationType:   kind,
+		DerivationParams: params,
+	}
+	s.processor.analyzeImage(ctx, &asset, img, task.ProjectID)
+	s.processor.applyAutoTags(ctx, &asset)
+	if err := s.assetRepo.Create(ctx, &asset); err != nil {
+		_ = backend.Delete(ctx, key)
+		return nil, fmt.Errorf("save asset failed: %w", err)
+	}
+	s.processor.refreshTagUsage(ctx, &asset)
+	s.processor.saveModerationScore(ctx, asset.ID, moderated)
+	return &asset, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/settings"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// 时长预估参数
+const (
+	// latencySamples 参与预估的最近成功调用数
+	latencySamples = 50
+	// imageTraceModel 图片生成 trace 的 model_name（model_version 为具体模型）
+	imageTraceModel = "tongyi-image"
+)
+
+// PlanInput 生成预演：TaskID 非空时按 /creative/start 的规则在已有任务上合并 Start 选项，
+// 否则按 /creative/generate 的规则用 Create 构造新任务
+type PlanInput struct {
+	TaskID string
+	Create CreateTaskInput
+	Start  StartCreativeOptions
+}
+
+// PlanStep 一次图片生成调用
+type PlanStep struct {
+	VariantIndex int    `json:"variant_index"`
+	Prompt       string `json:"prompt"`
+	Style        string `json:"style,omitempty"`
+	Format       string `json:"format"`
+	Size         string `json:"size"`
+	NumImages    int    `json:"num_images"`
+}
+
+// PlanEstimate 成本与时长预估；Basis 为 recent_traces（按最近调用耗时中位数）或 default（无历史数据）
+type PlanEstimate struct {
+	Currency          string  `json:"currency,omitempty"`
+	Cost              float64 `json:"cost"`
+	DurationSeconds   float64 `json:"duration_seconds"`
+	LatencyP50Ms      int     `json:"latency_p50_ms"`
+	LatencyP90Ms      int     `json:"latency_p90_ms"`
+	LatencySamples    int     `json:"latency_samples"`
+	Basis             string  `json:"basis"`
+	WithProductImage  bool    `json:"with_product_image"`
+	EstimatedRequests int     `json:"estimated_requests"`
+}
+
+// PlanResult 生成计划与预估
+type PlanResult struct {
+	TaskID      string       `json:"task_id,omitempty"`
+	Model       string       `json:"model"`
+	NumVariants int          `json:"num_variants"`
+	TotalImages int          `json:"total_images"`
+	Steps       []PlanStep   `json:"steps"`
+	Estimate    PlanEstimate `json:"estimate"`
+}
+
+// SetCostEstimator 设置费用估算（nil 时预演不估算费用）
+func (s *CreativeService) SetCostEstimator(e ports.CostEstimator) {
+	s.pricer = e
+}
+
+// SetLatencySource 设置模型耗时来源（nil 时按默认耗时预估）
+func (s *CreativeService) SetLatencySource(l ports.LatencySource) {
+	s.latency = l
+}
+
+// PlanGeneration 按与实际生成相同的规则构造生成计划，不调用模型、不落库、不扣减配额
+func (s *CreativeService) PlanGeneration(ctx context.Context, in PlanInput) (*PlanResult, error) {
+	if s.processor == nil {
+		return nil, errors.New("task processor not configured")
+	}
+	task, err := s.planTask(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	model := llm.ImageModelFrom(llm.WithImageModel(ctx, task.ImageModel))
+	plan := s.processor.buildPlan(task)
+	result := &PlanResult{TaskID: in.TaskID, Model: model, NumVariants: task.NumVariants, Steps: make([]PlanStep, 0, len(plan))}
+	for _, req := range plan {
+		result.Steps = append(result.Steps, PlanStep{
+			VariantIndex: req.VariantIndex,
+			Prompt:       req.Prompt,
+			Style:        req.Style,
+			Format:       req.Format,
+			Size:         req.Size,
+			NumImages:    req.NumImages,
+		})
+		result.TotalImages += req.NumImages
+	}
+
+	est := PlanEstimate{Basis: "default", WithProductImage: task.ProductImageURL != "", EstimatedRequests: len(plan)}
+	if s.pricer != nil {
+		est.Currency = s.pricer.Currency()
+		est.Cost = s.pricer.Price(shared.ModelUsage{Kind: shared.CostKindImage, Model: model, Images: int64(result.TotalImages)})
+	}
+	est.LatencyP50Ms = int(settings.DefaultImageLatency.Milliseconds())
+	est.LatencyP90Ms = est.LatencyP50Ms
+	if s.latency != nil {
+		samples, err := s.latency.RecentLatencies(ctx, imageTraceModel, model, latencySamples)
+		if err != nil {
+			return nil, fmt.Errorf("load model latencies failed: %w", err)
+		}
+		if len(samples) > 0 {
+			est.Basis = "recent_traces"
+			est.LatencySamples = len(samples)
+			est.LatencyP50Ms = percentile(samples, 0.5)
+			est.LatencyP90Ms = percentile(samples, 0.9)
+		}
+	}
+	// 计划内的调用按顺序执行
+	est.DurationSeconds = math.Round(float64(est.LatencyP50Ms*len(plan))/100) / 10
+	result.Estimate = est
+	return result, nil
+}
+
+// planTask 构造预演用的任务（内存副本）
+func (s *CreativeService) planTask(ctx context.Context, in PlanInput) (*models.CreativeTask, error) {
+	if in.TaskID == "" {
+		if in.Create.Title == "" {
+			return nil, errors.New("title or task_id is required")
+		}
+		return s.buildTask(ctx, in.Create)
+	}
+	task, err := s.scopedTask(ctx, in.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	if task.Source == models.TaskSourceImport {
+		return nil, errors.New("imported tasks cannot be regenerated")
+	}
+	ps, err := s.projectSettings(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	planned := *task
+	// 与 StartCreativeGeneration 相同的合并规则，只作用于内存副本
+	for k, v := range generationUpdates(task, &in.Start, ps) {
+		switch k {
+		case "product_image_url":
+			planned.ProductImageURL = v.(string)
+		case "requested_styles":
+			planned.RequestedStyles = v.(models.StringArray)
+		case "num_variants":
+			planned.NumVariants = v.(int)
+		case "requested_formats":
+			planned.RequestedFormats = v.(models.StringArray)
+		case "variant_prompts":
+			planned.VariantPrompts = v.(models.StringArray)
+		case "variant_styles":
+			planned.VariantStyles = v.(models.StringArray)
+		case "prompt_template":
+			planned.PromptTemplate = v.(string)
+		case "image_model":
+			planned.ImageModel = v.(string)
+		}
+	}
+	if in.Start.ProductImageID != "" {
+		url, err := s.resolveProductImage(ctx, in.Start.ProductImageID)
+		if err != nil {
+			return nil, err
+		}
+		planned.ProductImageURL = url
+	}
+	return &planned, nil
+}
+
+// percentile 最近耗时的分位数（最近邻）
+func percentile(samples []int, p float64) int {
+	sorted := append([]int(nil), samples...)
+	sort.Ints(sorted)
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
@@ -8,6 +8,7 @@ import (
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/ports"
 	"ads-creative-gen-platform/internal/settings"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 // Poller 控制轮询策略。
@@ -42,16 +43,21 @@ func (p *Poller) sleep(d time.Duration) {
 // TaskProcessor 负责执行创意任务的完整工作流。
 type TaskProcessor struct {
 	llmClient     ports.TongyiClient
-	storageClient ports.StorageUploader
+	storageClient ports.StorageBackend
 	taskRepo      ports.TaskRepository
 	assetRepo     ports.AssetRepository
+	moderator     ports.Moderator
+	tagger        ports.AssetTagger
+	reader        ports.ObjectReader
+	brands        ports.BrandColorSource
+	costs         ports.CostRecorder
 	poller        Poller
 }
 
 // NewTaskProcessor 创建处理器，注入依赖与轮询策略。
 func NewTaskProcessor(
 	llmClient ports.TongyiClient,
-	storageClient ports.StorageUploader,
+	storageClient ports.StorageBackend,
 	taskRepo ports.TaskRepository,
 	assetRepo ports.AssetRepository,
 	poller Poller,
@@ -65,6 +71,31 @@ func NewTaskProcessor(
 	}
 }
 
+// SetModerator 设置内容安全审核器（nil 表示不审核）
+func (p *TaskProcessor) SetModerator(m ports.Moderator) {
+	p.moderator = m
+}
+
+// SetAssetTagger 设置自动打标器（nil 表示不打标）
+func (p *TaskProcessor) SetAssetTagger(t ports.AssetTagger) {
+	p.tagger = t
+}
+
+// SetObjectReader 设置对象读取器，用于落库前计算视觉特征（nil 表示不计算）
+func (p *TaskProcessor) SetObjectReader(r ports.ObjectReader) {
+	p.reader = r
+}
+
+// SetBrandColorSource 设置项目品牌色来源，用于计算品牌色符合度（nil 表示不计算）
+func (p *TaskProcessor) SetBrandColorSource(b ports.BrandColorSource) {
+	p.brands = b
+}
+
+// SetCostRecorder 设置成本记录器（nil 表示不核算）
+func (p *TaskProcessor) SetCostRecorder(r ports.CostRecorder) {
+	p.costs = r
+}
+
 // Process 执行任务，负责生成、轮询与落地。
 func (p *TaskProcessor) Process(ctx context.Context, taskID uint) error {
 	if ctx == nil {
@@ -84,6 +115,8 @@ func (p *TaskProcessor) Process(ctx context.Context, taskID uint) error {
 	if err != nil {
 		return fmt.Errorf("load task: %w", err)
 	}
+	// 后台处理不限数据范围，仅用于标记模型调用 trace 所属项目
+	ctx = shared.WithScope(ctx, &shared.ProjectScope{All: true, ProjectID: task.ProjectID})
 
 	plan := p.buildPlan(task)
 	if len(plan) == 0 {
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/infra/moderation"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// moderationOutcome 单个素材的审核结论
+type moderationOutcome struct {
+	checked bool
+	safe    bool
+	score   *float64
+	reason  string
+}
+
+// moderateAsset 审核生成图片及其附带文案；imageURL 须为审核服务可访问的地址（见 moderationTarget）。
+// 审核服务异常时素材按未通过处理（隔离），可通过复核接口解除
+func (p *TaskProcessor) moderateAsset(ctx context.Context, task *models.CreativeTask, imageURL string) moderationOutcome {
+	return p.moderateImage(ctx, task, imageURL).merge(p.moderateText(ctx, task))
+}
+
+// moderateImage 审核单张图片
+func (p *TaskProcessor) moderateImage(ctx context.Context, task *models.CreativeTask, imageURL string) moderationOutcome {
+	out := moderationOutcome{safe: true}
+	if p.moderator == nil {
+		return out
+	}
+	res, err := p.moderator.ModerateImage(ctx, imageURL)
+	if err != nil {
+		log.Printf("图片审核失败(task=%s): %v", task.UUID, err)
+		out.safe = false
+		out.reason = "image: moderation unavailable, pending manual review"
+	} else if !res.Skipped {
+		out.checked = true
+		score := res.Score
+		out.score = &score
+		if !res.Safe {
+			out.safe = false
+			out.reason = "image: " + reasonOf(res)
+		}
+	}
+	return out
+}
+
+// moderateText 审核任务文案；同一任务的多张图片共用文案，只需审核一次
+func (p *TaskProcessor) moderateText(ctx context.Context, task *models.CreativeTask) moderationOutcome {
+	out := moderationOutcome{safe: true}
+	if p.moderator == nil {
+		return out
+	}
+	res, err := p.moderator.ModerateText(ctx, copyText(task.Title, task.CTAText, task.SellingPoints))
+	if err != nil {
+		log.Printf("文案审核失败(task=%s): %v", task.UUID, err)
+		out.safe = false
+		out.reason = "text: moderation unavailable, pending manual review"
+	} else if !res.Skipped {
+		out.checked = true
+		if !res.Safe {
+			out.safe = false
+			out.reason = "text: " + reasonOf(res)
+		}
+	}
+	return out
+}
+
+// merge 合并图片与文案的审核结论：任一未通过即隔离，评分取图片结果
+func (o moderationOutcome) merge(other moderationOutcome) moderationOutcome {
+	out := moderationOutcome{checked: o.checked || other.checked, safe: o.safe && other.safe, score: o.score}
+	if out.score == nil {
+		out.score = other.score
+	}
+	var reasons []string
+	for _, r := range []string{o.reason, other.reason} {
+		if r != "" {
+			reasons = append(reasons, r)
+		}
+	}
+	out.reason = truncate(strings.Join(reasons, "; "), 512)
+	return out
+}
+
+// moderationTarget 返回交给审核服务的图片地址：存储返回的公共 URL 是相对路径（本地存储未配置
+// STORAGE_LOCAL_PUBLIC_URL）时，审核服务无法访问，改用源地址，没有源地址时以 data URI 内联图片内容
+func moderationTarget(publicURL, sourceURL string, data []byte, contentType string) string {
+	if isAbsoluteHTTPURL(publicURL) {
+		return publicURL
+	}
+	if isAbsoluteHTTPURL(sourceURL) {
+		return sourceURL
+	}
+	if len(data) > 0 {
+		return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+	return publicURL
+}
+
+func isAbsoluteHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// saveModerationScore 将审核结果写入 CreativeScore
+func (p *TaskProcessor) saveModerationScore(ctx context.Context, assetID uint, out moderationOutcome) {
+	if !out.checked {
+		return
+	}
+	now := time.Now()
+	score := models.CreativeScore{
+		CreativeID: assetID,
+		NSFWScore:  out.score,
+		IsSafe:     out.safe,
+		ScoredAt:   now,
+		UpdatedAt:  now,
+	}
+	if err := p.assetRepo.SaveScore(ctx, &score); err != nil {
+		log.Printf("保存审核评分失败(asset=%d): %v", assetID, err)
+	}
+}
+
+func copyText(title, cta string, sellingPoints []string) string {
+	parts := []string{title, cta}
+	parts = append(parts, sellingPoints...)
+	return strings.Join(parts, "\n")
+}
+
+func reasonOf(res moderation.Result) string {
+	if res.Reason != "" {
+		return res.Reason
+	}
+	if len(res.Labels) > 0 {
+		return strings.Join(res.Labels, ",")
+	}
+	return "flagged"
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
@@ -24,7 +24,7 @@ func (p *TaskProcessor) buildPlan(task *models.CreativeTask) []GenRequest {
 
 	if !p.hasVariantPlan(task) {
 		style := styleAt(task.RequestedStyles, 0)
-		prompt := generatePrompt(task.Title, task.SellingPoints, style)
+		prompt := taskPrompt(task, style)
 		format := formatAt(task.RequestedFormats, 0, settings.DefaultFormat)
 
 		return []GenRequest{{
@@ -46,7 +46,7 @@ func (p *TaskProcessor) buildPlan(task *models.CreativeTask) []GenRequest {
 
 		prompt := strings.TrimSpace(styleAt(task.VariantPrompts, idx))
 		if prompt == "" {
-			prompt = generatePrompt(task.Title, task.SellingPoints, style)
+			prompt = taskPrompt(task, style)
 		}
 
 		plan = append(plan, GenRequest{
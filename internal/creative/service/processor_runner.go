@@ -7,9 +7,11 @@ import (
 
 	"ads-creative-gen-platform/internal/infra/llm"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 func (p *TaskProcessor) run(ctx context.Context, task *models.CreativeTask, req GenRequest, onPending func(int, int)) (*llm.ImageGenResponse, string, error) {
+	ctx = llm.WithImageModel(ctx, task.ImageModel)
 	resp, traceID, err := p.submit(ctx, task, req)
 	if err != nil {
 		p.finishTrace(traceID, "failed", "", err.Error())
@@ -21,10 +23,32 @@ func (p *TaskProcessor) run(ctx context.Context, task *models.CreativeTask, req
 		p.finishTrace(traceID, "failed", "", err.Error())
 		return nil, traceID, err
 	}
+	p.recordImageCost(ctx, task, traceID, queryResp)
 
 	return queryResp, traceID, nil
 }
 
+// recordImageCost 按查询结果的计费图片数记录成本，未返回用量时按结果数计
+func (p *TaskProcessor) recordImageCost(ctx context.Context, task *models.CreativeTask, traceID string, resp *llm.ImageGenResponse) {
+	if p.costs == nil {
+		return
+	}
+	images := resp.Usage.ImageCount
+	if images == 0 {
+		images = len(resp.Output.Results)
+	}
+	taskID := task.ID
+	p.costs.RecordUsage(ctx, shared.ModelUsage{
+		Kind:      shared.CostKindImage,
+		Model:     llm.ImageModelFrom(ctx),
+		TaskID:    &taskID,
+		UserID:    task.UserID,
+		ProjectID: task.ProjectID,
+		TraceID:   traceID,
+		Images:    int64(images),
+	})
+}
+
 func (p *TaskProcessor) submit(ctx context.Context, task *models.CreativeTask, req GenRequest) (*llm.ImageGenResponse, string, error) {
 	numImages := req.NumImages
 	if numImages <= 0 {
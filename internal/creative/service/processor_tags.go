@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// applyAutoTags 落库前按规则写入 asset.Tags，失败只记录日志
+func (p *TaskProcessor) applyAutoTags(ctx context.Context, asset *models.CreativeAsset) {
+	if p == nil || p.tagger == nil {
+		return
+	}
+	tags, err := p.tagger.AutoTags(ctx, asset)
+	if err != nil {
+		log.Printf("自动打标失败(asset=%s): %v", asset.UUID, err)
+	}
+	asset.Tags = tags
+}
+
+// refreshTagUsage 素材落库后更新其标签的 usage_count
+func (p *TaskProcessor) refreshTagUsage(ctx context.Context, asset *models.CreativeAsset) {
+	if p == nil || p.tagger == nil || len(asset.Tags) == 0 {
+		return
+	}
+	if err := p.tagger.RefreshUsage(ctx, asset.Tags); err != nil {
+		log.Printf("更新标签使用次数失败(asset=%s): %v", asset.UUID, err)
+	}
+}
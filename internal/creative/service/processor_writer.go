@@ -29,9 +29,13 @@ func (p *TaskProcessor) persistAssets(
 
 	var first string
 	count := 0
+	parents := p.retryParents(ctx, task)
+	textModeration := p.moderateText(ctx, task)
 
 	for i, result := range queryResp.Output.Results {
-		publicURL, storageType, originalPath := p.handleUpload(ctx, task.UUID, req.VariantIndex*1000+i, result.URL)
+		publicURL, storageType, storageKey := p.handleUpload(ctx, task.UUID, req.VariantIndex*1000+i, result.URL)
+
+		moderated := p.moderateImage(ctx, task, moderationTarget(publicURL, result.URL, nil, "")).merge(textModeration)
 
 		idx := req.VariantIndex
 		asset := models.CreativeAsset{
@@ -46,22 +50,42 @@ func (p *TaskProcessor) persistAssets(
 			Height:           settings.DefaultImageHeight,
 			StorageType:      storageType,
 			PublicURL:        publicURL,
-			OriginalPath:     originalPath,
+			StorageKey:       storageKey,
+			OriginalPath:     result.URL,
 			Style:            req.Style,
 			VariantIndex:     &idx,
 			GenerationPrompt: req.Prompt,
 			ModelName:        settings.ModelName,
+			Quarantined:      !moderated.safe,
+			ModerationReason: moderated.reason,
+		}
+		if parent := parents.take(req.VariantIndex, req.Format); parent != nil {
+			asset.ParentAssetID = &parent.ID
+			asset.DerivationType = models.DerivationRegenerate
+			asset.DerivationParams = models.JSONMap{
+				"retry_from_task": task.RetryFrom,
+				"prompt":          req.Prompt,
+			}
 		}
 
+		p.applyAutoTags(ctx, &asset)
+		p.applyVisualFeatures(ctx, &asset, task.ProjectID)
 		if err := p.assetRepo.Create(ctx, &asset); err != nil {
 			log.Printf("保存资产失败: %v", err)
 			continue
 		}
+		p.refreshTagUsage(ctx, &asset)
+		p.saveModerationScore(ctx, asset.ID, moderated)
+		count++
 
+		// 被隔离的素材不作为任务首图展示
+		if asset.Quarantined {
+			log.Printf("素材 %s 未通过内容审核，已隔离: %s", asset.UUID, asset.ModerationReason)
+			continue
+		}
 		if first == "" {
 			first = publicURL
 		}
-		count++
 	}
 
 	if count == 0 {
@@ -71,25 +95,63 @@ func (p *TaskProcessor) persistAssets(
 	return GenResult{FirstPublicURL: first, Count: count}, nil
 }
 
-// handleUpload 处理存储上传并返回最终 URL/存储信息。
-func (p *TaskProcessor) handleUpload(ctx context.Context, taskUUID string, idx int, originalURL string) (string, models.StorageType, string) {
-	publicURL := originalURL
-	storageType := models.StorageLocal
-	originalPath := originalURL
-
+// handleUpload 将生成结果落地到存储后端，返回最终 URL、存储类型与对象 key。
+// 上传失败时保留服务商临时 URL，并标记为 StorageProvider。
+func (p *TaskProcessor) handleUpload(ctx context.Context, taskUUID string, idx int, sourceURL string) (string, models.StorageType, string) {
 	if p.storageClient == nil {
-		return publicURL, storageType, originalPath
+		return sourceURL, models.StorageProvider, ""
 	}
 
-	fileName := fmt.Sprintf("%s_%d", taskUUID, idx)
-	qiniuURL, err := p.storageClient.UploadFromURL(ctx, originalURL, fileName)
+	key := p.storageClient.GenerateKey(fmt.Sprintf("%s_%d", taskUUID, idx))
+	publicURL, err := p.storageClient.UploadFromURL(ctx, sourceURL, key)
 	if err != nil {
-		log.Printf("上传到存储失败: %v，使用原始URL", err)
-		return publicURL, storageType, originalPath
+		log.Printf("上传到存储(%s)失败: %v，使用原始URL", p.storageClient.Type(), err)
+		return sourceURL, models.StorageProvider, ""
+	}
+
+	return publicURL, p.storageClient.Type(), key
+}
+
+// retryParentSet 重试任务中按变体序号与尺寸匹配原任务素材，作为新素材的父素材
+type retryParentSet map[string][]models.CreativeAsset
+
+// retryParents 加载重试来源任务的素材；非重试任务或加载失败时返回空集合
+func (p *TaskProcessor) retryParents(ctx context.Context, task *models.CreativeTask) retryParentSet {
+	if task.RetryFrom == "" {
+		return nil
 	}
+	old, err := p.taskRepo.GetByUUID(ctx, task.RetryFrom)
+	if err != nil {
+		log.Printf("加载重试来源任务失败(task=%s): %v", task.RetryFrom, err)
+		return nil
+	}
+	assets, err := p.assetRepo.ListByTaskID(ctx, old.ID)
+	if err != nil {
+		log.Printf("加载重试来源素材失败(task=%s): %v", task.RetryFrom, err)
+		return nil
+	}
+	set := make(retryParentSet)
+	for _, a := range assets {
+		if a.VariantIndex == nil {
+			continue
+		}
+		k := retryParentKey(*a.VariantIndex, a.Format)
+		set[k] = append(set[k], a)
+	}
+	return set
+}
+
+// take 依次取出匹配的父素材，原任务素材用完后返回 nil
+func (s retryParentSet) take(variant int, format string) *models.CreativeAsset {
+	k := retryParentKey(variant, format)
+	list := s[k]
+	if len(list) == 0 {
+		return nil
+	}
+	s[k] = list[1:]
+	return &list[0]
+}
 
-	publicURL = qiniuURL
-	storageType = models.StorageQiniu
-	originalPath = p.storageClient.GenerateKey(fileName)
-	return publicURL, storageType, originalPath
+func retryParentKey(variant int, format string) string {
+	return fmt.Sprintf("%d|%s", variant, format)
 }
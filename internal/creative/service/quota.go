@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// SetQuotaGuard 设置配额检查（nil 表示不限额）
+func (s *CreativeService) SetQuotaGuard(q ports.QuotaGuard) {
+	s.quota = q
+}
+
+// SetBudgetGuard 设置项目预算检查（nil 表示不检查）
+func (s *CreativeService) SetBudgetGuard(b ports.BudgetGuard) {
+	s.budget = b
+}
+
+// SetCostRecorder 设置模型调用成本记录器
+func (s *CreativeService) SetCostRecorder(r ports.CostRecorder) {
+	if s.processor != nil {
+		s.processor.SetCostRecorder(r)
+	}
+}
+
+// consumeGeneration 按本任务 numVariants 张图片的预估成本检查项目预算，并按 1 个任务与 numVariants 张图片扣减配额
+func (s *CreativeService) consumeGeneration(ctx context.Context, projectID *uint, imageModel string, numVariants int) error {
+	if numVariants <= 0 {
+		numVariants = 1
+	}
+	if s.budget != nil && projectID != nil {
+		planned := shared.ModelUsage{
+			Kind:      shared.CostKindImage,
+			Model:     llm.ImageModelFrom(llm.WithImageModel(ctx, imageModel)),
+			ProjectID: projectID,
+			Images:    int64(numVariants),
+		}
+		if err := s.budget.CheckBudget(ctx, *projectID, planned); err != nil {
+			return err
+		}
+	}
+	if s.quota == nil {
+		return nil
+	}
+	return s.quota.Consume(ctx, projectID, shared.Usage{Tasks: 1, Images: int64(numVariants)})
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+var (
+	// ErrAssetNotFound 素材不存在
+	ErrAssetNotFound = errors.New("asset not found")
+	// ErrInvalidReview 审核参数不合法或素材状态不允许
+	ErrInvalidReview = errors.New("invalid review")
+)
+
+// maxBulkReview 单次批量审核的素材上限
+const maxBulkReview = 200
+
+// ReviewInput 审核决定
+type ReviewInput struct {
+	// Decision approve | reject
+	Decision   string
+	Comment    string
+	ReviewerID *uint
+}
+
+// BulkReviewResult 批量审核结果
+type BulkReviewResult struct {
+	Updated []string            `json:"updated"`
+	Failed  []BulkReviewFailure `json:"failed,omitempty"`
+}
+
+// BulkReviewFailure 批量审核中失败的素材
+type BulkReviewFailure struct {
+	AssetID string `json:"asset_id"`
+	Error   string `json:"error"`
+}
+
+// ReviewAsset 审核通过或驳回素材，并把决定记录到评论线程；被隔离的素材不能通过
+func (s *CreativeService) ReviewAsset(ctx context.Context, assetUUID string, in ReviewInput) (*models.CreativeAsset, error) {
+	status, action, err := reviewDecision(in.Decision)
+	if err != nil {
+		return nil, err
+	}
+	comment := strings.TrimSpace(in.Comment)
+	if status == models.ReviewRejected && comment == "" {
+		return nil, fmt.Errorf("%w: comment is required when rejecting", ErrInvalidReview)
+	}
+	asset, err := s.reviewableAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	// 已登录时以认证身份作为审核人
+	if id := shared.UserIDPtrFrom(ctx); id != nil {
+		in.ReviewerID = id
+	}
+	if status == models.ReviewApproved && asset.Quarantined {
+		return nil, fmt.Errorf("%w: asset is quarantined by content moderation", ErrInvalidReview)
+	}
+
+	now := time.Now()
+	if err := s.assetRepo.UpdateFields(ctx, asset.ID, map[string]interface{}{
+		"review_status": status,
+		"reviewed_by":   in.ReviewerID,
+		"reviewed_at":   &now,
+	}); err != nil {
+		return nil, fmt.Errorf("update review status failed: %w", err)
+	}
+	if err := s.assetRepo.AddReviewComment(ctx, &models.AssetReviewComment{
+		AssetID: asset.ID,
+		UserID:  in.ReviewerID,
+		Action:  action,
+		Body:    comment,
+	}); err != nil {
+		return nil, fmt.Errorf("save review comment failed: %w", err)
+	}
+
+	auditAction := audit.ActionAssetApprove
+	if status == models.ReviewRejected {
+		auditAction = audit.ActionAssetReject
+	}
+	s.auditAsset(ctx, auditAction, asset,
+		map[string]interface{}{"review_status": asset.ReviewStatus},
+		map[string]interface{}{"review_status": status, "comment": comment})
+
+	asset.ReviewStatus = status
+	asset.ReviewedBy = in.ReviewerID
+	asset.ReviewedAt = &now
+	return asset, nil
+}
+
+// BulkReview 批量审核；单个素材失败不影响其他素材
+func (s *CreativeService) BulkReview(ctx context.Context, assetUUIDs []string, in ReviewInput) (*BulkReviewResult, error) {
+	if len(assetUUIDs) == 0 {
+		return nil, fmt.Errorf("%w: asset_ids is required", ErrInvalidReview)
+	}
+	if len(assetUUIDs) > maxBulkReview {
+		return nil, fmt.Errorf("%w: at most %d assets per request", ErrInvalidReview, maxBulkReview)
+	}
+	if _, _, err := reviewDecision(in.Decision); err != nil {
+		return nil, err
+	}
+
+	result := &BulkReviewResult{Updated: []string{}}
+	seen := make(map[string]bool, len(assetUUIDs))
+	for _, id := range assetUUIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.ReviewAsset(ctx, id, in); err != nil {
+			result.Failed = append(result.Failed, BulkReviewFailure{AssetID: id, Error: err.Error()})
+			continue
+		}
+		result.Updated = append(result.Updated, id)
+	}
+	return result, nil
+}
+
+// AddReviewComment 在素材审核线程中添加评论
+func (s *CreativeService) AddReviewComment(ctx context.Context, assetUUID, body string, userID *uint) (*models.AssetReviewComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment body is required", ErrInvalidReview)
+	}
+	asset, err := s.reviewableAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	if id := shared.UserIDPtrFrom(ctx); id != nil {
+		userID = id
+	}
+	comment := &models.AssetReviewComment{AssetID: asset.ID, UserID: userID, Action: models.ReviewActionComment, Body: body}
+	if err := s.assetRepo.AddReviewComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("save comment failed: %w", err)
+	}
+	return comment, nil
+}
+
+// ListReviewComments 素材的审核评论线程（含审批记录）
+func (s *CreativeService) ListReviewComments(ctx context.Context, assetUUID string) ([]models.AssetReviewComment, error) {
+	asset, err := s.reviewableAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	return s.assetRepo.ListReviewComments(ctx, asset.ID)
+}
+
+func (s *CreativeService) reviewableAsset(ctx context.Context, assetUUID string) (*models.CreativeAsset, error) {
+	if assetUUID == "" {
+		return nil, fmt.Errorf("%w: asset_id is required", ErrInvalidReview)
+	}
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAssetNotFound, assetUUID)
+	}
+	return asset, nil
+}
+
+// reviewDecision 审核决定对应的状态与评论动作
+func reviewDecision(decision string) (models.ReviewStatus, string, error) {
+	switch strings.ToLower(strings.TrimSpace(decision)) {
+	case "approve", "approved":
+		return models.ReviewApproved, models.ReviewActionApprove, nil
+	case "reject", "rejected":
+		return models.ReviewRejected, models.ReviewActionReject, nil
+	default:
+		return "", "", fmt.Errorf("%w: decision must be approve or reject", ErrInvalidReview)
+	}
+}
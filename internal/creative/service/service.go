@@ -2,21 +2,30 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/audit"
 	"ads-creative-gen-platform/internal/creative/repository"
 	"ads-creative-gen-platform/internal/infra/cache"
 	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/infra/moderation"
 	"ads-creative-gen-platform/internal/infra/storage"
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/project"
 	"ads-creative-gen-platform/internal/settings"
 	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/internal/storagegc"
+	"ads-creative-gen-platform/internal/tag"
 	"ads-creative-gen-platform/internal/tracing"
+	"ads-creative-gen-platform/internal/upload"
 	"ads-creative-gen-platform/pkg/database"
 
 	"github.com/google/uuid"
@@ -29,12 +38,21 @@ type CreativeService struct {
 	processor   *TaskProcessor
 	enqueueFunc func(taskID uint) error
 	traceSvc    *tracing.TraceService
+	cleaner     ports.ObjectCleaner
+	images      ports.ProductImageResolver
+	reader      ports.ObjectReader
+	tagger      ports.AssetTagger
+	projects    ports.ProjectSettingsSource
+	audit       ports.AuditRecorder
+	quota       ports.QuotaGuard
+	budget      ports.BudgetGuard
+	pricer      ports.CostEstimator
+	latency     ports.LatencySource
 }
 
-// NewCreativeService 创建服务
-func NewCreativeService() *CreativeService {
+// NewCreativeService 创建服务，storageRegistry 由调用方创建并在各模块间共享
+func NewCreativeService(storageRegistry *storage.Registry) *CreativeService {
 	llmClient := llm.NewTongyiClient()
-	storageClient := storage.NewQiniuClient()
 	baseTaskRepo := repository.NewTaskRepository(database.DB)
 	baseAssetRepo := repository.NewAssetRepository(database.DB)
 
@@ -53,11 +71,27 @@ func NewCreativeService() *CreativeService {
 	taskRepo := repository.NewCachedTaskRepository(baseTaskRepo, dataCache, ttl)
 	assetRepo := repository.NewCachedAssetRepository(baseAssetRepo, dataCache, ttl)
 
+	processor := NewTaskProcessor(llmClient, storageRegistry.Primary(), taskRepo, assetRepo, poller)
+	processor.SetModerator(moderation.NewConfiguredModerator(config.ModerationConfig))
+	tagger := tag.NewService()
+	processor.SetAssetTagger(tagger)
+	processor.SetObjectReader(storageRegistry)
+	projectSvc := project.NewService()
+	processor.SetBrandColorSource(projectSvc)
+
+	traceSvc := tracing.NewTraceService()
+
 	return &CreativeService{
 		taskRepo:  taskRepo,
 		assetRepo: assetRepo,
-		processor: NewTaskProcessor(llmClient, storageClient, taskRepo, assetRepo, poller),
-		traceSvc:  tracing.NewTraceService(),
+		processor: processor,
+		traceSvc:  traceSvc,
+		latency:   traceSvc,
+		cleaner:   storagegc.New(storagegc.Config{}, storagegc.NewGormStore(database.DB), storageRegistry),
+		images:    upload.NewService(storageRegistry),
+		reader:    storageRegistry,
+		tagger:    tagger,
+		projects:  projectSvc,
 	}
 }
 
@@ -81,6 +115,44 @@ func NewCreativeServiceWithDeps(
 	}
 }
 
+// SetObjectCleaner 设置存储对象清理器（物理删除任务时使用）
+func (s *CreativeService) SetObjectCleaner(cleaner ports.ObjectCleaner) {
+	s.cleaner = cleaner
+}
+
+// SetProductImageResolver 设置上传商品图 ID 解析器
+func (s *CreativeService) SetProductImageResolver(r ports.ProductImageResolver) {
+	s.images = r
+}
+
+// SetObjectReader 设置存储对象读取器（导出、视觉特征计算使用）
+func (s *CreativeService) SetObjectReader(r ports.ObjectReader) {
+	s.reader = r
+	if s.processor != nil {
+		s.processor.SetObjectReader(r)
+	}
+}
+
+// SetBrandColorSource 设置项目品牌色来源
+func (s *CreativeService) SetBrandColorSource(b ports.BrandColorSource) {
+	if s.processor != nil {
+		s.processor.SetBrandColorSource(b)
+	}
+}
+
+// SetProjectSettingsSource 设置项目默认设置来源（nil 表示只使用全局默认值）
+func (s *CreativeService) SetProjectSettingsSource(p ports.ProjectSettingsSource) {
+	s.projects = p
+}
+
+// SetAssetTagger 设置标签服务（手动关联与自动打标）
+func (s *CreativeService) SetAssetTagger(t ports.AssetTagger) {
+	s.tagger = t
+	if s.processor != nil {
+		s.processor.SetAssetTagger(t)
+	}
+}
+
 // SetEnqueuer 设置任务入队方法（便于外部注入 Runner）
 func (s *CreativeService) SetEnqueuer(enqueue func(taskID uint) error) {
 	s.enqueueFunc = enqueue
@@ -92,6 +164,7 @@ type CreateTaskInput struct {
 	Title           string
 	SellingPoints   []string
 	ProductImageURL string
+	ProductImageID  string // 上传接口返回的 asset_id，优先于 ProductImageURL
 	Formats         []string
 	Style           string
 	CTAText         string
@@ -100,22 +173,57 @@ type CreateTaskInput struct {
 	VariantStyles   []string
 }
 
-// CreateTask 创建创意生成任务
-func (s *CreativeService) CreateTask(input CreateTaskInput) (*models.CreativeTask, error) {
-	// 默认值
+// CreateTask 创建创意生成任务，归属当前请求的项目
+func (s *CreativeService) CreateTask(ctx context.Context, input CreateTaskInput) (*models.CreativeTask, error) {
+	task, err := s.buildTask(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.consumeGeneration(ctx, task.ProjectID, task.ImageModel, task.NumVariants); err != nil {
+		return nil, err
+	}
+
+	// 保存到数据库
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	s.enqueueOrProcess(task.ID)
+
+	return task, nil
+}
+
+// buildTask 按请求构造（未落库的）生成任务
+func (s *CreativeService) buildTask(ctx context.Context, input CreateTaskInput) (*models.CreativeTask, error) {
+	// 默认值：请求值优先，其次项目默认设置，最后全局默认值
+	projectID := shared.ProjectIDFrom(ctx)
+	ps, err := s.projectSettings(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	input.Formats = ps.Formats(input.Formats)
 	if len(input.Formats) == 0 {
 		input.Formats = []string{settings.DefaultFormat}
 	}
-	if input.NumVariants <= 0 {
-		input.NumVariants = settings.DefaultNumVariants
+	input.NumVariants = ps.Variants(input.NumVariants, settings.DefaultNumVariants)
+	if len(input.VariantStyles) == 0 {
+		input.Style, input.VariantStyles = ps.Styles(input.Style)
+	}
+	if input.ProductImageID != "" {
+		url, err := s.resolveProductImage(ctx, input.ProductImageID)
+		if err != nil {
+			return nil, err
+		}
+		input.ProductImageURL = url
 	}
 
-	// 创建任务
-	task := models.CreativeTask{
+	task := &models.CreativeTask{
 		UUIDModel: models.UUIDModel{
 			UUID: uuid.New().String(),
 		},
 		UserID:           input.UserID,
+		ProjectID:        projectID,
+		Source:           models.TaskSourceGenerate,
 		Title:            input.Title,
 		SellingPoints:    models.StringArray(input.SellingPoints),
 		ProductImageURL:  input.ProductImageURL,
@@ -128,20 +236,17 @@ func (s *CreativeService) CreateTask(input CreateTaskInput) (*models.CreativeTas
 		Status:           models.TaskPending,
 		Progress:         0,
 	}
-
-	// 保存到数据库
-	if err := s.taskRepo.Create(context.Background(), &task); err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+	if ps != nil {
+		task.PromptTemplate = ps.PromptTemplate
+		task.ImageModel = ps.ImageProvider
 	}
-
-	s.enqueueOrProcess(task.ID)
-
-	return &task, nil
+	return task, nil
 }
 
 // StartCreativeOptions 启动创意生成选项
 type StartCreativeOptions struct {
 	ProductImageURL string
+	ProductImageID  string
 	Style           string
 	NumVariants     int
 	Formats         []string
@@ -150,16 +255,26 @@ type StartCreativeOptions struct {
 }
 
 // StartCreativeGeneration 根据已有任务启动生成
-func (s *CreativeService) StartCreativeGeneration(taskUUID string, opts *StartCreativeOptions) error {
+func (s *CreativeService) StartCreativeGeneration(ctx context.Context, taskUUID string, opts *StartCreativeOptions) error {
 	if taskUUID == "" {
 		return errors.New("task_id is required")
 	}
-	ctx := context.Background()
 
-	task, err := s.taskRepo.GetByUUID(ctx, taskUUID)
+	task, err := s.scopedTask(ctx, taskUUID)
 	if err != nil {
 		return fmt.Errorf("task not found: %w", err)
 	}
+	if task.Source == models.TaskSourceImport {
+		return errors.New("imported tasks cannot be regenerated")
+	}
+
+	if opts != nil && opts.ProductImageID != "" {
+		url, err := s.resolveProductImage(ctx, opts.ProductImageID)
+		if err != nil {
+			return err
+		}
+		opts.ProductImageURL = url
+	}
 
 	now := time.Now()
 	updates := map[string]interface{}{
@@ -176,6 +291,58 @@ func (s *CreativeService) StartCreativeGeneration(taskUUID string, opts *StartCr
 		"prompt_used":           "",
 		"copywriting_generated": task.CopywritingGenerated,
 	}
+	ps, err := s.projectSettings(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	for k, v := range generationUpdates(task, opts, ps) {
+		updates[k] = v
+	}
+
+	numVariants := task.NumVariants
+	if n, ok := updates["num_variants"].(int); ok {
+		numVariants = n
+	}
+	imageModel := task.ImageModel
+	if m, ok := updates["image_model"].(string); ok {
+		imageModel = m
+	}
+	if err := s.consumeGeneration(ctx, task.ProjectID, imageModel, numVariants); err != nil {
+		return err
+	}
+
+	if s.traceSvc != nil {
+		_, _ = s.traceSvc.FailRunningBySource(task.UUID, "restart creative task")
+	}
+
+	if err := s.taskRepo.UpdateFields(ctx, task.ID, updates); err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	before := audit.TaskState(task)
+	s.auditTask(ctx, audit.ActionTaskStart, task, before, audit.Apply(before, updates))
+
+	if err := s.enqueueOrProcess(task.ID); err != nil {
+		return fmt.Errorf("enqueue task failed: %w", err)
+	}
+
+	return nil
+}
+
+// projectSettings 任务所属项目的默认设置；个人空间或未配置来源时返回 nil
+func (s *CreativeService) projectSettings(ctx context.Context, projectID *uint) (*models.ProjectSettings, error) {
+	if s.projects == nil || projectID == nil {
+		return nil, nil
+	}
+	ps, err := s.projects.ProjectSettings(ctx, *projectID)
+	if err != nil {
+		return nil, fmt.Errorf("load project settings failed: %w", err)
+	}
+	return ps, nil
+}
+
+// generationUpdates 启动生成时请求选项覆盖的生成参数，并按项目默认设置补全
+func generationUpdates(task *models.CreativeTask, opts *StartCreativeOptions, ps *models.ProjectSettings) map[string]interface{} {
+	updates := map[string]interface{}{}
 	if opts != nil {
 		if opts.ProductImageURL != "" {
 			updates["product_image_url"] = opts.ProductImageURL
@@ -196,20 +363,41 @@ func (s *CreativeService) StartCreativeGeneration(taskUUID string, opts *StartCr
 			updates["variant_styles"] = models.StringArray(opts.VariantStyles)
 		}
 	}
+	applyProjectDefaults(task, ps, updates)
+	return updates
+}
 
-	if s.traceSvc != nil {
-		_, _ = s.traceSvc.FailRunningBySource(task.UUID, "restart creative task")
+// applyProjectDefaults 请求与任务均未指定的格式、风格按项目默认设置补全，
+// 并刷新提示词模板与图片模型快照，使重新生成使用项目当前设置
+func applyProjectDefaults(task *models.CreativeTask, ps *models.ProjectSettings, updates map[string]interface{}) {
+	if ps == nil {
+		return
 	}
-
-	if err := s.taskRepo.UpdateFields(ctx, task.ID, updates); err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
+	if _, ok := updates["requested_formats"]; !ok && formatAt(task.RequestedFormats, 0, "") == "" {
+		if formats := ps.Formats(nil); len(formats) > 0 {
+			updates["requested_formats"] = models.StringArray(formats)
+		}
 	}
-
-	if err := s.enqueueOrProcess(task.ID); err != nil {
-		return fmt.Errorf("enqueue task failed: %w", err)
+	_, styled := updates["requested_styles"]
+	_, varied := updates["variant_styles"]
+	if !styled && !varied && styleAt(task.RequestedStyles, 0) == "" && len(task.VariantStyles) == 0 {
+		if style, variants := ps.Styles(""); style != "" {
+			updates["requested_styles"] = models.StringArray{style}
+			if len(variants) > 0 {
+				updates["variant_styles"] = models.StringArray(variants)
+			}
+		}
 	}
+	updates["prompt_template"] = ps.PromptTemplate
+	updates["image_model"] = ps.ImageProvider
+}
 
-	return nil
+// resolveProductImage 将上传 ID 解析为商品图 URL
+func (s *CreativeService) resolveProductImage(ctx context.Context, id string) (string, error) {
+	if s.images == nil {
+		return "", errors.New("product image upload is not configured")
+	}
+	return s.images.ResolveProductImageURL(ctx, id)
 }
 
 // cloneTask 基于旧任务创建新任务，保留配置并标记来源
@@ -249,22 +437,53 @@ func (s *CreativeService) cloneTask(old *models.CreativeTask) *models.CreativeTa
 }
 
 // GetTask 查询任务详情（含资产）
-func (s *CreativeService) GetTask(taskUUID string) (*models.CreativeTask, error) {
+func (s *CreativeService) GetTask(ctx context.Context, taskUUID string) (*models.CreativeTask, error) {
 	if taskUUID == "" {
 		return nil, errors.New("task_id is required")
 	}
-	task, err := s.taskRepo.GetByUUIDWithAssets(context.Background(), taskUUID)
+	task, err := s.taskRepo.GetByUUIDWithAssets(ctx, taskUUID)
 	if err != nil {
 		return nil, fmt.Errorf("task not found: %w", err)
 	}
+	if !shared.ScopeFrom(ctx).Allows(task.ProjectID, task.UserID) {
+		return nil, fmt.Errorf("task not found: %w", ErrOutOfScope)
+	}
 	return task, nil
 }
 
-// DeleteTask 删除任务及其资产（软删除）
-func (s *CreativeService) DeleteTask(taskUUID string) error {
-	ctx := context.Background()
-
+// scopedTask 按 UUID 查询任务，不在当前请求的项目范围内时视为不存在
+func (s *CreativeService) scopedTask(ctx context.Context, taskUUID string) (*models.CreativeTask, error) {
 	task, err := s.taskRepo.GetByUUID(ctx, taskUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !shared.ScopeFrom(ctx).Allows(task.ProjectID, task.UserID) {
+		return nil, ErrOutOfScope
+	}
+	return task, nil
+}
+
+// scopedAsset 按 UUID 查询素材，按所属任务校验项目范围
+func (s *CreativeService) scopedAsset(ctx context.Context, assetUUID string) (*models.CreativeAsset, error) {
+	asset, err := s.assetRepo.GetByUUID(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	if scope := shared.ScopeFrom(ctx); scope != nil && !scope.All {
+		task, err := s.taskRepo.GetByID(ctx, asset.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		if !scope.Allows(task.ProjectID, task.UserID) {
+			return nil, ErrOutOfScope
+		}
+	}
+	return asset, nil
+}
+
+// DeleteTask 删除任务及其资产（软删除）
+func (s *CreativeService) DeleteTask(ctx context.Context, taskUUID string) error {
+	task, err := s.scopedTask(ctx, taskUUID)
 	if err != nil {
 		return fmt.Errorf("task not found: %w", err)
 	}
@@ -276,7 +495,70 @@ func (s *CreativeService) DeleteTask(taskUUID string) error {
 	if err := s.taskRepo.Delete(ctx, task); err != nil {
 		return fmt.Errorf("delete task failed: %w", err)
 	}
+	s.auditTask(ctx, audit.ActionTaskDelete, task, audit.TaskState(task), nil)
+
+	return nil
+}
+
+// HardDeleteTask 物理删除任务、素材及存储对象，已软删除的任务同样可以清除；
+// 被实验变体引用的素材只做软删除并保留对象，此时任务同样只软删除
+func (s *CreativeService) HardDeleteTask(ctx context.Context, taskUUID string) error {
+	task, err := s.taskRepo.GetByUUIDWithDeleted(ctx, taskUUID)
+	if err != nil {
+		return fmt.Errorf("task not found: %w", err)
+	}
+	scope := shared.ScopeFrom(ctx)
+	if !scope.Allows(task.ProjectID, task.UserID) {
+		return fmt.Errorf("task not found: %w", ErrOutOfScope)
+	}
+	// 物理删除不可恢复，项目内仅管理员可执行
+	if !scope.CanWrite(shared.ProjectRoleAdmin) {
+		return ErrForbidden
+	}
+
+	assets, err := s.assetRepo.ListByTaskIDWithDeleted(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("list assets failed: %w", err)
+	}
+	ids := make([]uint, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.ID)
+	}
+	protected, err := s.assetRepo.ReferencedByExperiments(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("check experiment references failed: %w", err)
+	}
+
+	var purge []models.CreativeAsset
+	var purgeIDs []uint
+	for _, a := range assets {
+		if !protected[a.ID] {
+			purge = append(purge, a)
+			purgeIDs = append(purgeIDs, a.ID)
+		}
+	}
+	if err := s.assetRepo.PurgeByIDs(ctx, purgeIDs); err != nil {
+		return fmt.Errorf("purge assets failed: %w", err)
+	}
+
+	if len(protected) > 0 {
+		if err := s.assetRepo.DeleteByTaskID(ctx, task.ID); err != nil {
+			return fmt.Errorf("delete assets failed: %w", err)
+		}
+		if err := s.taskRepo.Delete(ctx, task); err != nil {
+			return fmt.Errorf("delete task failed: %w", err)
+		}
+	} else if err := s.taskRepo.Purge(ctx, task); err != nil {
+		return fmt.Errorf("purge task failed: %w", err)
+	}
+	s.auditTask(ctx, audit.ActionTaskPurge, task, audit.TaskState(task), nil)
 
+	// 对象删除失败不回滚，残留对象由周期 GC 清理
+	if s.cleaner != nil {
+		if err := s.cleaner.DeleteAssetObjects(ctx, purge); err != nil {
+			log.Printf("删除任务 %s 的存储对象失败: %v", taskUUID, err)
+		}
+	}
 	return nil
 }
 
@@ -302,6 +584,7 @@ type TaskDTO struct {
 	ErrorMessage  string             `json:"error_message,omitempty"`
 	FirstImage    string             `json:"first_image,omitempty"`
 	RetryFrom     string             `json:"retry_from,omitempty"`
+	Source        string             `json:"source,omitempty"`
 	RetryTo       string             `json:"retry_to,omitempty"`
 }
 
@@ -311,8 +594,48 @@ type ListAssetsQuery struct {
 	PageSize int    `json:"page_size"`
 	Format   string `json:"format"`
 	TaskID   string `json:"task_id"`
+	// Quarantined 为 true 时返回待复核的隔离素材
+	Quarantined bool `json:"quarantined"`
+	// Tags 标签名，需同时具备
+	Tags []string `json:"tags,omitempty"`
+
+	Query       string     `json:"q,omitempty"`
+	Style       string     `json:"style,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	MinScore    *float64   `json:"min_score,omitempty"`
+	MaxScore    *float64   `json:"max_score,omitempty"`
+	// MinConformance/MaxConformance 品牌色符合度（0~1）
+	MinConformance *float64 `json:"min_conformance,omitempty"`
+	MaxConformance *float64 `json:"max_conformance,omitempty"`
+	ReviewStatus   string   `json:"review_status,omitempty"`
+	StorageType    string   `json:"storage_type,omitempty"`
+	ModelName      string   `json:"model_name,omitempty"`
+	InExperiment   *bool    `json:"in_experiment,omitempty"`
+	// Sort newest | score | ctr | conformance
+	Sort string `json:"sort,omitempty"`
+	// Cursor 上一页返回的 next_cursor，非空时忽略 Page
+	Cursor string `json:"cursor,omitempty"`
 }
 
+// AssetListResult 素材列表结果
+type AssetListResult struct {
+	Assets     []CreativeAssetDTO
+	Total      int64
+	NextCursor string
+}
+
+var (
+	// ErrOutOfScope 数据不在当前请求的项目范围内，对外按不存在处理
+	ErrOutOfScope = errors.New("not in current project")
+	// ErrForbidden 当前项目角色不足以执行该操作
+	ErrForbidden = errors.New("project role admin required")
+	// ErrInvalidCursor 游标无效或与排序方式不匹配
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort 不支持的排序方式
+	ErrInvalidSort = errors.New("unsupported sort")
+)
+
 // CreativeAssetDTO 素材数据传输对象
 type CreativeAssetDTO struct {
 	ID               string             `json:"id"`
@@ -327,60 +650,267 @@ type CreativeAssetDTO struct {
 	SellingPoints    models.StringArray `json:"selling_points,omitempty"`
 	Style            string             `json:"style,omitempty"`
 	GenerationPrompt string             `json:"generation_prompt,omitempty"`
+	Quarantined      bool               `json:"quarantined,omitempty"`
+	ModerationReason string             `json:"moderation_reason,omitempty"`
+	Tags             []TagDTO           `json:"tags,omitempty"`
+	Palette          models.Palette     `json:"palette,omitempty"`
+	BrandConformance *float64           `json:"brand_conformance,omitempty"`
+	ReviewStatus     string             `json:"review_status,omitempty"`
+	ReviewedBy       *uint              `json:"reviewed_by,omitempty"`
+	ReviewedAt       string             `json:"reviewed_at,omitempty"`
+	QualityScore     *float64           `json:"quality_score,omitempty"`
+	CTRPrediction    *float64           `json:"ctr_prediction,omitempty"`
+	CreatedAt        string             `json:"created_at,omitempty"`
+}
+
+// TagDTO 素材上的标签
+type TagDTO struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Color    string `json:"color,omitempty"`
+}
+
+func toTagDTOs(tags []models.Tag) []TagDTO {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make([]TagDTO, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, TagDTO{ID: t.ID, Name: t.Name, Category: t.Category, Color: t.Color})
+	}
+	return out
 }
 
 // ListAllAssets 获取素材列表
-func (s *CreativeService) ListAllAssets(query ListAssetsQuery) ([]CreativeAssetDTO, int64, error) {
+func (s *CreativeService) ListAllAssets(ctx context.Context, query ListAssetsQuery) ([]CreativeAssetDTO, int64, error) {
+	result, err := s.SearchAssets(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Assets, result.Total, nil
+}
+
+// SearchAssets 检索当前项目范围内的素材：全文 + 筛选 + 排序，返回下一页游标
+func (s *CreativeService) SearchAssets(ctx context.Context, query ListAssetsQuery) (*AssetListResult, error) {
 	if query.Page <= 0 {
 		query.Page = 1
 	}
 	if query.PageSize <= 0 || query.PageSize > 100 {
 		query.PageSize = 20
 	}
+	switch query.Sort {
+	case "":
+		query.Sort = shared.AssetSortNewest
+	case shared.AssetSortNewest, shared.AssetSortScore, shared.AssetSortCTR, shared.AssetSortConformance:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSort, query.Sort)
+	}
 
 	domainQuery := shared.ListAssetsQuery{
-		Page:     query.Page,
-		PageSize: query.PageSize,
-		Format:   query.Format,
-		TaskID:   query.TaskID,
+		Page:           query.Page,
+		PageSize:       query.PageSize,
+		Format:         query.Format,
+		TaskID:         query.TaskID,
+		Quarantined:    query.Quarantined,
+		Tags:           query.Tags,
+		Query:          query.Query,
+		Style:          query.Style,
+		CreatedFrom:    query.CreatedFrom,
+		CreatedTo:      query.CreatedTo,
+		MinScore:       query.MinScore,
+		MaxScore:       query.MaxScore,
+		MinConformance: query.MinConformance,
+		MaxConformance: query.MaxConformance,
+		ReviewStatus:   query.ReviewStatus,
+		StorageType:    query.StorageType,
+		ModelName:      query.ModelName,
+		InExperiment:   query.InExperiment,
+		Sort:           query.Sort,
+		Scope:          shared.ScopeFrom(ctx),
+	}
+	if query.Cursor != "" {
+		cursor, err := decodeAssetCursor(query.Cursor)
+		if err != nil || cursor.Sort != query.Sort {
+			return nil, ErrInvalidCursor
+		}
+		domainQuery.Cursor = cursor
 	}
 
-	assets, total, err := s.assetRepo.List(context.Background(), domainQuery)
+	assets, total, err := s.assetRepo.List(ctx, domainQuery)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	result := make([]CreativeAssetDTO, 0, len(assets))
+	result := &AssetListResult{Assets: make([]CreativeAssetDTO, 0, len(assets)), Total: total}
 	for _, asset := range assets {
-		result = append(result, CreativeAssetDTO{
-			ID:               asset.UUID,
-			NumericID:        asset.ID,
-			Format:           asset.Format,
-			ImageURL:         asset.PublicURL,
-			Width:            asset.Width,
-			Height:           asset.Height,
-			Title:            asset.Title,
-			ProductName:      asset.ProductName,
-			CTAText:          asset.CTAText,
-			SellingPoints:    asset.SellingPoints,
-			Style:            asset.Style,
-			GenerationPrompt: asset.GenerationPrompt,
-		})
-	}
-
-	return result, total, nil
-}
-
-// ListAllTasks 获取所有任务
-func (s *CreativeService) ListAllTasks(query ListTasksQuery) ([]TaskDTO, int64, error) {
+		result.Assets = append(result.Assets, toAssetDTO(asset))
+	}
+
+	// 满页才给出下一页游标
+	if n := len(assets); n > 0 && n == query.PageSize {
+		last := assets[n-1]
+		next := shared.AssetCursor{Sort: query.Sort, CreatedAt: last.CreatedAt, ID: last.ID}
+		if last.SortValue != nil {
+			next.Value = *last.SortValue
+		}
+		result.NextCursor = encodeAssetCursor(next)
+	}
+
+	return result, nil
+}
+
+// toAssetDTO 素材转换为列表项
+func toAssetDTO(asset models.CreativeAsset) CreativeAssetDTO {
+	dto := CreativeAssetDTO{
+		ID:               asset.UUID,
+		NumericID:        asset.ID,
+		Format:           asset.Format,
+		ImageURL:         asset.PublicURL,
+		Width:            asset.Width,
+		Height:           asset.Height,
+		Title:            asset.Title,
+		ProductName:      asset.ProductName,
+		CTAText:          asset.CTAText,
+		SellingPoints:    asset.SellingPoints,
+		Style:            asset.Style,
+		GenerationPrompt: asset.GenerationPrompt,
+		Quarantined:      asset.Quarantined,
+		ModerationReason: asset.ModerationReason,
+		Tags:             toTagDTOs(asset.Tags),
+		Palette:          asset.Palette,
+		BrandConformance: asset.BrandConformance,
+		ReviewStatus:     string(asset.ReviewStatus),
+		ReviewedBy:       asset.ReviewedBy,
+		CreatedAt:        asset.CreatedAt.Format(time.RFC3339),
+	}
+	if asset.ReviewedAt != nil {
+		dto.ReviewedAt = asset.ReviewedAt.Format(time.RFC3339)
+	}
+	if asset.Score != nil {
+		dto.QualityScore = asset.Score.QualityOverall
+		dto.CTRPrediction = asset.Score.CTRPrediction
+	}
+	return dto
+}
+
+// encodeAssetCursor 游标编码为 URL 安全的 base64 JSON
+func encodeAssetCursor(c shared.AssetCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeAssetCursor(s string) (*shared.AssetCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c shared.AssetCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	if c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ReviewQuarantinedAsset 人工复核被隔离的素材：safe=true 解除隔离，否则删除素材
+func (s *CreativeService) ReviewQuarantinedAsset(ctx context.Context, assetUUID string, safe bool) error {
+	if assetUUID == "" {
+		return errors.New("asset_id is required")
+	}
+
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return fmt.Errorf("asset not found: %w", err)
+	}
+	if !asset.Quarantined {
+		return errors.New("asset is not quarantined")
+	}
+
+	before := map[string]interface{}{"quarantined": true, "moderation_reason": asset.ModerationReason}
+	if !safe {
+		if err := s.assetRepo.Delete(ctx, asset); err != nil {
+			return fmt.Errorf("delete asset failed: %w", err)
+		}
+		s.auditAsset(ctx, audit.ActionAssetQuarantineDelete, asset, before, nil)
+		return nil
+	}
+
+	if err := s.assetRepo.UpdateFields(ctx, asset.ID, map[string]interface{}{
+		"quarantined":       false,
+		"moderation_reason": "",
+	}); err != nil {
+		return fmt.Errorf("release asset failed: %w", err)
+	}
+	s.auditAsset(ctx, audit.ActionAssetRelease, asset, before, map[string]interface{}{"quarantined": false, "moderation_reason": ""})
+	// CreativeScore 保留审核器的原始结论，人工结论体现在 quarantined 字段上
+	return nil
+}
+
+// AttachAssetTags 为素材关联标签（按 ID 或名称，名称不存在时创建），返回素材当前标签
+func (s *CreativeService) AttachAssetTags(ctx context.Context, assetUUID string, tagIDs []uint, names []string) ([]TagDTO, error) {
+	asset, err := s.taggableAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tagger.Attach(ctx, asset.ID, tagIDs, names)
+	if err != nil {
+		return nil, err
+	}
+	s.touchAsset(ctx, asset.ID)
+	s.auditAsset(ctx, audit.ActionAssetTagAttach, asset, nil, map[string]interface{}{"tag_ids": tagIDs, "names": names})
+	return toTagDTOs(tags), nil
+}
+
+// DetachAssetTag 解除素材与标签的关联，返回素材剩余标签
+func (s *CreativeService) DetachAssetTag(ctx context.Context, assetUUID string, tagID uint) ([]TagDTO, error) {
+	asset, err := s.taggableAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tagger.Detach(ctx, asset.ID, []uint{tagID})
+	if err != nil {
+		return nil, err
+	}
+	s.touchAsset(ctx, asset.ID)
+	s.auditAsset(ctx, audit.ActionAssetTagDetach, asset, map[string]interface{}{"tag_id": tagID}, nil)
+	return toTagDTOs(tags), nil
+}
+
+func (s *CreativeService) taggableAsset(ctx context.Context, assetUUID string) (*models.CreativeAsset, error) {
+	if s.tagger == nil {
+		return nil, errors.New("tag service not configured")
+	}
+	if assetUUID == "" {
+		return nil, errors.New("asset_id is required")
+	}
+	asset, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("asset not found: %w", err)
+	}
+	return asset, nil
+}
+
+// touchAsset 更新 updated_at，同时让素材列表缓存失效
+func (s *CreativeService) touchAsset(ctx context.Context, id uint) {
+	if err := s.assetRepo.UpdateFields(ctx, id, map[string]interface{}{"updated_at": time.Now()}); err != nil {
+		log.Printf("更新素材 %d 失败: %v", id, err)
+	}
+}
+
+// ListAllTasks 获取当前项目范围内的任务
+func (s *CreativeService) ListAllTasks(ctx context.Context, query ListTasksQuery) ([]TaskDTO, int64, error) {
 	domainQuery := shared.ListTasksQuery{
 		Page:     query.Page,
 		PageSize: query.PageSize,
 		Status:   query.Status,
 		UserID:   query.UserID,
+		Scope:    shared.ScopeFrom(ctx),
 	}
 
-	tasks, total, err := s.taskRepo.List(context.Background(), domainQuery)
+	tasks, total, err := s.taskRepo.List(ctx, domainQuery)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -413,6 +943,7 @@ func (s *CreativeService) ListAllTasks(query ListTasksQuery) ([]TaskDTO, int64,
 			ErrorMessage:  task.ErrorMessage,
 			FirstImage:    firstImage,
 			RetryFrom:     task.RetryFrom,
+			Source:        task.Source,
 			RetryTo:       task.RetryTo,
 		}
 		taskDTOs = append(taskDTOs, taskDTO)
@@ -437,6 +968,19 @@ func generatePrompt(title string, sellingPoints models.StringArray, style string
 	return prompt
 }
 
+// taskPrompt 任务设置了项目提示词模板时按模板渲染，否则使用内置提示词
+func taskPrompt(task *models.CreativeTask, style string) string {
+	if strings.TrimSpace(task.PromptTemplate) == "" {
+		return generatePrompt(task.Title, task.SellingPoints, style)
+	}
+	return strings.NewReplacer(
+		"{title}", task.Title,
+		"{selling_points}", strings.Join(task.SellingPoints, "; "),
+		"{style}", style,
+		"{cta}", task.CTAText,
+	).Replace(task.PromptTemplate)
+}
+
 func styleAt(arr models.StringArray, idx int) string {
 	if len(arr) == 0 {
 		return ""
@@ -1,8 +1,22 @@
 package service
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"io"
 	"strings"
 	"testing"
+
+	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/infra/moderation"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 func TestGeneratePromptIncludesTitleAndSellingPoints(t *testing.T) {
@@ -23,3 +37,362 @@ func TestGeneratePromptIncludesTitleAndSellingPoints(t *testing.T) {
 		t.Fatalf("prompt missing style: %s", prompt)
 	}
 }
+
+func TestNearestFormat(t *testing.T) {
+	cases := map[[2]int]string{
+		{1024, 1024}: "1:1",
+		{1920, 1080}: "16:9",
+		{1080, 1920}: "9:16",
+		{1200, 900}:  "4:3",
+		{1000, 1300}: "3:4",
+	}
+	for size, want := range cases {
+		if got := nearestFormat(size[0], size[1]); got != want {
+			t.Errorf("%dx%d: want %s, got %s", size[0], size[1], want, got)
+		}
+	}
+}
+
+func TestTaskExportWriteZip(t *testing.T) {
+	v0, v1 := 0, 1
+	export := &TaskExport{
+		Task: &models.CreativeTask{UUIDModel: models.UUIDModel{UUID: "task-1"}, Title: "Shoes"},
+		Assets: []models.CreativeAsset{
+			{UUIDModel: models.UUIDModel{UUID: "aaaaaaaa-1"}, Format: "1:1", VariantIndex: &v0, StorageKey: "k/a.jpg", CTAText: "Buy"},
+			{UUIDModel: models.UUIDModel{UUID: "bbbbbbbb-2"}, Format: "16:9", VariantIndex: &v1, PublicURL: "https://x/b.png"},
+		},
+		open: func(_ context.Context, a models.CreativeAsset) (io.ReadCloser, error) {
+			if a.UUID == "bbbbbbbb-2" {
+				return nil, errors.New("gone")
+			}
+			return io.NopCloser(strings.NewReader("img")), nil
+		},
+	}
+
+	if n, err := export.ObjectSize(context.Background(), export.Assets[0]); err != nil || n != 3 {
+		t.Fatalf("ObjectSize = %d, %v", n, err)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]*zip.File{}
+	for _, f := range zr.File {
+		names[f.Name] = f
+	}
+	for _, want := range []string{"1x1/v0_aaaaaaaa.jpg", "manifest.json", "manifest.csv"} {
+		if names[want] == nil {
+			t.Fatalf("missing %s in zip, got %v", want, names)
+		}
+	}
+
+	rc, _ := names["manifest.json"].Open()
+	var manifest ExportManifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Assets) != 2 || manifest.Assets[1].Error == "" || manifest.Assets[1].File != "" {
+		t.Fatalf("unexpected manifest: %+v", manifest.Assets)
+	}
+}
+
+func TestAnimationFrames(t *testing.T) {
+	cases := map[int]int{0: 0, -3: 0, 1: -1, 3: 2}
+	for loops, want := range cases {
+		if got := gifLoopCount(loops); got != want {
+			t.Fatalf("gifLoopCount(%d) = %d, want %d", loops, got, want)
+		}
+	}
+
+	base := image.NewRGBA(image.Rect(0, 0, 320, 180))
+	frame := textFrame(base, "Lightweight and fast")
+	var lit int
+	for i := 0; i < len(frame.Pix); i += 4 {
+		if frame.Pix[i] > 200 {
+			lit++
+		}
+	}
+	if lit == 0 {
+		t.Fatal("text frame has no text pixels")
+	}
+	if p := quantize(frame); p.Bounds() != frame.Bounds() {
+		t.Fatalf("quantized bounds = %v", p.Bounds())
+	}
+}
+
+func TestAssetCursorRoundTrip(t *testing.T) {
+	in := shared.AssetCursor{Sort: shared.AssetSortCTR, Value: 0.0421, ID: 42}
+	out, err := decodeAssetCursor(encodeAssetCursor(in))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Sort != in.Sort || out.Value != in.Value || out.ID != in.ID {
+		t.Fatalf("cursor mismatch: %+v", out)
+	}
+	if _, err := decodeAssetCursor("not-a-cursor"); err == nil {
+		t.Fatalf("expected error for malformed cursor")
+	}
+}
+
+func TestVisualDistanceRanksLookalikes(t *testing.T) {
+	split := func(left, right color.RGBA) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				c := left
+				if x >= 32 {
+					c = right
+				}
+				img.SetRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+	red, blue, green := color.RGBA{R: 220, A: 255}, color.RGBA{B: 220, A: 255}, color.RGBA{G: 200, A: 255}
+
+	base := ComputeVisualFeatures(split(red, blue))
+	same := ComputeVisualFeatures(split(red, blue))
+	near := ComputeVisualFeatures(split(color.RGBA{R: 200, G: 20, A: 255}, blue))
+	far := ComputeVisualFeatures(split(green, green))
+
+	if d := VisualDistance(base, same); d > 1e-9 {
+		t.Fatalf("identical images should have zero distance, got %f", d)
+	}
+	dn, df := VisualDistance(base, near), VisualDistance(base, far)
+	if dn >= df {
+		t.Fatalf("expected near (%f) < far (%f)", dn, df)
+	}
+	if len(base.Palette) != 2 || base.Palette[0].Weight != 0.5 {
+		t.Fatalf("unexpected palette: %+v", base.Palette)
+	}
+}
+
+func TestBrandConformanceScore(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 230, G: 30, B: 30, A: 255}
+			if y >= 30 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	palette := ComputePalette(img)
+	if len(palette) != 2 || palette[0].Hex != "#e61e1e" || palette[0].Weight != 0.75 {
+		t.Fatalf("unexpected palette: %+v", palette)
+	}
+
+	if s := BrandConformanceScore(palette, []string{"#e31b23"}); s == nil || *s != 1 {
+		t.Fatalf("on-brand red should conform fully, got %v", s)
+	}
+	if s := BrandConformanceScore(palette, []string{"#0050ff"}); s == nil || *s > 0.1 {
+		t.Fatalf("blue brand should not match red creative, got %v", s)
+	}
+	if s := BrandConformanceScore(palette, nil); s != nil {
+		t.Fatalf("no brand colors should yield nil, got %v", *s)
+	}
+}
+
+func TestLineageRollup(t *testing.T) {
+	root := &LineageNode{CreativeAssetDTO: CreativeAssetDTO{NumericID: 1}}
+	child := &LineageNode{CreativeAssetDTO: CreativeAssetDTO{NumericID: 2}}
+	grandchild := &LineageNode{CreativeAssetDTO: CreativeAssetDTO{NumericID: 3}}
+	child.Children = []*LineageNode{grandchild}
+	root.Children = []*LineageNode{child}
+
+	rollupLineage(root, map[uint]shared.MetricTotals{
+		1: {Impressions: 100, Clicks: 5},
+		3: {Impressions: 300, Clicks: 15},
+	})
+	if root.Metrics.Impressions != 100 || root.Rollup.Impressions != 400 || root.Rollup.Clicks != 20 {
+		t.Fatalf("unexpected root metrics: %+v / %+v", root.Metrics, root.Rollup)
+	}
+	if child.Metrics.Impressions != 0 || child.Rollup.Clicks != 15 {
+		t.Fatalf("unexpected child metrics: %+v / %+v", child.Metrics, child.Rollup)
+	}
+	if root.Rollup.CTR != 0.05 {
+		t.Fatalf("ctr = %v, want 0.05", root.Rollup.CTR)
+	}
+}
+
+func TestProjectDefaultsAndPromptTemplate(t *testing.T) {
+	ps := &models.ProjectSettings{
+		DefaultFormats: []string{"9:16"},
+		DefaultStyles:  []string{"minimal", "neon"},
+		PromptTemplate: "{title} in {style} style, {selling_points}",
+		ImageProvider:  "wanx2.1-t2i-turbo",
+	}
+
+	task := &models.CreativeTask{Title: "Mug", SellingPoints: models.StringArray{"keeps warm"}, RequestedStyles: models.StringArray{""}, NumVariants: 2}
+	updates := map[string]interface{}{}
+	applyProjectDefaults(task, ps, updates)
+	if f := updates["requested_formats"].(models.StringArray); len(f) != 1 || f[0] != "9:16" {
+		t.Fatalf("formats not defaulted: %v", updates)
+	}
+	if v := updates["variant_styles"].(models.StringArray); len(v) != 2 || updates["image_model"] != "wanx2.1-t2i-turbo" {
+		t.Fatalf("styles/model not defaulted: %v", updates)
+	}
+
+	explicit := &models.CreativeTask{RequestedFormats: models.StringArray{"1:1"}, RequestedStyles: models.StringArray{"retro"}}
+	updates = map[string]interface{}{}
+	applyProjectDefaults(explicit, ps, updates)
+	if _, ok := updates["requested_formats"]; ok {
+		t.Fatalf("task formats must win over project defaults: %v", updates)
+	}
+	if _, ok := updates["requested_styles"]; ok {
+		t.Fatalf("task style must win over project defaults: %v", updates)
+	}
+
+	task.RequestedStyles = models.StringArray{"minimal"}
+	task.VariantStyles = models.StringArray{"minimal", "neon"}
+	task.PromptTemplate = ps.PromptTemplate
+	plan := (&TaskProcessor{}).buildPlan(task)
+	if len(plan) != 2 || plan[1].Prompt != "Mug in neon style, keeps warm" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+}
+
+type fixedPricer struct{}
+
+func (fixedPricer) Price(u shared.ModelUsage) float64 { return float64(u.Images) * 0.2 }
+func (fixedPricer) Currency() string                  { return "CNY" }
+
+type fixedLatency []int
+
+func (l fixedLatency) RecentLatencies(context.Context, string, string, int) ([]int, error) {
+	return l, nil
+}
+
+func TestPlanGenerationEstimatesWithoutCallingProvider(t *testing.T) {
+	svc := NewCreativeServiceWithDeps(nil, nil, &TaskProcessor{}, nil, nil)
+	svc.SetCostEstimator(fixedPricer{})
+	svc.SetLatencySource(fixedLatency{9000, 3000, 5000, 4000})
+
+	result, err := svc.PlanGeneration(context.Background(), PlanInput{Create: CreateTaskInput{
+		Title:          "Mug",
+		NumVariants:    3,
+		Formats:        []string{"9:16"},
+		VariantPrompts: []string{"a mug on a desk"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Steps) != 3 || result.TotalImages != 3 || result.Steps[0].Prompt != "a mug on a desk" || result.Steps[2].Format != "9:16" {
+		t.Fatalf("plan: %+v", result.Steps)
+	}
+	est := result.Estimate
+	if est.Cost < 0.599 || est.Cost > 0.601 || est.Currency != "CNY" {
+		t.Fatalf("cost estimate: %+v", est)
+	}
+	if est.Basis != "recent_traces" || est.LatencyP50Ms != 4000 || est.LatencyP90Ms != 9000 || est.DurationSeconds != 12 {
+		t.Fatalf("duration estimate: %+v", est)
+	}
+
+	svc.SetLatencySource(fixedLatency{})
+	result, _ = svc.PlanGeneration(context.Background(), PlanInput{Create: CreateTaskInput{Title: "Mug", NumVariants: 2}})
+	if len(result.Steps) != 1 || result.TotalImages != 2 || result.Estimate.Basis != "default" {
+		t.Fatalf("single request plan without history: %+v", result)
+	}
+}
+
+// failingModerator 图片审核服务不可用，记录收到的图片地址与文案审核次数
+type failingModerator struct {
+	imageURL  string
+	textCalls int
+}
+
+func (m *failingModerator) ModerateImage(_ context.Context, imageURL string) (moderation.Result, error) {
+	m.imageURL = imageURL
+	return moderation.Result{}, errors.New("connection refused")
+}
+
+func (m *failingModerator) ModerateText(context.Context, string) (moderation.Result, error) {
+	m.textCalls++
+	return moderation.Result{Safe: true}, nil
+}
+
+func TestModerationFailsClosedAndUsesReachableImage(t *testing.T) {
+	mod := &failingModerator{}
+	p := &TaskProcessor{moderator: mod}
+	task := &models.CreativeTask{UUIDModel: models.UUIDModel{UUID: "t1"}, Title: "Phone"}
+
+	out := p.moderateAsset(context.Background(), task, moderationTarget("/files/a.png", "https://dashscope.example.com/a.png", nil, ""))
+	if out.safe || !strings.Contains(out.reason, "moderation unavailable") {
+		t.Fatalf("moderator errors must quarantine the asset: %+v", out)
+	}
+	if mod.imageURL != "https://dashscope.example.com/a.png" {
+		t.Fatalf("relative public URL must fall back to the source URL, got %q", mod.imageURL)
+	}
+	if got := moderationTarget("/files/a.png", "", []byte("png"), "image/png"); got != "data:image/png;base64,cG5n" {
+		t.Fatalf("stored data must be inlined when no URL is reachable, got %q", got)
+	}
+	if got := moderationTarget("https://cdn.example.com/a.png", "https://src", nil, ""); got != "https://cdn.example.com/a.png" {
+		t.Fatalf("absolute public URL should be used as is, got %q", got)
+	}
+}
+
+// recordingAssetRepo 只记录落库的素材
+type recordingAssetRepo struct {
+	ports.AssetRepository
+	created []models.CreativeAsset
+}
+
+func (r *recordingAssetRepo) Create(_ context.Context, a *models.CreativeAsset) error {
+	a.ID = uint(len(r.created) + 1)
+	r.created = append(r.created, *a)
+	return nil
+}
+
+func (r *recordingAssetRepo) SaveScore(context.Context, *models.CreativeScore) error { return nil }
+
+func TestPersistAssetsModeratesTaskTextOnce(t *testing.T) {
+	mod := &failingModerator{}
+	repo := &recordingAssetRepo{}
+	p := &TaskProcessor{moderator: mod, assetRepo: repo}
+	task := &models.CreativeTask{UUIDModel: models.UUIDModel{ID: 1, UUID: "t1"}, Title: "Phone"}
+
+	var resp llm.ImageGenResponse
+	for _, u := range []string{"https://dashscope.example.com/a.png", "https://dashscope.example.com/b.png", "https://dashscope.example.com/c.png"} {
+		resp.Output.Results = append(resp.Output.Results, struct {
+			URL string `json:"url"`
+		}{URL: u})
+	}
+	if _, err := p.persistAssets(context.Background(), task, GenRequest{Format: "1:1"}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if mod.textCalls != 1 || len(repo.created) != 3 {
+		t.Fatalf("text moderated %d times for %d assets, want once", mod.textCalls, len(repo.created))
+	}
+	for _, a := range repo.created {
+		if !a.Quarantined {
+			t.Fatalf("image moderation failure must still quarantine each asset: %+v", a)
+		}
+	}
+}
+
+// stubTaskRepo 只返回固定任务
+type stubTaskRepo struct {
+	ports.TaskRepository
+	task *models.CreativeTask
+}
+
+func (r *stubTaskRepo) GetByUUIDWithDeleted(context.Context, string) (*models.CreativeTask, error) {
+	return r.task, nil
+}
+
+func TestHardDeleteRequiresProjectAdmin(t *testing.T) {
+	project := uint(3)
+	task := &models.CreativeTask{UUIDModel: models.UUIDModel{ID: 1, UUID: "t1"}, ProjectID: &project, UserID: 9}
+	svc := NewCreativeServiceWithDeps(&stubTaskRepo{task: task}, &recordingAssetRepo{}, &TaskProcessor{}, nil, nil)
+
+	member := shared.WithScope(context.Background(), &shared.ProjectScope{ProjectID: &project, Role: shared.ProjectRoleMember})
+	if err := svc.HardDeleteTask(member, "t1"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("project members must not purge tasks, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+const (
+	// featurePaletteSize 特征中保留的直方图主色数
+	featurePaletteSize = 5
+	// dominantPaletteSize k-means 主色数
+	dominantPaletteSize = 5
+	// similarCandidateLimit 相似检索最多比较的候选素材数（按创建时间倒序）
+	similarCandidateLimit = 5000
+	// 距离权重：颜色分布、构图结构（pHash）、主色
+	weightHistogram = 0.45
+	weightPHash     = 0.35
+	weightPalette   = 0.20
+)
+
+// SimilarAsset 相似素材及其距离
+type SimilarAsset struct {
+	CreativeAssetDTO
+	Distance   float64 `json:"distance"`
+	Similarity float64 `json:"similarity"`
+}
+
+// SimilarAssetsResult 相似检索结果
+type SimilarAssetsResult struct {
+	AssetID    string         `json:"asset_id"`
+	Candidates int            `json:"candidates"`
+	Assets     []SimilarAsset `json:"assets"`
+}
+
+// FeatureBackfillResult 视觉特征回填结果
+type FeatureBackfillResult struct {
+	Processed int      `json:"processed"`
+	Failed    int      `json:"failed"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// ComputeVisualFeatures 提取可持久化的视觉特征
+func ComputeVisualFeatures(img image.Image) *models.VisualFeatures {
+	f := imaging.ExtractFeatures(img, featurePaletteSize)
+	out := &models.VisualFeatures{
+		Histogram: make([]float64, len(f.Histogram)),
+		Palette:   make([]models.PaletteColor, 0, len(f.Palette)),
+		PHash:     fmt.Sprintf("%016x", f.PHash),
+	}
+	for i, v := range f.Histogram {
+		out.Histogram[i] = math.Round(v*1e4) / 1e4
+	}
+	for _, s := range f.Palette {
+		out.Palette = append(out.Palette, models.PaletteColor{Hex: imaging.HexColor(s.Color), Weight: math.Round(s.Weight*1e4) / 1e4})
+	}
+	return out
+}
+
+// VisualDistance 两组特征的加权距离，取值 [0,1]，越小越相似
+func VisualDistance(a, b *models.VisualFeatures) float64 {
+	if a == nil || b == nil {
+		return 1
+	}
+	d := weightHistogram * imaging.HistogramDistance(a.Histogram, b.Histogram)
+	ha, errA := strconv.ParseUint(a.PHash, 16, 64)
+	hb, errB := strconv.ParseUint(b.PHash, 16, 64)
+	if errA == nil && errB == nil {
+		d += weightPHash * float64(imaging.HammingDistance(ha, hb)) / 64
+	} else {
+		d += weightPHash
+	}
+	d += weightPalette * imaging.PaletteDistance(swatchesOf(a.Palette), swatchesOf(b.Palette))
+	return d
+}
+
+// SimilarAssets 按视觉距离对素材库排序，返回最相似的 limit 个；目标素材缺少特征时即时计算并保存
+func (s *CreativeService) SimilarAssets(ctx context.Context, assetUUID string, limit int) (*SimilarAssetsResult, error) {
+	if assetUUID == "" {
+		return nil, errors.New("asset_id is required")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	target, err := s.scopedAsset(ctx, assetUUID)
+	if err != nil {
+		return nil, fmt.Errorf("asset not found: %w", err)
+	}
+	if target.Features == nil {
+		if err := s.fillVisualFeatures(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates, err := s.assetRepo.ListWithFeatures(ctx, shared.ScopeFrom(ctx), similarCandidateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("list candidates failed: %w", err)
+	}
+
+	ranked := make([]SimilarAsset, 0, len(candidates))
+	for _, c := range candidates {
+		if c.ID == target.ID {
+			continue
+		}
+		d := VisualDistance(target.Features, c.Features)
+		ranked = append(ranked, SimilarAsset{
+			CreativeAssetDTO: toAssetDTO(c),
+			Distance:         math.Round(d*1e4) / 1e4,
+			Similarity:       math.Round((1-d)*1e4) / 1e4,
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Distance < ranked[j].Distance })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return &SimilarAssetsResult{AssetID: target.UUID, Candidates: len(candidates), Assets: ranked}, nil
+}
+
+// BackfillVisualFeatures 为当前范围内的历史素材补算视觉特征，单个失败不影响其他素材
+func (s *CreativeService) BackfillVisualFeatures(ctx context.Context, limit int) (*FeatureBackfillResult, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	assets, err := s.assetRepo.ListMissingFeatures(ctx, shared.ScopeFrom(ctx), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list assets failed: %w", err)
+	}
+	result := &FeatureBackfillResult{}
+	for i := range assets {
+		if err := s.fillVisualFeatures(ctx, &assets[i]); err != nil {
+			result.Failed++
+			if len(result.Errors) < 20 {
+				result.Errors = append(result.Errors, assets[i].UUID+": "+err.Error())
+			}
+			continue
+		}
+		result.Processed++
+	}
+	return result, nil
+}
+
+// fillVisualFeatures 读取素材图片计算特征、主色与品牌色符合度并保存
+func (s *CreativeService) fillVisualFeatures(ctx context.Context, asset *models.CreativeAsset) error {
+	if s.reader == nil {
+		return errors.New("storage reader not configured")
+	}
+	data, err := s.readAssetImage(ctx, asset)
+	if err != nil {
+		return err
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return fmt.Errorf("decode image failed: %w", err)
+	}
+	var projectID *uint
+	if task, err := s.taskRepo.GetByID(ctx, asset.TaskID); err == nil {
+		projectID = task.ProjectID
+	}
+	s.processor.analyzeImage(ctx, asset, img, projectID)
+	if err := s.assetRepo.UpdateFields(ctx, asset.ID, map[string]interface{}{
+		"features":          asset.Features,
+		"palette":           asset.Palette,
+		"brand_conformance": asset.BrandConformance,
+	}); err != nil {
+		return fmt.Errorf("save features failed: %w", err)
+	}
+	return nil
+}
+
+// applyVisualFeatures 落库前读取已上传的图片做视觉分析，失败只记录日志
+func (p *TaskProcessor) applyVisualFeatures(ctx context.Context, asset *models.CreativeAsset, projectID *uint) {
+	if p == nil || p.reader == nil {
+		return
+	}
+	rc, err := p.reader.Open(ctx, asset.StorageType, asset.StorageKey, asset.PublicURL)
+	if err != nil {
+		log.Printf("读取素材计算特征失败(asset=%s): %v", asset.UUID, err)
+		return
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxAssetImageBytes+1))
+	if err != nil || len(data) > maxAssetImageBytes {
+		log.Printf("读取素材计算特征失败(asset=%s): 读取失败或图片过大", asset.UUID)
+		return
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		log.Printf("解码素材计算特征失败(asset=%s): %v", asset.UUID, err)
+		return
+	}
+	p.analyzeImage(ctx, asset, img, projectID)
+}
+
+// analyzeImage 计算相似检索特征、k-means 主色及品牌色符合度
+func (p *TaskProcessor) analyzeImage(ctx context.Context, asset *models.CreativeAsset, img image.Image, projectID *uint) {
+	asset.Features = ComputeVisualFeatures(img)
+	asset.Palette = ComputePalette(img)
+	asset.BrandConformance = p.brandConformance(ctx, projectID, asset.Palette)
+}
+
+func swatchesOf(palette []models.PaletteColor) []imaging.Swatch {
+	out := make([]imaging.Swatch, 0, len(palette))
+	for _, p := range palette {
+		if c, ok := imaging.ParseHexColor(p.Hex); ok {
+			out = append(out, imaging.Swatch{Color: c, Weight: p.Weight})
+		}
+	}
+	return out
+}
@@ -46,7 +46,7 @@ func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
 		})
 	}
 
-	exp, err := h.service.CreateExperiment(service.CreateExperimentInput{
+	exp, err := h.service.CreateExperiment(c.Request.Context(), service.CreateExperimentInput{
 		Name:        req.Name,
 		ProductName: req.ProductName,
 		Variants:    variants,
@@ -67,7 +67,7 @@ func (h *ExperimentHandler) ListExperiments(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	status := c.Query("status")
 
-	result, err := h.service.ListExperiments(page, pageSize, status)
+	result, err := h.service.ListExperiments(c.Request.Context(), page, pageSize, status)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to list experiments: "+err.Error()))
 		return
@@ -153,7 +153,7 @@ func (h *ExperimentHandler) UpdateStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
 		return
 	}
-	if err := h.service.UpdateStatus(id, models.ExperimentStatus(req.Status)); err != nil {
+	if err := h.service.UpdateStatus(c.Request.Context(), id, models.ExperimentStatus(req.Status)); err != nil {
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to update status: "+err.Error()))
 		return
 	}
@@ -254,7 +254,7 @@ func (h *ExperimentHandler) Click(c *gin.Context) {
 // Metrics 结果
 func (h *ExperimentHandler) Metrics(c *gin.Context) {
 	id := c.Param("id")
-	dto, err := h.service.GetMetrics(id)
+	dto, err := h.service.GetMetrics(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Metrics failed: "+err.Error()))
 		return
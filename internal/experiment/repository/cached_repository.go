@@ -6,6 +6,7 @@ import (
 
 	"ads-creative-gen-platform/internal/infra/cache"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 // CachedExperimentRepository 为实验相关读路径增加缓存，写路径自动失效。
@@ -37,14 +38,14 @@ func NewCachedExperimentRepository(inner ExperimentRepository, c cache.DataCache
 	}
 }
 
-func (r *CachedExperimentRepository) ListExperiments(status string, page, pageSize int) ([]models.Experiment, int64, error) {
-	key := r.keys.ExperimentList(status, page, pageSize)
+func (r *CachedExperimentRepository) ListExperiments(scope *shared.ProjectScope, status string, page, pageSize int) ([]models.Experiment, int64, error) {
+	key := r.keys.ExperimentList(scope, status, page, pageSize)
 	var payload struct {
 		Experiments []models.Experiment
 		Total       int64
 	}
 	if err := r.cache.GetOrLoad(context.Background(), key, r.ttl, func(context.Context) (any, error) {
-		list, total, err := r.inner.ListExperiments(status, page, pageSize)
+		list, total, err := r.inner.ListExperiments(scope, status, page, pageSize)
 		if err != nil {
 			return nil, err
 		}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 	"ads-creative-gen-platform/pkg/database"
 )
 
 // ExperimentRepository 定义实验相关的数据访问
 type ExperimentRepository interface {
-	ListExperiments(status string, page, pageSize int) ([]models.Experiment, int64, error)
+	// ListExperiments 按项目范围分页查询，scope 为 nil 表示不限
+	ListExperiments(scope *shared.ProjectScope, status string, page, pageSize int) ([]models.Experiment, int64, error)
 	CreateExperiment(exp *models.Experiment) error
 	CreateVariants(variants []models.ExperimentVariant) error
 	FindAssetByID(id uint) (*models.CreativeAsset, error)
@@ -30,8 +32,11 @@ func NewExperimentRepository() ExperimentRepository {
 	return &gormExperimentRepo{}
 }
 
-func (r *gormExperimentRepo) ListExperiments(status string, page, pageSize int) ([]models.Experiment, int64, error) {
+func (r *gormExperimentRepo) ListExperiments(scope *shared.ProjectScope, status string, page, pageSize int) ([]models.Experiment, int64, error) {
 	db := database.DB.Model(&models.Experiment{})
+	if cond, args := scope.Condition("project_id", "user_id"); cond != "" {
+		db = db.Where(cond, args...)
+	}
 	if status != "" {
 		db = db.Where("status = ?", status)
 	}
@@ -9,9 +9,13 @@ import (
 	"time"
 
 	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/audit"
 	"ads-creative-gen-platform/internal/experiment/repository"
 	"ads-creative-gen-platform/internal/infra/cache"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/project"
+	"ads-creative-gen-platform/internal/shared"
 
 	"github.com/google/uuid"
 )
@@ -19,6 +23,8 @@ import (
 // ExperimentService 实验服务
 type ExperimentService struct {
 	repo       repository.ExperimentRepository
+	reviews    ports.AssetReviewPolicy
+	audit      ports.AuditRecorder
 	cacheTTL   time.Duration
 	metricsTTL time.Duration
 }
@@ -31,7 +37,7 @@ func NewExperimentService() *ExperimentService {
 	} else {
 		ttl = 5 * time.Minute
 	}
-	return NewExperimentServiceWithDeps(
+	svc := NewExperimentServiceWithDeps(
 		repository.NewCachedExperimentRepository(
 			repository.NewExperimentRepository(),
 			cache.NewConfiguredCache(cacheCfg),
@@ -42,6 +48,8 @@ func NewExperimentService() *ExperimentService {
 		ttl,
 		ttl/2,
 	)
+	svc.SetReviewPolicy(project.NewService())
+	return svc
 }
 
 // NewExperimentServiceWithRepo 支持依赖注入
@@ -81,6 +89,31 @@ func NewExperimentServiceWithDeps(repo repository.ExperimentRepository, ttl time
 	}
 }
 
+// SetReviewPolicy 设置素材审核策略（nil 表示不校验审核状态）
+func (s *ExperimentService) SetReviewPolicy(p ports.AssetReviewPolicy) {
+	s.reviews = p
+}
+
+// SetAuditRecorder 设置审计日志记录器（nil 表示不记录）
+func (s *ExperimentService) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// recordAudit 记录实验变更
+func (s *ExperimentService) recordAudit(ctx context.Context, action string, exp *models.Experiment, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceExperiment,
+		ResourceID:   exp.UUID,
+		ProjectID:    exp.ProjectID,
+		Before:       before,
+		After:        after,
+	})
+}
+
 // CreateExperimentInput 创建实验输入
 type CreateExperimentInput struct {
 	Name        string                   `json:"name"`
@@ -123,8 +156,8 @@ type ListExperimentsResult struct {
 	PageSize    int
 }
 
-// ListExperiments 获取实验列表
-func (s *ExperimentService) ListExperiments(page int, pageSize int, status string) (*ListExperimentsResult, error) {
+// ListExperiments 获取当前项目范围内的实验列表
+func (s *ExperimentService) ListExperiments(ctx context.Context, page int, pageSize int, status string) (*ListExperimentsResult, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -132,7 +165,7 @@ func (s *ExperimentService) ListExperiments(page int, pageSize int, status strin
 		pageSize = 20
 	}
 
-	experiments, total, err := s.repo.ListExperiments(status, page, pageSize)
+	experiments, total, err := s.repo.ListExperiments(shared.ScopeFrom(ctx), status, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -145,8 +178,8 @@ func (s *ExperimentService) ListExperiments(page int, pageSize int, status strin
 	}, nil
 }
 
-// CreateExperiment 创建实验并计算桶
-func (s *ExperimentService) CreateExperiment(input CreateExperimentInput) (*models.Experiment, error) {
+// CreateExperiment 在当前项目下创建实验并计算桶，变体素材须属于同一项目
+func (s *ExperimentService) CreateExperiment(ctx context.Context, input CreateExperimentInput) (*models.Experiment, error) {
 	if input.Name == "" {
 		return nil, errors.New("name is required")
 	}
@@ -163,12 +196,27 @@ func (s *ExperimentService) CreateExperiment(input CreateExperimentInput) (*mode
 	if totalWeight <= 0 {
 		return nil, errors.New("total weight invalid")
 	}
+	resolved, err := s.resolveVariants(ctx, input.Variants)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkScope(ctx, resolved); err != nil {
+		return nil, err
+	}
+	if err := checkModerated(resolved); err != nil {
+		return nil, err
+	}
+	if err := s.checkReviewed(ctx, resolved); err != nil {
+		return nil, err
+	}
 
 	exp := models.Experiment{
 		UUIDModel:   models.UUIDModel{UUID: uuid.New().String()},
 		Name:        input.Name,
 		ProductName: input.ProductName,
 		Status:      models.ExpDraft,
+		ProjectID:   shared.ProjectIDFrom(ctx),
+		UserID:      shared.UserIDPtrFrom(ctx),
 	}
 
 	if err := s.repo.CreateExperiment(&exp); err != nil {
@@ -178,15 +226,8 @@ func (s *ExperimentService) CreateExperiment(input CreateExperimentInput) (*mode
 	// 计算桶
 	acc := 0
 	var variants []models.ExperimentVariant
-	for _, v := range input.Variants {
-		if v.CreativeID == "" {
-			return nil, errors.New("creative_id required")
-		}
-		asset, creativeNumericID, err := s.lookupAsset(v.CreativeID)
-		if err != nil {
-			return nil, err
-		}
-
+	for _, r := range resolved {
+		v, asset := r.input, r.asset
 		width := int(math.Round((v.Weight / totalWeight) * 10000))
 		if width <= 0 {
 			width = 1
@@ -222,7 +263,7 @@ func (s *ExperimentService) CreateExperiment(input CreateExperimentInput) (*mode
 
 		variants = append(variants, models.ExperimentVariant{
 			ExperimentID:  exp.ID,
-			CreativeID:    creativeNumericID,
+			CreativeID:    r.creativeID,
 			Weight:        v.Weight,
 			BucketStart:   start,
 			BucketEnd:     end,
@@ -243,6 +284,12 @@ func (s *ExperimentService) CreateExperiment(input CreateExperimentInput) (*mode
 	}
 
 	exp.Variants = variants
+	s.recordAudit(ctx, audit.ActionExperimentCreate, &exp, nil, map[string]interface{}{
+		"name":         exp.Name,
+		"product_name": exp.ProductName,
+		"status":       exp.Status,
+		"variants":     input.Variants,
+	})
 	return &exp, nil
 }
 
@@ -269,11 +316,120 @@ func (s *ExperimentService) lookupAsset(creativeID string) (*models.CreativeAsse
 	return asset, numericID, nil
 }
 
+// resolvedVariant 变体输入及其素材；创建实验时每个素材只查询一次
+type resolvedVariant struct {
+	input      ExperimentVariantInput
+	asset      *models.CreativeAsset
+	creativeID uint
+}
+
+// resolveVariants 查询变体素材，同一素材在多个变体中复用查询结果；素材不存在时拒绝。
+// 当前为受限范围时一并加载所属任务，供范围校验使用
+func (s *ExperimentService) resolveVariants(ctx context.Context, variants []ExperimentVariantInput) ([]resolvedVariant, error) {
+	scope := shared.ScopeFrom(ctx)
+	withTask := scope != nil && !scope.All
+	seen := make(map[string]resolvedVariant, len(variants))
+	out := make([]resolvedVariant, 0, len(variants))
+	for _, v := range variants {
+		if v.CreativeID == "" {
+			return nil, errors.New("creative_id required")
+		}
+		r, ok := seen[v.CreativeID]
+		if !ok {
+			asset, id, err := s.lookupAsset(v.CreativeID)
+			if err != nil {
+				return nil, err
+			}
+			if withTask && asset != nil && asset.Task == nil {
+				if asset, err = s.loadAssetWithTask(ctx, id); err != nil {
+					return nil, fmt.Errorf("creative_id %s not found", v.CreativeID)
+				}
+			}
+			if asset == nil {
+				return nil, fmt.Errorf("creative_id %s not found", v.CreativeID)
+			}
+			r = resolvedVariant{asset: asset, creativeID: id}
+			seen[v.CreativeID] = r
+		}
+		r.input = v
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// checkScope 变体素材须在当前项目范围内，范围外的素材按不存在处理
+func checkScope(ctx context.Context, variants []resolvedVariant) error {
+	scope := shared.ScopeFrom(ctx)
+	if scope == nil || scope.All {
+		return nil
+	}
+	for _, v := range variants {
+		if v.asset.Task == nil || !scope.Allows(v.asset.Task.ProjectID, v.asset.Task.UserID) {
+			return fmt.Errorf("creative_id %s not found", v.input.CreativeID)
+		}
+	}
+	return nil
+}
+
+// scopedExperiment 查询实验，不在当前项目范围内时视为不存在
+func (s *ExperimentService) scopedExperiment(ctx context.Context, id string) (*models.Experiment, error) {
+	exp, err := s.repo.GetExperimentByUUID(id)
+	if err != nil {
+		return nil, fmt.Errorf("experiment not found: %w", err)
+	}
+	var owner uint
+	if exp.UserID != nil {
+		owner = *exp.UserID
+	}
+	if !shared.ScopeFrom(ctx).Allows(exp.ProjectID, owner) {
+		return nil, fmt.Errorf("experiment not found: %s", id)
+	}
+	return exp, nil
+}
+
+// checkModerated 被内容审核隔离的素材不能进入实验；在创建实验前校验
+func checkModerated(variants []resolvedVariant) error {
+	for _, v := range variants {
+		if v.asset.Quarantined {
+			return fmt.Errorf("creative_id %s is quarantined by content moderation", v.input.CreativeID)
+		}
+	}
+	return nil
+}
+
+// checkReviewed 所属项目要求审核时，素材必须已审核通过且不能用 image_url 覆盖为未审核的图片；
+// 在创建实验前校验，避免留下半成品实验。查询失败时拒绝创建
+func (s *ExperimentService) checkReviewed(ctx context.Context, variants []resolvedVariant) error {
+	if s.reviews == nil {
+		return nil
+	}
+	for _, v := range variants {
+		required, err := s.reviews.RequiresApproval(ctx, v.asset)
+		if err != nil {
+			return fmt.Errorf("check review policy failed: %w", err)
+		}
+		if !required {
+			continue
+		}
+		if v.asset.ReviewStatus != models.ReviewApproved {
+			return fmt.Errorf("creative_id %s is not approved (review_status=%s)", v.input.CreativeID, v.asset.ReviewStatus)
+		}
+		if v.input.ImageURL != "" && v.input.ImageURL != v.asset.PublicURL {
+			return fmt.Errorf("creative_id %s: image_url override is not allowed when the project requires review", v.input.CreativeID)
+		}
+	}
+	return nil
+}
+
 // UpdateStatus 更新实验状态
-func (s *ExperimentService) UpdateStatus(id string, status models.ExperimentStatus) error {
+func (s *ExperimentService) UpdateStatus(ctx context.Context, id string, status models.ExperimentStatus) error {
 	if status != models.ExpActive && status != models.ExpPaused && status != models.ExpArchived && status != models.ExpDraft {
 		return fmt.Errorf("invalid status")
 	}
+	exp, err := s.scopedExperiment(ctx, id)
+	if err != nil {
+		return err
+	}
 	fields := map[string]interface{}{"status": status}
 	now := time.Now()
 	if status == models.ExpActive {
@@ -285,6 +441,7 @@ func (s *ExperimentService) UpdateStatus(id string, status models.ExperimentStat
 	if err := s.repo.UpdateExperimentFields(id, fields); err != nil {
 		return err
 	}
+	s.recordAudit(ctx, audit.ActionExperimentStatus, exp, map[string]interface{}{"status": exp.Status}, map[string]interface{}{"status": status})
 	return nil
 }
 
@@ -319,10 +476,10 @@ func (s *ExperimentService) Click(id string, creativeID uint) error {
 }
 
 // GetMetrics 获取实验指标
-func (s *ExperimentService) GetMetrics(id string) (interface{}, error) {
-	exp, err := s.repo.GetExperimentByUUID(id)
+func (s *ExperimentService) GetMetrics(ctx context.Context, id string) (interface{}, error) {
+	exp, err := s.scopedExperiment(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("experiment not found: %w", err)
+		return nil, err
 	}
 	metrics, err := s.repo.ListMetrics(exp.ID)
 	if err != nil {
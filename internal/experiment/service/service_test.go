@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 // mockExperimentRepo implements repository.ExperimentRepository for unit tests.
@@ -12,15 +16,23 @@ type mockExperimentRepo struct {
 	asset         *models.CreativeAsset
 	metrics       []models.ExperimentMetric
 	updatedFields map[string]interface{}
+	created       int
+	lookups       int
 }
 
-func (m *mockExperimentRepo) ListExperiments(status string, page, pageSize int) ([]models.Experiment, int64, error) {
+func (m *mockExperimentRepo) ListExperiments(scope *shared.ProjectScope, status string, page, pageSize int) ([]models.Experiment, int64, error) {
 	return nil, 0, nil
 }
-func (m *mockExperimentRepo) CreateExperiment(exp *models.Experiment) error         { return nil }
-func (m *mockExperimentRepo) CreateVariants([]models.ExperimentVariant) error       { return nil }
-func (m *mockExperimentRepo) FindAssetByID(uint) (*models.CreativeAsset, error)     { return nil, nil }
-func (m *mockExperimentRepo) FindAssetByUUID(string) (*models.CreativeAsset, error) { return nil, nil }
+func (m *mockExperimentRepo) CreateExperiment(exp *models.Experiment) error {
+	m.created++
+	return nil
+}
+func (m *mockExperimentRepo) CreateVariants([]models.ExperimentVariant) error   { return nil }
+func (m *mockExperimentRepo) FindAssetByID(uint) (*models.CreativeAsset, error) { return nil, nil }
+func (m *mockExperimentRepo) FindAssetByUUID(string) (*models.CreativeAsset, error) {
+	m.lookups++
+	return m.asset, nil
+}
 func (m *mockExperimentRepo) FindAssetWithTaskByID(id uint) (*models.CreativeAsset, error) {
 	return m.asset, nil
 }
@@ -81,7 +93,7 @@ func TestUpdateStatusSetsTimestamps(t *testing.T) {
 	repo := &mockExperimentRepo{}
 	svc := NewExperimentServiceWithRepo(repo)
 
-	if err := svc.UpdateStatus("exp-1", models.ExpActive); err != nil {
+	if err := svc.UpdateStatus(context.Background(), "exp-1", models.ExpActive); err != nil {
 		t.Fatalf("UpdateStatus returned error: %v", err)
 	}
 	if repo.updatedFields["status"] != models.ExpActive {
@@ -101,7 +113,7 @@ func TestGetMetricsComputesCTR(t *testing.T) {
 	}
 	svc := NewExperimentServiceWithRepo(repo)
 
-	dto, err := svc.GetMetrics("exp-1")
+	dto, err := svc.GetMetrics(context.Background(), "exp-1")
 	if err != nil {
 		t.Fatalf("GetMetrics returned error: %v", err)
 	}
@@ -125,3 +137,78 @@ func TestRandBucketRange(t *testing.T) {
 		}
 	}
 }
+
+type requireReviewPolicy struct{}
+
+func (requireReviewPolicy) RequiresApproval(ctx context.Context, _ *models.CreativeAsset) (bool, error) {
+	return true, ctx.Err()
+}
+
+func TestCreateExperimentRequiresApprovedAssets(t *testing.T) {
+	asset := &models.CreativeAsset{UUIDModel: models.UUIDModel{ID: 7, UUID: "asset-7"}, ReviewStatus: models.ReviewPending}
+	repo := &mockExperimentRepo{asset: asset}
+	svc := NewExperimentServiceWithRepo(repo)
+	svc.SetReviewPolicy(requireReviewPolicy{})
+
+	input := CreateExperimentInput{
+		Name: "review gate",
+		Variants: []ExperimentVariantInput{
+			{CreativeID: "asset-7", Weight: 0.5},
+			{CreativeID: "asset-7", Weight: 0.5},
+		},
+	}
+	if _, err := svc.CreateExperiment(context.Background(), input); err == nil || !strings.Contains(err.Error(), "not approved") {
+		t.Fatalf("expected not approved error, got %v", err)
+	}
+
+	asset.ReviewStatus = models.ReviewApproved
+	repo.lookups = 0
+	if _, err := svc.CreateExperiment(context.Background(), input); err != nil {
+		t.Fatalf("approved assets should be accepted: %v", err)
+	}
+	if repo.lookups != 1 {
+		t.Fatalf("variants sharing an asset should look it up once, got %d lookups", repo.lookups)
+	}
+
+	// 审核策略使用请求 ctx
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.CreateExperiment(cancelled, input); !errors.Is(err, context.Canceled) {
+		t.Fatalf("review policy should see the request ctx, got %v", err)
+	}
+
+	input.Variants[1].ImageURL = "https://cdn.example.com/unreviewed.png"
+	if _, err := svc.CreateExperiment(context.Background(), input); err == nil || !strings.Contains(err.Error(), "image_url override") {
+		t.Fatalf("expected image_url override to be rejected, got %v", err)
+	}
+	input.Variants[1] = ExperimentVariantInput{Weight: 0.5}
+	if _, err := svc.CreateExperiment(context.Background(), input); err == nil || !strings.Contains(err.Error(), "creative_id required") {
+		t.Fatalf("expected missing creative_id to be rejected, got %v", err)
+	}
+}
+
+func TestCreateExperimentRejectsQuarantinedAssetsBeforeCreate(t *testing.T) {
+	asset := &models.CreativeAsset{UUIDModel: models.UUIDModel{ID: 7, UUID: "asset-7"}, Quarantined: true}
+	repo := &mockExperimentRepo{asset: asset}
+	svc := NewExperimentServiceWithRepo(repo)
+
+	input := CreateExperimentInput{
+		Name: "moderation gate",
+		Variants: []ExperimentVariantInput{
+			{CreativeID: "asset-7", Weight: 0.5},
+			{CreativeID: "asset-7", Weight: 0.5},
+		},
+	}
+	if _, err := svc.CreateExperiment(context.Background(), input); err == nil || !strings.Contains(err.Error(), "quarantined") {
+		t.Fatalf("expected quarantined error, got %v", err)
+	}
+
+	// 数字 ID 查不到素材时不能放行
+	input.Variants[0].CreativeID = "42"
+	if _, err := svc.CreateExperiment(context.Background(), input); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing asset to be rejected, got %v", err)
+	}
+	if repo.created != 0 {
+		t.Fatalf("rejected requests must not create experiments, created %d", repo.created)
+	}
+}
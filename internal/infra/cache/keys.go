@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"ads-creative-gen-platform/internal/shared"
@@ -14,19 +17,22 @@ func (KeyBuilder) TaskDetail(uuid string) string {
 }
 
 func (KeyBuilder) TaskList(q shared.ListTasksQuery) string {
-	return fmt.Sprintf("task:list:p=%d:ps=%d:status=%s:user=%d", q.Page, q.PageSize, q.Status, q.UserID)
+	return fmt.Sprintf("task:list:p=%d:ps=%d:status=%s:user=%d:scope=%s", q.Page, q.PageSize, q.Status, q.UserID, q.Scope.Key())
 }
 
 func (KeyBuilder) AssetList(q shared.ListAssetsQuery) string {
-	return fmt.Sprintf("asset:list:p=%d:ps=%d:fmt=%s:task=%s", q.Page, q.PageSize, q.Format, q.TaskID)
+	// 筛选项较多，统一取摘要
+	raw, _ := json.Marshal(q)
+	sum := sha1.Sum(raw)
+	return fmt.Sprintf("asset:list:p=%d:ps=%d:%s", q.Page, q.PageSize, hex.EncodeToString(sum[:]))
 }
 
 func (KeyBuilder) Experiment(uuid string) string {
 	return fmt.Sprintf("exp:%s", uuid)
 }
 
-func (KeyBuilder) ExperimentList(status string, page, pageSize int) string {
-	return fmt.Sprintf("explist:status=%s:p=%d:ps=%d", status, page, pageSize)
+func (KeyBuilder) ExperimentList(scope *shared.ProjectScope, status string, page, pageSize int) string {
+	return fmt.Sprintf("explist:scope=%s:status=%s:p=%d:ps=%d", scope.Key(), status, page, pageSize)
 }
 
 func (KeyBuilder) ExperimentMetrics(uuid string) string {
@@ -37,8 +43,8 @@ func (KeyBuilder) Asset(id uint) string {
 	return fmt.Sprintf("asset:%d", id)
 }
 
-func (KeyBuilder) TraceList(status, modelName, traceID, productName string, page, pageSize int) string {
-	return fmt.Sprintf("traces:list:s=%s:m=%s:id=%s:pname=%s:p=%d:ps=%d", status, modelName, traceID, productName, page, pageSize)
+func (KeyBuilder) TraceList(scope *shared.ProjectScope, status, modelName, traceID, productName string, page, pageSize int) string {
+	return fmt.Sprintf("traces:list:scope=%s:s=%s:m=%s:id=%s:pname=%s:p=%d:ps=%d", scope.Key(), status, modelName, traceID, productName, page, pageSize)
 }
 
 func (KeyBuilder) TraceDetail(traceID string) string {
@@ -0,0 +1,37 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册 GIF 解码
+	"image/jpeg"
+	"image/png"
+)
+
+// Decode 解码图片并按 EXIF 方向摆正，返回图片与格式（jpeg/png/gif）
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image failed: %w", err)
+	}
+	if format == "jpeg" {
+		img = ApplyOrientation(img, Orientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode 编码图片：png 保留透明度，其余输出 JPEG；返回数据、Content-Type 与扩展名
+func Encode(img image.Image, format string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", fmt.Errorf("encode png failed: %w", err)
+		}
+		return buf.Bytes(), "image/png", ".png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, "", "", fmt.Errorf("encode jpeg failed: %w", err)
+	}
+	return buf.Bytes(), "image/jpeg", ".jpg", nil
+}
@@ -0,0 +1,211 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// HistogramLevels 每个通道的量化级数，直方图维度为其立方（64）
+const HistogramLevels = 4
+
+// featureMaxDim 提取直方图/色板前的缩放上限，兼顾速度与精度
+const featureMaxDim = 256
+
+// maxColorDistance RGB 空间最大欧氏距离
+var maxColorDistance = math.Sqrt(3 * 255 * 255)
+
+// Swatch 色板中的一种颜色及其像素占比
+type Swatch struct {
+	Color  color.RGBA
+	Weight float64
+}
+
+// Features 相似检索用的视觉特征
+type Features struct {
+	Histogram []float64 // 归一化 RGB 直方图，和为 1
+	Palette   []Swatch  // 按占比降序的主色
+	PHash     uint64    // 64 位感知哈希
+}
+
+// ExtractFeatures 计算颜色直方图、直方图主色与感知哈希
+func ExtractFeatures(img image.Image, paletteSize int) Features {
+	small := ToRGBA(Fit(img, featureMaxDim))
+	counts, sums, total := colorHistogram(small)
+
+	hist := make([]float64, len(counts))
+	if total > 0 {
+		for i, c := range counts {
+			hist[i] = c / total
+		}
+	}
+	return Features{
+		Histogram: hist,
+		Palette:   histogramPalette(hist, counts, sums, paletteSize),
+		PHash:     PerceptualHash(img),
+	}
+}
+
+// colorHistogram 统计量化直方图及每个桶的颜色和；忽略半透明以下像素
+func colorHistogram(img *image.RGBA) ([]float64, [][3]float64, float64) {
+	n := HistogramLevels * HistogramLevels * HistogramLevels
+	counts := make([]float64, n)
+	sums := make([][3]float64, n)
+	b := img.Bounds()
+	total := 0.0
+	for y := 0; y < b.Dy(); y++ {
+		off := y * img.Stride
+		for x := 0; x < b.Dx(); x++ {
+			r, g, bl, a := img.Pix[off], img.Pix[off+1], img.Pix[off+2], img.Pix[off+3]
+			off += 4
+			if a < 128 {
+				continue
+			}
+			i := (bin(r)*HistogramLevels+bin(g))*HistogramLevels + bin(bl)
+			counts[i]++
+			sums[i][0] += float64(r)
+			sums[i][1] += float64(g)
+			sums[i][2] += float64(bl)
+			total++
+		}
+	}
+	return counts, sums, total
+}
+
+func bin(v uint8) int {
+	return int(v) * HistogramLevels / 256
+}
+
+// histogramPalette 取占比最高的若干桶，以桶内平均色作为主色
+func histogramPalette(hist, counts []float64, sums [][3]float64, size int) []Swatch {
+	idx := make([]int, 0, len(hist))
+	for i, c := range counts {
+		if c > 0 {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return counts[idx[a]] > counts[idx[b]] })
+	if size > 0 && len(idx) > size {
+		idx = idx[:size]
+	}
+	out := make([]Swatch, 0, len(idx))
+	for _, i := range idx {
+		c := color.RGBA{
+			R: uint8(sums[i][0] / counts[i]),
+			G: uint8(sums[i][1] / counts[i]),
+			B: uint8(sums[i][2] / counts[i]),
+			A: 255,
+		}
+		out = append(out, Swatch{Color: c, Weight: hist[i]})
+	}
+	return out
+}
+
+// PerceptualHash 64 位 pHash：32x32 灰度图做 DCT，取左上 8x8 低频系数与中位数比较
+func PerceptualHash(img image.Image) uint64 {
+	const size, low = 32, 8
+	small := Resize(img, size, size)
+
+	var gray [size][size]float64
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			off := y*small.Stride + x*4
+			gray[y][x] = 0.299*float64(small.Pix[off]) + 0.587*float64(small.Pix[off+1]) + 0.114*float64(small.Pix[off+2])
+		}
+	}
+
+	// 可分离二维 DCT-II，只计算需要的低频部分
+	var cos [low][size]float64
+	for u := 0; u < low; u++ {
+		for x := 0; x < size; x++ {
+			cos[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+	var rows [size][low]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < low; u++ {
+			s := 0.0
+			for x := 0; x < size; x++ {
+				s += gray[y][x] * cos[u][x]
+			}
+			rows[y][u] = s
+		}
+	}
+	coeffs := make([]float64, 0, low*low)
+	for v := 0; v < low; v++ {
+		for u := 0; u < low; u++ {
+			s := 0.0
+			for y := 0; y < size; y++ {
+				s += rows[y][u] * cos[v][y]
+			}
+			coeffs = append(coeffs, s)
+		}
+	}
+
+	// 直流分量不参与中位数计算
+	sorted := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// HammingDistance 两个哈希不同的位数
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// HistogramDistance 归一化直方图的 L1 距离，取值 [0,1]
+func HistogramDistance(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 1
+	}
+	d := 0.0
+	for i := range a {
+		d += math.Abs(a[i] - b[i])
+	}
+	return math.Min(d/2, 1)
+}
+
+// PaletteDistance 色板间的加权最近色距离（双向平均），取值 [0,1]
+func PaletteDistance(a, b []Swatch) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 1
+	}
+	return (nearestSwatchDistance(a, b) + nearestSwatchDistance(b, a)) / 2
+}
+
+func nearestSwatchDistance(from, to []Swatch) float64 {
+	sum, weight := 0.0, 0.0
+	for _, s := range from {
+		best := math.MaxFloat64
+		for _, t := range to {
+			if d := ColorDistance(s.Color, t.Color); d < best {
+				best = d
+			}
+		}
+		w := s.Weight
+		if w <= 0 {
+			w = 1
+		}
+		sum += best * w
+		weight += w
+	}
+	return sum / weight / maxColorDistance
+}
+
+// ColorDistance RGB 欧氏距离
+func ColorDistance(a, b color.RGBA) float64 {
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
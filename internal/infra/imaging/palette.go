@@ -0,0 +1,191 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// paletteMaxDim k-means 采样前的缩放上限
+	paletteMaxDim = 128
+	// kmeansIterations k-means 最大迭代次数
+	kmeansIterations = 12
+	// neutralChroma Lab 色度低于该值视为中性色（黑/白/灰），不参与品牌色判定
+	neutralChroma = 12.0
+	// brandDeltaEPass / brandDeltaEFail 与最近品牌色的 ΔE 在 pass 内记满分，超过 fail 记 0，之间线性
+	brandDeltaEPass = 10.0
+	brandDeltaEFail = 45.0
+)
+
+// KMeansPalette 对像素做 k-means 聚类，返回按占比降序的 k 个主色。
+// 以直方图高频桶作为初始中心，结果是确定的。
+func KMeansPalette(img image.Image, k int) []Swatch {
+	if k <= 0 {
+		return nil
+	}
+	small := ToRGBA(Fit(img, paletteMaxDim))
+	b := small.Bounds()
+	pixels := make([][3]float64, 0, b.Dx()*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		off := y * small.Stride
+		for x := 0; x < b.Dx(); x++ {
+			if small.Pix[off+3] >= 128 {
+				pixels = append(pixels, [3]float64{float64(small.Pix[off]), float64(small.Pix[off+1]), float64(small.Pix[off+2])})
+			}
+			off += 4
+		}
+	}
+	if len(pixels) == 0 {
+		return nil
+	}
+
+	counts, sums, total := colorHistogram(small)
+	hist := make([]float64, len(counts))
+	for i, c := range counts {
+		hist[i] = c / total
+	}
+	var centers [][3]float64
+	for _, s := range histogramPalette(hist, counts, sums, k) {
+		centers = append(centers, [3]float64{float64(s.Color.R), float64(s.Color.G), float64(s.Color.B)})
+	}
+
+	assign := make([]int, len(pixels))
+	sizes := make([]int, len(centers))
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := iter == 0
+		for i, p := range pixels {
+			best, bestD := 0, math.MaxFloat64
+			for j, c := range centers {
+				d := sqDist(p, c)
+				if d < bestD {
+					best, bestD = j, d
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		next := make([][3]float64, len(centers))
+		for j := range sizes {
+			sizes[j] = 0
+		}
+		for i, p := range pixels {
+			j := assign[i]
+			next[j][0] += p[0]
+			next[j][1] += p[1]
+			next[j][2] += p[2]
+			sizes[j]++
+		}
+		for j := range centers {
+			if sizes[j] > 0 {
+				n := float64(sizes[j])
+				centers[j] = [3]float64{next[j][0] / n, next[j][1] / n, next[j][2] / n}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	out := make([]Swatch, 0, len(centers))
+	for j, c := range centers {
+		if sizes[j] == 0 {
+			continue
+		}
+		out = append(out, Swatch{
+			Color:  color.RGBA{R: uint8(math.Round(c[0])), G: uint8(math.Round(c[1])), B: uint8(math.Round(c[2])), A: 255},
+			Weight: float64(sizes[j]) / float64(len(pixels)),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Weight > out[j].Weight })
+	return out
+}
+
+func sqDist(a, b [3]float64) float64 {
+	d0, d1, d2 := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return d0*d0 + d1*d1 + d2*d2
+}
+
+// BrandConformance 主色与品牌色的符合度，取值 [0,1]。
+// 中性色不参与计算；画面几乎全是中性色时视为符合。
+func BrandConformance(palette []Swatch, brand []color.RGBA) float64 {
+	if len(brand) == 0 {
+		return 1
+	}
+	brandLab := make([][3]float64, 0, len(brand))
+	for _, c := range brand {
+		brandLab = append(brandLab, ToLab(c))
+	}
+	score, weight := 0.0, 0.0
+	for _, s := range palette {
+		lab := ToLab(s.Color)
+		if math.Hypot(lab[1], lab[2]) < neutralChroma {
+			continue
+		}
+		best := math.MaxFloat64
+		for _, bl := range brandLab {
+			if d := DeltaE(lab, bl); d < best {
+				best = d
+			}
+		}
+		v := 1 - (best-brandDeltaEPass)/(brandDeltaEFail-brandDeltaEPass)
+		score += math.Max(0, math.Min(1, v)) * s.Weight
+		weight += s.Weight
+	}
+	if weight < 0.05 {
+		return 1
+	}
+	return score / weight
+}
+
+// ToLab sRGB 转 CIELAB（D65）
+func ToLab(c color.RGBA) [3]float64 {
+	lin := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	r, g, b := lin(c.R), lin(c.G), lin(c.B)
+	x := (0.4124*r + 0.3576*g + 0.1805*b) / 0.95047
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+	z := (0.0193*r + 0.1192*g + 0.9505*b) / 1.08883
+	f := func(t float64) float64 {
+		if t > 0.008856 {
+			return math.Cbrt(t)
+		}
+		return 7.787*t + 16.0/116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
+
+// DeltaE CIE76 色差
+func DeltaE(a, b [3]float64) float64 {
+	return math.Sqrt(sqDist(a, b))
+}
+
+// HexColor 格式化为 #rrggbb
+func HexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ParseHexColor 解析 #rrggbb（# 可省略）
+func ParseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, true
+}
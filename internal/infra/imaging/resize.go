@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit 等比缩小到最长边不超过 maxDim，已满足时原样返回
+func Fit(img image.Image, maxDim int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxDim/w)
+		w = maxDim
+	} else {
+		w = max(1, w*maxDim/h)
+		h = maxDim
+	}
+	return Resize(img, w, h)
+}
+
+// Resize 缩放到 w*h：缩小时按区域平均采样，放大时取最近像素
+func Resize(img image.Image, w, h int) *image.RGBA {
+	src := ToRGBA(img)
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if sw == 0 || sh == 0 {
+		return dst
+	}
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max(y0+1, (y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max(x0+1, (x+1)*sw/w)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				off := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[off])
+					g += uint32(src.Pix[off+1])
+					bl += uint32(src.Pix[off+2])
+					a += uint32(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// ToRGBA 转换为以 (0,0) 为原点的 RGBA
+func ToRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Cover 等比缩放铺满 w*h 后居中裁剪
+func Cover(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 || w <= 0 || h <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, max(w, 0), max(h, 0)))
+	}
+	// 按源图比例截取与目标比例一致的中心区域，再缩放
+	cw, ch := sw, sw*h/w
+	if ch > sh {
+		cw, ch = sh*w/h, sh
+	}
+	x0 := b.Min.X + (sw-cw)/2
+	y0 := b.Min.Y + (sh-ch)/2
+	crop := image.NewRGBA(image.Rect(0, 0, cw, ch))
+	draw.Draw(crop, crop.Bounds(), img, image.Pt(x0, y0), draw.Src)
+	return Resize(crop, w, h)
+}
@@ -59,8 +59,15 @@ type CopywritingResponse struct {
 			} `json:"message"`
 		} `json:"choices"`
 	} `json:"output"`
-	RequestID string `json:"request_id"`
-	Message   string `json:"message"`
+	Usage     TokenUsage `json:"usage"`
+	RequestID string     `json:"request_id"`
+	Message   string     `json:"message"`
+}
+
+// TokenUsage 计费 token 数
+type TokenUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
 }
 
 // CopywritingResult 结构化结果
@@ -68,6 +75,9 @@ type CopywritingResult struct {
 	CTAOptions          []string `json:"cta_options"`
 	SellingPointOptions []string `json:"selling_point_options"`
 	RawResponse         string   `json:"-"`
+	// Model 与 Usage 用于成本核算
+	Model string     `json:"-"`
+	Usage TokenUsage `json:"-"`
 }
 
 // GenerateCopywriting 调用 LLM 生成文案
@@ -192,6 +202,8 @@ func (c *QwenClient) parseResponse(resp CopywritingResponse, raw string) (*Copyw
 		return nil, fmt.Errorf("parse JSON failed: %w", err)
 	}
 	result.RawResponse = raw
+	result.Model = c.model
+	result.Usage = resp.Usage
 
 	// 容错：过滤空白，截断/补齐
 	filteredCTA := make([]string, 0, len(result.CTAOptions))
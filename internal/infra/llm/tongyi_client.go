@@ -36,6 +36,27 @@ func NewTongyiClient() *TongyiClient {
 	}
 }
 
+type imageModelKey struct{}
+
+// WithImageModel 指定本次生成使用的图片模型（如项目设置的 image_provider），空表示默认模型
+func WithImageModel(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, imageModelKey{}, model)
+}
+
+// ImageModelFrom 本次调用的图片模型，未指定时使用配置的默认模型（未加载配置时为内置默认模型）
+func ImageModelFrom(ctx context.Context) string {
+	if m, ok := ctx.Value(imageModelKey{}).(string); ok && m != "" {
+		return m
+	}
+	if config.TongyiConfig == nil {
+		return settings.ModelName
+	}
+	return config.TongyiConfig.ImageModel
+}
+
 // ImageGenRequest 图像生成请求
 type ImageGenRequest struct {
 	Model      string         `json:"model"`
@@ -66,6 +87,10 @@ type ImageGenResponse struct {
 		} `json:"results"`
 		Message string `json:"message,omitempty"`
 	} `json:"output"`
+	// Usage 计费用量，任务成功后的查询结果中返回
+	Usage struct {
+		ImageCount int `json:"image_count"`
+	} `json:"usage"`
 	RequestID string `json:"request_id"`
 }
 
@@ -75,7 +100,7 @@ func (c *TongyiClient) GenerateImage(ctx context.Context, prompt string, size st
 		ctx = context.Background()
 	}
 	if traceID == "" {
-		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", config.TongyiConfig.ImageModel, productName, prompt, productName)
+		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", ImageModelFrom(ctx), productName, prompt, productName)
 	} else {
 		ctx = context.WithValue(ctx, tracing.CtxKeyTraceID, traceID)
 	}
@@ -107,7 +132,7 @@ func (c *TongyiClient) GenerateImage(ctx context.Context, prompt string, size st
 	}
 
 	req := ImageGenRequest{
-		Model: config.TongyiConfig.ImageModel,
+		Model: ImageModelFrom(ctx),
 		Input: ImageGenInput{
 			Prompt: prompt,
 		},
@@ -137,7 +162,7 @@ func (c *TongyiClient) GenerateImageWithProduct(ctx context.Context, prompt stri
 		ctx = context.Background()
 	}
 	if traceID == "" {
-		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", config.TongyiConfig.ImageModel, productName, prompt, productName)
+		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", ImageModelFrom(ctx), productName, prompt, productName)
 	} else {
 		ctx = context.WithValue(ctx, tracing.CtxKeyTraceID, traceID)
 	}
@@ -168,7 +193,7 @@ func (c *TongyiClient) GenerateImageWithProduct(ctx context.Context, prompt stri
 	}
 
 	req := ImageGenRequest{
-		Model: config.TongyiConfig.ImageModel,
+		Model: ImageModelFrom(ctx),
 		Input: ImageGenInput{
 			Prompt: prompt,
 		},
@@ -250,7 +275,7 @@ func (c *TongyiClient) QueryTask(ctx context.Context, traceID string, taskID str
 	}
 	if traceID == "" {
 		// 若未传 traceID，仍创建但建议上层复用同一 trace
-		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", config.TongyiConfig.ImageModel, source, taskID, "")
+		ctx, traceID = c.tracer.Start(ctx, "tongyi-image", ImageModelFrom(ctx), source, taskID, "")
 	} else {
 		ctx = context.WithValue(ctx, tracing.CtxKeyTraceID, traceID)
 	}
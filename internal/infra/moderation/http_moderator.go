@@ -0,0 +1,89 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPModerator 通过 HTTP 调用外部审核服务（可用本地 stub 替代，见 cmd/moderation-stub）
+//
+// 请求：POST {endpoint}  {"type":"image|text","content":"..."}，图片 content 为 http(s) URL 或 data URI
+// 响应：{"safe":true,"score":0.01,"labels":[],"reason":""}
+type HTTPModerator struct {
+	endpoint  string
+	threshold float64
+	client    *http.Client
+}
+
+type httpModerationRequest struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// NewHTTPModerator 创建 HTTP 审核器；threshold 为判定不安全的分数阈值
+func NewHTTPModerator(endpoint string, timeout time.Duration, threshold float64) *HTTPModerator {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.7
+	}
+	return &HTTPModerator{
+		endpoint:  endpoint,
+		threshold: threshold,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (m *HTTPModerator) ModerateImage(ctx context.Context, imageURL string) (Result, error) {
+	return m.call(ctx, "image", imageURL)
+}
+
+func (m *HTTPModerator) ModerateText(ctx context.Context, text string) (Result, error) {
+	return m.call(ctx, "text", text)
+}
+
+func (m *HTTPModerator) call(ctx context.Context, kind, content string) (Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	body, err := json.Marshal(httpModerationRequest{Type: kind, Content: content})
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal moderation request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("create moderation request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("read moderation response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result Result
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Result{}, fmt.Errorf("unmarshal moderation response failed: %w", err)
+	}
+	// 以阈值为准，避免服务端 safe 字段与分数不一致
+	if result.Score >= m.threshold {
+		result.Safe = false
+	}
+	return result, nil
+}
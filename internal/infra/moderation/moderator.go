@@ -0,0 +1,52 @@
+package moderation
+
+import (
+	"context"
+	"log"
+
+	"ads-creative-gen-platform/config"
+)
+
+// Result 审核结果
+type Result struct {
+	Safe    bool     `json:"safe"`
+	Score   float64  `json:"score"`             // 0-1，越高越不安全
+	Labels  []string `json:"labels,omitempty"`  // 命中的类别/词
+	Reason  string   `json:"reason,omitempty"`  // 可读原因
+	Skipped bool     `json:"skipped,omitempty"` // 当前审核器不支持该类型，未实际检测
+}
+
+// Moderator 内容安全审核器
+type Moderator interface {
+	ModerateImage(ctx context.Context, imageURL string) (Result, error)
+	ModerateText(ctx context.Context, text string) (Result, error)
+}
+
+// NoopModerator 关闭审核时使用，所有内容均视为安全
+type NoopModerator struct{}
+
+func (NoopModerator) ModerateImage(context.Context, string) (Result, error) {
+	return Result{Safe: true, Skipped: true}, nil
+}
+
+func (NoopModerator) ModerateText(context.Context, string) (Result, error) {
+	return Result{Safe: true, Skipped: true}, nil
+}
+
+// NewConfiguredModerator 根据配置创建审核器
+func NewConfiguredModerator(cfg *config.Moderation) Moderator {
+	if cfg == nil {
+		return NewRuleModerator(nil)
+	}
+	switch cfg.Provider {
+	case "none", "off", "disabled":
+		return NoopModerator{}
+	case "http":
+		return NewHTTPModerator(cfg.Endpoint, cfg.Timeout, cfg.NSFWThreshold)
+	case "rule", "":
+		return NewRuleModerator(cfg.BlockedWords)
+	default:
+		log.Printf("unknown moderation provider %q, using rule moderator", cfg.Provider)
+		return NewRuleModerator(cfg.BlockedWords)
+	}
+}
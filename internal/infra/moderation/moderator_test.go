@@ -0,0 +1,87 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRuleModeratorText(t *testing.T) {
+	m := NewRuleModerator([]string{"Forbidden"})
+
+	tests := []struct {
+		name string
+		text string
+		safe bool
+	}{
+		{"clean zh", "轻薄便携，续航持久", true},
+		{"clean en", "Shop today and save", true},
+		{"builtin zh", "线上博彩优惠", false},
+		{"builtin en case insensitive", "Best CASINO deals", false},
+		{"extra word", "this is forbidden copy", false},
+		{"en whole word", "No casino-style odds", false},
+		{"en inside word", "Heroine sneakers for everyday runs", true},
+		{"ambiguous ad copy", "Nude lipstick, Gore-Tex jacket, XXX placeholder", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := m.ModerateText(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("ModerateText returned error: %v", err)
+			}
+			if res.Safe != tt.safe {
+				t.Fatalf("ModerateText(%q).Safe = %v, want %v (labels=%v)", tt.text, res.Safe, tt.safe, res.Labels)
+			}
+		})
+	}
+}
+
+func TestRuleModeratorSkipsImages(t *testing.T) {
+	res, err := NewRuleModerator(nil).ModerateImage(context.Background(), "https://example.com/a.png")
+	if err != nil {
+		t.Fatalf("ModerateImage returned error: %v", err)
+	}
+	if !res.Safe || !res.Skipped {
+		t.Fatalf("expected skipped safe result, got %#v", res)
+	}
+}
+
+func TestHTTPModeratorAppliesThreshold(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req httpModerationRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		score := 0.1
+		if req.Content == "bad" {
+			score = 0.8
+		}
+		// 服务端声称安全，但分数超过阈值
+		_ = json.NewEncoder(w).Encode(Result{Safe: true, Score: score})
+	}))
+	defer srv.Close()
+
+	m := NewHTTPModerator(srv.URL, time.Second, 0.7)
+
+	res, err := m.ModerateImage(context.Background(), "good")
+	if err != nil || !res.Safe {
+		t.Fatalf("expected safe result, got %#v err=%v", res, err)
+	}
+	res, err = m.ModerateImage(context.Background(), "bad")
+	if err != nil || res.Safe {
+		t.Fatalf("expected unsafe result, got %#v err=%v", res, err)
+	}
+}
+
+func TestHTTPModeratorPropagatesErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if _, err := NewHTTPModerator(srv.URL, time.Second, 0.7).ModerateText(context.Background(), "x"); err == nil {
+		t.Fatalf("expected error on non-200 response")
+	}
+}
@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// defaultBlockedWords 内置的违禁词（广告法/平台政策常见拒审类别）；
+// 英文词按整词匹配，不收录 nude（裸色）、gore（Gore-Tex）、xxx（占位符）等常见于正常广告的歧义词
+var defaultBlockedWords = []string{
+	// 色情
+	"色情", "裸体", "porn", "pornography",
+	// 赌博
+	"赌博", "博彩", "casino", "gambling",
+	// 毒品
+	"毒品", "大麻", "cocaine", "heroin",
+	// 武器
+	"枪支", "弹药", "firearm", "ammunition",
+	// 暴力
+	"血腥", "暴力",
+}
+
+// RuleModerator 基于违禁词的本地文本审核器；不具备图片识别能力
+type RuleModerator struct {
+	words []string
+}
+
+// NewRuleModerator 创建规则审核器，extra 会追加到内置词表
+func NewRuleModerator(extra []string) *RuleModerator {
+	words := make([]string, 0, len(defaultBlockedWords)+len(extra))
+	seen := make(map[string]struct{})
+	for _, w := range append(append([]string{}, defaultBlockedWords...), extra...) {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" {
+			continue
+		}
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		words = append(words, w)
+	}
+	return &RuleModerator{words: words}
+}
+
+// ModerateText 命中任一违禁词即判定不安全（英文词按整词匹配）
+func (m *RuleModerator) ModerateText(_ context.Context, text string) (Result, error) {
+	lower := strings.ToLower(text)
+	var hits []string
+	for _, w := range m.words {
+		if shared.ContainsTerm(lower, w) {
+			hits = append(hits, w)
+		}
+	}
+	if len(hits) == 0 {
+		return Result{Safe: true}, nil
+	}
+	return Result{
+		Safe:   false,
+		Score:  1,
+		Labels: hits,
+		Reason: "blocked words: " + strings.Join(hits, ", "),
+	}, nil
+}
+
+// ModerateImage 规则审核器无法识别图片，返回 Skipped
+func (m *RuleModerator) ModerateImage(context.Context, string) (Result, error) {
+	return Result{Safe: true, Skipped: true}, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// maxDownloadSize 单个源文件下载上限，避免异常响应撑爆内存
+const maxDownloadSize = 50 << 20
+
+// Backend 存储后端抽象：七牛、S3/MinIO、本地磁盘都实现该接口
+type Backend interface {
+	// Type 返回写入 CreativeAsset.StorageType 的存储类型
+	Type() models.StorageType
+	// GenerateKey 根据文件名生成对象 key
+	GenerateKey(fileName string) string
+	// UploadFromURL 下载 sourceURL 并以 key 存储，返回公共访问 URL
+	UploadFromURL(ctx context.Context, sourceURL, key string) (string, error)
+	// Put 直接写入数据，返回公共访问 URL
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete 删除对象；对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// PublicURL 返回 key 对应的公共访问 URL
+	PublicURL(key string) string
+}
+
+// ObjectInfo 存储中的对象元信息
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Reader 可直接读取对象内容的后端（本地磁盘）；其余后端通过公共 URL 读取
+type Reader interface {
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Lister 可枚举自身 basePath 下对象的后端（用于存储 GC）
+type Lister interface {
+	// ListObjects 逐个回调 basePath 下的对象，fn 返回错误时中止
+	ListObjects(ctx context.Context, fn func(ObjectInfo) error) error
+}
+
+// datedKey 生成形如 {basePath}{yyyy}/{mm}/{dd}/{fileName}{ext} 的 key
+func datedKey(basePath, fileName string, now time.Time) string {
+	dir := fmt.Sprintf("%s%d/%02d/%02d",
+		basePath,
+		now.Year(),
+		now.Month(),
+		now.Day(),
+	)
+
+	if filepath.Ext(fileName) == "" {
+		fileName += ".png"
+	}
+	return fmt.Sprintf("%s/%s", dir, fileName)
+}
+
+// download 下载源文件，返回数据与 Content-Type
+func download(ctx context.Context, client *http.Client, sourceURL string) ([]byte, string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build download request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image data: %w", err)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, "", fmt.Errorf("source file exceeds %d bytes", maxDownloadSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// uploadFromURL 各后端共用的「下载后写入」实现
+func uploadFromURL(ctx context.Context, b Backend, client *http.Client, sourceURL, key string) (string, error) {
+	data, contentType, err := download(ctx, client, sourceURL)
+	if err != nil {
+		return "", err
+	}
+	return b.Put(ctx, key, data, contentType)
+}
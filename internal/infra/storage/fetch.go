@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedURL 用户提供的地址不是 http(s) 或指向内网/本机/元数据地址
+var ErrBlockedURL = errors.New("url is not allowed")
+
+// blockedNets 除 IsPrivate/IsLoopback/IsLinkLocal 外需额外拒绝的网段
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // 本网络
+	"100.64.0.0/10", // 运营商级 NAT
+	"192.0.0.0/24",  // IETF 协议分配（含部分云厂商元数据地址）
+	"198.18.0.0/15", // 基准测试
+	"64:ff9b::/96",  // NAT64 映射的 IPv4
+)
+
+// fetchClient 下载用户提供的 URL：在建立连接时校验解析后的 IP（含重定向），不走代理
+var fetchClient = &http.Client{
+	Timeout: 60 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: guardDial,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		return checkScheme(req.URL)
+	},
+}
+
+// Fetch 下载用户提供的远程文件（同样受 50MB 上限约束），返回数据与 Content-Type；
+// 只允许 http(s)，拒绝内网、本机、链路本地（含云元数据）地址
+func Fetch(ctx context.Context, sourceURL string) ([]byte, string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrBlockedURL, err)
+	}
+	if err := checkScheme(u); err != nil {
+		return nil, "", err
+	}
+	return download(ctx, fetchClient, sourceURL)
+}
+
+func checkScheme(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: only absolute http(s) URLs are supported", ErrBlockedURL)
+	}
+	return nil
+}
+
+// guardDial 在 DNS 解析之后、连接之前校验目标地址，避免 DNS 重绑定绕过
+func guardDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrBlockedURL, host)
+	}
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	out := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
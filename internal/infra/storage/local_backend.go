@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// LocalBackend 本地磁盘存储，文件由 gin 路由（urlPrefix）对外提供访问
+type LocalBackend struct {
+	root       string
+	urlPrefix  string
+	publicBase string
+	httpClient *http.Client
+}
+
+// NewLocalBackend 创建本地存储；publicBase 为空时返回相对路径 URL（{urlPrefix}/{key}）
+func NewLocalBackend(root, urlPrefix, publicBase string) *LocalBackend {
+	if root == "" {
+		root = "./data/uploads"
+	}
+	if urlPrefix == "" {
+		urlPrefix = "/files"
+	}
+	if publicBase == "" {
+		publicBase = urlPrefix
+	}
+	return &LocalBackend{
+		root:       root,
+		urlPrefix:  "/" + strings.Trim(urlPrefix, "/"),
+		publicBase: strings.TrimSuffix(publicBase, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Root 本地存储根目录
+func (b *LocalBackend) Root() string { return b.root }
+
+// URLPrefix gin 托管路由前缀
+func (b *LocalBackend) URLPrefix() string { return b.urlPrefix }
+
+func (b *LocalBackend) Type() models.StorageType { return models.StorageLocal }
+
+func (b *LocalBackend) GenerateKey(fileName string) string {
+	return datedKey("", fileName, time.Now())
+}
+
+func (b *LocalBackend) UploadFromURL(ctx context.Context, sourceURL, key string) (string, error) {
+	return uploadFromURL(ctx, b, b.httpClient, sourceURL, key)
+}
+
+func (b *LocalBackend) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	path, err := b.pathFor(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create storage dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return b.PublicURL(key), nil
+}
+
+func (b *LocalBackend) Delete(_ context.Context, key string) error {
+	path, err := b.pathFor(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (b *LocalBackend) PublicURL(key string) string {
+	return b.publicBase + "/" + strings.TrimPrefix(key, "/")
+}
+
+// Open 打开对象文件
+func (b *LocalBackend) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := b.pathFor(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// ListObjects 遍历根目录下的所有文件
+func (b *LocalBackend) ListObjects(ctx context.Context, fn func(ObjectInfo) error) error {
+	err := filepath.WalkDir(b.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		return fn(ObjectInfo{Key: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// pathFor 将 key 映射为根目录下的路径，拒绝越界 key
+func (b *LocalBackend) pathFor(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return filepath.Join(b.root, filepath.FromSlash(clean)), nil
+}
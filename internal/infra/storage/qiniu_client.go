@@ -6,11 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
 
 	"github.com/qiniu/go-sdk/v7/auth/qbox"
 	"github.com/qiniu/go-sdk/v7/storage"
@@ -64,44 +64,75 @@ func NewQiniuClient() *QiniuClient {
 	}
 }
 
-// UploadFromURL 从 URL 下载并上传到七牛云
-func (c *QiniuClient) UploadFromURL(ctx context.Context, sourceURL string, fileName string) (string, error) {
+// Type 存储类型
+func (c *QiniuClient) Type() models.StorageType { return models.StorageQiniu }
+
+// UploadFromURL 从 URL 下载并以 key 上传到七牛云
+func (c *QiniuClient) UploadFromURL(ctx context.Context, sourceURL string, key string) (string, error) {
 	if c == nil {
 		return sourceURL, fmt.Errorf("qiniu client not initialized")
 	}
+	return uploadFromURL(ctx, c, c.httpClient, sourceURL, key)
+}
 
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to build download request: %w", err)
+// Put 上传字节数据
+func (c *QiniuClient) Put(ctx context.Context, key string, data []byte, _ string) (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("qiniu client not initialized")
 	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
+	if err := c.uploadBytes(ctx, key, data); err != nil {
+		return "", fmt.Errorf("failed to upload to qiniu: %w", err)
 	}
-	defer resp.Body.Close()
+	return c.getPublicURL(key), nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+// Delete 删除对象，不存在时忽略
+func (c *QiniuClient) Delete(_ context.Context, key string) error {
+	if c == nil {
+		return fmt.Errorf("qiniu client not initialized")
 	}
-
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image data: %w", err)
+	manager := storage.NewBucketManager(c.mac, c.cfg)
+	if err := manager.Delete(c.bucket, key); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "no such file") {
+			return nil
+		}
+		return fmt.Errorf("failed to delete from qiniu: %w", err)
 	}
+	return nil
+}
 
-	key := c.GenerateKey(fileName)
-
-	if err := c.uploadBytes(ctx, key, data); err != nil {
-		return "", fmt.Errorf("failed to upload to qiniu: %w", err)
+// ListObjects 分页列出 basePath（QINIU_BASE_PATH）下的对象
+func (c *QiniuClient) ListObjects(ctx context.Context, fn func(ObjectInfo) error) error {
+	if c == nil {
+		return fmt.Errorf("qiniu client not initialized")
+	}
+	manager := storage.NewBucketManager(c.mac, c.cfg)
+	marker := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entries, _, next, hasNext, err := manager.ListFiles(c.bucket, c.basePath, "", marker, 1000)
+		if err != nil {
+			return fmt.Errorf("failed to list qiniu objects: %w", err)
+		}
+		for _, e := range entries {
+			// PutTime 单位为 100 纳秒
+			info := ObjectInfo{Key: e.Key, Size: e.Fsize, ModTime: time.Unix(0, e.PutTime*100)}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if !hasNext || next == "" {
+			return nil
+		}
+		marker = next
 	}
+}
 
-	publicURL := c.getPublicURL(key)
-	return publicURL, nil
+// PublicURL 返回 key 的公共访问 URL
+func (c *QiniuClient) PublicURL(key string) string {
+	return c.getPublicURL(key)
 }
 
 // UploadFile 上传本地文件
@@ -133,20 +164,7 @@ func (c *QiniuClient) UploadFile(ctx context.Context, localPath string, fileName
 
 // GenerateKey 生成存储路径
 func (c *QiniuClient) GenerateKey(fileName string) string {
-	now := time.Now()
-	dir := fmt.Sprintf("%s%d/%02d/%02d",
-		c.basePath,
-		now.Year(),
-		now.Month(),
-		now.Day(),
-	)
-
-	ext := filepath.Ext(fileName)
-	if ext == "" {
-		ext = ".png"
-	}
-
-	return fmt.Sprintf("%s/%s%s", dir, fileName, ext)
+	return datedKey(c.basePath, fileName, time.Now())
 }
 
 func (c *QiniuClient) uploadBytes(ctx context.Context, key string, data []byte) error {
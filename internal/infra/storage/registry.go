@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// Registry 管理所有已配置的存储后端；Primary 用于新写入，其余用于读取/删除历史对象
+type Registry struct {
+	primary  Backend
+	backends map[models.StorageType]Backend
+}
+
+// NewRegistry 创建注册表，primary 同时会被注册
+func NewRegistry(primary Backend, others ...Backend) *Registry {
+	r := &Registry{backends: make(map[models.StorageType]Backend)}
+	for _, b := range append([]Backend{primary}, others...) {
+		if b != nil {
+			r.backends[b.Type()] = b
+		}
+	}
+	r.primary = primary
+	return r
+}
+
+// NewConfiguredRegistry 根据配置创建注册表：本地磁盘始终可用，七牛/S3 在配置完整时注册
+func NewConfiguredRegistry(cfg *config.Storage) *Registry {
+	if cfg == nil {
+		cfg = &config.Storage{Backend: "local"}
+	}
+
+	local := NewLocalBackend(cfg.LocalDir, cfg.LocalURLPrefix, cfg.LocalPublicURL)
+	backends := []Backend{local}
+
+	var qiniu Backend
+	if client := NewQiniuClient(); client != nil {
+		qiniu = client
+		backends = append(backends, client)
+	}
+
+	var s3 Backend
+	if cfg.S3Endpoint != "" && cfg.S3AccessKey != "" {
+		storageType := models.StorageS3
+		if cfg.Backend == "minio" {
+			storageType = models.StorageMinio
+		}
+		b, err := NewS3Backend(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			PublicURL: cfg.S3PublicURL,
+			BasePath:  cfg.S3BasePath,
+			Type:      storageType,
+		})
+		if err != nil {
+			log.Printf("⚠ S3 storage disabled: %v", err)
+		} else {
+			s3 = b
+			backends = append(backends, b)
+		}
+	}
+
+	var primary Backend = local
+	switch cfg.Backend {
+	case "qiniu":
+		if qiniu != nil {
+			primary = qiniu
+		}
+	case "s3", "minio":
+		if s3 != nil {
+			primary = s3
+		}
+	}
+
+	r := NewRegistry(primary, backends...)
+	log.Printf("✓ Storage registry ready (primary=%s, backends=%d)", primary.Type(), len(r.backends))
+	return r
+}
+
+// Primary 返回用于新写入的后端
+func (r *Registry) Primary() Backend {
+	return r.primary
+}
+
+// Get 按存储类型获取后端
+func (r *Registry) Get(t models.StorageType) (Backend, bool) {
+	b, ok := r.backends[t]
+	return b, ok
+}
+
+// All 返回所有已注册的后端
+func (r *Registry) All() []Backend {
+	out := make([]Backend, 0, len(r.backends))
+	for _, b := range r.backends {
+		out = append(out, b)
+	}
+	return out
+}
+
+// Local 返回本地磁盘后端（供路由托管静态文件）
+func (r *Registry) Local() *LocalBackend {
+	if b, ok := r.backends[models.StorageLocal].(*LocalBackend); ok {
+		return b
+	}
+	return nil
+}
+
+// Open 读取对象内容：后端支持直接读取时按 key 读取，否则通过公共 URL 下载
+func (r *Registry) Open(ctx context.Context, t models.StorageType, key, publicURL string) (io.ReadCloser, error) {
+	if b, ok := r.backends[t]; ok && key != "" {
+		if reader, ok := b.(Reader); ok {
+			return reader.Open(ctx, key)
+		}
+	}
+	if !strings.HasPrefix(publicURL, "http://") && !strings.HasPrefix(publicURL, "https://") {
+		return nil, fmt.Errorf("object %q is not readable", publicURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, publicURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := (&http.Client{Timeout: 60 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetch %s: status %d", publicURL, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
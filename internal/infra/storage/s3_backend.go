@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// S3Config S3 兼容存储配置（AWS S3 / MinIO）
+type S3Config struct {
+	Endpoint  string // 如 http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // MinIO 通常使用 path-style
+	PublicURL string // 对外访问前缀，为空时使用 endpoint/bucket
+	BasePath  string
+	Type      models.StorageType
+}
+
+// S3Backend 基于 SigV4 签名的最小 S3 客户端，只依赖标准库
+type S3Backend struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3Backend 创建 S3 兼容存储
+func NewS3Backend(cfg S3Config) (*S3Backend, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Type == "" {
+		cfg.Type = models.StorageS3
+	}
+	return &S3Backend{
+		cfg:        cfg,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}, nil
+}
+
+func (b *S3Backend) Type() models.StorageType { return b.cfg.Type }
+
+func (b *S3Backend) GenerateKey(fileName string) string {
+	return datedKey(b.cfg.BasePath, fileName, time.Now())
+}
+
+func (b *S3Backend) UploadFromURL(ctx context.Context, sourceURL, key string) (string, error) {
+	return uploadFromURL(ctx, b, b.httpClient, sourceURL, key)
+}
+
+func (b *S3Backend) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	headers := map[string]string{}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	resp, err := b.do(ctx, http.MethodPut, key, nil, data, headers)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to s3: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to upload to s3: status %d: %s", resp.StatusCode, string(body))
+	}
+	return b.PublicURL(key), nil
+}
+
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete from s3: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete from s3: status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (b *S3Backend) PublicURL(key string) string {
+	if b.cfg.PublicURL != "" {
+		return strings.TrimSuffix(b.cfg.PublicURL, "/") + "/" + key
+	}
+	return b.objectURL(key, nil).String()
+}
+
+// listObjectsResult ListObjectsV2 响应
+type listObjectsResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjects 通过 ListObjectsV2 分页列出 BasePath 下的对象
+func (b *S3Backend) ListObjects(ctx context.Context, fn func(ObjectInfo) error) error {
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {b.cfg.BasePath}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to list s3 objects: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to list s3 objects: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to list s3 objects: status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var result listObjectsResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("failed to parse s3 list response: %w", err)
+		}
+		for _, c := range result.Contents {
+			if err := fn(ObjectInfo{Key: c.Key, Size: c.Size, ModTime: c.LastModified}); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// objectURL 构造对象 URL（path-style 或 virtual-hosted-style）
+func (b *S3Backend) objectURL(key string, query url.Values) *url.URL {
+	u := *b.endpoint
+	if b.cfg.PathStyle {
+		u.Path = "/" + b.cfg.Bucket + "/" + key
+	} else {
+		u.Host = b.cfg.Bucket + "." + b.endpoint.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+func (b *S3Backend) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	u := b.objectURL(key, query)
+	uri := s3Escape(u.Path)
+	u.RawPath = uri
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	b.sign(req, uri, query, body)
+	return b.httpClient.Do(req)
+}
+
+// sign 对请求做 AWS Signature Version 4 签名
+func (b *S3Backend) sign(req *http.Request, canonicalURI string, query url.Values, body []byte) {
+	t := b.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	dateStamp := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(query),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + b.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	kDate := hmacSHA256([]byte("AWS4"+b.cfg.SecretKey), dateStamp)
+	kRegion := hmacSHA256(kDate, b.cfg.Region)
+	kService := hmacSHA256(kRegion, "s3")
+	kSigning := hmacSHA256(kService, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(kSigning, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery 按 SigV4 规则排序并编码 query
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vals := append([]string{}, query[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, s3EscapeComponent(k)+"="+s3EscapeComponent(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape 编码路径（保留 '/'）
+func s3Escape(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = s3EscapeComponent(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3EscapeComponent 仅保留 RFC 3986 非保留字符
+func s3EscapeComponent(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
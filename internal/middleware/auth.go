@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"ads-creative-gen-platform/internal/auth"
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey gin 上下文中认证身份的键
+const PrincipalKey = "principal"
+
+// Authenticator 校验访问令牌
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*shared.Principal, error)
+}
+
+// RequireAuth 校验 Authorization: Bearer 访问令牌（或 X-API-Key），并把认证身份写入请求 context
+func RequireAuth(a Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := credential(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse(401, "missing bearer token or api key"))
+			return
+		}
+		authenticate(c, a, token)
+	}
+}
+
+// OptionalAuth 未携带凭证时匿名放行；携带时必须有效
+func OptionalAuth(a Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := credential(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+		authenticate(c, a, token)
+	}
+}
+
+// RequireScope API Key 必须具备指定 scope；登录用户与匿名请求不受限制
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p := shared.PrincipalFrom(c.Request.Context()); p != nil && !p.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, "api key lacks scope "+scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScopeRule 路由前缀对应的 scope：GET/HEAD 需要 Read，其他方法需要 Write；为空表示 API Key 不可访问
+type ScopeRule struct {
+	Prefix string
+	Read   string
+	Write  string
+}
+
+// EnforceScopes 按路由表校验 API Key 的 scope，未匹配任何规则的路由拒绝 API Key 访问。
+// 规则按顺序匹配 gin 路由模板（去掉 trimPrefix 后），更具体的前缀应放在前面。
+func EnforceScopes(trimPrefix string, rules []ScopeRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := shared.PrincipalFrom(c.Request.Context())
+		if p == nil || p.APIKeyID == 0 {
+			c.Next()
+			return
+		}
+		path := strings.TrimPrefix(c.FullPath(), trimPrefix)
+		scope := ""
+		for _, r := range rules {
+			if path == r.Prefix || strings.HasPrefix(path, strings.TrimSuffix(r.Prefix, "/")+"/") {
+				scope = r.Write
+				if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+					scope = r.Read
+				}
+				break
+			}
+		}
+		if scope == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, "endpoint is not available to api keys"))
+			return
+		}
+		if !p.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, "api key lacks scope "+scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, a Authenticator, token string) {
+	principal, err := a.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserDisabled) {
+			c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse(401, err.Error()))
+		return
+	}
+	setPrincipal(c, principal)
+	c.Next()
+}
+
+// credential 优先读取 X-API-Key，其次 Authorization: Bearer
+func credential(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		return key
+	}
+	return bearerToken(c.GetHeader("Authorization"))
+}
+
+// Anonymous 关闭认证时以固定身份执行请求
+func Anonymous(principal func(ctx context.Context) *shared.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setPrincipal(c, principal(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// RequireRole 只允许指定角色访问，需在 RequireAuth/Anonymous 之后使用
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := shared.PrincipalFrom(c.Request.Context())
+		if p == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, shared.ErrorResponse(401, "authentication required"))
+			return
+		}
+		for _, r := range roles {
+			if p.Role == r {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, "insufficient role"))
+	}
+}
+
+func setPrincipal(c *gin.Context, p *shared.Principal) {
+	c.Set(PrincipalKey, p)
+	c.Request = c.Request.WithContext(shared.WithPrincipal(c.Request.Context(), p))
+}
+
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
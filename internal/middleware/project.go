@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectHeader 指定请求所属项目（UUID），也可用查询参数 project_id
+const ProjectHeader = "X-Project-ID"
+
+// ScopeKey gin 上下文中数据范围的键
+const ScopeKey = "project_scope"
+
+// ScopeResolver 按认证身份与项目 UUID 解析数据范围与项目角色
+type ScopeResolver interface {
+	ResolveScope(ctx context.Context, p *shared.Principal, projectUUID string) (*shared.ProjectScope, error)
+}
+
+// ProjectScope 解析请求的项目范围并写入 context，需在认证中间件之后使用；
+// 未认证的请求（如匿名埋点）不设置范围
+func ProjectScope(r ScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := shared.PrincipalFrom(c.Request.Context())
+		if p == nil {
+			c.Next()
+			return
+		}
+		projectUUID := strings.TrimSpace(c.GetHeader(ProjectHeader))
+		if projectUUID == "" {
+			projectUUID = strings.TrimSpace(c.Query("project_id"))
+		}
+		scope, err := r.ResolveScope(c.Request.Context(), p, projectUUID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+			return
+		}
+		c.Set(ScopeKey, scope)
+		c.Request = c.Request.WithContext(shared.WithScope(c.Request.Context(), scope))
+		c.Next()
+	}
+}
+
+// ProjectRoleRule 路由前缀写操作所需的最低项目角色；读操作对所有项目成员开放
+type ProjectRoleRule struct {
+	Prefix string
+	Write  string
+}
+
+// EnforceProjectRoles 按路由表校验当前项目角色，需在 ProjectScope 之后使用；
+// 未匹配规则的路由（如项目管理接口）由业务层自行校验
+func EnforceProjectRoles(trimPrefix string, rules []ProjectRoleRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := shared.ScopeFrom(c.Request.Context())
+		if scope == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		path := strings.TrimPrefix(c.FullPath(), trimPrefix)
+		for _, r := range rules {
+			if path == r.Prefix || strings.HasPrefix(path, strings.TrimSuffix(r.Prefix, "/")+"/") {
+				if !scope.CanWrite(r.Write) {
+					c.AbortWithStatusJSON(http.StatusForbidden, shared.ErrorResponse(403, "project role "+r.Write+" required"))
+					return
+				}
+				break
+			}
+		}
+		c.Next()
+	}
+}
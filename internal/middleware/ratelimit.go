@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bucketIdleTTL 超过该时长未访问的令牌桶会被清理
+const bucketIdleTTL = 10 * time.Minute
+
+// RateLimiter 按调用方划分的内存令牌桶限流器
+type RateLimiter struct {
+	rate      float64
+	burst     float64
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限流器；rps<=0 时返回 nil（不限流）
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: rps, burst: float64(burst), buckets: make(map[string]*bucket)}
+}
+
+// Allow 尝试为 key 取一个令牌；拒绝时返回需要等待的时长
+func (l *RateLimiter) Allow(key string, now time.Time) (bool, time.Duration, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, int(b.tokens)
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait, 0
+}
+
+// prune 定期清理空闲的令牌桶，避免 key 无限增长
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < bucketIdleTTL {
+		return
+	}
+	l.lastPrune = now
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// RateLimit 限流中间件：按 API Key、用户、IP 的顺序确定调用方，超限返回 429 与 Retry-After
+func RateLimit(l *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		ok, wait, remaining := l.Allow(rateLimitKey(c), time.Now())
+		c.Header("X-RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(RetryAfterSeconds(wait)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, shared.ErrorResponse(429, "rate limit exceeded"))
+			return
+		}
+		c.Next()
+	}
+}
+
+func rateLimitKey(c *gin.Context) string {
+	if p := shared.PrincipalFrom(c.Request.Context()); p != nil {
+		if p.APIKeyID != 0 {
+			return fmt.Sprintf("key:%d", p.APIKeyID)
+		}
+		if p.UserID != 0 {
+			return fmt.Sprintf("user:%d", p.UserID)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RetryAfterSeconds 将等待时长向上取整为 Retry-After 秒数
+func RetryAfterSeconds(d time.Duration) int {
+	s := int(math.Ceil(d.Seconds()))
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	l := NewRateLimiter(2, 3)
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := l.Allow("a", now); !ok {
+			t.Fatalf("request %d within burst should pass", i)
+		}
+	}
+	ok, wait, _ := l.Allow("a", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("burst exhausted: ok=%v wait=%s", ok, wait)
+	}
+	if ok, _, _ := l.Allow("b", now); !ok {
+		t.Fatal("buckets are per key")
+	}
+	if ok, _, _ := l.Allow("a", now.Add(500*time.Millisecond)); !ok {
+		t.Fatal("token should refill at 2/s")
+	}
+	if NewRateLimiter(0, 10) != nil {
+		t.Fatal("rps<=0 disables limiting")
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strings"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader 请求 ID，客户端未携带时由服务端生成并在响应头返回
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen 客户端传入的请求 ID 最大长度，超出时重新生成
+const maxRequestIDLen = 64
+
+// RequestMeta 记录请求 ID、来源 IP 与 User-Agent 到 context，供审计日志使用
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(RequestIDHeader))
+		if id == "" || len(id) > maxRequestIDLen {
+			id = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, id)
+		meta := &shared.RequestMeta{
+			RequestID: id,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(shared.WithRequestMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// 审计资源类型
+const (
+	AuditResourceTask       = "task"
+	AuditResourceExperiment = "experiment"
+	AuditResourceTrace      = "trace"
+	AuditResourceWarmup     = "warmup"
+	AuditResourceAsset      = "asset"
+	AuditResourceAPIKey     = "api_key"
+	AuditResourceProject    = "project"
+	// AuditResourceMember 项目成员，resource_id 为 <项目 UUID>/<用户 ID>
+	AuditResourceMember = "project_member"
+	AuditResourceTag    = "tag"
+)
+
+// AuditLog 变更操作审计日志，只追加不修改，按保留期清理
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// 操作者：后台任务触发时为空
+	ActorID   *uint  `gorm:"index" json:"actor_id,omitempty"`
+	ActorName string `gorm:"type:varchar(64)" json:"actor_name,omitempty"`
+	ActorRole string `gorm:"type:varchar(20)" json:"actor_role,omitempty"`
+	APIKeyID  *uint  `json:"api_key_id,omitempty"`
+	ProjectID *uint  `gorm:"index" json:"project_id,omitempty"`
+
+	// 操作与资源
+	Action       string  `gorm:"type:varchar(64);not null;index" json:"action"`
+	ResourceType string  `gorm:"type:varchar(32);not null;index:idx_audit_resource" json:"resource_type"`
+	ResourceID   string  `gorm:"type:varchar(64);index:idx_audit_resource" json:"resource_id"`
+	Before       JSONMap `gorm:"type:json" json:"before,omitempty"`
+	After        JSONMap `gorm:"type:json" json:"after,omitempty"`
+
+	// 请求元数据
+	RequestID string `gorm:"type:varchar(64);index" json:"request_id,omitempty"`
+	Method    string `gorm:"type:varchar(10)" json:"method,omitempty"`
+	Path      string `gorm:"type:varchar(255)" json:"path,omitempty"`
+	IP        string `gorm:"type:varchar(64)" json:"ip,omitempty"`
+	UserAgent string `gorm:"type:varchar(255)" json:"user_agent,omitempty"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
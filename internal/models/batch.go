@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// BatchStatus 批量生成状态
+type BatchStatus string
+
+const (
+	BatchPending    BatchStatus = "pending"    // 已创建，尚未开始提交
+	BatchSubmitting BatchStatus = "submitting" // 正在逐个提交任务
+	BatchRunning    BatchStatus = "running"    // 全部提交完成，任务生成中
+	BatchCompleted  BatchStatus = "completed"  // 全部任务完成
+	BatchPartial    BatchStatus = "partial"    // 结束，但部分商品失败
+	BatchFailed     BatchStatus = "failed"     // 结束，全部商品失败
+)
+
+// BatchItemStatus 批量商品条目状态（提交阶段）
+type BatchItemStatus string
+
+const (
+	BatchItemPending   BatchItemStatus = "pending"
+	BatchItemSubmitted BatchItemStatus = "submitted"
+	BatchItemFailed    BatchItemStatus = "failed"
+)
+
+// 批量来源
+const (
+	BatchSourceCSV  = "csv"
+	BatchSourceJSON = "json"
+)
+
+// Batch 批量生成：一次提交多个商品，每个商品对应一个创意任务
+type Batch struct {
+	UUIDModel
+	UserID    uint   `gorm:"not null;index" json:"user_id"`
+	ProjectID *uint  `gorm:"index" json:"project_id,omitempty"`
+	Name      string `gorm:"type:varchar(128)" json:"name"`
+	Source    string `gorm:"type:varchar(8)" json:"source"`
+	// AutoCopywriting 为 true 时先调用 LLM 生成文案，自动选用候选后再启动生成
+	AutoCopywriting bool        `gorm:"default:false" json:"auto_copywriting"`
+	Status          BatchStatus `gorm:"type:varchar(16);default:'pending';index" json:"status"`
+	TotalItems      int         `gorm:"default:0" json:"total_items"`
+	CompletedAt     *time.Time  `json:"completed_at,omitempty"`
+	Items           []BatchItem `gorm:"foreignKey:BatchID" json:"items,omitempty"`
+}
+
+// BatchItem 批量中的单个商品及其任务
+type BatchItem struct {
+	ID      uint `gorm:"primarykey" json:"id"`
+	BatchID uint `gorm:"not null;index" json:"batch_id"`
+	Row     int  `gorm:"column:row_num;not null" json:"row"` // 源文件中的行号 / 数组下标（从 1 开始）
+
+	// 商品输入与覆盖项，为空时使用项目默认设置
+	Title           string      `gorm:"type:varchar(255);not null" json:"title"`
+	ProductName     string      `gorm:"type:varchar(255)" json:"product_name,omitempty"`
+	SellingPoints   StringArray `gorm:"type:json" json:"selling_points,omitempty"`
+	ProductImageURL string      `gorm:"type:varchar(512)" json:"product_image_url,omitempty"`
+	CTAText         string      `gorm:"type:varchar(64)" json:"cta_text,omitempty"`
+	Style           string      `gorm:"type:varchar(64)" json:"style,omitempty"`
+	Formats         StringArray `gorm:"type:json" json:"formats,omitempty"`
+	NumVariants     int         `gorm:"default:0" json:"num_variants,omitempty"`
+	Language        string      `gorm:"type:varchar(8)" json:"language,omitempty"`
+
+	TaskID    *uint           `gorm:"index" json:"task_id,omitempty"`
+	TaskUUID  string          `gorm:"type:varchar(36)" json:"task_uuid,omitempty"`
+	Status    BatchItemStatus `gorm:"type:varchar(16);default:'pending'" json:"status"`
+	Error     string          `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func (Batch) TableName() string {
+	return "batches"
+}
+
+func (BatchItem) TableName() string {
+	return "batch_items"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// CostRecord 一次模型调用的计费用量与费用
+type CostRecord struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+	Kind         string    `gorm:"type:varchar(16);not null" json:"kind"` // image / llm
+	Model        string    `gorm:"type:varchar(64);not null;index" json:"model"`
+	TaskID       *uint     `gorm:"index" json:"task_id,omitempty"`
+	UserID       uint      `gorm:"index" json:"user_id"`
+	ProjectID    *uint     `gorm:"index" json:"project_id,omitempty"`
+	TraceID      string    `gorm:"type:varchar(64)" json:"trace_id,omitempty"`
+	Images       int64     `gorm:"not null;default:0" json:"images"`
+	InputTokens  int64     `gorm:"not null;default:0" json:"input_tokens"`
+	OutputTokens int64     `gorm:"not null;default:0" json:"output_tokens"`
+	Cost         float64   `gorm:"type:decimal(14,6);not null;default:0" json:"cost"`
+	Currency     string    `gorm:"type:varchar(8)" json:"currency"`
+}
+
+// TableName 指定表名
+func (CostRecord) TableName() string {
+	return "cost_records"
+}
@@ -3,7 +3,10 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // TaskStatus 任务状态
@@ -19,6 +22,12 @@ const (
 	TaskDraft      TaskStatus = "draft"
 )
 
+// 任务来源
+const (
+	TaskSourceGenerate = "generate" // 模型生成
+	TaskSourceImport   = "import"   // 外部导入的素材
+)
+
 // StringArray 字符串数组类型（用于 JSON 存储）
 type StringArray []string
 
@@ -46,8 +55,9 @@ func (s StringArray) Value() (driver.Value, error) {
 // CreativeTask 创意生成任务
 type CreativeTask struct {
 	UUIDModel
-	UserID    uint  `gorm:"not null;index" json:"user_id"`
-	ProjectID *uint `gorm:"index" json:"project_id,omitempty"`
+	UserID    uint   `gorm:"not null;index" json:"user_id"`
+	ProjectID *uint  `gorm:"index" json:"project_id,omitempty"`
+	Source    string `gorm:"type:varchar(16);default:'generate';index" json:"source"`
 
 	// 输入信息
 	Title           string      `gorm:"type:varchar(255);not null" json:"title"`
@@ -73,6 +83,13 @@ type CreativeTask struct {
 	RetryFrom              string      `gorm:"type:varchar(64);index" json:"retry_from,omitempty"`
 	RetryTo                string      `gorm:"type:varchar(64);index" json:"retry_to,omitempty"`
 
+	// 项目设置快照：启动生成时写入，为空表示使用内置提示词与默认模型
+	PromptTemplate string `gorm:"type:text" json:"prompt_template,omitempty"`
+	ImageModel     string `gorm:"type:varchar(64)" json:"image_model,omitempty"`
+
+	// Cost 该任务全部模型调用（文案与图片）的累计成本，明细见 cost_records
+	Cost float64 `gorm:"type:decimal(14,6);not null;default:0" json:"cost"`
+
 	// 任务状态
 	Status        TaskStatus `gorm:"type:varchar(20);default:'pending';index" json:"status"`
 	Progress      int        `gorm:"default:0" json:"progress"` // 0-100
@@ -105,6 +122,8 @@ const (
 	StorageS3    StorageType = "s3"
 	StorageMinio StorageType = "minio"
 	StorageQiniu StorageType = "qiniu"
+	// StorageProvider 模型服务商返回的临时 URL，未落地到自有存储，会过期
+	StorageProvider StorageType = "provider"
 )
 
 // JSONMap 用于存储 JSON 对象
@@ -131,6 +150,58 @@ func (j JSONMap) Value() (driver.Value, error) {
 	return json.Marshal(j)
 }
 
+// PaletteColor 色板中的一种颜色
+type PaletteColor struct {
+	Hex    string  `json:"hex"`
+	Weight float64 `json:"weight"`
+}
+
+// Palette 主色列表（JSON 存储），按占比降序
+type Palette []PaletteColor
+
+// Scan 实现 sql.Scanner 接口
+func (p *Palette) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	*p = nil
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (p Palette) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// VisualFeatures 相似检索用的视觉特征：RGB 直方图、直方图主色、感知哈希
+type VisualFeatures struct {
+	Histogram []float64      `json:"hist"`
+	Palette   []PaletteColor `json:"palette"`
+	PHash     string         `json:"phash"` // 16 位十六进制
+}
+
+// Scan 实现 sql.Scanner 接口
+func (f *VisualFeatures) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	}
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (f VisualFeatures) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
 // CreativeAsset 创意素材
 type CreativeAsset struct {
 	UUIDModel
@@ -148,8 +219,9 @@ type CreativeAsset struct {
 
 	// 存储信息
 	StorageType  StorageType `gorm:"type:varchar(20);default:'local';not null" json:"storage_type"`
-	PublicURL    string      `gorm:"type:varchar(1024);not null" json:"public_url"`    // 公共访问URL
-	OriginalPath string      `gorm:"type:varchar(512)" json:"original_path,omitempty"` // 原始内部路径
+	PublicURL    string      `gorm:"type:varchar(1024);not null" json:"public_url"`        // 公共访问URL
+	StorageKey   string      `gorm:"type:varchar(512);index" json:"storage_key,omitempty"` // 存储后端中的对象 key
+	OriginalPath string      `gorm:"type:varchar(1024)" json:"original_path,omitempty"`    // 来源 URL（如模型服务商返回的临时地址）
 
 	// 生成元数据
 	Style            string  `gorm:"type:varchar(64);index" json:"style,omitempty"`
@@ -167,6 +239,32 @@ type CreativeAsset struct {
 	// 排序
 	Rank *int `gorm:"index" json:"rank,omitempty"`
 
+	// 内容安全：不安全素材被隔离，待人工复核前不可见、不可用于实验
+	Quarantined      bool   `gorm:"default:false;index" json:"quarantined"`
+	ModerationReason string `gorm:"type:varchar(512)" json:"moderation_reason,omitempty"`
+
+	// 检索：标题、商品名、提示词、CTA、卖点拼接，保存时自动生成，建有全文索引
+	SearchText string `gorm:"type:text" json:"-"`
+	// SortValue 列表按评分/CTR 排序时的排序值，只读、不建列
+	SortValue *float64 `gorm:"->;-:migration" json:"sort_value,omitempty"`
+
+	// Features 视觉特征，落库时计算，用于相似素材检索
+	Features *VisualFeatures `gorm:"type:json" json:"-"`
+	// Palette k-means 提取的主色，落库时计算
+	Palette Palette `gorm:"type:json" json:"palette,omitempty"`
+	// BrandConformance 主色与项目品牌色的符合度（0~1），项目未配置品牌色时为空
+	BrandConformance *float64 `gorm:"type:decimal(4,3);index" json:"brand_conformance,omitempty"`
+
+	// 派生关系：由父素材经缩放/叠加/编辑/重新生成等得到
+	ParentAssetID    *uint          `gorm:"index" json:"parent_asset_id,omitempty"`
+	DerivationType   DerivationType `gorm:"type:varchar(20);index" json:"derivation_type,omitempty"`
+	DerivationParams JSONMap        `gorm:"type:json" json:"derivation_params,omitempty"`
+
+	// 人工审核
+	ReviewStatus ReviewStatus `gorm:"type:varchar(20);default:'pending_review';index" json:"review_status"`
+	ReviewedBy   *uint        `gorm:"index" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time   `json:"reviewed_at,omitempty"`
+
 	// 关联
 	Task  *CreativeTask  `gorm:"foreignKey:TaskID" json:"task,omitempty"`
 	Score *CreativeScore `gorm:"foreignKey:CreativeID" json:"score,omitempty"`
@@ -178,6 +276,69 @@ func (CreativeAsset) TableName() string {
 	return "creative_assets"
 }
 
+// BeforeCreate 生成检索文本
+func (a *CreativeAsset) BeforeCreate(*gorm.DB) error {
+	a.SearchText = a.BuildSearchText()
+	return nil
+}
+
+// BuildSearchText 拼接参与全文检索的字段
+func (a *CreativeAsset) BuildSearchText() string {
+	parts := []string{a.Title, a.ProductName, a.GenerationPrompt, a.CTAText}
+	parts = append(parts, a.SellingPoints...)
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// DerivationType 素材派生方式
+type DerivationType string
+
+const (
+	DerivationResize     DerivationType = "resize"
+	DerivationOverlay    DerivationType = "overlay"
+	DerivationEdit       DerivationType = "edit"
+	DerivationRegenerate DerivationType = "regenerate"
+	// DerivationAnimate 由多张素材合成的动图，父素材为首帧
+	DerivationAnimate DerivationType = "animate"
+)
+
+// ReviewStatus 素材人工审核状态
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending_review"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// 审核评论的动作类型
+const (
+	ReviewActionComment = "comment"
+	ReviewActionApprove = "approve"
+	ReviewActionReject  = "reject"
+)
+
+// AssetReviewComment 素材审核评论，审批操作也记录为一条评论
+type AssetReviewComment struct {
+	BaseModel
+	AssetID uint   `gorm:"not null;index" json:"asset_id"`
+	UserID  *uint  `gorm:"index" json:"user_id,omitempty"`
+	Action  string `gorm:"type:varchar(20);default:'comment'" json:"action"`
+	Body    string `gorm:"type:text" json:"body,omitempty"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (AssetReviewComment) TableName() string {
+	return "asset_review_comments"
+}
+
 // CreativeScore 创意评分
 type CreativeScore struct {
 	ID         uint `gorm:"primarykey" json:"id"`
@@ -15,12 +15,15 @@ const (
 // Experiment 实验
 type Experiment struct {
 	UUIDModel
-	Name        string              `gorm:"type:varchar(128);not null" json:"name"`
-	ProductName string              `gorm:"type:varchar(255)" json:"product_name,omitempty"`
-	Status      ExperimentStatus    `gorm:"type:varchar(16);default:'draft';index" json:"status"`
-	StartAt     *time.Time          `json:"start_at,omitempty"`
-	EndAt       *time.Time          `json:"end_at,omitempty"`
-	Variants    []ExperimentVariant `gorm:"foreignKey:ExperimentID" json:"variants,omitempty"`
+	Name        string           `gorm:"type:varchar(128);not null" json:"name"`
+	ProductName string           `gorm:"type:varchar(255)" json:"product_name,omitempty"`
+	Status      ExperimentStatus `gorm:"type:varchar(16);default:'draft';index" json:"status"`
+	StartAt     *time.Time       `json:"start_at,omitempty"`
+	EndAt       *time.Time       `json:"end_at,omitempty"`
+	// ProjectID 所属项目，nil 为个人空间；UserID 创建人
+	ProjectID *uint               `gorm:"index" json:"project_id,omitempty"`
+	UserID    *uint               `gorm:"index" json:"user_id,omitempty"`
+	Variants  []ExperimentVariant `gorm:"foreignKey:ExperimentID" json:"variants,omitempty"`
 }
 
 // ExperimentVariant 实验变体
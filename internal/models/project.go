@@ -1,5 +1,11 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+)
+
 // ProjectStatus 项目状态
 type ProjectStatus string
 
@@ -11,11 +17,15 @@ const (
 // Project 项目/团队
 type Project struct {
 	UUIDModel
-	Name        string        `gorm:"type:varchar(128);not null" json:"name"`
-	Description string        `gorm:"type:text" json:"description,omitempty"`
-	OwnerID     uint          `gorm:"not null;index" json:"owner_id"`
-	Status      ProjectStatus `gorm:"type:varchar(20);default:'active';index" json:"status"`
-	Settings    JSONMap       `gorm:"type:json" json:"settings,omitempty"`
+	Name        string          `gorm:"type:varchar(128);not null" json:"name"`
+	Description string          `gorm:"type:text" json:"description,omitempty"`
+	OwnerID     uint            `gorm:"not null;index" json:"owner_id"`
+	Status      ProjectStatus   `gorm:"type:varchar(20);default:'active';index" json:"status"`
+	Settings    ProjectSettings `gorm:"type:json" json:"settings"`
+	// BrandColors 品牌色（#rrggbb），用于素材品牌色符合度检查
+	BrandColors StringArray `gorm:"type:json" json:"brand_colors,omitempty"`
+	// MonthlyBudget 每月模型调用成本上限，达到后不能创建新任务；0 表示不限
+	MonthlyBudget float64 `gorm:"type:decimal(12,2);not null;default:0" json:"monthly_budget"`
 
 	// 关联
 	Owner   *User           `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
@@ -27,6 +37,84 @@ func (Project) TableName() string {
 	return "projects"
 }
 
+// ProjectSettings 项目默认设置（JSON 存储）；请求未指定的生成参数按此补全，
+// 均未指定时使用 internal/settings 中的全局默认值
+type ProjectSettings struct {
+	DefaultFormats []string `json:"default_formats,omitempty"`
+	DefaultStyles  []string `json:"default_styles,omitempty"`
+	NumVariants    int      `json:"num_variants,omitempty"`
+	// CopyLanguage 文案语言 zh / en，空表示按商品名自动检测
+	CopyLanguage string `json:"copy_language,omitempty"`
+	// PromptTemplate 图片提示词模板，支持 {title} {selling_points} {style} {cta}
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	// ImageProvider 图片生成模型，空表示使用服务配置的默认模型
+	ImageProvider string `json:"image_provider,omitempty"`
+	// RequireReview 素材需人工审核通过才能进入实验
+	RequireReview bool `json:"require_review,omitempty"`
+}
+
+// Scan 实现 sql.Scanner 接口
+func (s *ProjectSettings) Scan(value interface{}) error {
+	*s = ProjectSettings{}
+	switch v := value.(type) {
+	case []byte:
+		if len(v) > 0 {
+			return json.Unmarshal(v, s)
+		}
+	case string:
+		if v != "" {
+			return json.Unmarshal([]byte(v), s)
+		}
+	}
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (s ProjectSettings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Formats 请求值优先，其次项目默认格式，均为空时返回 nil
+func (s *ProjectSettings) Formats(requested []string) []string {
+	if len(requested) > 0 {
+		return requested
+	}
+	if s != nil && len(s.DefaultFormats) > 0 {
+		return append([]string(nil), s.DefaultFormats...)
+	}
+	return nil
+}
+
+// Styles 请求风格为空时使用项目默认风格：返回主风格，多个默认风格时同时返回按变体轮换的风格列表
+func (s *ProjectSettings) Styles(requested string) (string, []string) {
+	if strings.TrimSpace(requested) != "" || s == nil || len(s.DefaultStyles) == 0 {
+		return requested, nil
+	}
+	if len(s.DefaultStyles) == 1 {
+		return s.DefaultStyles[0], nil
+	}
+	return s.DefaultStyles[0], append([]string(nil), s.DefaultStyles...)
+}
+
+// Variants 请求值优先，其次项目默认变体数，最后 fallback
+func (s *ProjectSettings) Variants(requested, fallback int) int {
+	if requested > 0 {
+		return requested
+	}
+	if s != nil && s.NumVariants > 0 {
+		return s.NumVariants
+	}
+	return fallback
+}
+
+// Language 请求值优先，其次项目默认文案语言
+func (s *ProjectSettings) Language(requested string) string {
+	if strings.TrimSpace(requested) != "" || s == nil {
+		return requested
+	}
+	return s.CopyLanguage
+}
+
 // ProjectMemberRole 项目成员角色
 type ProjectMemberRole string
 
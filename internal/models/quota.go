@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UsageCounter 配额用量计数：按主体（user / project）、周期（2006-01-02 / 2006-01）与指标累计
+type UsageCounter struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	SubjectType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_usage_counter" json:"subject_type"`
+	SubjectID   uint      `gorm:"not null;uniqueIndex:idx_usage_counter" json:"subject_id"`
+	Period      string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_usage_counter" json:"period"`
+	Metric      string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_usage_counter" json:"metric"`
+	Used        int64     `gorm:"not null;default:0" json:"used"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UsageCounter) TableName() string {
+	return "usage_counters"
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ReuploadStatus 补传状态
+type ReuploadStatus string
+
+const (
+	ReuploadPending   ReuploadStatus = "pending"
+	ReuploadSucceeded ReuploadStatus = "succeeded"
+	ReuploadFailed    ReuploadStatus = "failed" // 超过最大重试次数
+)
+
+// AssetReupload 记录仍指向临时 URL 的素材的补传进度
+type AssetReupload struct {
+	ID            uint           `gorm:"primarykey" json:"id"`
+	AssetID       uint           `gorm:"uniqueIndex;not null" json:"asset_id"`
+	Status        ReuploadStatus `gorm:"type:varchar(16);default:'pending';index" json:"status"`
+	Attempts      int            `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time      `gorm:"index" json:"next_attempt_at"`
+	ExpiresAt     time.Time      `json:"expires_at"` // 源 URL 预计过期时间
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+	AlertedAt     *time.Time     `json:"alerted_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func (AssetReupload) TableName() string {
+	return "asset_reuploads"
+}
+
+// UploadedImage 用户上传的商品图，可在创建/启动任务时通过 ID 引用
+type UploadedImage struct {
+	UUIDModel
+	UserID      *uint       `gorm:"index" json:"user_id,omitempty"`
+	ProjectID   *uint       `gorm:"index" json:"project_id,omitempty"` // 上传时所在项目，空表示个人空间
+	FileName    string      `gorm:"type:varchar(255)" json:"file_name"`
+	ContentType string      `gorm:"type:varchar(64)" json:"content_type"`
+	Size        int64       `json:"size"`
+	Width       int         `json:"width"`
+	Height      int         `json:"height"`
+	StorageType StorageType `gorm:"type:varchar(20);not null" json:"storage_type"`
+	StorageKey  string      `gorm:"type:varchar(512);index" json:"storage_key"`
+	PublicURL   string      `gorm:"type:varchar(1024);not null" json:"public_url"`
+}
+
+func (UploadedImage) TableName() string {
+	return "uploaded_images"
+}
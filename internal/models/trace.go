@@ -14,6 +14,7 @@ type ModelTrace struct {
 	StartAt       time.Time        `json:"start_at"`
 	EndAt         time.Time        `json:"end_at"`
 	Source        string           `gorm:"type:varchar(255)" json:"source,omitempty"` // 可放 experiment/task/user
+	ProjectID     *uint            `gorm:"index" json:"project_id,omitempty"`
 	InputPreview  string           `gorm:"type:text" json:"input_preview,omitempty"`
 	OutputPreview string           `gorm:"type:text" json:"output_preview,omitempty"`
 	ErrorMessage  string           `gorm:"type:text" json:"error_message,omitempty"`
@@ -39,3 +39,55 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// RefreshToken 登录会话：刷新令牌只保存 SHA-256 摘要，刷新时轮换
+type RefreshToken struct {
+	BaseModel
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	UserAgent string     `gorm:"type:varchar(255)" json:"user_agent,omitempty"`
+	IP        string     `gorm:"type:varchar(64)" json:"ip,omitempty"`
+
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// Active 会话未撤销且未过期
+func (t *RefreshToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// APIKey 服务间调用的 API Key：只保存 SHA-256 摘要，Prefix 用于展示与识别
+type APIKey struct {
+	UUIDModel
+	Name       string      `gorm:"type:varchar(128);not null" json:"name"`
+	Prefix     string      `gorm:"type:varchar(16);index" json:"prefix"`
+	KeyHash    string      `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	UserID     uint        `gorm:"not null;index" json:"user_id"`
+	ProjectID  *uint       `gorm:"index" json:"project_id,omitempty"`
+	Scopes     StringArray `gorm:"type:json" json:"scopes"`
+	ExpiresAt  *time.Time  `gorm:"index" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	// RotatedFrom 轮换生成的新 Key 指向旧 Key
+	RotatedFrom *uint `gorm:"index" json:"rotated_from,omitempty"`
+
+	User    *User    `gorm:"foreignKey:UserID" json:"-"`
+	Project *Project `gorm:"foreignKey:ProjectID" json:"-"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active 未撤销且未过期
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -2,8 +2,10 @@ package ports
 
 import (
 	"context"
+	"io"
 
 	"ads-creative-gen-platform/internal/infra/llm"
+	"ads-creative-gen-platform/internal/infra/moderation"
 	"ads-creative-gen-platform/internal/models"
 	"ads-creative-gen-platform/internal/shared"
 )
@@ -21,16 +23,96 @@ type QwenClient interface {
 	GenerateCopywriting(productName string, language string) (*llm.CopywritingResult, error)
 }
 
+// ===== Moderation =====
+
+type Moderator interface {
+	ModerateImage(ctx context.Context, imageURL string) (moderation.Result, error)
+	ModerateText(ctx context.Context, text string) (moderation.Result, error)
+}
+
 // ===== Storage =====
 
-type QiniuClient interface {
-	UploadFromURL(ctx context.Context, url, fileName string) (string, error)
+type StorageBackend interface {
+	Type() models.StorageType
 	GenerateKey(fileName string) string
+	UploadFromURL(ctx context.Context, sourceURL, key string) (string, error)
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+	PublicURL(key string) string
 }
 
-type StorageUploader interface {
-	UploadFromURL(ctx context.Context, url, fileName string) (string, error)
-	GenerateKey(fileName string) string
+// ObjectCleaner 删除素材在存储后端中的对象
+type ObjectCleaner interface {
+	DeleteAssetObjects(ctx context.Context, assets []models.CreativeAsset) error
+}
+
+// ProductImageResolver 将上传的商品图 ID 解析为 URL
+type ProductImageResolver interface {
+	ResolveProductImageURL(ctx context.Context, id string) (string, error)
+}
+
+// ObjectReader 读取已存储对象的内容
+type ObjectReader interface {
+	Open(ctx context.Context, storageType models.StorageType, key, publicURL string) (io.ReadCloser, error)
+}
+
+// BrandColorSource 项目品牌色（#rrggbb）
+type BrandColorSource interface {
+	BrandColors(ctx context.Context, projectID uint) ([]string, error)
+}
+
+// BrandConformanceRefresher 品牌色变更后重算项目素材的符合度，返回更新的素材数
+type BrandConformanceRefresher interface {
+	RefreshBrandConformance(ctx context.Context, projectID uint) (int, error)
+}
+
+// AuditRecorder 记录变更操作审计日志
+type AuditRecorder interface {
+	Record(ctx context.Context, e shared.AuditEvent)
+}
+
+// ProjectSettingsSource 项目默认设置，用于补全请求未指定的生成参数
+type ProjectSettingsSource interface {
+	ProjectSettings(ctx context.Context, projectID uint) (*models.ProjectSettings, error)
+}
+
+// QuotaGuard 检查并扣减当前用户与项目的配额，超出时返回 *shared.QuotaError
+type QuotaGuard interface {
+	Consume(ctx context.Context, projectID *uint, usage shared.Usage) error
+}
+
+// CostRecorder 记录模型调用的计费用量；失败只记日志，不影响生成
+type CostRecorder interface {
+	RecordUsage(ctx context.Context, u shared.ModelUsage)
+}
+
+// BudgetGuard 项目本月已发生成本、进行中任务与本次调用（planned）的预估成本超出预算时返回 *shared.BudgetError
+type BudgetGuard interface {
+	CheckBudget(ctx context.Context, projectID uint, planned shared.ModelUsage) error
+}
+
+// CostEstimator 按价格表估算模型调用费用
+type CostEstimator interface {
+	Price(u shared.ModelUsage) float64
+	Currency() string
+}
+
+// LatencySource 模型最近成功调用的耗时（毫秒），modelVersion 为空表示不限版本
+type LatencySource interface {
+	RecentLatencies(ctx context.Context, modelName, modelVersion string, limit int) ([]int, error)
+}
+
+// AssetReviewPolicy 判断素材进入实验前是否必须通过人工审核
+type AssetReviewPolicy interface {
+	RequiresApproval(ctx context.Context, asset *models.CreativeAsset) (bool, error)
+}
+
+// AssetTagger 素材标签：落库前自动打标，以及手动关联/解除
+type AssetTagger interface {
+	AutoTags(ctx context.Context, asset *models.CreativeAsset) ([]models.Tag, error)
+	RefreshUsage(ctx context.Context, tags []models.Tag) error
+	Attach(ctx context.Context, assetID uint, tagIDs []uint, names []string) ([]models.Tag, error)
+	Detach(ctx context.Context, assetID uint, tagIDs []uint) ([]models.Tag, error)
 }
 
 // ===== Repositories =====
@@ -40,15 +122,43 @@ type TaskRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.CreativeTask, error)
 	GetByUUID(ctx context.Context, uuid string) (*models.CreativeTask, error)
 	GetByUUIDWithAssets(ctx context.Context, uuid string) (*models.CreativeTask, error)
+	// GetByUUIDWithDeleted 按 UUID 查询任务，包含已软删除的任务（供物理删除使用）
+	GetByUUIDWithDeleted(ctx context.Context, uuid string) (*models.CreativeTask, error)
 	UpdateStatus(ctx context.Context, id uint, status models.TaskStatus, progress int) error
 	UpdateProgress(ctx context.Context, id uint, progress int) error
 	UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error
 	List(ctx context.Context, query shared.ListTasksQuery) ([]models.CreativeTask, int64, error)
 	Delete(ctx context.Context, task *models.CreativeTask) error
+	// Purge 物理删除任务
+	Purge(ctx context.Context, task *models.CreativeTask) error
 }
 
 type AssetRepository interface {
 	Create(ctx context.Context, asset *models.CreativeAsset) error
+	GetByUUID(ctx context.Context, uuid string) (*models.CreativeAsset, error)
 	List(ctx context.Context, query shared.ListAssetsQuery) ([]models.CreativeAsset, int64, error)
+	UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error
+	SaveScore(ctx context.Context, score *models.CreativeScore) error
+	Delete(ctx context.Context, asset *models.CreativeAsset) error
 	DeleteByTaskID(ctx context.Context, taskID uint) error
+	ListByTaskID(ctx context.Context, taskID uint) ([]models.CreativeAsset, error)
+	// ListByTaskIDWithDeleted 任务下全部素材，包含已软删除的素材
+	ListByTaskIDWithDeleted(ctx context.Context, taskID uint) ([]models.CreativeAsset, error)
+	// ListWithFeatures 范围内已有视觉特征的未隔离素材（最近 limit 个），scope 为 nil 表示不限
+	ListWithFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error)
+	GetByID(ctx context.Context, id uint) (*models.CreativeAsset, error)
+	// ListChildren 派生自 parentIDs 的素材
+	ListChildren(ctx context.Context, parentIDs []uint) ([]models.CreativeAsset, error)
+	// MetricTotals 素材在所有实验中的曝光/点击汇总
+	MetricTotals(ctx context.Context, ids []uint) (map[uint]shared.MetricTotals, error)
+	AddReviewComment(ctx context.Context, comment *models.AssetReviewComment) error
+	ListReviewComments(ctx context.Context, assetID uint) ([]models.AssetReviewComment, error)
+	// ListPalettesByProject 项目下已提取主色的素材
+	ListPalettesByProject(ctx context.Context, projectID uint) ([]models.CreativeAsset, error)
+	// ListMissingFeatures 范围内尚未计算视觉特征或主色的素材，scope 为 nil 表示不限
+	ListMissingFeatures(ctx context.Context, scope *shared.ProjectScope, limit int) ([]models.CreativeAsset, error)
+	// ReferencedByExperiments 返回被实验变体引用的素材 ID
+	ReferencedByExperiments(ctx context.Context, ids []uint) (map[uint]bool, error)
+	// PurgeByIDs 物理删除素材及其评分、标签关联
+	PurgeByIDs(ctx context.Context, ids []uint) error
 }
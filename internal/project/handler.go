@@ -0,0 +1,264 @@
+package project
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 项目接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务供其他模块注入
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// ListProjects 当前用户可访问的项目
+func (h *Handler) ListProjects(c *gin.Context) {
+	list, err := h.service.ListProjects(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"projects": list, "total": len(list)}))
+}
+
+// CreateProject 创建项目
+func (h *Handler) CreateProject(c *gin.Context) {
+	var req ProjectInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	p, err := h.service.CreateProject(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(p))
+}
+
+// GetProject 项目详情
+func (h *Handler) GetProject(c *gin.Context) {
+	p, err := h.service.GetProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(p))
+}
+
+// UpdateProject 修改项目名称、描述、状态或每月预算
+func (h *Handler) UpdateProject(c *gin.Context) {
+	var req ProjectInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	p, err := h.service.UpdateProject(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(p))
+}
+
+// ArchiveProject 归档项目
+func (h *Handler) ArchiveProject(c *gin.Context) {
+	p, err := h.service.ArchiveProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(p))
+}
+
+// ListMembers 项目成员列表
+func (h *Handler) ListMembers(c *gin.Context) {
+	members, err := h.service.ListMembers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"members": members, "total": len(members)}))
+}
+
+// AddMember 添加项目成员
+func (h *Handler) AddMember(c *gin.Context) {
+	var req MemberInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	m, err := h.service.AddMember(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(m))
+}
+
+// MemberRoleRequest 修改成员角色请求
+type MemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateMember 修改成员角色
+func (h *Handler) UpdateMember(c *gin.Context) {
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	var req MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	m, err := h.service.UpdateMemberRole(c.Request.Context(), c.Param("id"), userID, req.Role)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(m))
+}
+
+// RemoveMember 移除成员或退出项目
+func (h *Handler) RemoveMember(c *gin.Context) {
+	userID, ok := memberID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveMember(c.Request.Context(), c.Param("id"), userID); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": c.Param("id"), "user_id": userID, "status": "removed"}))
+}
+
+func memberID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid user_id"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// BrandColorsRequest 设置品牌色请求
+type BrandColorsRequest struct {
+	Colors []string `json:"colors"`
+}
+
+// GetBrandColors 查询项目品牌色
+func (h *Handler) GetBrandColors(c *gin.Context) {
+	p, err := h.service.GetBrandColors(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": p.UUID, "colors": p.BrandColors}))
+}
+
+// SetBrandColors 设置项目品牌色（空数组表示清除），并重算素材符合度
+func (h *Handler) SetBrandColors(c *gin.Context) {
+	var req BrandColorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	p, updated, err := h.service.SetBrandColors(c.Request.Context(), c.Param("id"), req.Colors)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{
+		"project_id":     p.UUID,
+		"colors":         p.BrandColors,
+		"updated_assets": updated,
+	}))
+}
+
+// GetSettings 查询项目默认设置
+func (h *Handler) GetSettings(c *gin.Context) {
+	p, err := h.service.GetProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": p.UUID, "settings": p.Settings}))
+}
+
+// UpdateSettings 整体替换项目默认设置
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	var req models.ProjectSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	p, err := h.service.UpdateSettings(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": p.UUID, "settings": p.Settings}))
+}
+
+// ReviewPolicyRequest 设置审核要求
+type ReviewPolicyRequest struct {
+	RequireReview bool `json:"require_review"`
+}
+
+// GetReviewPolicy 查询项目是否要求素材审核通过后才能进入实验
+func (h *Handler) GetReviewPolicy(c *gin.Context) {
+	p, err := h.service.GetProject(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": p.UUID, "require_review": p.Settings.RequireReview}))
+}
+
+// SetReviewPolicy 设置项目审核要求
+func (h *Handler) SetReviewPolicy(c *gin.Context) {
+	var req ReviewPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	p, err := h.service.SetRequireReview(c.Request.Context(), c.Param("id"), req.RequireReview)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"project_id": p.UUID, "require_review": req.RequireReview}))
+}
+
+// writeError 按错误类型映射状态码
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, ErrForbidden):
+		c.JSON(http.StatusForbidden, shared.ErrorResponse(403, err.Error()))
+	case errors.Is(err, ErrInvalid):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
@@ -0,0 +1,283 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"gorm.io/gorm"
+)
+
+// ErrMemberNotFound 用户不是项目成员
+var ErrMemberNotFound = errors.New("project member not found")
+
+// MemberInput 添加成员：按 user_id 或用户名/邮箱指定用户，角色默认 member
+type MemberInput struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// MemberDTO 项目成员
+type MemberDTO struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+// ResolveScope 解析请求的数据范围，实现 middleware.ScopeResolver：
+// 未指定项目时为个人空间（系统管理员不限范围）；绑定项目的 API Key 只能访问该项目；
+// 归档项目对所有成员只读
+func (s *Service) ResolveScope(ctx context.Context, principal *shared.Principal, projectUUID string) (*shared.ProjectScope, error) {
+	if principal == nil {
+		return nil, nil
+	}
+	var p *models.Project
+	var err error
+	switch {
+	case principal.ProjectID != nil:
+		if p, err = s.repo.GetByID(ctx, *principal.ProjectID); err != nil {
+			return nil, wrapNotFound(err)
+		}
+		if projectUUID != "" && projectUUID != p.UUID {
+			return nil, fmt.Errorf("%w: api key is bound to project %s", ErrForbidden, p.UUID)
+		}
+	case projectUUID == "":
+		if principal.Role == string(models.RoleAdmin) {
+			return &shared.ProjectScope{All: true, Role: shared.ProjectRoleOwner}, nil
+		}
+		role := shared.ProjectRoleOwner
+		if principal.Role == string(models.RoleViewer) {
+			role = shared.ProjectRoleViewer
+		}
+		return &shared.ProjectScope{OwnerID: principal.UserID, Role: role}, nil
+	default:
+		if p, err = s.repo.GetByUUID(ctx, projectUUID); err != nil {
+			return nil, wrapNotFound(err)
+		}
+	}
+
+	role, err := s.memberRole(ctx, principal, p)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, fmt.Errorf("%w: not a member of project %s", ErrForbidden, p.UUID)
+	}
+	if p.Status == models.ProjectArchived {
+		role = models.ProjectRoleViewer
+	}
+	id := p.ID
+	return &shared.ProjectScope{ProjectID: &id, Role: string(role)}, nil
+}
+
+// ListMembers 项目成员列表（项目成员可见）
+func (s *Service) ListMembers(ctx context.Context, projectUUID string) ([]MemberDTO, error) {
+	p, _, err := s.authorize(ctx, projectUUID, models.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list members failed: %w", err)
+	}
+	out := make([]MemberDTO, 0, len(members))
+	for _, m := range members {
+		out = append(out, toMemberDTO(m))
+	}
+	return out, nil
+}
+
+// AddMember 添加成员（项目管理员）；授予 admin 需要负责人
+func (s *Service) AddMember(ctx context.Context, projectUUID string, in MemberInput) (*MemberDTO, error) {
+	p, actor, err := s.authorize(ctx, projectUUID, models.ProjectRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	role, err := assignableRole(actor, in.Role)
+	if err != nil {
+		return nil, err
+	}
+	login := strings.TrimSpace(in.Username)
+	if in.UserID == 0 && login == "" {
+		return nil, fmt.Errorf("%w: user_id or username is required", ErrInvalid)
+	}
+	user, err := s.repo.GetUser(ctx, in.UserID, login)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user not found", ErrInvalid)
+		}
+		return nil, err
+	}
+	if user.ID == p.OwnerID {
+		return nil, fmt.Errorf("%w: user is the project owner", ErrInvalid)
+	}
+	if _, err := s.repo.GetMember(ctx, p.ID, user.ID); err == nil {
+		return nil, fmt.Errorf("%w: user is already a member", ErrInvalid)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	m := models.ProjectMember{ProjectID: p.ID, UserID: user.ID, Role: role, User: user}
+	if err := s.repo.CreateMember(ctx, &m); err != nil {
+		return nil, fmt.Errorf("add member failed: %w", err)
+	}
+	s.recordMemberAudit(ctx, audit.ActionProjectMemberAdd, p, user.ID, "", role)
+	dto := toMemberDTO(m)
+	return &dto, nil
+}
+
+// UpdateMemberRole 修改成员角色（项目管理员）；涉及 admin 角色的变更需要负责人，负责人角色不可修改
+func (s *Service) UpdateMemberRole(ctx context.Context, projectUUID string, userID uint, roleName string) (*MemberDTO, error) {
+	p, actor, err := s.authorize(ctx, projectUUID, models.ProjectRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	role, err := assignableRole(actor, roleName)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.manageableMember(ctx, p, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateMemberRole(ctx, p.ID, userID, role); err != nil {
+		return nil, fmt.Errorf("update member failed: %w", err)
+	}
+	s.recordMemberAudit(ctx, audit.ActionProjectMemberRole, p, userID, m.Role, role)
+	m.Role = role
+	dto := toMemberDTO(*m)
+	return &dto, nil
+}
+
+// RemoveMember 移除成员（项目管理员）；成员可以自行退出，负责人不可移除
+func (s *Service) RemoveMember(ctx context.Context, projectUUID string, userID uint) error {
+	min := models.ProjectRoleAdmin
+	if principal := shared.PrincipalFrom(ctx); principal != nil && principal.UserID == userID {
+		min = models.ProjectRoleViewer
+	}
+	p, actor, err := s.authorize(ctx, projectUUID, min)
+	if err != nil {
+		return err
+	}
+	var m *models.ProjectMember
+	if min == models.ProjectRoleViewer {
+		if userID == p.OwnerID {
+			return fmt.Errorf("%w: the owner cannot leave the project", ErrForbidden)
+		}
+		if m, err = s.repo.GetMember(ctx, p.ID, userID); err != nil {
+			return wrapMemberNotFound(err)
+		}
+	} else if m, err = s.manageableMember(ctx, p, actor, userID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMember(ctx, p.ID, userID); err != nil {
+		return fmt.Errorf("remove member failed: %w", err)
+	}
+	s.recordMemberAudit(ctx, audit.ActionProjectMemberRemove, p, userID, m.Role, "")
+	return nil
+}
+
+// authorize 校验当前用户在项目中的角色不低于 min；context 中没有认证身份（内部调用）时不校验
+func (s *Service) authorize(ctx context.Context, projectUUID string, min models.ProjectMemberRole) (*models.Project, models.ProjectMemberRole, error) {
+	p, err := s.repo.GetByUUID(ctx, projectUUID)
+	if err != nil {
+		return nil, "", wrapNotFound(err)
+	}
+	principal := shared.PrincipalFrom(ctx)
+	if principal != nil && principal.ProjectID != nil && *principal.ProjectID != p.ID {
+		return nil, "", fmt.Errorf("%w: api key is bound to another project", ErrForbidden)
+	}
+	role, err := s.memberRole(ctx, principal, p)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", fmt.Errorf("%w: not a member of project %s", ErrForbidden, p.UUID)
+	}
+	if !shared.RoleAtLeast(string(role), string(min)) {
+		return nil, "", fmt.Errorf("%w: project role %s required", ErrForbidden, min)
+	}
+	return p, role, nil
+}
+
+// memberRole 用户在项目中的角色，非成员返回空
+func (s *Service) memberRole(ctx context.Context, principal *shared.Principal, p *models.Project) (models.ProjectMemberRole, error) {
+	if principal == nil || principal.Role == string(models.RoleAdmin) || principal.UserID == p.OwnerID {
+		return roleOf(principal, p, ""), nil
+	}
+	m, err := s.repo.GetMember(ctx, p.ID, principal.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return roleOf(principal, p, m.Role), nil
+}
+
+// roleOf 系统管理员与项目负责人视为 owner；系统只读用户在任何项目中最多为 viewer
+func roleOf(principal *shared.Principal, p *models.Project, member models.ProjectMemberRole) models.ProjectMemberRole {
+	role := member
+	if principal == nil || principal.Role == string(models.RoleAdmin) || principal.UserID == p.OwnerID {
+		role = models.ProjectRoleOwner
+	}
+	if role != "" && principal != nil && principal.Role == string(models.RoleViewer) {
+		role = models.ProjectRoleViewer
+	}
+	return role
+}
+
+// assignableRole 校验可授予的角色：不可授予 owner，授予 admin 需要负责人
+func assignableRole(actor models.ProjectMemberRole, name string) (models.ProjectMemberRole, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = string(models.ProjectRoleMember)
+	}
+	if !shared.ValidProjectRole(name) || name == string(models.ProjectRoleOwner) {
+		return "", fmt.Errorf("%w: role must be admin, member or viewer", ErrInvalid)
+	}
+	role := models.ProjectMemberRole(name)
+	if role == models.ProjectRoleAdmin && actor != models.ProjectRoleOwner {
+		return "", fmt.Errorf("%w: only the owner can grant admin", ErrForbidden)
+	}
+	return role, nil
+}
+
+// manageableMember 管理员可管理 member/viewer，负责人可管理除自己以外的全部成员
+func (s *Service) manageableMember(ctx context.Context, p *models.Project, actor models.ProjectMemberRole, userID uint) (*models.ProjectMember, error) {
+	if userID == p.OwnerID {
+		return nil, fmt.Errorf("%w: the owner's membership cannot be changed", ErrForbidden)
+	}
+	m, err := s.repo.GetMember(ctx, p.ID, userID)
+	if err != nil {
+		return nil, wrapMemberNotFound(err)
+	}
+	if m.Role == models.ProjectRoleOwner || (m.Role == models.ProjectRoleAdmin && actor != models.ProjectRoleOwner) {
+		return nil, fmt.Errorf("%w: only the owner can manage admins", ErrForbidden)
+	}
+	return m, nil
+}
+
+func toMemberDTO(m models.ProjectMember) MemberDTO {
+	dto := MemberDTO{UserID: m.UserID, Role: string(m.Role), JoinedAt: m.CreatedAt.Format(time.RFC3339)}
+	if m.User != nil {
+		dto.Username = m.User.Username
+		dto.Email = m.User.Email
+	}
+	return dto
+}
+
+func wrapMemberNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
@@ -0,0 +1,270 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+
+	"gorm.io/gorm"
+)
+
+type memRepo struct {
+	projects []*models.Project
+	members  []*models.ProjectMember
+	users    map[uint]*models.User
+}
+
+func (m *memRepo) GetByID(_ context.Context, id uint) (*models.Project, error) {
+	for _, p := range m.projects {
+		if p.ID == id {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) GetByUUID(_ context.Context, uuid string) (*models.Project, error) {
+	for _, p := range m.projects {
+		if p.UUID == uuid {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) UpdateFields(_ context.Context, id uint, fields map[string]interface{}) error {
+	for _, p := range m.projects {
+		if p.ID == id {
+			if v, ok := fields["status"].(models.ProjectStatus); ok {
+				p.Status = v
+			}
+			if v, ok := fields["settings"].(models.ProjectSettings); ok {
+				p.Settings = v
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memRepo) GetByTaskID(context.Context, uint) (*models.Project, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) List(_ context.Context, userID uint) ([]models.Project, error) {
+	var out []models.Project
+	for _, p := range m.projects {
+		if userID == 0 || p.OwnerID == userID {
+			out = append(out, *p)
+			continue
+		}
+		if _, err := m.GetMember(context.Background(), p.ID, userID); err == nil {
+			out = append(out, *p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) Create(_ context.Context, p *models.Project) error {
+	p.ID = uint(len(m.projects) + 1)
+	m.projects = append(m.projects, p)
+	return m.CreateMember(context.Background(), &models.ProjectMember{ProjectID: p.ID, UserID: p.OwnerID, Role: models.ProjectRoleOwner})
+}
+
+func (m *memRepo) GetMember(_ context.Context, projectID, userID uint) (*models.ProjectMember, error) {
+	for _, pm := range m.members {
+		if pm.ProjectID == projectID && pm.UserID == userID {
+			cp := *pm
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memRepo) ListMembers(_ context.Context, projectID uint) ([]models.ProjectMember, error) {
+	var out []models.ProjectMember
+	for _, pm := range m.members {
+		if pm.ProjectID == projectID {
+			out = append(out, *pm)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) ListMemberships(_ context.Context, userID uint) ([]models.ProjectMember, error) {
+	var out []models.ProjectMember
+	for _, pm := range m.members {
+		if pm.UserID == userID {
+			out = append(out, *pm)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) CreateMember(_ context.Context, pm *models.ProjectMember) error {
+	m.members = append(m.members, pm)
+	return nil
+}
+
+func (m *memRepo) UpdateMemberRole(_ context.Context, projectID, userID uint, role models.ProjectMemberRole) error {
+	for _, pm := range m.members {
+		if pm.ProjectID == projectID && pm.UserID == userID {
+			pm.Role = role
+		}
+	}
+	return nil
+}
+
+func (m *memRepo) DeleteMember(_ context.Context, projectID, userID uint) error {
+	for i, pm := range m.members {
+		if pm.ProjectID == projectID && pm.UserID == userID {
+			m.members = append(m.members[:i], m.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *memRepo) GetUser(_ context.Context, id uint, login string) (*models.User, error) {
+	for _, u := range m.users {
+		if (id > 0 && u.ID == id) || (id == 0 && u.Username == login) {
+			return u, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func as(userID uint, role models.UserRole) context.Context {
+	return shared.WithPrincipal(context.Background(), &shared.Principal{UserID: userID, Role: string(role)})
+}
+
+func TestProjectMembershipAndScope(t *testing.T) {
+	repo := &memRepo{users: map[uint]*models.User{}}
+	for id, name := range map[uint]string{1: "admin", 2: "owner", 3: "alice", 4: "bob", 5: "eve"} {
+		repo.users[id] = &models.User{UUIDModel: models.UUIDModel{ID: id}, Username: name}
+	}
+	svc := NewServiceWithDeps(repo)
+	owner := as(2, models.RoleUser)
+
+	name := "Spring campaign"
+	p, err := svc.CreateProject(owner, ProjectInput{Name: &name})
+	if err != nil || p.Role != shared.ProjectRoleOwner {
+		t.Fatalf("create: %v %+v", err, p)
+	}
+	if _, err := svc.AddMember(owner, p.UUID, MemberInput{Username: "alice", Role: "admin"}); err != nil {
+		t.Fatalf("owner grants admin: %v", err)
+	}
+	alice := as(3, models.RoleUser)
+	if _, err := svc.AddMember(alice, p.UUID, MemberInput{UserID: 4, Role: "admin"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("admin must not grant admin, got %v", err)
+	}
+	if _, err := svc.AddMember(alice, p.UUID, MemberInput{UserID: 4, Role: "viewer"}); err != nil {
+		t.Fatalf("admin adds viewer: %v", err)
+	}
+	if _, err := svc.AddMember(alice, p.UUID, MemberInput{UserID: 4}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("duplicate member must be rejected, got %v", err)
+	}
+	bob := as(4, models.RoleUser)
+	if _, err := svc.AddMember(bob, p.UUID, MemberInput{UserID: 5}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("viewer must not add members, got %v", err)
+	}
+	if err := svc.RemoveMember(alice, p.UUID, 2); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("owner cannot be removed, got %v", err)
+	}
+
+	scope, err := svc.ResolveScope(context.Background(), shared.PrincipalFrom(bob), p.UUID)
+	if err != nil || scope.Role != shared.ProjectRoleViewer || *scope.ProjectID != p.ID {
+		t.Fatalf("viewer scope: %v %+v", err, scope)
+	}
+	if scope.CanWrite(shared.ProjectRoleMember) {
+		t.Fatal("viewer must be read-only")
+	}
+	if _, err := svc.ResolveScope(context.Background(), shared.PrincipalFrom(as(5, models.RoleUser)), p.UUID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("non-member must be rejected, got %v", err)
+	}
+	admin, _ := svc.ResolveScope(context.Background(), shared.PrincipalFrom(as(1, models.RoleAdmin)), "")
+	if !admin.All {
+		t.Fatal("system admin without project should see everything")
+	}
+	personal, _ := svc.ResolveScope(context.Background(), shared.PrincipalFrom(bob), "")
+	if personal.ProjectID != nil || personal.OwnerID != 4 || !personal.Allows(nil, 4) || personal.Allows(nil, 3) || personal.Allows(&p.ID, 4) {
+		t.Fatalf("personal scope: %+v", personal)
+	}
+
+	key := &shared.Principal{UserID: 3, Role: string(models.RoleUser), APIKeyID: 9, ProjectID: &p.ID}
+	if _, err := svc.ResolveScope(context.Background(), key, "other"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("project-bound key must not switch project, got %v", err)
+	}
+	if s, err := svc.ResolveScope(context.Background(), key, ""); err != nil || *s.ProjectID != p.ID {
+		t.Fatalf("project-bound key scope: %v %+v", err, s)
+	}
+
+	if _, err := svc.ArchiveProject(alice, p.UUID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("only the owner archives, got %v", err)
+	}
+	if _, err := svc.ArchiveProject(owner, p.UUID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if s, _ := svc.ResolveScope(context.Background(), shared.PrincipalFrom(alice), p.UUID); s.CanWrite(shared.ProjectRoleMember) {
+		t.Fatal("archived project must be read-only")
+	}
+
+	list, _ := svc.ListProjects(as(5, models.RoleUser))
+	if len(list) != 0 {
+		t.Fatalf("non-member should see no projects, got %d", len(list))
+	}
+	if err := svc.RemoveMember(bob, p.UUID, 4); err != nil {
+		t.Fatalf("member leaves: %v", err)
+	}
+}
+
+type auditSpy struct {
+	events []shared.AuditEvent
+}
+
+func (a *auditSpy) Record(_ context.Context, e shared.AuditEvent) {
+	a.events = append(a.events, e)
+}
+
+func TestMemberChangesAreAudited(t *testing.T) {
+	repo := &memRepo{users: map[uint]*models.User{
+		2: {UUIDModel: models.UUIDModel{ID: 2}, Username: "owner"},
+		3: {UUIDModel: models.UUIDModel{ID: 3}, Username: "alice"},
+	}}
+	svc := NewServiceWithDeps(repo)
+	spy := &auditSpy{}
+	svc.SetAuditRecorder(spy)
+	owner := as(2, models.RoleUser)
+
+	name := "Audit"
+	p, err := svc.CreateProject(owner, ProjectInput{Name: &name})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.AddMember(owner, p.UUID, MemberInput{UserID: 3, Role: "viewer"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := svc.UpdateMemberRole(owner, p.UUID, 3, "member"); err != nil {
+		t.Fatalf("update role: %v", err)
+	}
+	if err := svc.RemoveMember(owner, p.UUID, 3); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	want := []string{"project.member_add", "project.member_role", "project.member_remove"}
+	if len(spy.events) != len(want) {
+		t.Fatalf("expected %d audit events, got %+v", len(want), spy.events)
+	}
+	for i, e := range spy.events {
+		if e.Action != want[i] || e.ResourceType != models.AuditResourceMember || e.ResourceID != p.UUID+"/3" {
+			t.Fatalf("event %d: %+v", i, e)
+		}
+	}
+	if before := spy.events[1].Before.(map[string]interface{}); before["role"] != models.ProjectRoleViewer {
+		t.Fatalf("role change must record the previous role, got %+v", before)
+	}
+}
@@ -0,0 +1,148 @@
+package project
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 项目仓储
+type Repository interface {
+	GetByID(ctx context.Context, id uint) (*models.Project, error)
+	GetByUUID(ctx context.Context, uuid string) (*models.Project, error)
+	UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error
+	// GetByTaskID 任务所属项目；任务未关联项目时返回 gorm.ErrRecordNotFound
+	GetByTaskID(ctx context.Context, taskID uint) (*models.Project, error)
+	// List 项目列表，userID 非 0 时只返回其为负责人或成员的项目
+	List(ctx context.Context, userID uint) ([]models.Project, error)
+	// Create 创建项目，并在同一事务中写入负责人成员记录
+	Create(ctx context.Context, p *models.Project) error
+
+	GetMember(ctx context.Context, projectID, userID uint) (*models.ProjectMember, error)
+	// ListMembers 项目成员（含用户信息）
+	ListMembers(ctx context.Context, projectID uint) ([]models.ProjectMember, error)
+	// ListMemberships 用户加入的全部项目成员记录
+	ListMemberships(ctx context.Context, userID uint) ([]models.ProjectMember, error)
+	CreateMember(ctx context.Context, m *models.ProjectMember) error
+	UpdateMemberRole(ctx context.Context, projectID, userID uint, role models.ProjectMemberRole) error
+	DeleteMember(ctx context.Context, projectID, userID uint) error
+	// GetUser 按 ID 或用户名/邮箱查询用户
+	GetUser(ctx context.Context, id uint, login string) (*models.User, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) GetByID(ctx context.Context, id uint) (*models.Project, error) {
+	var p models.Project
+	if err := r.db.WithContext(ctx).First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *gormRepository) GetByUUID(ctx context.Context, uuid string) (*models.Project, error) {
+	var p models.Project
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *gormRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Project{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *gormRepository) GetByTaskID(ctx context.Context, taskID uint) (*models.Project, error) {
+	var p models.Project
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN creative_tasks ON creative_tasks.project_id = projects.id").
+		Where("creative_tasks.id = ?", taskID).
+		First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *gormRepository) List(ctx context.Context, userID uint) ([]models.Project, error) {
+	db := r.db.WithContext(ctx).Model(&models.Project{})
+	if userID > 0 {
+		db = db.Where("owner_id = ? OR id IN (?)", userID,
+			r.db.Model(&models.ProjectMember{}).Select("project_id").Where("user_id = ?", userID))
+	}
+	var list []models.Project
+	if err := db.Order("created_at desc").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormRepository) Create(ctx context.Context, p *models.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ProjectMember{ProjectID: p.ID, UserID: p.OwnerID, Role: models.ProjectRoleOwner}).Error
+	})
+}
+
+func (r *gormRepository) GetMember(ctx context.Context, projectID, userID uint) (*models.ProjectMember, error) {
+	var m models.ProjectMember
+	if err := r.db.WithContext(ctx).Where("project_id = ? AND user_id = ?", projectID, userID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *gormRepository) ListMembers(ctx context.Context, projectID uint) ([]models.ProjectMember, error) {
+	var list []models.ProjectMember
+	if err := r.db.WithContext(ctx).Preload("User").Where("project_id = ?", projectID).Order("id asc").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormRepository) ListMemberships(ctx context.Context, userID uint) ([]models.ProjectMember, error) {
+	var list []models.ProjectMember
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *gormRepository) CreateMember(ctx context.Context, m *models.ProjectMember) error {
+	return r.db.WithContext(ctx).Create(m).Error
+}
+
+func (r *gormRepository) UpdateMemberRole(ctx context.Context, projectID, userID uint, role models.ProjectMemberRole) error {
+	return r.db.WithContext(ctx).Model(&models.ProjectMember{}).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Update("role", role).Error
+}
+
+func (r *gormRepository) DeleteMember(ctx context.Context, projectID, userID uint) error {
+	return r.db.WithContext(ctx).Where("project_id = ? AND user_id = ?", projectID, userID).
+		Delete(&models.ProjectMember{}).Error
+}
+
+func (r *gormRepository) GetUser(ctx context.Context, id uint, login string) (*models.User, error) {
+	db := r.db.WithContext(ctx)
+	if id > 0 {
+		db = db.Where("id = ?", id)
+	} else {
+		db = db.Where("username = ? OR email = ?", login, login)
+	}
+	var u models.User
+	if err := db.First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
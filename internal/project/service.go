@@ -0,0 +1,344 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound 项目不存在
+	ErrNotFound = errors.New("project not found")
+	// ErrInvalid 参数不合法
+	ErrInvalid = errors.New("invalid project input")
+	// ErrForbidden 项目角色不足或不是项目成员
+	ErrForbidden = errors.New("project permission denied")
+)
+
+// maxBrandColors 单个项目最多配置的品牌色数量
+const maxBrandColors = 16
+
+// Service 项目服务
+type Service struct {
+	repo      Repository
+	refresher ports.BrandConformanceRefresher
+	audit     ports.AuditRecorder
+}
+
+// NewService 创建服务
+func NewService() *Service {
+	return NewServiceWithDeps(NewRepository(database.DB))
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetConformanceRefresher 设置品牌色变更后重算素材符合度的回调（nil 表示不重算）
+func (s *Service) SetConformanceRefresher(r ports.BrandConformanceRefresher) {
+	s.refresher = r
+}
+
+// SetAuditRecorder 设置审计日志记录器（nil 表示不记录）
+func (s *Service) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// recordAudit 记录项目变更
+func (s *Service) recordAudit(ctx context.Context, action string, p *models.Project, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   p.UUID,
+		ProjectID:    &p.ID,
+		Before:       before,
+		After:        after,
+	})
+}
+
+// recordMemberAudit 记录项目成员变更，before/after 为成员角色（空表示不是成员）
+func (s *Service) recordMemberAudit(ctx context.Context, action string, p *models.Project, userID uint, before, after models.ProjectMemberRole) {
+	if s.audit == nil {
+		return
+	}
+	e := shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceMember,
+		ResourceID:   fmt.Sprintf("%s/%d", p.UUID, userID),
+		ProjectID:    &p.ID,
+	}
+	if before != "" {
+		e.Before = map[string]interface{}{"role": before}
+	}
+	if after != "" {
+		e.After = map[string]interface{}{"role": after}
+	}
+	s.audit.Record(ctx, e)
+}
+
+// BrandColors 项目品牌色，实现 ports.BrandColorSource
+func (s *Service) BrandColors(ctx context.Context, projectID uint) ([]string, error) {
+	p, err := s.repo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, wrapNotFound(err)
+	}
+	return p.BrandColors, nil
+}
+
+// RequiresApproval 素材所属项目是否要求审核通过，实现 ports.AssetReviewPolicy
+func (s *Service) RequiresApproval(ctx context.Context, asset *models.CreativeAsset) (bool, error) {
+	if asset == nil {
+		return false, nil
+	}
+	p, err := s.repo.GetByTaskID(ctx, asset.TaskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return p.Settings.RequireReview, nil
+}
+
+// SetRequireReview 开启/关闭项目的素材审核要求（项目管理员）
+func (s *Service) SetRequireReview(ctx context.Context, projectUUID string, required bool) (*models.Project, error) {
+	p, _, err := s.authorize(ctx, projectUUID, models.ProjectRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	settings := p.Settings
+	settings.RequireReview = required
+	if err := s.repo.UpdateFields(ctx, p.ID, map[string]interface{}{"settings": settings}); err != nil {
+		return nil, fmt.Errorf("update settings failed: %w", err)
+	}
+	s.recordAudit(ctx, audit.ActionProjectReviewPolicy, p,
+		map[string]interface{}{"require_review": p.Settings.RequireReview},
+		map[string]interface{}{"require_review": required})
+	p.Settings = settings
+	return p, nil
+}
+
+// GetProject 按 UUID 查询项目，当前用户须为项目成员
+func (s *Service) GetProject(ctx context.Context, projectUUID string) (*models.Project, error) {
+	p, _, err := s.authorize(ctx, projectUUID, models.ProjectRoleViewer)
+	return p, err
+}
+
+// GetBrandColors 按项目 UUID 查询品牌色
+func (s *Service) GetBrandColors(ctx context.Context, projectUUID string) (*models.Project, error) {
+	return s.GetProject(ctx, projectUUID)
+}
+
+// ProjectInput 创建/更新项目，更新时空字段保持不变
+type ProjectInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	// Status active | archived，仅负责人可修改
+	Status *string `json:"status"`
+	// MonthlyBudget 每月成本预算，0 表示不限
+	MonthlyBudget *float64 `json:"monthly_budget"`
+}
+
+// ProjectDTO 项目及当前用户在项目中的角色
+type ProjectDTO struct {
+	models.Project
+	Role string `json:"role,omitempty"`
+}
+
+// maxProjectNameLen 项目名称最大长度，与表结构一致
+const maxProjectNameLen = 128
+
+// ListProjects 当前用户可访问的项目；系统管理员可见全部项目，绑定项目的 API Key 只可见该项目
+func (s *Service) ListProjects(ctx context.Context) ([]ProjectDTO, error) {
+	principal := shared.PrincipalFrom(ctx)
+	var userID uint
+	if principal != nil && principal.Role != string(models.RoleAdmin) {
+		userID = principal.UserID
+	}
+	list, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list projects failed: %w", err)
+	}
+	roles := make(map[uint]models.ProjectMemberRole)
+	if principal != nil {
+		memberships, err := s.repo.ListMemberships(ctx, principal.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("list memberships failed: %w", err)
+		}
+		for _, m := range memberships {
+			roles[m.ProjectID] = m.Role
+		}
+	}
+	out := make([]ProjectDTO, 0, len(list))
+	for _, p := range list {
+		if principal != nil && principal.ProjectID != nil && *principal.ProjectID != p.ID {
+			continue
+		}
+		out = append(out, ProjectDTO{Project: p, Role: string(roleOf(principal, &p, roles[p.ID]))})
+	}
+	return out, nil
+}
+
+// CreateProject 创建项目，当前用户成为负责人
+func (s *Service) CreateProject(ctx context.Context, in ProjectInput) (*ProjectDTO, error) {
+	principal := shared.PrincipalFrom(ctx)
+	if principal == nil || principal.UserID == 0 {
+		return nil, fmt.Errorf("%w: login required", ErrForbidden)
+	}
+	if principal.ProjectID != nil || principal.Role == string(models.RoleViewer) {
+		return nil, fmt.Errorf("%w: cannot create projects", ErrForbidden)
+	}
+	if in.Name == nil {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	name, err := normalizeName(*in.Name)
+	if err != nil {
+		return nil, err
+	}
+	p := models.Project{
+		UUIDModel: models.UUIDModel{UUID: uuid.New().String()},
+		Name:      name,
+		OwnerID:   principal.UserID,
+		Status:    models.ProjectActive,
+	}
+	if in.Description != nil {
+		p.Description = strings.TrimSpace(*in.Description)
+	}
+	if err := s.repo.Create(ctx, &p); err != nil {
+		return nil, fmt.Errorf("create project failed: %w", err)
+	}
+	return &ProjectDTO{Project: p, Role: string(models.ProjectRoleOwner)}, nil
+}
+
+// UpdateProject 修改名称/描述/预算（管理员）或状态（负责人）
+func (s *Service) UpdateProject(ctx context.Context, projectUUID string, in ProjectInput) (*ProjectDTO, error) {
+	min := models.ProjectRoleAdmin
+	if in.Status != nil {
+		min = models.ProjectRoleOwner
+	}
+	p, role, err := s.authorize(ctx, projectUUID, min)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if in.Name != nil {
+		name, err := normalizeName(*in.Name)
+		if err != nil {
+			return nil, err
+		}
+		fields["name"] = name
+		p.Name = name
+	}
+	if in.Description != nil {
+		p.Description = strings.TrimSpace(*in.Description)
+		fields["description"] = p.Description
+	}
+	if in.Status != nil {
+		status := models.ProjectStatus(*in.Status)
+		if status != models.ProjectActive && status != models.ProjectArchived {
+			return nil, fmt.Errorf("%w: status must be active or archived", ErrInvalid)
+		}
+		fields["status"] = status
+		p.Status = status
+	}
+	if in.MonthlyBudget != nil {
+		if *in.MonthlyBudget < 0 {
+			return nil, fmt.Errorf("%w: monthly_budget must not be negative", ErrInvalid)
+		}
+		fields["monthly_budget"] = *in.MonthlyBudget
+		p.MonthlyBudget = *in.MonthlyBudget
+	}
+	if len(fields) > 0 {
+		if err := s.repo.UpdateFields(ctx, p.ID, fields); err != nil {
+			return nil, fmt.Errorf("update project failed: %w", err)
+		}
+	}
+	return &ProjectDTO{Project: *p, Role: string(role)}, nil
+}
+
+// ArchiveProject 归档项目（负责人），归档后项目数据只读
+func (s *Service) ArchiveProject(ctx context.Context, projectUUID string) (*ProjectDTO, error) {
+	status := string(models.ProjectArchived)
+	return s.UpdateProject(ctx, projectUUID, ProjectInput{Status: &status})
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if len([]rune(name)) > maxProjectNameLen {
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalid, maxProjectNameLen)
+	}
+	return name, nil
+}
+
+// SetBrandColors 设置品牌色并重算项目下素材的符合度，返回重算的素材数（项目管理员）
+func (s *Service) SetBrandColors(ctx context.Context, projectUUID string, colors []string) (*models.Project, int, error) {
+	normalized, err := NormalizeBrandColors(colors)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, _, err := s.authorize(ctx, projectUUID, models.ProjectRoleAdmin)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.repo.UpdateFields(ctx, p.ID, map[string]interface{}{"brand_colors": normalized}); err != nil {
+		return nil, 0, fmt.Errorf("update brand colors failed: %w", err)
+	}
+	s.recordAudit(ctx, audit.ActionProjectBrandColors, p,
+		map[string]interface{}{"brand_colors": p.BrandColors},
+		map[string]interface{}{"brand_colors": normalized})
+	p.BrandColors = normalized
+
+	updated := 0
+	if s.refresher != nil {
+		if updated, err = s.refresher.RefreshBrandConformance(ctx, p.ID); err != nil {
+			return p, updated, fmt.Errorf("refresh brand conformance failed: %w", err)
+		}
+	}
+	return p, updated, nil
+}
+
+// NormalizeBrandColors 校验并规范化为小写 #rrggbb，去重
+func NormalizeBrandColors(colors []string) (models.StringArray, error) {
+	if len(colors) > maxBrandColors {
+		return nil, fmt.Errorf("%w: at most %d brand colors", ErrInvalid, maxBrandColors)
+	}
+	out := make(models.StringArray, 0, len(colors))
+	seen := make(map[string]bool)
+	for _, c := range colors {
+		rgba, ok := imaging.ParseHexColor(c)
+		if !ok {
+			return nil, fmt.Errorf("%w: color %q must be #rrggbb", ErrInvalid, strings.TrimSpace(c))
+		}
+		hex := imaging.HexColor(rgba)
+		if !seen[hex] {
+			seen[hex] = true
+			out = append(out, hex)
+		}
+	}
+	return out, nil
+}
+
+func wrapNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/settings"
+)
+
+// 项目设置限制
+const (
+	maxDefaultStyles     = 10
+	maxStyleLen          = 64
+	maxPromptTemplateLen = 2000
+)
+
+// promptPlaceholders 提示词模板支持的占位符
+var promptPlaceholders = map[string]bool{
+	"{title}":          true,
+	"{selling_points}": true,
+	"{style}":          true,
+	"{cta}":            true,
+}
+
+var placeholderPattern = regexp.MustCompile(`\{[A-Za-z_]+\}`)
+
+// ProjectSettings 项目默认设置，实现 ports.ProjectSettingsSource
+func (s *Service) ProjectSettings(ctx context.Context, projectID uint) (*models.ProjectSettings, error) {
+	p, err := s.repo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, wrapNotFound(err)
+	}
+	settings := p.Settings
+	return &settings, nil
+}
+
+// UpdateSettings 校验并整体替换项目默认设置（项目管理员）；
+// 审核要求只能通过 SetRequireReview 修改，这里保留已存储的值
+func (s *Service) UpdateSettings(ctx context.Context, projectUUID string, in models.ProjectSettings) (*models.Project, error) {
+	normalized, err := NormalizeSettings(in)
+	if err != nil {
+		return nil, err
+	}
+	p, _, err := s.authorize(ctx, projectUUID, models.ProjectRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	normalized.RequireReview = p.Settings.RequireReview
+	if err := s.repo.UpdateFields(ctx, p.ID, map[string]interface{}{"settings": normalized}); err != nil {
+		return nil, fmt.Errorf("update settings failed: %w", err)
+	}
+	s.recordAudit(ctx, audit.ActionProjectSettings, p, p.Settings, normalized)
+	p.Settings = normalized
+	return p, nil
+}
+
+// NormalizeSettings 校验项目设置：格式与语言须受支持，风格去空去重，模板只能使用已知占位符
+func NormalizeSettings(in models.ProjectSettings) (models.ProjectSettings, error) {
+	out := models.ProjectSettings{RequireReview: in.RequireReview}
+
+	seen := make(map[string]bool)
+	for _, f := range in.DefaultFormats {
+		f = strings.TrimSpace(f)
+		if !settings.IsSupportedFormat(f) {
+			return out, fmt.Errorf("%w: format %q must be one of %s", ErrInvalid, f, strings.Join(settings.SupportedFormats, ", "))
+		}
+		if !seen[f] {
+			seen[f] = true
+			out.DefaultFormats = append(out.DefaultFormats, f)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, style := range in.DefaultStyles {
+		style = strings.TrimSpace(style)
+		if style == "" || seen[style] {
+			continue
+		}
+		if len([]rune(style)) > maxStyleLen {
+			return out, fmt.Errorf("%w: style must be at most %d characters", ErrInvalid, maxStyleLen)
+		}
+		seen[style] = true
+		out.DefaultStyles = append(out.DefaultStyles, style)
+	}
+	if len(out.DefaultStyles) > maxDefaultStyles {
+		return out, fmt.Errorf("%w: at most %d default styles", ErrInvalid, maxDefaultStyles)
+	}
+
+	if in.NumVariants < 0 || in.NumVariants > settings.MaxNumVariants {
+		return out, fmt.Errorf("%w: num_variants must be between 0 and %d", ErrInvalid, settings.MaxNumVariants)
+	}
+	out.NumVariants = in.NumVariants
+
+	out.CopyLanguage = strings.ToLower(strings.TrimSpace(in.CopyLanguage))
+	if out.CopyLanguage != "" && out.CopyLanguage != "zh" && out.CopyLanguage != "en" {
+		return out, fmt.Errorf("%w: copy_language must be zh or en", ErrInvalid)
+	}
+
+	out.PromptTemplate = strings.TrimSpace(in.PromptTemplate)
+	if len([]rune(out.PromptTemplate)) > maxPromptTemplateLen {
+		return out, fmt.Errorf("%w: prompt_template must be at most %d characters", ErrInvalid, maxPromptTemplateLen)
+	}
+	for _, ph := range placeholderPattern.FindAllString(out.PromptTemplate, -1) {
+		if !promptPlaceholders[ph] {
+			return out, fmt.Errorf("%w: unknown placeholder %s in prompt_template", ErrInvalid, ph)
+		}
+	}
+
+	out.ImageProvider = strings.TrimSpace(in.ImageProvider)
+	if out.ImageProvider != "" && !supportedImageProvider(out.ImageProvider) {
+		return out, fmt.Errorf("%w: image_provider %q is not enabled", ErrInvalid, out.ImageProvider)
+	}
+	return out, nil
+}
+
+// supportedImageProvider 模型须在 TONGYI_IMAGE_MODELS 中启用；未加载配置时（如测试）不限制
+func supportedImageProvider(model string) bool {
+	if config.TongyiConfig == nil {
+		return true
+	}
+	for _, m := range config.TongyiConfig.ImageModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
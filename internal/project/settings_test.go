@@ -0,0 +1,65 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+func TestNormalizeSettings(t *testing.T) {
+	got, err := NormalizeSettings(models.ProjectSettings{
+		DefaultFormats: []string{" 16:9", "16:9", "1:1"},
+		DefaultStyles:  []string{"minimal", " ", "minimal", "neon"},
+		NumVariants:    4,
+		CopyLanguage:   "EN",
+		PromptTemplate: "{title}, {style}",
+		RequireReview:  true,
+	})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if len(got.DefaultFormats) != 2 || len(got.DefaultStyles) != 2 || got.CopyLanguage != "en" || !got.RequireReview {
+		t.Fatalf("unexpected settings: %+v", got)
+	}
+
+	invalid := []models.ProjectSettings{
+		{DefaultFormats: []string{"2:1"}},
+		{NumVariants: 99},
+		{CopyLanguage: "fr"},
+		{PromptTemplate: "{title} {brand}"},
+	}
+	for _, in := range invalid {
+		if _, err := NormalizeSettings(in); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("expected ErrInvalid for %+v, got %v", in, err)
+		}
+	}
+
+	var legacy models.ProjectSettings
+	if err := legacy.Scan([]byte(`{"require_review":true,"unknown":1}`)); err != nil || !legacy.RequireReview {
+		t.Fatalf("legacy settings should load: %v %+v", err, legacy)
+	}
+}
+
+func TestUpdateSettingsKeepsReviewPolicy(t *testing.T) {
+	repo := &memRepo{users: map[uint]*models.User{}}
+	svc := NewServiceWithDeps(repo)
+	owner := as(2, models.RoleUser)
+	name := "Spring campaign"
+	p, err := svc.CreateProject(owner, ProjectInput{Name: &name})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.SetRequireReview(owner, p.UUID, true); err != nil {
+		t.Fatalf("enable review: %v", err)
+	}
+
+	if _, err := svc.UpdateSettings(owner, p.UUID, models.ProjectSettings{DefaultFormats: []string{"1:1"}}); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	got, err := svc.ProjectSettings(context.Background(), p.ID)
+	if err != nil || !got.RequireReview || len(got.DefaultFormats) != 1 {
+		t.Fatalf("settings update must keep review enforcement: %v %+v", err, got)
+	}
+}
@@ -0,0 +1,37 @@
+package quota
+
+import (
+	"net/http"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 配额接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务（供生成模块扣减配额）
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// Usage 当前用户与项目的配额用量
+func (h *Handler) Usage(c *gin.Context) {
+	report, err := h.service.Usage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(report))
+}
@@ -0,0 +1,53 @@
+package quota
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository 配额用量仓储
+type Repository interface {
+	// Get 主体在某周期内各指标的已用量
+	Get(ctx context.Context, subjectType string, subjectID uint, period string) (map[string]int64, error)
+	// Increment 累加用量，不存在时创建
+	Increment(ctx context.Context, subjectType string, subjectID uint, period, metric string, n int64) error
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Get(ctx context.Context, subjectType string, subjectID uint, period string) (map[string]int64, error) {
+	var rows []models.UsageCounter
+	err := r.db.WithContext(ctx).
+		Where("subject_type = ? AND subject_id = ? AND period = ?", subjectType, subjectID, period).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		out[row.Metric] = row.Used
+	}
+	return out, nil
+}
+
+func (r *gormRepository) Increment(ctx context.Context, subjectType string, subjectID uint, period, metric string, n int64) error {
+	row := models.UsageCounter{SubjectType: subjectType, SubjectID: subjectID, Period: period, Metric: metric, Used: n}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject_type"}, {Name: "subject_id"}, {Name: "period"}, {Name: "metric"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"used":       gorm.Expr("usage_counters.used + ?", n),
+			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		}),
+	}).Create(&row).Error
+}
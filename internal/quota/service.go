@@ -0,0 +1,207 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+)
+
+// 配额主体与周期
+const (
+	SubjectUser    = "user"
+	SubjectProject = "project"
+	WindowDaily    = "daily"
+	WindowMonthly  = "monthly"
+)
+
+// Limits 各主体在各周期的上限
+type Limits struct {
+	UserDaily      config.QuotaLimits
+	UserMonthly    config.QuotaLimits
+	ProjectDaily   config.QuotaLimits
+	ProjectMonthly config.QuotaLimits
+}
+
+// Service 配额服务：按自然日 / 自然月计数
+type Service struct {
+	repo   Repository
+	limits Limits
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewService 创建服务，上限取自配置
+func NewService() *Service {
+	svc := NewServiceWithDeps(NewRepository(database.DB))
+	if c := config.QuotaConfig; c != nil {
+		svc.limits = Limits{UserDaily: c.UserDaily, UserMonthly: c.UserMonthly, ProjectDaily: c.ProjectDaily, ProjectMonthly: c.ProjectMonthly}
+	}
+	return svc
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// SetLimits 设置配额上限
+func (s *Service) SetLimits(l Limits) {
+	s.limits = l
+}
+
+// window 一个主体在一个周期内的计数范围
+type window struct {
+	subject   string
+	subjectID uint
+	name      string
+	period    string
+	resetAt   time.Time
+	limits    config.QuotaLimits
+}
+
+// windows 当前用户与项目的日、月计数范围
+func (s *Service) windows(userID uint, projectID *uint) []window {
+	now := s.now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	var out []window
+	add := func(subject string, id uint, daily, monthly config.QuotaLimits) {
+		out = append(out,
+			window{subject, id, WindowDaily, day.Format("2006-01-02"), day.AddDate(0, 0, 1), daily},
+			window{subject, id, WindowMonthly, month.Format("2006-01"), month.AddDate(0, 1, 0), monthly},
+		)
+	}
+	if userID > 0 {
+		add(SubjectUser, userID, s.limits.UserDaily, s.limits.UserMonthly)
+	}
+	if projectID != nil {
+		add(SubjectProject, *projectID, s.limits.ProjectDaily, s.limits.ProjectMonthly)
+	}
+	return out
+}
+
+// Consume 检查并扣减配额，实现 ports.QuotaGuard；任一上限将被突破时整体拒绝，不扣减
+func (s *Service) Consume(ctx context.Context, projectID *uint, usage shared.Usage) error {
+	windows := s.windows(shared.UserIDFrom(ctx), projectID)
+	if len(windows) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range windows {
+		used, err := s.repo.Get(ctx, w.subject, w.subjectID, w.period)
+		if err != nil {
+			return fmt.Errorf("load quota usage failed: %w", err)
+		}
+		limits := limitMap(w.limits)
+		for metric, n := range usage.Metrics() {
+			if n <= 0 || limits[metric] <= 0 {
+				continue
+			}
+			if used[metric]+n > limits[metric] {
+				return &shared.QuotaError{
+					Subject:   w.subject,
+					SubjectID: w.subjectID,
+					Window:    w.name,
+					Metric:    metric,
+					Limit:     limits[metric],
+					Used:      used[metric],
+					ResetAt:   w.resetAt,
+				}
+			}
+		}
+	}
+	for _, w := range windows {
+		for metric, n := range usage.Metrics() {
+			if n <= 0 {
+				continue
+			}
+			if err := s.repo.Increment(ctx, w.subject, w.subjectID, w.period, metric, n); err != nil {
+				return fmt.Errorf("record quota usage failed: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// MetricUsage 单项指标用量；Limit 为 0 表示不限，此时 Remaining 为空
+type MetricUsage struct {
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Remaining *int64 `json:"remaining"`
+}
+
+// WindowUsage 一个周期内的用量
+type WindowUsage struct {
+	Period   string      `json:"period"`
+	ResetsAt time.Time   `json:"resets_at"`
+	Tasks    MetricUsage `json:"tasks"`
+	Images   MetricUsage `json:"images"`
+	LLMCalls MetricUsage `json:"llm_calls"`
+}
+
+// SubjectUsage 用户或项目的日、月用量
+type SubjectUsage struct {
+	ID      uint        `json:"id"`
+	Daily   WindowUsage `json:"daily"`
+	Monthly WindowUsage `json:"monthly"`
+}
+
+// UsageReport 当前用户与当前项目（如有）的配额使用情况
+type UsageReport struct {
+	User    *SubjectUsage `json:"user,omitempty"`
+	Project *SubjectUsage `json:"project,omitempty"`
+}
+
+// Usage 查询当前用户与请求范围内项目的用量
+func (s *Service) Usage(ctx context.Context) (*UsageReport, error) {
+	report := &UsageReport{}
+	for _, w := range s.windows(shared.UserIDFrom(ctx), shared.ProjectIDFrom(ctx)) {
+		used, err := s.repo.Get(ctx, w.subject, w.subjectID, w.period)
+		if err != nil {
+			return nil, fmt.Errorf("load quota usage failed: %w", err)
+		}
+		wu := WindowUsage{
+			Period:   w.period,
+			ResetsAt: w.resetAt,
+			Tasks:    metricUsage(used[shared.MetricTasks], w.limits.Tasks),
+			Images:   metricUsage(used[shared.MetricImages], w.limits.Images),
+			LLMCalls: metricUsage(used[shared.MetricLLMCalls], w.limits.LLMCalls),
+		}
+		target := &report.User
+		if w.subject == SubjectProject {
+			target = &report.Project
+		}
+		if *target == nil {
+			*target = &SubjectUsage{ID: w.subjectID}
+		}
+		if w.name == WindowDaily {
+			(*target).Daily = wu
+		} else {
+			(*target).Monthly = wu
+		}
+	}
+	return report, nil
+}
+
+func metricUsage(used, limit int64) MetricUsage {
+	m := MetricUsage{Used: used, Limit: limit}
+	if limit > 0 {
+		remaining := limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		m.Remaining = &remaining
+	}
+	return m
+}
+
+func limitMap(l config.QuotaLimits) map[string]int64 {
+	return map[string]int64{shared.MetricTasks: l.Tasks, shared.MetricImages: l.Images, shared.MetricLLMCalls: l.LLMCalls}
+}
@@ -0,0 +1,82 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+type memRepo struct {
+	used map[string]int64
+}
+
+func (m *memRepo) Get(_ context.Context, subjectType string, subjectID uint, period string) (map[string]int64, error) {
+	out := map[string]int64{}
+	prefix := fmt.Sprintf("%s/%d/%s/", subjectType, subjectID, period)
+	for k, v := range m.used {
+		if len(k) > len(prefix) && k[:len(prefix)] == prefix {
+			out[k[len(prefix):]] = v
+		}
+	}
+	return out, nil
+}
+
+func (m *memRepo) Increment(_ context.Context, subjectType string, subjectID uint, period, metric string, n int64) error {
+	m.used[fmt.Sprintf("%s/%d/%s/%s", subjectType, subjectID, period, metric)] += n
+	return nil
+}
+
+func TestConsumeEnforcesUserAndProjectLimits(t *testing.T) {
+	repo := &memRepo{used: map[string]int64{}}
+	svc := NewServiceWithDeps(repo)
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.SetLimits(Limits{
+		UserDaily:    config.QuotaLimits{Tasks: 2, Images: 5},
+		ProjectDaily: config.QuotaLimits{LLMCalls: 1},
+	})
+	projectID := uint(7)
+	ctx := shared.WithPrincipal(context.Background(), &shared.Principal{UserID: 3})
+
+	if err := svc.Consume(ctx, &projectID, shared.Usage{Tasks: 1, Images: 4}); err != nil {
+		t.Fatalf("within limits: %v", err)
+	}
+	err := svc.Consume(ctx, &projectID, shared.Usage{Tasks: 1, Images: 2})
+	var qe *shared.QuotaError
+	if !errors.As(err, &qe) || qe.Subject != SubjectUser || qe.Metric != shared.MetricImages || qe.Used != 4 {
+		t.Fatalf("image limit should reject, got %v", err)
+	}
+	if !qe.ResetAt.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) || qe.RetryAfter(now) != 9*time.Hour {
+		t.Fatalf("daily quota resets at midnight: %s", qe.ResetAt)
+	}
+	if repo.used["user/3/2026-10-19/tasks"] != 1 {
+		t.Fatal("rejected request must not consume quota")
+	}
+
+	if err := svc.Consume(ctx, &projectID, shared.Usage{LLMCalls: 1}); err != nil {
+		t.Fatalf("first llm call: %v", err)
+	}
+	other := shared.WithPrincipal(context.Background(), &shared.Principal{UserID: 4})
+	if err := svc.Consume(other, &projectID, shared.Usage{LLMCalls: 1}); !errors.As(err, &qe) || qe.Subject != SubjectProject {
+		t.Fatalf("project limit is shared by members, got %v", err)
+	}
+	if err := svc.Consume(other, nil, shared.Usage{LLMCalls: 1}); err != nil {
+		t.Fatalf("personal space is not bound by project limits: %v", err)
+	}
+
+	report, err := svc.Usage(shared.WithScope(ctx, &shared.ProjectScope{ProjectID: &projectID}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.User.Daily.Images.Used != 4 || *report.User.Daily.Images.Remaining != 1 || report.User.Monthly.Images.Remaining != nil {
+		t.Fatalf("user usage: %+v", report.User)
+	}
+	if report.Project == nil || report.Project.Daily.LLMCalls.Used != 1 || report.Project.Monthly.Period != "2026-10" {
+		t.Fatalf("project usage: %+v", report.Project)
+	}
+}
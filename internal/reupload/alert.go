@@ -0,0 +1,67 @@
+package reupload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Alert 补传告警内容
+type Alert struct {
+	AssetID   uint      `json:"asset_id"`
+	AssetUUID string    `json:"asset_uuid"`
+	SourceURL string    `json:"source_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+}
+
+// Alerter 告警通道
+type Alerter interface {
+	Alert(ctx context.Context, a Alert)
+}
+
+// LogAlerter 只写日志
+type LogAlerter struct{}
+
+func (LogAlerter) Alert(_ context.Context, a Alert) {
+	log.Printf("[补传告警] 素材 %s 的临时 URL 将于 %s 过期，已重试 %d 次仍未转存: %s",
+		a.AssetUUID, a.ExpiresAt.Format(time.RFC3339), a.Attempts, a.LastError)
+}
+
+// WebhookAlerter 写日志并 POST JSON 到 webhook
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter 创建 webhook 告警
+func NewWebhookAlerter(url string) *WebhookAlerter {
+	return &WebhookAlerter{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *WebhookAlerter) Alert(ctx context.Context, a Alert) {
+	LogAlerter{}.Alert(ctx, a)
+	body, err := json.Marshal(map[string]interface{}{
+		"event": "asset_reupload_at_risk",
+		"data":  a,
+	})
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[补传告警] 创建 webhook 请求失败: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		log.Printf("[补传告警] webhook 发送失败: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
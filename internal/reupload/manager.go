@@ -0,0 +1,227 @@
+package reupload
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+)
+
+// Config 补传任务配置
+type Config struct {
+	Interval       time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	ProviderURLTTL time.Duration // 服务商临时 URL 有效期（自素材创建起算）
+	AlertBefore    time.Duration // 距过期不足该时长仍未成功时告警
+}
+
+// Stats 补传状态
+type Stats struct {
+	Runs        int                             `json:"runs"`
+	LastRun     *time.Time                      `json:"last_run,omitempty"`
+	LastError   string                          `json:"last_error,omitempty"`
+	Processed   int                             `json:"processed"`
+	Succeeded   int                             `json:"succeeded"`
+	Failed      int                             `json:"failed"`
+	Alerts      int                             `json:"alerts"`
+	JobStatuses map[models.ReuploadStatus]int64 `json:"job_statuses,omitempty"`
+}
+
+// Manager 周期性地把仍指向临时 URL 的素材重新上传到存储后端
+type Manager struct {
+	cfg     Config
+	store   Store
+	backend ports.StorageBackend
+	alerter Alerter
+	now     func() time.Time
+
+	runMu sync.Mutex
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New 创建 Manager，alerter 为空时只打日志
+func New(cfg Config, store Store, backend ports.StorageBackend, alerter Alerter) *Manager {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Minute
+	}
+	if cfg.MaxBackoff < cfg.BaseBackoff {
+		cfg.MaxBackoff = cfg.BaseBackoff
+	}
+	if cfg.ProviderURLTTL <= 0 {
+		cfg.ProviderURLTTL = 24 * time.Hour
+	}
+	if alerter == nil {
+		alerter = LogAlerter{}
+	}
+	return &Manager{
+		cfg:     cfg,
+		store:   store,
+		backend: backend,
+		alerter: alerter,
+		now:     time.Now,
+	}
+}
+
+// Start 异步启动补传循环
+func (m *Manager) Start() {
+	go func() {
+		m.RunNow()
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.RunNow()
+		}
+	}()
+}
+
+// RunNow 立即执行一轮补传，同一时间只运行一轮
+func (m *Manager) RunNow() {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	now := m.now()
+	var processed, succeeded, failed, alerts int
+	var lastErr string
+
+	if m.backend == nil || m.backend.Type() == models.StorageProvider {
+		lastErr = "no storage backend configured"
+	} else if candidates, err := m.store.Candidates(context.Background(), now, m.cfg.BatchSize); err != nil {
+		lastErr = err.Error()
+	} else {
+		for _, c := range candidates {
+			ok, alerted, err := m.process(c, now)
+			processed++
+			if ok {
+				succeeded++
+			} else {
+				failed++
+			}
+			if alerted {
+				alerts++
+			}
+			if err != nil {
+				lastErr = err.Error()
+			}
+		}
+	}
+
+	counts, _ := m.store.Counts(context.Background())
+
+	m.mu.Lock()
+	m.stats.Runs++
+	m.stats.LastRun = &now
+	m.stats.LastError = lastErr
+	m.stats.Processed += processed
+	m.stats.Succeeded += succeeded
+	m.stats.Failed += failed
+	m.stats.Alerts += alerts
+	m.stats.JobStatuses = counts
+	m.mu.Unlock()
+
+	if processed > 0 {
+		log.Printf("[补传] 本轮处理 %d 个素材，成功 %d，失败 %d", processed, succeeded, failed)
+	}
+}
+
+// Stats 返回当前状态
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// process 处理单个素材，返回是否成功、是否触发告警
+func (m *Manager) process(c Candidate, now time.Time) (bool, bool, error) {
+	asset := c.Asset
+	job := c.Job
+	if job == nil {
+		job = &models.AssetReupload{
+			AssetID:   asset.ID,
+			Status:    models.ReuploadPending,
+			ExpiresAt: asset.CreatedAt.Add(m.cfg.ProviderURLTTL),
+		}
+	}
+
+	source := asset.OriginalPath
+	if source == "" {
+		source = asset.PublicURL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	key := m.backend.GenerateKey(asset.UUID)
+	url, err := m.backend.UploadFromURL(ctx, source, key)
+	job.Attempts++
+	if err == nil {
+		if err = m.store.Complete(ctx, &asset, job, url, key, m.backend.Type()); err == nil {
+			log.Printf("[补传] 素材 %s 已转存: %s", asset.UUID, url)
+			return true, false, nil
+		}
+		// 对象已上传但落库失败：删除孤儿对象，等待下次重试
+		_ = m.backend.Delete(ctx, key)
+	}
+
+	job.LastError = err.Error()
+	job.NextAttemptAt = now.Add(m.backoff(job.Attempts))
+	if job.Attempts >= m.cfg.MaxAttempts {
+		job.Status = models.ReuploadFailed
+	}
+
+	alerted := false
+	if job.AlertedAt == nil && m.shouldAlert(job, now) {
+		m.alerter.Alert(ctx, Alert{
+			AssetID:   asset.ID,
+			AssetUUID: asset.UUID,
+			SourceURL: source,
+			ExpiresAt: job.ExpiresAt,
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+		})
+		job.AlertedAt = &now
+		alerted = true
+	}
+
+	if saveErr := m.store.SaveJob(ctx, job); saveErr != nil {
+		return false, alerted, fmt.Errorf("save reupload job failed: %w", saveErr)
+	}
+	return false, alerted, fmt.Errorf("reupload asset %s failed: %w", asset.UUID, err)
+}
+
+// backoff 指数退避：base * 2^(attempts-1)，不超过 MaxBackoff
+func (m *Manager) backoff(attempts int) time.Duration {
+	d := m.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= m.cfg.MaxBackoff {
+			return m.cfg.MaxBackoff
+		}
+	}
+	return d
+}
+
+// shouldAlert 已放弃重试、已进入告警窗口，或退避后的下次重试晚于告警窗口起点（赶不上过期前补传）时告警
+func (m *Manager) shouldAlert(job *models.AssetReupload, now time.Time) bool {
+	if job.Status == models.ReuploadFailed {
+		return true
+	}
+	alertAt := job.ExpiresAt.Add(-m.cfg.AlertBefore)
+	return !now.Before(alertAt) || job.NextAttemptAt.After(alertAt)
+}
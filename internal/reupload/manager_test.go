@@ -0,0 +1,138 @@
+package reupload
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+type fakeStore struct {
+	candidates []Candidate
+	saved      []models.AssetReupload
+	completed  []string
+}
+
+func (s *fakeStore) Candidates(context.Context, time.Time, int) ([]Candidate, error) {
+	return s.candidates, nil
+}
+
+func (s *fakeStore) SaveJob(_ context.Context, job *models.AssetReupload) error {
+	s.saved = append(s.saved, *job)
+	return nil
+}
+
+func (s *fakeStore) Complete(_ context.Context, _ *models.CreativeAsset, job *models.AssetReupload, publicURL, _ string, _ models.StorageType) error {
+	job.Status = models.ReuploadSucceeded
+	s.completed = append(s.completed, publicURL)
+	return nil
+}
+
+func (s *fakeStore) Counts(context.Context) (map[models.ReuploadStatus]int64, error) {
+	return nil, nil
+}
+
+type fakeBackend struct {
+	err error
+}
+
+func (b *fakeBackend) Type() models.StorageType       { return models.StorageLocal }
+func (b *fakeBackend) GenerateKey(name string) string { return "k/" + name }
+func (b *fakeBackend) UploadFromURL(_ context.Context, _, key string) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return "https://cdn.example.com/" + key, nil
+}
+func (b *fakeBackend) Put(context.Context, string, []byte, string) (string, error) { return "", nil }
+func (b *fakeBackend) Delete(context.Context, string) error                        { return nil }
+func (b *fakeBackend) PublicURL(key string) string                                 { return key }
+
+type recordingAlerter struct{ alerts []Alert }
+
+func (r *recordingAlerter) Alert(_ context.Context, a Alert) { r.alerts = append(r.alerts, a) }
+
+func TestBackoffIsCapped(t *testing.T) {
+	m := New(Config{BaseBackoff: time.Minute, MaxBackoff: 5 * time.Minute}, &fakeStore{}, nil, nil)
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := m.backoff(i + 1); got != w {
+			t.Fatalf("attempt %d: want %s, got %s", i+1, w, got)
+		}
+	}
+}
+
+func TestRunNowSuccess(t *testing.T) {
+	asset := models.CreativeAsset{UUIDModel: models.UUIDModel{UUID: "a1"}, PublicURL: "https://provider/tmp.png"}
+	asset.ID = 1
+	store := &fakeStore{candidates: []Candidate{{Asset: asset}}}
+	m := New(Config{}, store, &fakeBackend{}, nil)
+	m.RunNow()
+
+	if len(store.completed) != 1 || store.completed[0] != "https://cdn.example.com/k/a1" {
+		t.Fatalf("unexpected completions: %v", store.completed)
+	}
+	if st := m.Stats(); st.Succeeded != 1 || st.Failed != 0 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestRunNowFailureBacksOffAndAlertsNearExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	asset := models.CreativeAsset{UUIDModel: models.UUIDModel{UUID: "a1"}, OriginalPath: "https://provider/tmp.png"}
+	asset.ID = 1
+	asset.CreatedAt = now.Add(-23 * time.Hour) // 1 小时后过期
+
+	store := &fakeStore{candidates: []Candidate{{Asset: asset}}}
+	alerter := &recordingAlerter{}
+	m := New(Config{BaseBackoff: time.Minute, MaxAttempts: 3, ProviderURLTTL: 24 * time.Hour, AlertBefore: 2 * time.Hour},
+		store, &fakeBackend{err: errors.New("boom")}, alerter)
+	m.now = func() time.Time { return now }
+	m.RunNow()
+
+	if len(store.saved) != 1 {
+		t.Fatalf("expected job to be saved, got %d", len(store.saved))
+	}
+	job := store.saved[0]
+	if job.Attempts != 1 || job.Status != models.ReuploadPending || !job.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	if len(alerter.alerts) != 1 || job.AlertedAt == nil {
+		t.Fatalf("expected one alert, got %d", len(alerter.alerts))
+	}
+
+	// 已告警的任务不重复告警；达到上限后标记失败
+	store.candidates = []Candidate{{Asset: asset, Job: &job}}
+	store.saved = nil
+	m.RunNow()
+	m.RunNow()
+	last := store.saved[len(store.saved)-1]
+	if last.Status != models.ReuploadFailed {
+		t.Fatalf("expected failed status, got %s", last.Status)
+	}
+	if len(alerter.alerts) != 1 {
+		t.Fatalf("expected no repeated alerts, got %d", len(alerter.alerts))
+	}
+}
+
+func TestShouldAlertWhenNextAttemptMissesAlertWindow(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	m := New(Config{AlertBefore: 2 * time.Hour}, &fakeStore{}, nil, nil)
+	job := &models.AssetReupload{Status: models.ReuploadPending, ExpiresAt: now.Add(6 * time.Hour)}
+
+	job.NextAttemptAt = now.Add(time.Hour)
+	if m.shouldAlert(job, now) {
+		t.Fatal("next attempt before the alert window should not alert")
+	}
+	// 退避后下次重试在过期前 1 小时，已晚于告警窗口起点（过期前 2 小时）
+	job.NextAttemptAt = now.Add(5 * time.Hour)
+	if !m.shouldAlert(job, now) {
+		t.Fatal("next attempt after the alert window start should alert now")
+	}
+	job.NextAttemptAt = now.Add(7 * time.Hour)
+	if !m.shouldAlert(job, now) {
+		t.Fatal("next attempt after expiry should alert now")
+	}
+}
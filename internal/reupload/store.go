@@ -0,0 +1,134 @@
+package reupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Candidate 待补传的素材及其补传记录（首次处理时 Job 为空）
+type Candidate struct {
+	Asset models.CreativeAsset
+	Job   *models.AssetReupload
+}
+
+// Store 补传任务的持久化
+type Store interface {
+	// Candidates 返回仍指向临时 URL 且已到重试时间的素材
+	Candidates(ctx context.Context, now time.Time, limit int) ([]Candidate, error)
+	SaveJob(ctx context.Context, job *models.AssetReupload) error
+	// Complete 在同一事务内更新素材、实验变体快照、任务首图并标记成功
+	Complete(ctx context.Context, asset *models.CreativeAsset, job *models.AssetReupload, publicURL, key string, storageType models.StorageType) error
+	Counts(ctx context.Context) (map[models.ReuploadStatus]int64, error)
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 基于 gorm 的 Store
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+// pendingAssetsScope 仍指向服务商临时 URL 的素材：
+// storage_type=provider，或旧版本上传失败时写入的 local + 外链 URL
+func pendingAssetsScope(db *gorm.DB) *gorm.DB {
+	return db.Where("creative_assets.storage_type = ? OR (creative_assets.storage_type = ? AND COALESCE(creative_assets.storage_key, '') = '' AND creative_assets.public_url LIKE ?)",
+		models.StorageProvider, models.StorageLocal, "http%")
+}
+
+func (s *gormStore) Candidates(ctx context.Context, now time.Time, limit int) ([]Candidate, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var assets []models.CreativeAsset
+	err := pendingAssetsScope(s.db.WithContext(ctx).Model(&models.CreativeAsset{})).
+		Joins("LEFT JOIN asset_reuploads ON asset_reuploads.asset_id = creative_assets.id").
+		Where("asset_reuploads.id IS NULL OR (asset_reuploads.status = ? AND asset_reuploads.next_attempt_at <= ?)", models.ReuploadPending, now).
+		Order("creative_assets.created_at asc").
+		Limit(limit).
+		Find(&assets).Error
+	if err != nil {
+		return nil, fmt.Errorf("query reupload candidates failed: %w", err)
+	}
+	if len(assets) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.ID)
+	}
+	var jobs []models.AssetReupload
+	if err := s.db.WithContext(ctx).Where("asset_id IN ?", ids).Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("query reupload jobs failed: %w", err)
+	}
+	byAsset := make(map[uint]*models.AssetReupload, len(jobs))
+	for i := range jobs {
+		byAsset[jobs[i].AssetID] = &jobs[i]
+	}
+
+	out := make([]Candidate, 0, len(assets))
+	for _, a := range assets {
+		out = append(out, Candidate{Asset: a, Job: byAsset[a.ID]})
+	}
+	return out, nil
+}
+
+func (s *gormStore) SaveJob(ctx context.Context, job *models.AssetReupload) error {
+	return s.db.WithContext(ctx).Save(job).Error
+}
+
+func (s *gormStore) Complete(ctx context.Context, asset *models.CreativeAsset, job *models.AssetReupload, publicURL, key string, storageType models.StorageType) error {
+	oldURL := asset.PublicURL
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.CreativeAsset{}).
+			Where("id = ? AND public_url = ?", asset.ID, oldURL).
+			Updates(map[string]interface{}{
+				"public_url":   publicURL,
+				"storage_key":  key,
+				"storage_type": storageType,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("update asset failed: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("asset changed concurrently")
+		}
+		if err := tx.Model(&models.ExperimentVariant{}).
+			Where("creative_id = ? AND image_url = ?", asset.ID, oldURL).
+			Update("image_url", publicURL).Error; err != nil {
+			return fmt.Errorf("update experiment variants failed: %w", err)
+		}
+		if err := tx.Model(&models.CreativeTask{}).
+			Where("id = ? AND first_asset_url = ?", asset.TaskID, oldURL).
+			Update("first_asset_url", publicURL).Error; err != nil {
+			return fmt.Errorf("update task first asset failed: %w", err)
+		}
+		job.Status = models.ReuploadSucceeded
+		job.LastError = ""
+		return tx.Save(job).Error
+	})
+}
+
+func (s *gormStore) Counts(ctx context.Context) (map[models.ReuploadStatus]int64, error) {
+	var rows []struct {
+		Status models.ReuploadStatus
+		Total  int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.AssetReupload{}).
+		Select("status, COUNT(*) AS total").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[models.ReuploadStatus]int64, len(rows))
+	for _, r := range rows {
+		out[r.Status] = r.Total
+	}
+	return out, nil
+}
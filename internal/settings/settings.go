@@ -19,10 +19,26 @@ const (
 	// DefaultFormat 默认格式
 	DefaultFormat = "1:1"
 
+	// MaxNumVariants 项目默认变体数量上限
+	MaxNumVariants = 10
+
 	// ModelName 模型名称
 	ModelName = "wanx-v1"
 )
 
+// SupportedFormats 支持的画面比例
+var SupportedFormats = []string{"1:1", "4:3", "3:4", "16:9", "9:16"}
+
+// IsSupportedFormat 是否为支持的画面比例
+func IsSupportedFormat(f string) bool {
+	for _, s := range SupportedFormats {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
 // 任务轮询配置
 const (
 	// MaxPollAttempts 最大轮询次数
@@ -33,6 +49,9 @@ const (
 
 	// TaskTimeout 任务总超时时间
 	TaskTimeout = 3 * time.Minute
+
+	// DefaultImageLatency 无历史 trace 时预估的单次图片生成耗时
+	DefaultImageLatency = 30 * time.Second
 )
 
 // 进度常量
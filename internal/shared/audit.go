@@ -0,0 +1,40 @@
+package shared
+
+import "context"
+
+// AuditEvent 一次变更操作，由业务层上报；操作者与请求信息由审计服务从 context 补全
+type AuditEvent struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	// ProjectID 资源所属项目，nil 时取当前请求的项目
+	ProjectID *uint
+	// Before/After 变更前后的状态（结构体或 map），只记录有差异的字段
+	Before interface{}
+	After  interface{}
+}
+
+// RequestMeta 请求元数据，用于审计
+type RequestMeta struct {
+	RequestID string
+	Method    string
+	Path      string
+	IP        string
+	UserAgent string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta 将请求元数据写入 context
+func WithRequestMeta(ctx context.Context, m *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, m)
+}
+
+// RequestMetaFrom 读取请求元数据；后台任务等非请求上下文返回 nil
+func RequestMetaFrom(ctx context.Context) *RequestMeta {
+	if ctx == nil {
+		return nil
+	}
+	m, _ := ctx.Value(requestMetaKey{}).(*RequestMeta)
+	return m
+}
@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+)
+
+// 计费调用类型
+const (
+	CostKindImage = "image"
+	CostKindLLM   = "llm"
+)
+
+// ModelUsage 一次模型调用的计费用量
+type ModelUsage struct {
+	Kind         string
+	Model        string
+	TaskID       *uint
+	UserID       uint
+	ProjectID    *uint
+	TraceID      string
+	Images       int64
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// BudgetError 项目本月成本（含进行中任务与本次调用的预估成本）将超出预算
+type BudgetError struct {
+	ProjectID uint
+	Budget    float64
+	Spent     float64
+	// Pending 进行中任务与本次调用的预估成本
+	Pending  float64
+	Currency string
+	ResetAt  time.Time
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("project %d monthly budget exceeded: spent %.2f (+%.2f pending) of %.2f %s, resets at %s",
+		e.ProjectID, e.Spent, e.Pending, e.Budget, e.Currency, e.ResetAt.Format(time.RFC3339))
+}
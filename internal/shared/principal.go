@@ -0,0 +1,66 @@
+package shared
+
+import "context"
+
+// Principal 当前请求的认证身份
+type Principal struct {
+	UserID   uint   `json:"user_id"`
+	UUID     string `json:"uuid,omitempty"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// SessionID 登录会话（刷新令牌）ID，匿名模式下为 0
+	SessionID uint `json:"-"`
+	// APIKeyID 通过 API Key 认证时非 0，此时只允许 Scopes 内的操作
+	APIKeyID  uint     `json:"api_key_id,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ProjectID *uint    `json:"project_id,omitempty"`
+}
+
+// HasScope 登录用户不受 scope 限制；API Key 需显式授予
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将认证身份写入 context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom 读取 context 中的认证身份，未认证时返回 nil
+func PrincipalFrom(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// UserIDFrom 当前用户 ID，未认证时返回 0
+func UserIDFrom(ctx context.Context) uint {
+	if p := PrincipalFrom(ctx); p != nil {
+		return p.UserID
+	}
+	return 0
+}
+
+// UserIDPtrFrom 当前用户 ID 指针，未认证时返回 nil
+func UserIDPtrFrom(ctx context.Context) *uint {
+	if p := PrincipalFrom(ctx); p != nil && p.UserID > 0 {
+		id := p.UserID
+		return &id
+	}
+	return nil
+}
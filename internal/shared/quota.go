@@ -0,0 +1,49 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+)
+
+// 配额指标
+const (
+	MetricTasks    = "tasks"
+	MetricImages   = "images"
+	MetricLLMCalls = "llm_calls"
+)
+
+// Usage 一次操作消耗的配额
+type Usage struct {
+	Tasks    int64 `json:"tasks"`
+	Images   int64 `json:"images"`
+	LLMCalls int64 `json:"llm_calls"`
+}
+
+// Metrics 按指标名展开，便于逐项检查
+func (u Usage) Metrics() map[string]int64 {
+	return map[string]int64{MetricTasks: u.Tasks, MetricImages: u.Images, MetricLLMCalls: u.LLMCalls}
+}
+
+// QuotaError 超出配额；Subject 为 user / project，Window 为 daily / monthly
+type QuotaError struct {
+	Subject   string
+	SubjectID uint
+	Window    string
+	Metric    string
+	Limit     int64
+	Used      int64
+	ResetAt   time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s %s quota exceeded for %s %d: %d/%d used, resets at %s",
+		e.Window, e.Metric, e.Subject, e.SubjectID, e.Used, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// RetryAfter 距配额重置的时长（至少 1 秒）
+func (e *QuotaError) RetryAfter(now time.Time) time.Duration {
+	if d := e.ResetAt.Sub(now); d > time.Second {
+		return d
+	}
+	return time.Second
+}
@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+)
+
+// 项目内角色，权限由低到高
+const (
+	ProjectRoleViewer = "viewer"
+	ProjectRoleMember = "member"
+	ProjectRoleAdmin  = "admin"
+	ProjectRoleOwner  = "owner"
+)
+
+var projectRoleRank = map[string]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleMember: 2,
+	ProjectRoleAdmin:  3,
+	ProjectRoleOwner:  4,
+}
+
+// RoleAtLeast 项目角色 role 是否不低于 min；未知角色视为无权限
+func RoleAtLeast(role, min string) bool {
+	r, ok := projectRoleRank[role]
+	return ok && r >= projectRoleRank[min]
+}
+
+// ValidProjectRole 是否为合法的项目角色
+func ValidProjectRole(role string) bool {
+	_, ok := projectRoleRank[role]
+	return ok
+}
+
+// ProjectScope 请求的数据可见范围：
+// All 为 true 时不限项目（系统管理员未指定项目）；
+// 否则只可见 ProjectID 下的数据，ProjectID 为 nil 表示个人空间（未归属项目的数据），
+// 此时 OwnerID 非 0 则只可见本人创建的数据
+type ProjectScope struct {
+	All       bool   `json:"all,omitempty"`
+	ProjectID *uint  `json:"project_id,omitempty"`
+	OwnerID   uint   `json:"owner_id,omitempty"`
+	Role      string `json:"-"`
+}
+
+// AllowsProject 数据所属项目是否在范围内，不校验创建人
+func (s *ProjectScope) AllowsProject(projectID *uint) bool {
+	if s == nil || s.All {
+		return true
+	}
+	if s.ProjectID == nil {
+		return projectID == nil
+	}
+	return projectID != nil && *projectID == *s.ProjectID
+}
+
+// Allows 数据（所属项目、创建人）是否在范围内；个人空间只可见本人创建的数据
+func (s *ProjectScope) Allows(projectID *uint, ownerID uint) bool {
+	if !s.AllowsProject(projectID) {
+		return false
+	}
+	return s == nil || s.All || s.ProjectID != nil || s.OwnerID == 0 || ownerID == s.OwnerID
+}
+
+// Condition 生成 SQL 过滤条件；ownerColumn 为空表示该表没有创建人字段。
+// 返回空字符串表示不过滤
+func (s *ProjectScope) Condition(projectColumn, ownerColumn string) (string, []interface{}) {
+	if s == nil || s.All {
+		return "", nil
+	}
+	if s.ProjectID != nil {
+		return projectColumn + " = ?", []interface{}{*s.ProjectID}
+	}
+	if s.OwnerID == 0 || ownerColumn == "" {
+		return projectColumn + " IS NULL", nil
+	}
+	return projectColumn + " IS NULL AND " + ownerColumn + " = ?", []interface{}{s.OwnerID}
+}
+
+// Key 缓存键片段，不同范围的列表结果互不复用
+func (s *ProjectScope) Key() string {
+	switch {
+	case s == nil || s.All:
+		return "all"
+	case s.ProjectID != nil:
+		return fmt.Sprintf("p%d", *s.ProjectID)
+	default:
+		return fmt.Sprintf("u%d", s.OwnerID)
+	}
+}
+
+// CanWrite 是否至少为 min 角色
+func (s *ProjectScope) CanWrite(min string) bool {
+	if s == nil {
+		return true
+	}
+	return RoleAtLeast(s.Role, min)
+}
+
+type scopeKey struct{}
+
+// WithScope 将数据范围写入 context
+func WithScope(ctx context.Context, s *ProjectScope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// ScopeFrom 读取 context 中的数据范围；后台任务等未设置范围时返回 nil，表示不限
+func ScopeFrom(ctx context.Context) *ProjectScope {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(scopeKey{}).(*ProjectScope)
+	return s
+}
+
+// ProjectIDFrom 当前操作所属项目：请求范围指定的项目，个人空间或未设置时返回 nil
+func ProjectIDFrom(ctx context.Context) *uint {
+	if s := ScopeFrom(ctx); s != nil && s.ProjectID != nil {
+		id := *s.ProjectID
+		return &id
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package shared
+
+import "strings"
+
+// ContainsTerm 判断已转小写的 text 是否包含 term：纯 ASCII 词按整词匹配（避免 order 命中 border），
+// 中日韩等其他词按子串匹配
+func ContainsTerm(text, term string) bool {
+	if term == "" {
+		return false
+	}
+	if !isASCII(term) {
+		return strings.Contains(text, term)
+	}
+	for from := 0; from <= len(text)-len(term); {
+		i := strings.Index(text[from:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(term)
+		if (start == 0 || !isWordByte(text[start-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		from = start + 1
+	}
+	return false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_'
+}
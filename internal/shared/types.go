@@ -1,11 +1,15 @@
 package shared
 
+import "time"
+
 // 查询 DTO（domain 层复用）
 type ListTasksQuery struct {
 	Page     int    `json:"page"`
 	PageSize int    `json:"page_size"`
 	Status   string `json:"status"`
 	UserID   uint   `json:"user_id"`
+	// Scope 项目范围，nil 表示不限
+	Scope *ProjectScope `json:"scope,omitempty"`
 }
 
 type ListAssetsQuery struct {
@@ -13,4 +17,53 @@ type ListAssetsQuery struct {
 	PageSize int    `json:"page_size"`
 	Format   string `json:"format"`
 	TaskID   string `json:"task_id"`
+	// Quarantined 为 true 时只列出被隔离的素材（复核队列），否则隐藏被隔离素材
+	Quarantined bool `json:"quarantined"`
+	// Tags 按标签名筛选，素材需具备全部标签
+	Tags []string `json:"tags,omitempty"`
+
+	// Query 全文检索：标题、商品名、提示词、CTA、卖点
+	Query       string     `json:"query,omitempty"`
+	Style       string     `json:"style,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	MinScore    *float64   `json:"min_score,omitempty"` // quality_overall
+	MaxScore    *float64   `json:"max_score,omitempty"`
+	// MinConformance/MaxConformance 品牌色符合度（0~1）
+	MinConformance *float64 `json:"min_conformance,omitempty"`
+	MaxConformance *float64 `json:"max_conformance,omitempty"`
+	// ReviewStatus pending_review | approved | rejected
+	ReviewStatus string `json:"review_status,omitempty"`
+	StorageType  string `json:"storage_type,omitempty"`
+	ModelName    string `json:"model_name,omitempty"`
+	InExperiment *bool  `json:"in_experiment,omitempty"`
+	// Sort newest（默认）| score | ctr | conformance
+	Sort string `json:"sort,omitempty"`
+	// Cursor 非空时按游标翻页，忽略 Page
+	Cursor *AssetCursor `json:"cursor,omitempty"`
+	// Scope 项目范围，nil 表示不限
+	Scope *ProjectScope `json:"scope,omitempty"`
+}
+
+// 素材排序方式
+const (
+	AssetSortNewest = "newest"
+	AssetSortScore  = "score"
+	AssetSortCTR    = "ctr"
+	// AssetSortConformance 按品牌色符合度
+	AssetSortConformance = "conformance"
+)
+
+// AssetCursor 素材列表游标：上一页最后一条的排序值与 ID
+type AssetCursor struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	Value     float64   `json:"v,omitempty"`
+	ID        uint      `json:"id"`
+}
+
+// MetricTotals 素材在所有实验中的曝光/点击汇总
+type MetricTotals struct {
+	Impressions int64 `json:"impressions"`
+	Clicks      int64 `json:"clicks"`
 }
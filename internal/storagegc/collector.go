@@ -0,0 +1,194 @@
+package storagegc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+)
+
+// maxReportedKeys 报告中每个后端最多列出的 key 数量
+const maxReportedKeys = 200
+
+// Config 存储 GC 配置
+type Config struct {
+	Interval    time.Duration
+	GracePeriod time.Duration // 对象上传后至少保留的时长，避免误删正在写库的对象
+	DryRun      bool          // 周期任务只报告不删除
+}
+
+// BackendReport 单个存储后端的清理结果
+type BackendReport struct {
+	StorageType models.StorageType `json:"storage_type"`
+	Scanned     int                `json:"scanned"`
+	Referenced  int                `json:"referenced"`
+	Protected   int                `json:"protected"`
+	InGrace     int                `json:"in_grace"`
+	Orphaned    int                `json:"orphaned"`
+	Deleted     int                `json:"deleted"`
+	OrphanBytes int64              `json:"orphan_bytes"`
+	OrphanKeys  []string           `json:"orphan_keys,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Report 一次清理的结果
+type Report struct {
+	StartedAt  time.Time       `json:"started_at"`
+	DurationMs int64           `json:"duration"`
+	DryRun     bool            `json:"dry_run"`
+	Backends   []BackendReport `json:"backends"`
+}
+
+// Collector 删除不再被引用的存储对象
+type Collector struct {
+	cfg      Config
+	store    Store
+	registry *storage.Registry
+	now      func() time.Time
+
+	runMu sync.Mutex
+	mu    sync.Mutex
+	last  *Report
+}
+
+// New 创建 Collector
+func New(cfg Config, store Store, registry *storage.Registry) *Collector {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.GracePeriod <= 0 {
+		cfg.GracePeriod = 72 * time.Hour
+	}
+	return &Collector{
+		cfg:      cfg,
+		store:    store,
+		registry: registry,
+		now:      time.Now,
+	}
+}
+
+// Start 异步启动周期清理
+func (c *Collector) Start() {
+	go func() {
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			c.Sweep(context.Background(), c.cfg.DryRun)
+		}
+	}()
+}
+
+// LastReport 返回最近一次清理结果
+func (c *Collector) LastReport() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// Sweep 扫描所有可枚举的后端，删除（dryRun 时只统计）宽限期外的未引用对象
+func (c *Collector) Sweep(ctx context.Context, dryRun bool) Report {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	start := c.now()
+	report := Report{StartedAt: start, DryRun: dryRun}
+
+	protectedURLs, err := c.store.ProtectedURLs(ctx)
+	if err != nil {
+		// 拿不到保护列表时不能安全删除
+		report.Backends = append(report.Backends, BackendReport{Error: err.Error()})
+	} else {
+		for _, b := range c.registry.All() {
+			lister, ok := b.(storage.Lister)
+			if !ok {
+				continue
+			}
+			report.Backends = append(report.Backends, c.sweepBackend(ctx, b, lister, protectedURLs, start, dryRun))
+		}
+	}
+	report.DurationMs = c.now().Sub(start).Milliseconds()
+
+	for _, br := range report.Backends {
+		if br.Orphaned > 0 || br.Error != "" {
+			log.Printf("[存储GC] %s: 扫描 %d，孤儿 %d，删除 %d，dry_run=%v %s", br.StorageType, br.Scanned, br.Orphaned, br.Deleted, dryRun, br.Error)
+		}
+	}
+
+	c.mu.Lock()
+	c.last = &report
+	c.mu.Unlock()
+	return report
+}
+
+func (c *Collector) sweepBackend(ctx context.Context, b storage.Backend, lister storage.Lister, protectedURLs map[string]bool, now time.Time, dryRun bool) BackendReport {
+	br := BackendReport{StorageType: b.Type()}
+
+	referenced, err := c.store.ReferencedKeys(ctx, b.Type())
+	if err != nil {
+		br.Error = err.Error()
+		return br
+	}
+
+	cutoff := now.Add(-c.cfg.GracePeriod)
+	var orphans []string
+	err = lister.ListObjects(ctx, func(obj storage.ObjectInfo) error {
+		br.Scanned++
+		switch {
+		case referenced[obj.Key]:
+			br.Referenced++
+		case protectedURLs[b.PublicURL(obj.Key)]:
+			br.Protected++
+		case obj.ModTime.After(cutoff):
+			br.InGrace++
+		default:
+			br.Orphaned++
+			br.OrphanBytes += obj.Size
+			orphans = append(orphans, obj.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		br.Error = err.Error()
+		return br
+	}
+
+	for i, key := range orphans {
+		if i < maxReportedKeys {
+			br.OrphanKeys = append(br.OrphanKeys, key)
+		}
+		if dryRun {
+			continue
+		}
+		if err := b.Delete(ctx, key); err != nil {
+			br.Error = err.Error()
+			continue
+		}
+		br.Deleted++
+	}
+	return br
+}
+
+// DeleteAssetObjects 删除素材对应的对象（任务物理删除时调用）
+func (c *Collector) DeleteAssetObjects(ctx context.Context, assets []models.CreativeAsset) error {
+	var errs []error
+	for _, a := range assets {
+		key := ObjectKey(a)
+		if key == "" {
+			continue
+		}
+		b, ok := c.registry.Get(a.StorageType)
+		if !ok {
+			errs = append(errs, fmt.Errorf("storage backend %s not configured for asset %s", a.StorageType, a.UUID))
+			continue
+		}
+		if err := b.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("delete object %s failed: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
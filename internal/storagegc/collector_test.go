@@ -0,0 +1,78 @@
+package storagegc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+)
+
+type fakeStore struct {
+	keys map[string]bool
+	urls map[string]bool
+}
+
+func (s fakeStore) ReferencedKeys(context.Context, models.StorageType) (map[string]bool, error) {
+	return s.keys, nil
+}
+
+func (s fakeStore) ProtectedURLs(context.Context) (map[string]bool, error) {
+	return s.urls, nil
+}
+
+func TestSweepDeletesOnlyOldUnreferencedObjects(t *testing.T) {
+	root := t.TempDir()
+	local := storage.NewLocalBackend(root, "/files", "")
+	old := time.Now().Add(-100 * time.Hour)
+	for _, key := range []string{"2024/01/01/live.png", "2024/01/01/variant.png", "2024/01/01/orphan.png", "2024/01/01/fresh.png"} {
+		if _, err := local.Put(context.Background(), key, []byte("x"), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		if key != "2024/01/01/fresh.png" {
+			if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	store := fakeStore{
+		keys: map[string]bool{"2024/01/01/live.png": true},
+		urls: map[string]bool{local.PublicURL("2024/01/01/variant.png"): true},
+	}
+	c := New(Config{GracePeriod: 72 * time.Hour}, store, storage.NewRegistry(local))
+
+	report := c.Sweep(context.Background(), true)
+	br := report.Backends[0]
+	if br.Scanned != 4 || br.Referenced != 1 || br.Protected != 1 || br.InGrace != 1 || br.Orphaned != 1 || br.Deleted != 0 {
+		t.Fatalf("unexpected dry-run report: %+v", br)
+	}
+	if _, err := os.Stat(filepath.Join(root, "2024/01/01/orphan.png")); err != nil {
+		t.Fatalf("dry run must not delete: %v", err)
+	}
+
+	report = c.Sweep(context.Background(), false)
+	if report.Backends[0].Deleted != 1 {
+		t.Fatalf("expected one deletion, got %+v", report.Backends[0])
+	}
+	if _, err := os.Stat(filepath.Join(root, "2024/01/01/orphan.png")); !os.IsNotExist(err) {
+		t.Fatalf("orphan should be deleted, stat err=%v", err)
+	}
+	if c.LastReport() == nil || c.LastReport().DryRun {
+		t.Fatalf("last report not recorded")
+	}
+}
+
+func TestObjectKeyFallsBackToLegacyQiniuPath(t *testing.T) {
+	a := models.CreativeAsset{StorageType: models.StorageQiniu, OriginalPath: "s3/2024/01/01/a.png"}
+	if got := ObjectKey(a); got != "s3/2024/01/01/a.png" {
+		t.Fatalf("got %q", got)
+	}
+	a = models.CreativeAsset{StorageType: models.StorageProvider, OriginalPath: "https://provider/a.png"}
+	if got := ObjectKey(a); got != "" {
+		t.Fatalf("provider asset should have no key, got %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package storagegc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Store 查询对象引用关系
+type Store interface {
+	// ReferencedKeys 返回指定存储类型下仍被引用的对象 key：
+	// 素材（含已软删除，物理删除前对象仍可恢复或被实验引用）以及上传的商品图
+	ReferencedKeys(ctx context.Context, t models.StorageType) (map[string]bool, error)
+	// ProtectedURLs 返回实验变体快照中的图片 URL
+	ProtectedURLs(ctx context.Context) (map[string]bool, error)
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 基于 gorm 的 Store
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) ReferencedKeys(ctx context.Context, t models.StorageType) (map[string]bool, error) {
+	var assets []models.CreativeAsset
+	err := s.db.WithContext(ctx).Unscoped().
+		Select("id", "storage_type", "storage_key", "original_path").
+		Where("storage_type = ?", t).
+		Find(&assets).Error
+	if err != nil {
+		return nil, fmt.Errorf("query referenced keys failed: %w", err)
+	}
+
+	var uploads []string
+	if err := s.db.WithContext(ctx).Unscoped().Model(&models.UploadedImage{}).
+		Where("storage_type = ?", t).
+		Pluck("storage_key", &uploads).Error; err != nil {
+		return nil, fmt.Errorf("query uploaded image keys failed: %w", err)
+	}
+
+	out := make(map[string]bool, len(assets)+len(uploads))
+	for _, a := range assets {
+		if key := ObjectKey(a); key != "" {
+			out[key] = true
+		}
+	}
+	for _, key := range uploads {
+		out[key] = true
+	}
+	return out, nil
+}
+
+func (s *gormStore) ProtectedURLs(ctx context.Context) (map[string]bool, error) {
+	var urls []string
+	if err := s.db.WithContext(ctx).Model(&models.ExperimentVariant{}).
+		Where("image_url <> ''").
+		Distinct().Pluck("image_url", &urls).Error; err != nil {
+		return nil, fmt.Errorf("query variant urls failed: %w", err)
+	}
+	out := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		out[u] = true
+	}
+	return out, nil
+}
+
+// ObjectKey 返回素材在存储后端中的 key；
+// 早期七牛素材没有 storage_key，key 记录在 original_path 中
+func ObjectKey(a models.CreativeAsset) string {
+	if a.StorageKey != "" {
+		return a.StorageKey
+	}
+	if a.StorageType == models.StorageQiniu && a.OriginalPath != "" && !strings.HasPrefix(a.OriginalPath, "http") {
+		return a.OriginalPath
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 标签管理接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	if service == nil {
+		service = NewService()
+	}
+	return &Handler{service: service}
+}
+
+// Service 暴露服务供其他模块注入
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// TagRequest 创建/更新标签请求
+type TagRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Color    string `json:"color"`
+}
+
+// List 列出标签（?category= 可选）
+func (h *Handler) List(c *gin.Context) {
+	tags, err := h.service.List(c.Request.Context(), c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to list tags: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"tags": tags}))
+}
+
+// Create 创建标签
+func (h *Handler) Create(c *gin.Context) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	tag, err := h.service.Create(c.Request.Context(), Input(req))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(tag))
+}
+
+// Update 修改标签
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid request: "+err.Error()))
+		return
+	}
+	tag, err := h.service.Update(c.Request.Context(), id, Input(req))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(tag))
+}
+
+// Delete 删除标签
+func (h *Handler) Delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared.SuccessResponse(gin.H{"id": id, "status": "deleted"}))
+}
+
+// Recount 重算全部标签的 usage_count
+func (h *Handler) Recount(c *gin.Context) {
+	if err := h.service.RefreshUsage(c.Request.Context(), nil); err != nil {
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to recount tags: "+err.Error()))
+		return
+	}
+	h.List(c)
+}
+
+func parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Invalid tag id"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError 按错误类型映射状态码
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.JSON(http.StatusNotFound, shared.ErrorResponse(404, err.Error()))
+	case errors.Is(err, ErrInvalid):
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, err.Error()))
+	}
+}
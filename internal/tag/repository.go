@@ -0,0 +1,160 @@
+package tag
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 标签仓储
+type Repository interface {
+	List(ctx context.Context, category string) ([]models.Tag, error)
+	GetByID(ctx context.Context, id uint) (*models.Tag, error)
+	GetByName(ctx context.Context, name string) (*models.Tag, error)
+	ListByIDs(ctx context.Context, ids []uint) ([]models.Tag, error)
+	Create(ctx context.Context, tag *models.Tag) error
+	UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error
+	// Delete 物理删除标签及其素材关联
+	Delete(ctx context.Context, id uint) error
+	Attach(ctx context.Context, assetID uint, tagIDs []uint) error
+	Detach(ctx context.Context, assetID uint, tagIDs []uint) error
+	ListForAsset(ctx context.Context, assetID uint) ([]models.Tag, error)
+	// RecountUsage 按未删除素材的关联数重算 usage_count，ids 为空时重算全部
+	RecountUsage(ctx context.Context, ids []uint) error
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) List(ctx context.Context, category string) ([]models.Tag, error) {
+	q := r.db.WithContext(ctx).Model(&models.Tag{})
+	if category != "" {
+		q = q.Where("category = ?", category)
+	}
+	var tags []models.Tag
+	if err := q.Order("usage_count desc, id asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormRepository) GetByID(ctx context.Context, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.WithContext(ctx).First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *gormRepository) GetByName(ctx context.Context, name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *gormRepository) ListByIDs(ctx context.Context, ids []uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("id asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+func (r *gormRepository) UpdateFields(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Tag{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *gormRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM creative_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		// 名称唯一，软删除会阻止同名标签重建
+		return tx.Unscoped().Delete(&models.Tag{}, id).Error
+	})
+}
+
+func (r *gormRepository) Attach(ctx context.Context, assetID uint, tagIDs []uint) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Table("creative_tags").Where("creative_asset_id = ? AND tag_id IN ?", assetID, tagIDs).
+			Pluck("tag_id", &existing).Error; err != nil {
+			return err
+		}
+		skip := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			skip[id] = true
+		}
+		for _, id := range tagIDs {
+			if skip[id] {
+				continue
+			}
+			skip[id] = true
+			if err := tx.Exec("INSERT INTO creative_tags (creative_asset_id, tag_id) VALUES (?, ?)", assetID, id).Error; err != nil {
+				return err
+			}
+		}
+		return recount(tx, tagIDs)
+	})
+}
+
+func (r *gormRepository) Detach(ctx context.Context, assetID uint, tagIDs []uint) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM creative_tags WHERE creative_asset_id = ? AND tag_id IN ?", assetID, tagIDs).Error; err != nil {
+			return err
+		}
+		return recount(tx, tagIDs)
+	})
+}
+
+func (r *gormRepository) ListForAsset(ctx context.Context, assetID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN creative_tags ON creative_tags.tag_id = tags.id").
+		Where("creative_tags.creative_asset_id = ?", assetID).
+		Order("tags.id asc").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormRepository) RecountUsage(ctx context.Context, ids []uint) error {
+	return recount(r.db.WithContext(ctx), ids)
+}
+
+// usageCountSQL 统计标签关联的未删除素材数
+const usageCountSQL = `UPDATE tags SET usage_count = (
+	SELECT COUNT(*) FROM creative_tags
+	JOIN creative_assets ON creative_assets.id = creative_tags.creative_asset_id
+	WHERE creative_tags.tag_id = tags.id AND creative_assets.deleted_at IS NULL
+)`
+
+func recount(db *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return db.Exec(usageCountSQL).Error
+	}
+	return db.Exec(usageCountSQL+" WHERE id IN ?", ids).Error
+}
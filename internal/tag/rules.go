@@ -0,0 +1,68 @@
+package tag
+
+import (
+	"strings"
+
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+// Rule 自动打标规则：style、format 精确匹配，keywords 在标题/商品名/CTA/卖点中匹配（英文按整词，中文按子串），任一命中即打标
+type Rule struct {
+	Tag      string
+	Category string
+	Color    string
+	Styles   []string
+	Formats  []string
+	Keywords []string
+}
+
+// DefaultRules 内置规则，与种子标签（电商、极简风…）对应
+var DefaultRules = []Rule{
+	{Tag: "极简风", Category: "style", Color: "#95E1D3", Styles: []string{"modern", "minimal", "simple"}, Keywords: []string{"极简", "简约"}},
+	{Tag: "活力风", Category: "style", Color: "#F38181", Styles: []string{"bright", "vibrant"}},
+	{Tag: "专业风", Category: "style", Color: "#AA96DA", Styles: []string{"professional", "business"}},
+	{Tag: "优雅风", Category: "style", Color: "#C9B79C", Styles: []string{"elegant", "luxury"}},
+	{Tag: "方图", Category: "format", Color: "#B8B8B8", Formats: []string{"1:1"}},
+	{Tag: "横版", Category: "format", Color: "#B8B8B8", Formats: []string{"16:9", "4:3"}},
+	{Tag: "竖版", Category: "format", Color: "#B8B8B8", Formats: []string{"9:16", "3:4"}},
+	{Tag: "动图", Category: "format", Color: "#B8B8B8", Formats: []string{"gif"}},
+	{Tag: "游戏", Category: "industry", Color: "#4ECDC4", Keywords: []string{"游戏", "手游", "网游", "电竞", "game", "games"}},
+	{Tag: "金融", Category: "industry", Color: "#45B7D1", Keywords: []string{"理财", "基金", "保险", "贷款", "信用卡", "银行", "finance", "insurance", "loan", "loans"}},
+	{Tag: "教育", Category: "industry", Color: "#FFA07A", Keywords: []string{"课程", "教育", "培训", "网课", "考试", "course", "courses", "education"}},
+	{Tag: "电商", Category: "industry", Color: "#FF6B6B", Keywords: []string{"购买", "抢购", "下单", "包邮", "折扣", "优惠", "促销", "秒杀", "shop", "buy", "sale", "order"}},
+}
+
+// Match 返回素材命中的规则
+func Match(asset *models.CreativeAsset, rules []Rule) []Rule {
+	style := strings.ToLower(strings.TrimSpace(asset.Style))
+	format := strings.ToLower(strings.TrimSpace(asset.Format))
+	text := strings.ToLower(strings.Join(append([]string{asset.Title, asset.ProductName, asset.CTAText}, asset.SellingPoints...), " "))
+
+	var matched []Rule
+	for _, r := range rules {
+		if ruleMatches(r, style, format, text) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+func ruleMatches(r Rule, style, format, text string) bool {
+	for _, s := range r.Styles {
+		if style != "" && style == s {
+			return true
+		}
+	}
+	for _, f := range r.Formats {
+		if format == f {
+			return true
+		}
+	}
+	for _, k := range r.Keywords {
+		if shared.ContainsTerm(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
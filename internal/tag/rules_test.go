@@ -0,0 +1,65 @@
+package tag
+
+import (
+	"testing"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+func TestMatch(t *testing.T) {
+	asset := &models.CreativeAsset{
+		Format:        "9:16",
+		Style:         "Modern",
+		Title:         "秋季新款运动鞋",
+		CTAText:       "立即购买",
+		SellingPoints: models.StringArray{"轻量透气"},
+	}
+	got := map[string]bool{}
+	for _, r := range Match(asset, DefaultRules) {
+		got[r.Tag] = true
+	}
+	for _, want := range []string{"极简风", "竖版", "电商"} {
+		if !got[want] {
+			t.Fatalf("missing tag %s, got %v", want, got)
+		}
+	}
+	if got["金融"] || got["方图"] || len(got) != 3 {
+		t.Fatalf("unexpected tags %v", got)
+	}
+}
+
+func TestMatchEnglishKeywordsAsWholeWords(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		want  bool
+	}{
+		{"Red border frame", false},
+		{"Weekend workshop", false},
+		{"Wholesale prices", false},
+		{"Shop now", true},
+		{"Summer sale!", true},
+		{"Order today", true},
+	} {
+		got := false
+		for _, r := range Match(&models.CreativeAsset{Title: tc.title}, DefaultRules) {
+			if r.Tag == "电商" {
+				got = true
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%q: 电商 matched=%v, want %v", tc.title, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validate(Input{Name: ""}, true); err == nil {
+		t.Fatal("expected name required")
+	}
+	if err := validate(Input{Name: "x", Color: "red"}, true); err == nil {
+		t.Fatal("expected color error")
+	}
+	if err := validate(Input{Name: "x", Color: "#FFaa00"}, true); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,264 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound 标签不存在
+	ErrNotFound = errors.New("tag not found")
+	// ErrInvalid 参数不合法（名称为空/过长、颜色格式、重名）
+	ErrInvalid = errors.New("invalid tag")
+)
+
+// CategoryCustom 手动关联时自动创建的标签分类
+const CategoryCustom = "custom"
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Input 创建/更新标签；更新时空字段表示不修改
+type Input struct {
+	Name     string
+	Category string
+	Color    string
+}
+
+// Service 标签管理与自动打标
+type Service struct {
+	repo  Repository
+	rules []Rule
+	audit ports.AuditRecorder
+}
+
+// NewService 使用默认规则创建服务
+func NewService() *Service {
+	return NewServiceWithDeps(NewRepository(database.DB), DefaultRules)
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository, rules []Rule) *Service {
+	return &Service{repo: repo, rules: rules}
+}
+
+// SetAuditRecorder 设置审计日志记录器，记录标签的创建、修改与删除（nil 表示不记录）
+func (s *Service) SetAuditRecorder(r ports.AuditRecorder) {
+	s.audit = r
+}
+
+// recordAudit 记录标签变更；标签为全局资源，不归属项目
+func (s *Service) recordAudit(ctx context.Context, action string, id uint, before, after interface{}) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, shared.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceTag,
+		ResourceID:   strconv.FormatUint(uint64(id), 10),
+		Before:       before,
+		After:        after,
+	})
+}
+
+// List 列出标签，按使用次数倒序
+func (s *Service) List(ctx context.Context, category string) ([]models.Tag, error) {
+	return s.repo.List(ctx, category)
+}
+
+// Create 创建标签
+func (s *Service) Create(ctx context.Context, in Input) (*models.Tag, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if err := validate(in, true); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByName(ctx, in.Name); err == nil {
+		return nil, fmt.Errorf("%w: tag %q already exists", ErrInvalid, in.Name)
+	}
+	tag := &models.Tag{Name: in.Name, Category: strings.TrimSpace(in.Category), Color: in.Color}
+	if err := s.repo.Create(ctx, tag); err != nil {
+		return nil, fmt.Errorf("create tag failed: %w", err)
+	}
+	s.recordAudit(ctx, audit.ActionTagCreate, tag.ID, nil, tag)
+	return tag, nil
+}
+
+// Update 修改标签名称/分类/颜色
+func (s *Service) Update(ctx context.Context, id uint, in Input) (*models.Tag, error) {
+	tag, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	in.Name = strings.TrimSpace(in.Name)
+	if err := validate(in, false); err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if in.Name != "" && in.Name != tag.Name {
+		if other, err := s.repo.GetByName(ctx, in.Name); err == nil && other.ID != id {
+			return nil, fmt.Errorf("%w: tag %q already exists", ErrInvalid, in.Name)
+		}
+		fields["name"] = in.Name
+	}
+	if in.Category != "" {
+		fields["category"] = strings.TrimSpace(in.Category)
+	}
+	if in.Color != "" {
+		fields["color"] = in.Color
+	}
+	if len(fields) > 0 {
+		if err := s.repo.UpdateFields(ctx, id, fields); err != nil {
+			return nil, fmt.Errorf("update tag failed: %w", err)
+		}
+	}
+	updated, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		s.recordAudit(ctx, audit.ActionTagUpdate, id, tag, updated)
+	}
+	return updated, nil
+}
+
+// Delete 删除标签并解除所有素材关联
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	tag, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.recordAudit(ctx, audit.ActionTagDelete, id, tag, nil)
+	return nil
+}
+
+// Attach 为素材关联标签；names 中不存在的标签以 custom 分类创建。返回素材当前全部标签
+func (s *Service) Attach(ctx context.Context, assetID uint, tagIDs []uint, names []string) ([]models.Tag, error) {
+	ids := append([]uint{}, tagIDs...)
+	if len(ids) > 0 {
+		found, err := s.repo.ListByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) != len(uniqueIDs(ids)) {
+			return nil, ErrNotFound
+		}
+	}
+	for _, name := range names {
+		tag, err := s.ensure(ctx, Input{Name: name, Category: CategoryCustom})
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, tag.ID)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: tag_ids or names is required", ErrInvalid)
+	}
+	if err := s.repo.Attach(ctx, assetID, uniqueIDs(ids)); err != nil {
+		return nil, fmt.Errorf("attach tags failed: %w", err)
+	}
+	return s.repo.ListForAsset(ctx, assetID)
+}
+
+// Detach 解除素材与标签的关联，返回素材剩余标签
+func (s *Service) Detach(ctx context.Context, assetID uint, tagIDs []uint) ([]models.Tag, error) {
+	if err := s.repo.Detach(ctx, assetID, tagIDs); err != nil {
+		return nil, fmt.Errorf("detach tags failed: %w", err)
+	}
+	return s.repo.ListForAsset(ctx, assetID)
+}
+
+// AutoTags 按规则计算素材应有的标签（缺失的标签会被创建），供落库前写入 asset.Tags
+func (s *Service) AutoTags(ctx context.Context, asset *models.CreativeAsset) ([]models.Tag, error) {
+	var tags []models.Tag
+	for _, r := range Match(asset, s.rules) {
+		tag, err := s.ensure(ctx, Input{Name: r.Tag, Category: r.Category, Color: r.Color})
+		if err != nil {
+			return tags, err
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+// RefreshUsage 重算标签的 usage_count；tags 为空时重算全部
+func (s *Service) RefreshUsage(ctx context.Context, tags []models.Tag) error {
+	if len(tags) == 0 {
+		return s.repo.RecountUsage(ctx, nil)
+	}
+	ids := make([]uint, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	return s.repo.RecountUsage(ctx, ids)
+}
+
+// ensure 按名称获取标签，不存在时创建
+func (s *Service) ensure(ctx context.Context, in Input) (*models.Tag, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	if tag, err := s.repo.GetByName(ctx, in.Name); err == nil {
+		return tag, nil
+	}
+	if err := validate(in, true); err != nil {
+		return nil, err
+	}
+	tag := &models.Tag{Name: in.Name, Category: in.Category, Color: in.Color}
+	if err := s.repo.Create(ctx, tag); err != nil {
+		// 并发创建同名标签时回读
+		if existing, getErr := s.repo.GetByName(ctx, in.Name); getErr == nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("create tag failed: %w", err)
+	}
+	return tag, nil
+}
+
+func (s *Service) get(ctx context.Context, id uint) (*models.Tag, error) {
+	tag, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return tag, err
+}
+
+func validate(in Input, requireName bool) error {
+	if requireName && in.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if utf8.RuneCountInString(in.Name) > 64 {
+		return fmt.Errorf("%w: name exceeds 64 characters", ErrInvalid)
+	}
+	if utf8.RuneCountInString(in.Category) > 64 {
+		return fmt.Errorf("%w: category exceeds 64 characters", ErrInvalid)
+	}
+	if in.Color != "" && !colorPattern.MatchString(in.Color) {
+		return fmt.Errorf("%w: color must be #RRGGBB", ErrInvalid)
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
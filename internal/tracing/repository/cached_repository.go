@@ -6,6 +6,7 @@ import (
 
 	"ads-creative-gen-platform/internal/infra/cache"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 )
 
 type CachedTraceRepository struct {
@@ -30,14 +31,14 @@ func NewCachedTraceRepository(inner TraceRepository, c cache.DataCache, ttl time
 	}
 }
 
-func (r *CachedTraceRepository) List(page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error) {
-	key := r.keys.TraceList(status, modelName, traceID, productName, page, pageSize)
+func (r *CachedTraceRepository) List(scope *shared.ProjectScope, page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error) {
+	key := r.keys.TraceList(scope, status, modelName, traceID, productName, page, pageSize)
 	var payload struct {
 		Traces []models.ModelTrace
 		Total  int64
 	}
 	if err := r.cache.GetOrLoad(context.Background(), key, r.ttl, func(context.Context) (any, error) {
-		list, total, err := r.inner.List(page, pageSize, status, modelName, traceID, productName)
+		list, total, err := r.inner.List(scope, page, pageSize, status, modelName, traceID, productName)
 		if err != nil {
 			return nil, err
 		}
@@ -102,6 +103,10 @@ func (r *CachedTraceRepository) FailRunningBySource(source, markMessage string)
 	return affected, err
 }
 
+func (r *CachedTraceRepository) RecentDurations(modelName, modelVersion string, limit int) ([]int, error) {
+	return r.inner.RecentDurations(modelName, modelVersion, limit)
+}
+
 func (r *CachedTraceRepository) invalidate(traceID string) {
 	r.cache.DeleteByPrefix(context.Background(), "traces:list:")
 	if traceID != "" {
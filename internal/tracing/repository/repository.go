@@ -2,19 +2,23 @@ package repository
 
 import (
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 	"ads-creative-gen-platform/pkg/database"
 	"fmt"
 	"time"
 )
 
 type TraceRepository interface {
-	List(page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error)
+	// List 按项目范围与条件分页查询，scope 为 nil 表示不限
+	List(scope *shared.ProjectScope, page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error)
 	Detail(traceID string) (*models.ModelTrace, error)
 	CreateTrace(trace *models.ModelTrace) error
 	UpdateTrace(traceID string, updates map[string]interface{}) error
 	AddStep(step *models.ModelTraceStep) error
 	RecoverStuckRunning(maxAge time.Duration, markMessage string) (int64, error)
 	FailRunningBySource(source, markMessage string) (int64, error)
+	// RecentDurations 最近成功 trace 的耗时（毫秒），按开始时间倒序
+	RecentDurations(modelName, modelVersion string, limit int) ([]int, error)
 }
 
 type gormTraceRepository struct{}
@@ -23,8 +27,12 @@ func NewTraceRepository() TraceRepository {
 	return &gormTraceRepository{}
 }
 
-func (r *gormTraceRepository) List(page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error) {
+func (r *gormTraceRepository) List(scope *shared.ProjectScope, page, pageSize int, status, modelName, traceID, productName string) ([]models.ModelTrace, int64, error) {
 	query := database.DB.Model(&models.ModelTrace{})
+	// trace 没有创建人字段，个人空间可见全部未归属项目的 trace
+	if cond, args := scope.Condition("project_id", ""); cond != "" {
+		query = query.Where(cond, args...)
+	}
 	if status != "" {
 		query = query.Where("status = ?", status)
 	}
@@ -133,3 +141,16 @@ func (r *gormTraceRepository) FailRunningBySource(source, markMessage string) (i
 	}
 	return affected, nil
 }
+
+func (r *gormTraceRepository) RecentDurations(modelName, modelVersion string, limit int) ([]int, error) {
+	query := database.DB.Model(&models.ModelTrace{}).
+		Where("model_name = ? AND status = ? AND duration_ms > 0", modelName, "success")
+	if modelVersion != "" {
+		query = query.Where("model_version = ?", modelVersion)
+	}
+	var durations []int
+	if err := query.Order("start_at desc").Limit(limit).Pluck("duration_ms", &durations).Error; err != nil {
+		return nil, fmt.Errorf("load trace durations failed: %w", err)
+	}
+	return durations, nil
+}
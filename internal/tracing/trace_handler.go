@@ -31,7 +31,7 @@ func (h *TraceHandler) ListTraces(c *gin.Context) {
 	traceID := c.Query("trace_id")
 	productName := c.Query("product_name")
 
-	result, err := h.service.List(page, pageSize, status, modelName, traceID, productName)
+	result, err := h.service.List(c.Request.Context(), page, pageSize, status, modelName, traceID, productName)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
@@ -53,7 +53,7 @@ func (h *TraceHandler) ListTraces(c *gin.Context) {
 // Trace detail
 func (h *TraceHandler) GetTrace(c *gin.Context) {
 	traceID := c.Param("id")
-	trace, err := h.service.Detail(traceID)
+	trace, err := h.service.Detail(c.Request.Context(), traceID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
@@ -71,7 +71,7 @@ func (h *TraceHandler) GetTrace(c *gin.Context) {
 func (h *TraceHandler) ForceFail(c *gin.Context) {
 	traceID := c.Param("id")
 	reason := c.DefaultPostForm("reason", "manually marked as failed")
-	if err := h.service.ForceFail(traceID, reason); err != nil {
+	if err := h.service.ForceFail(c.Request.Context(), traceID, reason); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "fail trace failed: " + err.Error(),
@@ -1,15 +1,22 @@
 package tracing
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"ads-creative-gen-platform/internal/audit"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
 	"ads-creative-gen-platform/internal/tracing/repository"
 
 	"github.com/google/uuid"
 )
 
+// ErrOutOfScope trace 不属于当前请求的项目
+var ErrOutOfScope = errors.New("trace not in current project")
+
 type TraceListResult struct {
 	Traces   []models.ModelTrace
 	Total    int64
@@ -18,7 +25,13 @@ type TraceListResult struct {
 }
 
 type TraceService struct {
-	repo repository.TraceRepository
+	repo  repository.TraceRepository
+	audit AuditRecorder
+}
+
+// AuditRecorder 审计日志记录器；ports 依赖本包，故在此单独声明，与 ports.AuditRecorder 一致
+type AuditRecorder interface {
+	Record(ctx context.Context, e shared.AuditEvent)
 }
 
 func NewTraceService() *TraceService {
@@ -27,8 +40,13 @@ func NewTraceService() *TraceService {
 	}
 }
 
-// List traces with filters
-func (s *TraceService) List(page, pageSize int, status, modelName, traceID, productName string) (*TraceListResult, error) {
+// SetAuditRecorder 设置审计日志记录器（nil 表示不记录）
+func (s *TraceService) SetAuditRecorder(r AuditRecorder) {
+	s.audit = r
+}
+
+// List traces with filters, scoped to the request's project
+func (s *TraceService) List(ctx context.Context, page, pageSize int, status, modelName, traceID, productName string) (*TraceListResult, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -36,7 +54,7 @@ func (s *TraceService) List(page, pageSize int, status, modelName, traceID, prod
 		pageSize = 20
 	}
 
-	traces, total, err := s.repo.List(page, pageSize, status, modelName, traceID, productName)
+	traces, total, err := s.repo.List(shared.ScopeFrom(ctx), page, pageSize, status, modelName, traceID, productName)
 	if err != nil {
 		return nil, err
 	}
@@ -48,19 +66,27 @@ func (s *TraceService) List(page, pageSize int, status, modelName, traceID, prod
 	}, nil
 }
 
-// Detail returns trace with steps
-func (s *TraceService) Detail(traceID string) (*models.ModelTrace, error) {
-	return s.repo.Detail(traceID)
+// Detail returns trace with steps; traces outside the request's project are reported as missing
+func (s *TraceService) Detail(ctx context.Context, traceID string) (*models.ModelTrace, error) {
+	trace, err := s.repo.Detail(traceID)
+	if err != nil {
+		return nil, err
+	}
+	if !shared.ScopeFrom(ctx).AllowsProject(trace.ProjectID) {
+		return nil, ErrOutOfScope
+	}
+	return trace, nil
 }
 
-// StartTrace 创建主 trace
-func (s *TraceService) StartTrace(modelName, modelVersion, source, inputPreview, productName string) (string, error) {
+// StartTrace 创建主 trace，projectID 为调用所属项目（可为空）
+func (s *TraceService) StartTrace(projectID *uint, modelName, modelVersion, source, inputPreview, productName string) (string, error) {
 	traceID := uuid.New().String()
 	mt := models.ModelTrace{
 		TraceID:       traceID,
 		ModelName:     modelName,
 		ModelVersion:  modelVersion,
 		ProductName:   productName,
+		ProjectID:     projectID,
 		Status:        "running",
 		StartAt:       time.Now(),
 		Source:        source,
@@ -95,6 +121,11 @@ func (s *TraceService) FinishTrace(traceID, status, outputPreview, errorMessage
 	return nil
 }
 
+// RecentLatencies 模型最近成功调用的耗时（毫秒），用于预估生成时长
+func (s *TraceService) RecentLatencies(_ context.Context, modelName, modelVersion string, limit int) ([]int, error) {
+	return s.repo.RecentDurations(modelName, modelVersion, limit)
+}
+
 // AddStep 写入步骤
 func (s *TraceService) AddStep(traceID, stepName, component, status, inputPreview, outputPreview, errorMessage string, startAt, endAt time.Time) error {
 	step := models.ModelTraceStep{
@@ -118,8 +149,8 @@ func (s *TraceService) RecoverStuckRunning(maxAge time.Duration, markMessage str
 }
 
 // ForceFail 手动标记 trace 为失败
-func (s *TraceService) ForceFail(traceID, reason string) error {
-	trace, err := s.repo.Detail(traceID)
+func (s *TraceService) ForceFail(ctx context.Context, traceID, reason string) error {
+	trace, err := s.Detail(ctx, traceID)
 	if err != nil || trace == nil {
 		return fmt.Errorf("trace not found: %w", err)
 	}
@@ -132,7 +163,20 @@ func (s *TraceService) ForceFail(traceID, reason string) error {
 		"error_message": reason,
 		"updated_at":    now,
 	}
-	return s.repo.UpdateTrace(traceID, updates)
+	if err := s.repo.UpdateTrace(traceID, updates); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		s.audit.Record(ctx, shared.AuditEvent{
+			Action:       audit.ActionTraceForceFail,
+			ResourceType: models.AuditResourceTrace,
+			ResourceID:   traceID,
+			ProjectID:    trace.ProjectID,
+			Before:       map[string]interface{}{"status": trace.Status, "error_message": trace.ErrorMessage},
+			After:        map[string]interface{}{"status": "failed", "error_message": reason},
+		})
+	}
+	return nil
 }
 
 // FailRunningBySource 将指定 source 的 running trace 标记失败
@@ -3,6 +3,8 @@ package tracing
 import (
 	"context"
 	"time"
+
+	"ads-creative-gen-platform/internal/shared"
 )
 
 type traceKeyType string
@@ -21,9 +23,9 @@ func NewTracer() *Tracer {
 	return &Tracer{svc: NewTraceService()}
 }
 
-// Start 开始一条 trace，返回携带 trace_id 的 context。
+// Start 开始一条 trace（归属 context 中的项目），返回携带 trace_id 的 context。
 func (t *Tracer) Start(ctx context.Context, modelName, modelVersion, source, inputPreview, productName string) (context.Context, string) {
-	traceID, err := t.svc.StartTrace(modelName, modelVersion, source, inputPreview, productName)
+	traceID, err := t.svc.StartTrace(shared.ProjectIDFrom(ctx), modelName, modelVersion, source, inputPreview, productName)
 	if err != nil {
 		return ctx, ""
 	}
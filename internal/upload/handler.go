@@ -0,0 +1,88 @@
+package upload
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"ads-creative-gen-platform/internal/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 上传接口
+type Handler struct {
+	service *Service
+}
+
+// NewHandler 创建处理器
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Service 暴露 service 供其他模块解析上传 ID
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// UploadProductImageData 上传结果
+type UploadProductImageData struct {
+	AssetID     string `json:"asset_id"`
+	URL         string `json:"url"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// UploadProductImage 上传商品图（multipart 字段名 file）
+func (h *Handler) UploadProductImage(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "file is required: "+err.Error()))
+		return
+	}
+	if max := h.service.MaxBytes(); max > 0 && fileHeader.Size > max {
+		c.JSON(http.StatusRequestEntityTooLarge, shared.ErrorResponse(413, "File too large"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to read file: "+err.Error()))
+		return
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if max := h.service.MaxBytes(); max > 0 {
+		reader = io.LimitReader(file, max+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, "Failed to read file: "+err.Error()))
+		return
+	}
+
+	img, err := h.service.UploadProductImage(c.Request.Context(), fileHeader.Filename, fileHeader.Header.Get("Content-Type"), data, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidImage) {
+			c.JSON(http.StatusBadRequest, shared.ErrorResponse(400, err.Error()))
+			return
+		}
+		if errors.Is(err, ErrNoPublicURL) {
+			c.JSON(http.StatusServiceUnavailable, shared.ErrorResponse(503, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, shared.ErrorResponse(500, "Failed to upload image: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, shared.SuccessResponse(UploadProductImageData{
+		AssetID:     img.UUID,
+		URL:         img.PublicURL,
+		Width:       img.Width,
+		Height:      img.Height,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+	}))
+}
@@ -0,0 +1,36 @@
+package upload
+
+import (
+	"context"
+
+	"ads-creative-gen-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Repository 上传图片仓储
+type Repository interface {
+	Create(ctx context.Context, img *models.UploadedImage) error
+	GetByUUID(ctx context.Context, uuid string) (*models.UploadedImage, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建仓储
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, img *models.UploadedImage) error {
+	return r.db.WithContext(ctx).Create(img).Error
+}
+
+func (r *gormRepository) GetByUUID(ctx context.Context, uuid string) (*models.UploadedImage, error) {
+	var img models.UploadedImage
+	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&img).Error; err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
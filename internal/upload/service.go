@@ -0,0 +1,175 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/infra/imaging"
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidImage 上传内容不符合要求（类型/大小/尺寸）
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrNoPublicURL 存储后端只能生成相对 URL，模型服务无法下载商品图
+	ErrNoPublicURL = errors.New("storage backend has no absolute public URL, set STORAGE_LOCAL_PUBLIC_URL")
+)
+
+// allowedTypes 允许上传的 MIME 类型
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Service 商品图上传服务
+type Service struct {
+	repo    Repository
+	backend ports.StorageBackend
+	limits  config.Upload
+}
+
+// ConfiguredLimits 全局配置的上传限制，未加载配置时使用默认值
+func ConfiguredLimits() config.Upload {
+	if config.UploadConfig != nil {
+		return *config.UploadConfig
+	}
+	return config.Upload{MaxBytes: 10 << 20, MinDimension: 64, MaxSourceDimension: 8000, MaxDimension: 2048}
+}
+
+// NewService 按全局配置创建服务，写入 storageRegistry 的主后端
+func NewService(storageRegistry *storage.Registry) *Service {
+	return NewServiceWithDeps(NewRepository(database.DB), storageRegistry.Primary(), ConfiguredLimits())
+}
+
+// NewServiceWithDeps 支持依赖注入
+func NewServiceWithDeps(repo Repository, backend ports.StorageBackend, limits config.Upload) *Service {
+	return &Service{repo: repo, backend: backend, limits: limits}
+}
+
+// MaxBytes 单文件大小上限
+func (s *Service) MaxBytes() int64 {
+	return s.limits.MaxBytes
+}
+
+// UploadProductImage 校验、归一化（EXIF 方向、最长边）并存储商品图
+func (s *Service) UploadProductImage(ctx context.Context, fileName, declaredType string, data []byte, userID *uint) (*models.UploadedImage, error) {
+	if userID == nil {
+		userID = shared.UserIDPtrFrom(ctx)
+	}
+	if _, err := CheckImage(data, s.limits); err != nil {
+		return nil, err
+	}
+
+	sniffed := http.DetectContentType(data)
+	if !allowedTypes[sniffed] {
+		return nil, fmt.Errorf("%w: unsupported content type %s", ErrInvalidImage, sniffed)
+	}
+	declared := strings.TrimSpace(strings.Split(declaredType, ";")[0])
+	if declared != "" && declared != "application/octet-stream" && declared != sniffed {
+		return nil, fmt.Errorf("%w: declared type %s does not match content %s", ErrInvalidImage, declared, sniffed)
+	}
+
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	img = imaging.Fit(img, s.limits.MaxDimension)
+	encoded, contentType, ext, err := imaging.Encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.backend == nil {
+		return nil, errors.New("storage backend not configured")
+	}
+	id := uuid.New().String()
+	key := s.backend.GenerateKey("product_" + id + ext)
+	// 商品图 URL 会交给模型服务下载，相对路径无法访问
+	if !isAbsoluteURL(s.backend.PublicURL(key)) {
+		return nil, ErrNoPublicURL
+	}
+	publicURL, err := s.backend.Put(ctx, key, encoded, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("store image failed: %w", err)
+	}
+
+	bounds := img.Bounds()
+	record := &models.UploadedImage{
+		UUIDModel:   models.UUIDModel{UUID: id},
+		UserID:      userID,
+		ProjectID:   shared.ProjectIDFrom(ctx),
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(encoded)),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		StorageType: s.backend.Type(),
+		StorageKey:  key,
+		PublicURL:   publicURL,
+	}
+	if err := s.repo.Create(ctx, record); err != nil {
+		_ = s.backend.Delete(ctx, key)
+		return nil, fmt.Errorf("save upload failed: %w", err)
+	}
+	return record, nil
+}
+
+// CheckImage 校验文件大小并只读头部尺寸，避免解码超大图片；供导入等入口复用上传限制
+func CheckImage(data []byte, limits config.Upload) (image.Config, error) {
+	if len(data) == 0 {
+		return image.Config{}, fmt.Errorf("%w: empty file", ErrInvalidImage)
+	}
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return image.Config{}, fmt.Errorf("%w: file exceeds %d bytes", ErrInvalidImage, limits.MaxBytes)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return cfg, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width < limits.MinDimension || cfg.Height < limits.MinDimension {
+		return cfg, fmt.Errorf("%w: image must be at least %dx%d", ErrInvalidImage, limits.MinDimension, limits.MinDimension)
+	}
+	if limits.MaxSourceDimension > 0 && (cfg.Width > limits.MaxSourceDimension || cfg.Height > limits.MaxSourceDimension) {
+		return cfg, fmt.Errorf("%w: image exceeds %dx%d", ErrInvalidImage, limits.MaxSourceDimension, limits.MaxSourceDimension)
+	}
+	return cfg, nil
+}
+
+// ResolveProductImageURL 根据上传 ID 返回图片 URL；上传不在当前项目范围内时按不存在处理
+func (s *Service) ResolveProductImageURL(ctx context.Context, id string) (string, error) {
+	img, err := s.repo.GetByUUID(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("product image %s not found: %w", id, err)
+	}
+	var owner uint
+	if img.UserID != nil {
+		owner = *img.UserID
+	}
+	if !shared.ScopeFrom(ctx).Allows(img.ProjectID, owner) {
+		return "", fmt.Errorf("product image %s not found", id)
+	}
+	if !isAbsoluteURL(img.PublicURL) {
+		return "", fmt.Errorf("product image %s: %w", id, ErrNoPublicURL)
+	}
+	return img.PublicURL, nil
+}
+
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
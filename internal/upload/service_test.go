@@ -0,0 +1,146 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/infra/storage"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/shared"
+)
+
+type memRepo struct {
+	items map[string]*models.UploadedImage
+}
+
+func (r *memRepo) Create(_ context.Context, img *models.UploadedImage) error {
+	r.items[img.UUID] = img
+	return nil
+}
+
+func (r *memRepo) GetByUUID(_ context.Context, id string) (*models.UploadedImage, error) {
+	if img, ok := r.items[id]; ok {
+		return img, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newTestService(t *testing.T) *Service {
+	backend := storage.NewLocalBackend(t.TempDir(), "/files", "http://localhost:4000/files")
+	limits := config.Upload{MaxBytes: 1 << 20, MinDimension: 16, MaxSourceDimension: 4000, MaxDimension: 100}
+	return NewServiceWithDeps(&memRepo{items: map[string]*models.UploadedImage{}}, backend, limits)
+}
+
+func solid(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+	return img
+}
+
+// withOrientation 在 JPEG 的 SOI 之后插入只含方向标记的 EXIF 段
+func withOrientation(jpg []byte, orientation byte) []byte {
+	tiff := []byte{'M', 'M', 0, 0x2A, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, 0, 0, 0, 0, 0, 0}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(payload) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, payload...)
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func TestUploadNormalizesOrientationAndSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, solid(400, 200), nil); err != nil {
+		t.Fatal(err)
+	}
+	svc := newTestService(t)
+
+	img, err := svc.UploadProductImage(context.Background(), "shot.jpg", "image/jpeg", withOrientation(buf.Bytes(), 6), nil)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	// 旋转 90 度后变为竖图，再缩到最长边 100
+	if img.Width != 50 || img.Height != 100 {
+		t.Fatalf("unexpected size %dx%d", img.Width, img.Height)
+	}
+	if img.ContentType != "image/jpeg" || img.StorageType != models.StorageLocal || img.StorageKey == "" {
+		t.Fatalf("unexpected record: %+v", img)
+	}
+
+	url, err := svc.ResolveProductImageURL(context.Background(), img.UUID)
+	if err != nil || url != img.PublicURL {
+		t.Fatalf("resolve failed: %q %v", url, err)
+	}
+}
+
+func TestUploadRejectsInvalidFiles(t *testing.T) {
+	svc := newTestService(t)
+
+	var tiny bytes.Buffer
+	_ = png.Encode(&tiny, solid(8, 8))
+
+	cases := map[string]struct {
+		data     []byte
+		declared string
+	}{
+		"not an image":  {data: []byte("hello world"), declared: "text/plain"},
+		"too small":     {data: tiny.Bytes(), declared: "image/png"},
+		"type mismatch": {data: tiny.Bytes(), declared: "image/jpeg"},
+		"empty":         {data: nil},
+		"over size cap": {data: bytes.Repeat([]byte{0}, 2<<20)},
+	}
+	for name, tc := range cases {
+		if _, err := svc.UploadProductImage(context.Background(), "x", tc.declared, tc.data, nil); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("%s: expected ErrInvalidImage, got %v", name, err)
+		}
+	}
+}
+
+func TestUploadRequiresAbsolutePublicURL(t *testing.T) {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, solid(64, 64))
+	backend := storage.NewLocalBackend(t.TempDir(), "/files", "")
+	svc := NewServiceWithDeps(&memRepo{items: map[string]*models.UploadedImage{}}, backend, config.Upload{MinDimension: 16, MaxDimension: 100})
+
+	if _, err := svc.UploadProductImage(context.Background(), "p.png", "image/png", buf.Bytes(), nil); !errors.Is(err, ErrNoPublicURL) {
+		t.Fatalf("expected ErrNoPublicURL for relative local URLs, got %v", err)
+	}
+}
+
+func TestResolveProductImageChecksScope(t *testing.T) {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, solid(64, 64))
+	svc := newTestService(t)
+
+	project, other := uint(5), uint(6)
+	ctx := shared.WithScope(context.Background(), &shared.ProjectScope{ProjectID: &project, OwnerID: 1})
+	img, err := svc.UploadProductImage(ctx, "p.png", "image/png", buf.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if img.ProjectID == nil || *img.ProjectID != project {
+		t.Fatalf("upload should record the project, got %v", img.ProjectID)
+	}
+	if _, err := svc.ResolveProductImageURL(ctx, img.UUID); err != nil {
+		t.Fatalf("same project should resolve: %v", err)
+	}
+	otherCtx := shared.WithScope(context.Background(), &shared.ProjectScope{ProjectID: &other, OwnerID: 1})
+	if _, err := svc.ResolveProductImageURL(otherCtx, img.UUID); err == nil {
+		t.Fatal("upload from another project must not resolve")
+	}
+	personal := shared.WithScope(context.Background(), &shared.ProjectScope{OwnerID: 2})
+	if _, err := svc.ResolveProductImageURL(personal, img.UUID); err == nil {
+		t.Fatal("project upload must not resolve in a personal space")
+	}
+}
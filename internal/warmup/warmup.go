@@ -11,9 +11,12 @@ import (
 	"sync"
 	"time"
 
+	"ads-creative-gen-platform/internal/audit"
 	"ads-creative-gen-platform/internal/creative/service"
 	expsvc "ads-creative-gen-platform/internal/experiment/service"
 	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/ports"
+	"ads-creative-gen-platform/internal/shared"
 	"ads-creative-gen-platform/internal/tracing"
 	"gorm.io/gorm"
 )
@@ -46,6 +49,7 @@ type Manager struct {
 	mu    sync.Mutex
 	stats Stats
 	limit int
+	audit ports.AuditRecorder
 }
 
 // Config 预热配置
@@ -117,9 +121,22 @@ func (m *Manager) Start() {
 	}()
 }
 
-// RunNow 立即执行一次预热
-func (m *Manager) RunNow() {
-	m.runOnce()
+// SetAuditRecorder 设置审计日志记录器，记录手动触发的预热（nil 表示不记录）
+func (m *Manager) SetAuditRecorder(r ports.AuditRecorder) {
+	m.audit = r
+}
+
+// RunNow 立即执行一次预热，ctx 携带触发者身份用于审计
+func (m *Manager) RunNow(ctx context.Context) {
+	rec := m.runOnce()
+	if m.audit != nil {
+		m.audit.Record(ctx, shared.AuditEvent{
+			Action:       audit.ActionWarmupRun,
+			ResourceType: models.AuditResourceWarmup,
+			ResourceID:   rec.StartedAt.Format(time.RFC3339),
+			After:        rec,
+		})
+	}
 }
 
 // Stats 返回当前状态
@@ -129,7 +146,7 @@ func (m *Manager) Stats() Stats {
 	return m.stats
 }
 
-func (m *Manager) runOnce() {
+func (m *Manager) runOnce() Record {
 	start := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
 	defer cancel()
@@ -185,7 +202,7 @@ func (m *Manager) runOnce() {
 		go func() {
 			defer wg.Done()
 			addAction("cache:tasks")
-			_, _, err := m.target.Creative.ListAllTasks(service.ListTasksQuery{Page: 1, PageSize: 20})
+			_, _, err := m.target.Creative.ListAllTasks(ctx, service.ListTasksQuery{Page: 1, PageSize: 20})
 			if err != nil {
 				addErr("tasks: " + err.Error())
 			}
@@ -194,7 +211,7 @@ func (m *Manager) runOnce() {
 		go func() {
 			defer wg.Done()
 			addAction("cache:assets")
-			_, _, err := m.target.Creative.ListAllAssets(service.ListAssetsQuery{Page: 1, PageSize: 20})
+			_, _, err := m.target.Creative.ListAllAssets(ctx, service.ListAssetsQuery{Page: 1, PageSize: 20})
 			if err != nil {
 				addErr("assets: " + err.Error())
 			}
@@ -206,7 +223,7 @@ func (m *Manager) runOnce() {
 		go func() {
 			defer wg.Done()
 			addAction("cache:experiments")
-			if _, err := m.target.Experiment.ListExperiments(1, 20, ""); err != nil {
+			if _, err := m.target.Experiment.ListExperiments(ctx, 1, 20, ""); err != nil {
 				addErr("experiments: " + err.Error())
 			}
 		}()
@@ -217,7 +234,7 @@ func (m *Manager) runOnce() {
 		go func() {
 			defer wg.Done()
 			addAction("cache:traces")
-			if _, err := m.target.Trace.List(1, 20, "", "", "", ""); err != nil {
+			if _, err := m.target.Trace.List(ctx, 1, 20, "", "", "", ""); err != nil {
 				addErr("traces: " + err.Error())
 			}
 		}()
@@ -238,6 +255,7 @@ func (m *Manager) runOnce() {
 	} else {
 		log.Printf("warmup failed in %dms, errors: %v", record.DurationMs, errs)
 	}
+	return record
 }
 
 func (m *Manager) saveRecord(rec Record) {
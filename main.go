@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -8,10 +9,23 @@ import (
 	"time"
 
 	"ads-creative-gen-platform/config"
+	"ads-creative-gen-platform/internal/audit"
+	"ads-creative-gen-platform/internal/auth"
+	"ads-creative-gen-platform/internal/batch"
+	"ads-creative-gen-platform/internal/cost"
 	creativehandler "ads-creative-gen-platform/internal/creative/handler"
 	experimenthandler "ads-creative-gen-platform/internal/experiment/handler"
+	"ads-creative-gen-platform/internal/infra/storage"
 	"ads-creative-gen-platform/internal/middleware"
+	"ads-creative-gen-platform/internal/models"
+	"ads-creative-gen-platform/internal/project"
+	"ads-creative-gen-platform/internal/quota"
+	"ads-creative-gen-platform/internal/reupload"
+	"ads-creative-gen-platform/internal/shared"
+	"ads-creative-gen-platform/internal/storagegc"
+	"ads-creative-gen-platform/internal/tag"
 	"ads-creative-gen-platform/internal/tracing"
+	"ads-creative-gen-platform/internal/upload"
 	"ads-creative-gen-platform/internal/warmup"
 	"ads-creative-gen-platform/pkg/database"
 
@@ -27,6 +41,46 @@ func getWarmupTimeout() time.Duration {
 	return 10 * time.Second
 }
 
+// apiKeyScopes API Key 可访问的路由及所需 scope；未列出的路由（认证、Key 管理）不接受 API Key
+var apiKeyScopes = []middleware.ScopeRule{
+	{Prefix: "/copywriting", Read: auth.ScopeCreativeRead, Write: auth.ScopeCreativeWrite},
+	{Prefix: "/creative", Read: auth.ScopeCreativeRead, Write: auth.ScopeCreativeWrite},
+	{Prefix: "/uploads", Write: auth.ScopeCreativeWrite},
+	{Prefix: "/tags", Read: auth.ScopeCreativeRead, Write: auth.ScopeCreativeWrite},
+	{Prefix: "/projects", Read: auth.ScopeProjectsRead, Write: auth.ScopeProjectsWrite},
+	{Prefix: "/experiments", Read: auth.ScopeExperimentRead, Write: auth.ScopeExperimentWrite},
+	{Prefix: "/model_traces", Read: auth.ScopeTracesRead, Write: auth.ScopeTracesWrite},
+	{Prefix: "/warmup", Read: auth.ScopeOps, Write: auth.ScopeOps},
+	{Prefix: "/storage", Read: auth.ScopeOps, Write: auth.ScopeOps},
+	{Prefix: "/audit", Read: auth.ScopeAuditRead},
+	{Prefix: "/quota", Read: auth.ScopeCreativeRead},
+	{Prefix: "/costs", Read: auth.ScopeProjectsRead},
+	{Prefix: "/batches", Read: auth.ScopeCreativeRead, Write: auth.ScopeCreativeWrite},
+}
+
+// projectRoles 项目内写操作所需的最低角色：viewer 只读，member 可创建内容，admin 管理实验与 trace
+var projectRoles = []middleware.ProjectRoleRule{
+	{Prefix: "/copywriting", Write: shared.ProjectRoleMember},
+	{Prefix: "/creative/reviews", Write: shared.ProjectRoleAdmin},
+	{Prefix: "/creative/assets/:id/review", Write: shared.ProjectRoleAdmin},
+	{Prefix: "/creative/assets/:id/moderation", Write: shared.ProjectRoleAdmin},
+	{Prefix: "/creative", Write: shared.ProjectRoleMember},
+	{Prefix: "/uploads", Write: shared.ProjectRoleMember},
+	{Prefix: "/tags", Write: shared.ProjectRoleMember},
+	{Prefix: "/batches", Write: shared.ProjectRoleMember},
+	{Prefix: "/experiments", Write: shared.ProjectRoleAdmin},
+	{Prefix: "/model_traces", Write: shared.ProjectRoleAdmin},
+}
+
+// authMiddleware 按配置选择令牌校验或匿名身份
+func authMiddleware(svc *auth.Service) gin.HandlerFunc {
+	if !svc.Enabled() {
+		fmt.Println("⚠ AUTH_ENABLED=false: API requests run as the default user")
+		return middleware.Anonymous(svc.AnonymousPrincipal)
+	}
+	return middleware.RequireAuth(svc)
+}
+
 func main() {
 	// 加载配置
 	config.LoadConfig()
@@ -50,11 +104,60 @@ func main() {
 
 	// 添加CORS中间件
 	r.Use(middleware.CORSMiddleware())
+	// 请求 ID 与来源信息，供审计日志使用
+	r.Use(middleware.RequestMeta())
 
 	// 创建处理器
-	creativeHandler := creativehandler.NewCreativeHandler()
+	// 存储后端注册表：所有模块共享同一实例
+	storageRegistry := storage.NewConfiguredRegistry(config.StorageConfig)
+	creativeHandler := creativehandler.NewCreativeHandler(storageRegistry)
+	if config.AuthConfig != nil {
+		creativeHandler.SetPreviewSecret([]byte(config.AuthConfig.JWTSecret))
+	}
 	experimentHandler := experimenthandler.NewExperimentHandler()
 	traceHandler := tracing.NewTraceHandler()
+	uploadHandler := upload.NewHandler(upload.NewService(storageRegistry))
+	creativeHandler.Service().SetProductImageResolver(uploadHandler.Service())
+	tagHandler := tag.NewHandler(nil)
+	projectHandler := project.NewHandler(nil)
+	authHandler := auth.NewHandler(nil)
+	authHandler.Service().SetProjectLookup(projectHandler.Service())
+	projectHandler.Service().SetConformanceRefresher(creativeHandler.Service())
+
+	// 审计日志：记录任务、素材审核、实验、trace、项目、API Key、标签与预热等变更操作
+	auditHandler := audit.NewHandler(nil)
+	creativeHandler.Service().SetAuditRecorder(auditHandler.Service())
+	creativeHandler.CopywritingService().SetAuditRecorder(auditHandler.Service())
+	experimentHandler.Service().SetAuditRecorder(auditHandler.Service())
+	traceHandler.Service().SetAuditRecorder(auditHandler.Service())
+	projectHandler.Service().SetAuditRecorder(auditHandler.Service())
+	authHandler.Service().SetAuditRecorder(auditHandler.Service())
+	tagHandler.Service().SetAuditRecorder(auditHandler.Service())
+	startAuditPurger(auditHandler.Service())
+
+	// 配额：按用户与项目限制每日 / 每月的任务数、图片数与 LLM 调用次数
+	quotaHandler := quota.NewHandler(nil)
+	creativeHandler.Service().SetQuotaGuard(quotaHandler.Service())
+	creativeHandler.CopywritingService().SetQuotaGuard(quotaHandler.Service())
+	// 成本核算：记录模型调用用量，项目预算用尽后拒绝新任务
+	costHandler := cost.NewHandler(nil)
+	creativeHandler.Service().SetCostRecorder(costHandler.Service())
+	creativeHandler.Service().SetBudgetGuard(costHandler.Service())
+	creativeHandler.Service().SetCostEstimator(costHandler.Service())
+	creativeHandler.CopywritingService().SetCostRecorder(costHandler.Service())
+	creativeHandler.CopywritingService().SetBudgetGuard(costHandler.Service())
+	// 批量生成：按商品列表逐个创建任务，可自动生成文案
+	batchHandler := batch.NewHandler(nil)
+	batchHandler.Service().SetGenerator(creativeHandler.Service())
+	batchHandler.Service().SetCopywriter(creativeHandler.CopywritingService())
+	// 重启前未提交完的批量继续提交
+	if n, err := batchHandler.Service().ResumeSubmissions(context.Background()); err != nil {
+		fmt.Printf("resume batches failed: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("resumed %d interrupted batches\n", n)
+	}
+	// 限流：按 API Key / 用户 / IP 的令牌桶
+	limiter := middleware.NewRateLimiter(config.QuotaConfig.RateLimitRPS, config.QuotaConfig.RateLimitBurst)
 
 	// 启动预热任务：保持 DB / 缓存温热
 	var sqlDB *sql.DB
@@ -74,8 +177,12 @@ func main() {
 			Trace:      traceHandler.Service(),
 		},
 	)
+	warmupManager.SetAuditRecorder(auditHandler.Service())
 	warmupManager.Start()
 	startTraceSweeper(traceHandler.Service())
+	reuploadManager := newReuploadManager(storageRegistry)
+	gcCollector := newStorageCollector(storageRegistry)
+	creativeHandler.Service().SetObjectCleaner(gcCollector)
 
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
@@ -85,46 +192,141 @@ func main() {
 		})
 	})
 
-	// API v1
-	v1 := r.Group("/api/v1")
+	// API v1：无需登录的接口
+	public := r.Group("/api/v1")
 	{
-		v1.GET("/ping", func(c *gin.Context) {
+		public.GET("/ping", func(c *gin.Context) {
 			c.JSON(200, gin.H{
 				"message": "pong",
 			})
 		})
+		public.POST("/auth/login", middleware.RateLimit(limiter), authHandler.Login)
+		public.POST("/auth/refresh", middleware.RateLimit(limiter), authHandler.Refresh)
+
+		// 投放端埋点（experiment-widget.js）不携带令牌；
+		// 服务端调用携带 API Key 时需要 experiment:track
+		track := public.Group("", middleware.OptionalAuth(authHandler.Service()), middleware.RequireScope(auth.ScopeExperimentTrack))
+		track.GET("/experiments/:id/assign", experimentHandler.Assign)
+		track.POST("/experiments/:id/hit", experimentHandler.Hit)
+		track.POST("/experiments/:id/click", experimentHandler.Click)
+		// HTML5 预览：由 POST /creative/assets/:id/html5/preview 签发的短期令牌授权
+		public.GET("/creative/html5-preview/:token/*file", creativeHandler.PreviewHTML5)
+	}
+
+	// API v1：需要登录（AUTH_ENABLED=false 时以默认用户身份执行）
+	v1 := r.Group("/api/v1", authMiddleware(authHandler.Service()), middleware.RateLimit(limiter), middleware.EnforceScopes("/api/v1", apiKeyScopes))
+	adminOnly := middleware.RequireRole(string(models.RoleAdmin))
+	{
+		v1.GET("/auth/me", authHandler.Me)
+		v1.POST("/auth/logout", authHandler.Logout)
+		v1.POST("/auth/password", authHandler.ChangePassword)
+
+		// API Key 管理（仅登录用户，API Key 不能管理 Key）
+		v1.GET("/api-keys/scopes", authHandler.ListScopes)
+		v1.GET("/api-keys", authHandler.ListAPIKeys)
+		v1.POST("/api-keys", authHandler.CreateAPIKey)
+		v1.POST("/api-keys/:id/rotate", authHandler.RotateAPIKey)
+		v1.DELETE("/api-keys/:id", authHandler.RevokeAPIKey)
+
+		// 项目与成员管理（按路径中的项目校验角色）
+		v1.GET("/projects", projectHandler.ListProjects)
+		v1.POST("/projects", projectHandler.CreateProject)
+		v1.GET("/projects/:id", projectHandler.GetProject)
+		v1.PATCH("/projects/:id", projectHandler.UpdateProject)
+		v1.DELETE("/projects/:id", projectHandler.ArchiveProject)
+		v1.GET("/projects/:id/members", projectHandler.ListMembers)
+		v1.POST("/projects/:id/members", projectHandler.AddMember)
+		v1.PUT("/projects/:id/members/:user_id", projectHandler.UpdateMember)
+		v1.DELETE("/projects/:id/members/:user_id", projectHandler.RemoveMember)
+		// 项目品牌色与审核要求
+		v1.GET("/projects/:id/brand-colors", projectHandler.GetBrandColors)
+		v1.PUT("/projects/:id/brand-colors", projectHandler.SetBrandColors)
+		v1.GET("/projects/:id/settings", projectHandler.GetSettings)
+		v1.PUT("/projects/:id/settings", projectHandler.UpdateSettings)
+		v1.GET("/projects/:id/review-policy", projectHandler.GetReviewPolicy)
+		v1.PUT("/projects/:id/review-policy", projectHandler.SetReviewPolicy)
+
+		// 以下业务数据按 X-Project-ID 指定的项目隔离，并按项目角色限制写操作
+		scoped := v1.Group("", middleware.ProjectScope(projectHandler.Service()), middleware.EnforceProjectRoles("/api/v1", projectRoles))
 
 		// 文案生成/确认
-		v1.POST("/copywriting/generate", creativeHandler.GenerateCopywriting)
-		v1.POST("/copywriting/confirm", creativeHandler.ConfirmCopywriting)
+		scoped.POST("/copywriting/generate", creativeHandler.GenerateCopywriting)
+		scoped.POST("/copywriting/confirm", creativeHandler.ConfirmCopywriting)
 
 		// 创意生成接口
-		v1.POST("/creative/generate", creativeHandler.Generate)
-		v1.POST("/creative/start", creativeHandler.StartCreative)
+		scoped.POST("/creative/generate", creativeHandler.Generate)
+		scoped.POST("/creative/start", creativeHandler.StartCreative)
+		scoped.POST("/creative/plan", creativeHandler.PlanCreative)
+		// 导入外部制作的素材
+		scoped.POST("/creative/import", creativeHandler.ImportCreatives)
+
+		// 商品图上传（multipart）
+		scoped.POST("/uploads/product-image", uploadHandler.UploadProductImage)
 
 		// 查询任务接口
-		v1.GET("/creative/task/:id", creativeHandler.GetTask)
-		v1.DELETE("/creative/task/:id", creativeHandler.DeleteTask)
+		scoped.GET("/creative/task/:id", creativeHandler.GetTask)
+		scoped.DELETE("/creative/task/:id", creativeHandler.DeleteTask)
+		scoped.GET("/creative/task/:id/export", creativeHandler.ExportTask)
+		scoped.GET("/creative/task/:id/export/:platform", creativeHandler.ExportTaskForPlatform)
+		scoped.POST("/creative/task/:id/animation", creativeHandler.CreateAnimation)
+		scoped.GET("/creative/assets/:id/html5", creativeHandler.ExportHTML5)
+		scoped.POST("/creative/assets/:id/html5/preview", creativeHandler.CreateHTML5Preview)
 
 		// 获取所有创意素材接口
-		v1.GET("/creative/assets", creativeHandler.ListAllAssets)
+		scoped.GET("/creative/assets", creativeHandler.ListAllAssets)
+		// 人工复核被内容审核隔离的素材
+		// 解除隔离须由系统管理员处理，避免素材创建者自行放行
+		scoped.POST("/creative/assets/:id/moderation", adminOnly, creativeHandler.ReviewModeration)
+		scoped.GET("/creative/assets/:id/similar", creativeHandler.SimilarAssets)
+		scoped.POST("/creative/assets/features/backfill", creativeHandler.BackfillVisualFeatures)
+		scoped.GET("/creative/assets/:id/lineage", creativeHandler.AssetLineage)
+		scoped.POST("/creative/assets/:id/derive", creativeHandler.DeriveAsset)
+		// 素材人工审核
+		scoped.GET("/creative/reviews", creativeHandler.ReviewQueue)
+		scoped.POST("/creative/reviews/bulk", creativeHandler.BulkReview)
+		scoped.POST("/creative/assets/:id/review", creativeHandler.ReviewAsset)
+		scoped.GET("/creative/assets/:id/comments", creativeHandler.ListReviewComments)
+		scoped.POST("/creative/assets/:id/comments", creativeHandler.AddReviewComment)
+		scoped.POST("/creative/assets/:id/tags", creativeHandler.AttachAssetTags)
+		scoped.DELETE("/creative/assets/:id/tags/:tag_id", creativeHandler.DetachAssetTag)
+
+		// 标签管理
+		scoped.GET("/tags", tagHandler.List)
+		// 标签为全局共享，增删改与重算仅限系统管理员
+		scoped.POST("/tags", adminOnly, tagHandler.Create)
+		scoped.POST("/tags/recount", adminOnly, tagHandler.Recount)
+		scoped.PUT("/tags/:id", adminOnly, tagHandler.Update)
+		scoped.DELETE("/tags/:id", adminOnly, tagHandler.Delete)
 
 		// 获取所有任务接口
-		v1.GET("/creative/tasks", creativeHandler.ListAllTasks)
+		scoped.GET("/creative/tasks", creativeHandler.ListAllTasks)
 
 		// 实验接口
-		v1.POST("/experiments", experimentHandler.CreateExperiment)
-		v1.GET("/experiments", experimentHandler.ListExperiments)
-		v1.POST("/experiments/:id/status", experimentHandler.UpdateStatus)
-		v1.GET("/experiments/:id/assign", experimentHandler.Assign)
-		v1.POST("/experiments/:id/hit", experimentHandler.Hit)
-		v1.POST("/experiments/:id/click", experimentHandler.Click)
-		v1.GET("/experiments/:id/metrics", experimentHandler.Metrics)
+		scoped.POST("/experiments", experimentHandler.CreateExperiment)
+		scoped.GET("/experiments", experimentHandler.ListExperiments)
+		scoped.POST("/experiments/:id/status", experimentHandler.UpdateStatus)
+		scoped.GET("/experiments/:id/metrics", experimentHandler.Metrics)
 
 		// Trace 调用链接口（目前为示例数据）
-		v1.GET("/model_traces", traceHandler.ListTraces)
-		v1.GET("/model_traces/:id", traceHandler.GetTrace)
-		v1.POST("/model_traces/:id/fail", traceHandler.ForceFail)
+		scoped.GET("/model_traces", traceHandler.ListTraces)
+		scoped.GET("/model_traces/:id", traceHandler.GetTrace)
+		scoped.POST("/model_traces/:id/fail", traceHandler.ForceFail)
+
+		// 审计日志（项目内需要 admin 角色）
+		scoped.GET("/audit", auditHandler.List)
+
+		// 配额用量（当前用户与当前项目）
+		scoped.GET("/quota/usage", quotaHandler.Usage)
+
+		// 成本（当前项目或个人空间）
+		scoped.GET("/costs/summary", costHandler.Summary)
+		scoped.GET("/costs/report", costHandler.Report)
+
+		// 批量生成（CSV / JSON 商品列表）
+		scoped.POST("/batches", batchHandler.Create)
+		scoped.GET("/batches", batchHandler.List)
+		scoped.GET("/batches/:id", batchHandler.Get)
+		scoped.GET("/batches/:id/export", batchHandler.Export)
 
 		// 预热状态
 		v1.GET("/warmup/status", func(c *gin.Context) {
@@ -134,13 +336,44 @@ func main() {
 			})
 		})
 		// 手动触发预热
-		v1.POST("/warmup/run", func(c *gin.Context) {
-			warmupManager.RunNow()
+		v1.POST("/warmup/run", adminOnly, func(c *gin.Context) {
+			warmupManager.RunNow(c.Request.Context())
 			c.JSON(200, gin.H{
 				"code": 0,
 				"data": warmupManager.Stats(),
 			})
 		})
+
+		// 临时 URL 补传状态
+		v1.GET("/storage/reupload/status", adminOnly, func(c *gin.Context) {
+			c.JSON(200, gin.H{
+				"code": 0,
+				"data": reuploadManager.Stats(),
+			})
+		})
+		// 手动触发补传
+		v1.POST("/storage/reupload/run", adminOnly, func(c *gin.Context) {
+			reuploadManager.RunNow()
+			c.JSON(200, gin.H{
+				"code": 0,
+				"data": reuploadManager.Stats(),
+			})
+		})
+
+		// 存储 GC：最近一次报告 / 手动触发（默认 dry_run=true 只报告）
+		v1.GET("/storage/gc/status", adminOnly, func(c *gin.Context) {
+			c.JSON(200, gin.H{
+				"code": 0,
+				"data": gcCollector.LastReport(),
+			})
+		})
+		v1.POST("/storage/gc/run", adminOnly, func(c *gin.Context) {
+			dryRun := c.DefaultQuery("dry_run", "true") != "false"
+			c.JSON(200, gin.H{
+				"code": 0,
+				"data": gcCollector.Sweep(c.Request.Context(), dryRun),
+			})
+		})
 	}
 
 	// 静态文件服务 - 托管前端
@@ -159,6 +392,11 @@ func main() {
 		// 未找到文件时回退到 SPA
 		c.File("./web/dist/index.html")
 	})
+	// 本地存储后端的文件托管
+	if local := storageRegistry.Local(); local != nil {
+		r.Static(local.URLPrefix(), local.Root())
+	}
+
 	r.StaticFile("/favicon.ico", "./web/dist/favicon.ico")
 	r.StaticFile("/vite.svg", "./web/dist/vite.svg")
 	r.StaticFile("/experiment-widget.js", "./web/dist/experiment-widget.js")
@@ -179,6 +417,51 @@ func main() {
 	}
 }
 
+// newReuploadManager 创建临时 URL 补传任务，REUPLOAD_ENABLED=false 时只支持手动触发
+func newReuploadManager(storageRegistry *storage.Registry) *reupload.Manager {
+	cfg := config.ReuploadConfig
+	var alerter reupload.Alerter
+	if cfg.AlertWebhookURL != "" {
+		alerter = reupload.NewWebhookAlerter(cfg.AlertWebhookURL)
+	}
+	m := reupload.New(
+		reupload.Config{
+			Interval:       cfg.Interval,
+			BatchSize:      cfg.BatchSize,
+			MaxAttempts:    cfg.MaxAttempts,
+			BaseBackoff:    cfg.BaseBackoff,
+			MaxBackoff:     cfg.MaxBackoff,
+			ProviderURLTTL: cfg.ProviderURLTTL,
+			AlertBefore:    cfg.AlertBefore,
+		},
+		reupload.NewGormStore(database.DB),
+		storageRegistry.Primary(),
+		alerter,
+	)
+	if cfg.Enabled && database.DB != nil {
+		m.Start()
+	}
+	return m
+}
+
+// newStorageCollector 创建存储 GC，STORAGE_GC_ENABLED=false 时只支持手动触发
+func newStorageCollector(storageRegistry *storage.Registry) *storagegc.Collector {
+	cfg := config.StorageConfig
+	c := storagegc.New(
+		storagegc.Config{
+			Interval:    cfg.GCInterval,
+			GracePeriod: cfg.GCGracePeriod,
+			DryRun:      cfg.GCDryRun,
+		},
+		storagegc.NewGormStore(database.DB),
+		storageRegistry,
+	)
+	if cfg.GCEnabled && database.DB != nil {
+		c.Start()
+	}
+	return c
+}
+
 func startTraceSweeper(svc *tracing.TraceService) {
 	timeout := getTraceTimeout()
 	if timeout <= 0 {
@@ -197,6 +480,24 @@ func startTraceSweeper(svc *tracing.TraceService) {
 	}()
 }
 
+// startAuditPurger 定期清理超过保留期的审计日志
+func startAuditPurger(svc *audit.Service) {
+	interval := 6 * time.Hour
+	if config.AuditConfig != nil && config.AuditConfig.PurgeInterval > 0 {
+		interval = config.AuditConfig.PurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if deleted, err := svc.Purge(context.Background()); err != nil {
+				fmt.Printf("audit purge failed: %v\n", err)
+			} else if deleted > 0 {
+				fmt.Printf("audit purge removed %d expired logs\n", deleted)
+			}
+		}
+	}()
+}
+
 func getTraceTimeout() time.Duration {
 	if val := os.Getenv("TRACE_RUNNING_TIMEOUT"); val != "" {
 		if d, err := time.ParseDuration(val); err == nil {
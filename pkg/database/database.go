@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -78,6 +79,8 @@ func MigrateTables() {
 	tables := []interface{}{
 		// 基础表
 		&models.User{},
+		&models.RefreshToken{},
+		&models.APIKey{},
 		&models.Tag{},
 		&models.Project{},
 
@@ -85,6 +88,9 @@ func MigrateTables() {
 		&models.CreativeTask{},
 		&models.CreativeAsset{}, // 这个表包含我们修改的字段
 		&models.CreativeScore{},
+		&models.AssetReviewComment{},
+		&models.AssetReupload{},
+		&models.UploadedImage{},
 		// 实验相关表
 		&models.Experiment{},
 		&models.ExperimentVariant{},
@@ -93,6 +99,15 @@ func MigrateTables() {
 		&models.ModelTrace{},
 		&models.ModelTraceStep{},
 		&models.WarmupRecord{},
+		// 审计日志
+		&models.AuditLog{},
+		// 配额用量
+		&models.UsageCounter{},
+		// 成本核算
+		&models.CostRecord{},
+		// 批量生成
+		&models.Batch{},
+		&models.BatchItem{},
 
 		// 关系表
 		&models.ProjectMember{},
@@ -107,6 +122,8 @@ func MigrateTables() {
 		}
 	}
 
+	ensureAssetSearch()
+
 	log.Println("✓ 数据库迁移完成")
 }
 
@@ -143,14 +160,19 @@ func SeedDefaultData() {
 		return
 	}
 
-	// 创建默认管理员
+	// 创建默认管理员（登录后请通过 /auth/password 修改默认密码）
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("⚠ Failed to hash default admin password: %v", err)
+		return
+	}
 	adminUser := models.User{
 		UUIDModel: models.UUIDModel{
 			UUID: "admin-uuid-0000-0000-000000000001",
 		},
 		Username:     "admin",
 		Email:        "admin@example.com",
-		PasswordHash: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", // admin123
+		PasswordHash: string(passwordHash),
 		Role:         models.RoleAdmin,
 		Status:       models.StatusActive,
 	}
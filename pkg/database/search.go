@@ -0,0 +1,57 @@
+package database
+
+import (
+	"log"
+
+	"ads-creative-gen-platform/internal/models"
+)
+
+// backfillBatchSize 回填检索文本的批大小
+const backfillBatchSize = 500
+
+// ensureAssetSearch 为素材检索文本建立全文索引（PostgreSQL GIN / MySQL ngram FULLTEXT），并回填历史数据
+func ensureAssetSearch() {
+	switch DB.Dialector.Name() {
+	case "postgres":
+		if err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_creative_assets_search ON creative_assets USING GIN (to_tsvector('simple', coalesce(search_text, '')))`).Error; err != nil {
+			log.Printf("✗ 创建素材全文索引失败: %v", err)
+		}
+	case "mysql":
+		var count int64
+		DB.Raw(`SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = 'creative_assets' AND index_name = 'idx_creative_assets_search'`).Scan(&count)
+		if count == 0 {
+			if err := DB.Exec(`ALTER TABLE creative_assets ADD FULLTEXT INDEX idx_creative_assets_search (search_text) WITH PARSER ngram`).Error; err != nil {
+				log.Printf("✗ 创建素材全文索引失败: %v", err)
+			}
+		}
+	}
+
+	var filled int
+	var lastID uint
+	for {
+		var assets []models.CreativeAsset
+		if err := DB.Unscoped().Where("id > ? AND (search_text IS NULL OR search_text = '')", lastID).
+			Order("id asc").Limit(backfillBatchSize).Find(&assets).Error; err != nil {
+			log.Printf("✗ 回填素材检索文本失败: %v", err)
+			return
+		}
+		for _, a := range assets {
+			lastID = a.ID
+			text := a.BuildSearchText()
+			if text == "" {
+				continue
+			}
+			if err := DB.Unscoped().Model(&models.CreativeAsset{}).Where("id = ?", a.ID).UpdateColumn("search_text", text).Error; err != nil {
+				log.Printf("✗ 回填素材 %d 检索文本失败: %v", a.ID, err)
+				continue
+			}
+			filled++
+		}
+		if len(assets) < backfillBatchSize {
+			break
+		}
+	}
+	if filled > 0 {
+		log.Printf("✓ 回填素材检索文本 %d 条", filled)
+	}
+}
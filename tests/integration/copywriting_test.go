@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 
 	"ads-creative-gen-platform/internal/copywriting"
@@ -40,7 +41,7 @@ func TestCopywriting_Confirm(t *testing.T) {
 	}
 
 	svc := copywriting.NewCopywritingServiceWithDeps(nil, crepo.NewTaskRepository(testutil.DB()))
-	updated, err := svc.ConfirmCopywriting(copywriting.ConfirmCopywritingInput{
+	updated, err := svc.ConfirmCopywriting(context.Background(), copywriting.ConfirmCopywritingInput{
 		TaskID:            task.UUID,
 		SelectedCTAIndex:  1,
 		SelectedSPIndexes: []int{0, 1},
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"testing"
 
 	"ads-creative-gen-platform/internal/creative/repository"
@@ -26,9 +27,10 @@ func TestCreative_CreateListDelete(t *testing.T) {
 	taskRepo := repository.NewTaskRepository(testutil.DB())
 	assetRepo := repository.NewAssetRepository(testutil.DB())
 
-	svc := service.NewCreativeServiceWithDeps(taskRepo, assetRepo, nil, func(uint) error { return nil })
+	ctx := context.Background()
+	svc := service.NewCreativeServiceWithDeps(taskRepo, assetRepo, nil, func(uint) error { return nil }, nil)
 
-	task, err := svc.CreateTask(service.CreateTaskInput{
+	task, err := svc.CreateTask(ctx, service.CreateTaskInput{
 		UserID:        user.ID,
 		Title:         "集成测试任务",
 		SellingPoints: []string{"亮点A", "亮点B"},
@@ -40,7 +42,7 @@ func TestCreative_CreateListDelete(t *testing.T) {
 		t.Fatalf("CreateTask 失败: %v", err)
 	}
 
-	tasks, total, err := svc.ListAllTasks(service.ListTasksQuery{Page: 1, PageSize: 10})
+	tasks, total, err := svc.ListAllTasks(ctx, service.ListTasksQuery{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("ListAllTasks 失败: %v", err)
 	}
@@ -48,11 +50,11 @@ func TestCreative_CreateListDelete(t *testing.T) {
 		t.Fatalf("任务列表结果不符合预期: total=%d len=%d data=%#v", total, len(tasks), tasks)
 	}
 
-	if err := svc.DeleteTask(task.UUID); err != nil {
+	if err := svc.DeleteTask(ctx, task.UUID); err != nil {
 		t.Fatalf("DeleteTask 失败: %v", err)
 	}
 
-	tasks, total, err = svc.ListAllTasks(service.ListTasksQuery{Page: 1, PageSize: 10})
+	tasks, total, err = svc.ListAllTasks(ctx, service.ListTasksQuery{Page: 1, PageSize: 10})
 	if err != nil {
 		t.Fatalf("ListAllTasks 失败: %v", err)
 	}
@@ -73,7 +75,7 @@ func TestCreative_StartOnly(t *testing.T) {
 	assetRepo := repository.NewAssetRepository(testutil.DB())
 	var enqueued uint
 
-	svc := service.NewCreativeServiceWithDeps(taskRepo, assetRepo, nil, func(id uint) error { enqueued = id; return nil })
+	svc := service.NewCreativeServiceWithDeps(taskRepo, assetRepo, nil, func(id uint) error { enqueued = id; return nil }, nil)
 
 	user := testutil.CreateTestUser(t)
 
@@ -87,7 +89,7 @@ func TestCreative_StartOnly(t *testing.T) {
 		t.Fatalf("预置任务失败: %v", err)
 	}
 
-	err := svc.StartCreativeGeneration(task.UUID, &service.StartCreativeOptions{Style: "modern", NumVariants: 2})
+	err := svc.StartCreativeGeneration(context.Background(), task.UUID, &service.StartCreativeOptions{Style: "modern", NumVariants: 2})
 	if err != nil {
 		t.Fatalf("StartCreativeGeneration 失败: %v", err)
 	}
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"strconv"
 	"testing"
 
@@ -59,7 +60,8 @@ func TestExperiment_Flow(t *testing.T) {
 	repo := repository.NewExperimentRepository()
 	svc := service.NewExperimentServiceWithRepo(repo)
 
-	exp, err := svc.CreateExperiment(service.CreateExperimentInput{
+	ctx := context.Background()
+	exp, err := svc.CreateExperiment(ctx, service.CreateExperimentInput{
 		Name:        "integration-exp",
 		ProductName: "Test Product",
 		Variants: []service.ExperimentVariantInput{
@@ -71,7 +73,7 @@ func TestExperiment_Flow(t *testing.T) {
 		t.Fatalf("CreateExperiment 失败: %v", err)
 	}
 
-	if err := svc.UpdateStatus(exp.UUID, models.ExpActive); err != nil {
+	if err := svc.UpdateStatus(ctx, exp.UUID, models.ExpActive); err != nil {
 		t.Fatalf("UpdateStatus 失败: %v", err)
 	}
 
@@ -90,7 +92,7 @@ func TestExperiment_Flow(t *testing.T) {
 		t.Fatalf("Click 失败: %v", err)
 	}
 
-	dto, err := svc.GetMetrics(exp.UUID)
+	dto, err := svc.GetMetrics(ctx, exp.UUID)
 	if err != nil {
 		t.Fatalf("GetMetrics 失败: %v", err)
 	}
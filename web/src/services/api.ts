@@ -32,6 +32,20 @@ const apiClient: AxiosInstance = axios.create({
   },
 });
 
+// 登录后由页面写入 localStorage，后端开启认证时随请求携带；
+// current_project 为当前项目 UUID，未选择时访问个人空间
+apiClient.interceptors.request.use(config => {
+  const token = localStorage.getItem('access_token');
+  if (token) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  const project = localStorage.getItem('current_project');
+  if (project) {
+    config.headers['X-Project-ID'] = project;
+  }
+  return config;
+});
+
 apiClient.interceptors.response.use(
   response => response,
   error => {